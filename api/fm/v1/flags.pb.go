@@ -0,0 +1,1016 @@
+// Flag operations of fm-actions as a gRPC service. The operations and their semantics match
+// the REST API of `fm-actions serve`: callers authenticate with an "authorization: Bearer <token>"
+// metadata entry holding a CloudBees API token, which is passed through to the platform.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: fm/v1/flags.proto
+
+package fmv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Environment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	IsDisabled    bool                   `protobuf:"varint,4,opt,name=is_disabled,json=isDisabled,proto3" json:"is_disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Environment) Reset() {
+	*x = Environment{}
+	mi := &file_fm_v1_flags_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Environment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Environment) ProtoMessage() {}
+
+func (x *Environment) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Environment.ProtoReflect.Descriptor instead.
+func (*Environment) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Environment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Environment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Environment) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Environment) GetIsDisabled() bool {
+	if x != nil {
+		return x.IsDisabled
+	}
+	return false
+}
+
+type Flag struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FlagType      string                 `protobuf:"bytes,3,opt,name=flag_type,json=flagType,proto3" json:"flag_type,omitempty"`
+	Variants      []string               `protobuf:"bytes,4,rep,name=variants,proto3" json:"variants,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	IsPermanent   bool                   `protobuf:"varint,6,opt,name=is_permanent,json=isPermanent,proto3" json:"is_permanent,omitempty"`
+	Labels        []string               `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Flag) Reset() {
+	*x = Flag{}
+	mi := &file_fm_v1_flags_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Flag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flag) ProtoMessage() {}
+
+func (x *Flag) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flag.ProtoReflect.Descriptor instead.
+func (*Flag) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Flag) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Flag) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Flag) GetFlagType() string {
+	if x != nil {
+		return x.FlagType
+	}
+	return ""
+}
+
+func (x *Flag) GetVariants() []string {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *Flag) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Flag) GetIsPermanent() bool {
+	if x != nil {
+		return x.IsPermanent
+	}
+	return false
+}
+
+func (x *Flag) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type FlagConfiguration struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// A single value, or a percentage split: [{"option": "true", "percentage": 20}, ...]
+	DefaultValue       *structpb.Value `protobuf:"bytes,2,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	Conditions         *structpb.Value `protobuf:"bytes,3,opt,name=conditions,proto3" json:"conditions,omitempty"`
+	VariantsEnabled    bool            `protobuf:"varint,4,opt,name=variants_enabled,json=variantsEnabled,proto3" json:"variants_enabled,omitempty"`
+	StickinessProperty string          `protobuf:"bytes,5,opt,name=stickiness_property,json=stickinessProperty,proto3" json:"stickiness_property,omitempty"`
+	// Opaque revision for optimistic concurrency (UpdateFlagConfigurationRequest.if_match), returned by
+	// GetFlagConfiguration; empty after an update, until the configuration is read again
+	Revision      string `protobuf:"bytes,6,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlagConfiguration) Reset() {
+	*x = FlagConfiguration{}
+	mi := &file_fm_v1_flags_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlagConfiguration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlagConfiguration) ProtoMessage() {}
+
+func (x *FlagConfiguration) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlagConfiguration.ProtoReflect.Descriptor instead.
+func (*FlagConfiguration) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FlagConfiguration) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FlagConfiguration) GetDefaultValue() *structpb.Value {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return nil
+}
+
+func (x *FlagConfiguration) GetConditions() *structpb.Value {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *FlagConfiguration) GetVariantsEnabled() bool {
+	if x != nil {
+		return x.VariantsEnabled
+	}
+	return false
+}
+
+func (x *FlagConfiguration) GetStickinessProperty() string {
+	if x != nil {
+		return x.StickinessProperty
+	}
+	return ""
+}
+
+func (x *FlagConfiguration) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+type ListEnvironmentsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEnvironmentsRequest) Reset() {
+	*x = ListEnvironmentsRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEnvironmentsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEnvironmentsRequest) ProtoMessage() {}
+
+func (x *ListEnvironmentsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEnvironmentsRequest.ProtoReflect.Descriptor instead.
+func (*ListEnvironmentsRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{3}
+}
+
+type ListEnvironmentsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Environments  []*Environment         `protobuf:"bytes,1,rep,name=environments,proto3" json:"environments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEnvironmentsResponse) Reset() {
+	*x = ListEnvironmentsResponse{}
+	mi := &file_fm_v1_flags_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEnvironmentsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEnvironmentsResponse) ProtoMessage() {}
+
+func (x *ListEnvironmentsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEnvironmentsResponse.ProtoReflect.Descriptor instead.
+func (*ListEnvironmentsResponse) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListEnvironmentsResponse) GetEnvironments() []*Environment {
+	if x != nil {
+		return x.Environments
+	}
+	return nil
+}
+
+type ListFlagsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Application string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	// Only return flags with any of these labels
+	Labels        []string `protobuf:"bytes,2,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlagsRequest) Reset() {
+	*x = ListFlagsRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlagsRequest) ProtoMessage() {}
+
+func (x *ListFlagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlagsRequest.ProtoReflect.Descriptor instead.
+func (*ListFlagsRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListFlagsRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *ListFlagsRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type ListFlagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Flags         []*Flag                `protobuf:"bytes,1,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFlagsResponse) Reset() {
+	*x = ListFlagsResponse{}
+	mi := &file_fm_v1_flags_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFlagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFlagsResponse) ProtoMessage() {}
+
+func (x *ListFlagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFlagsResponse.ProtoReflect.Descriptor instead.
+func (*ListFlagsResponse) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListFlagsResponse) GetFlags() []*Flag {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type GetFlagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Application   string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	Flag          string                 `protobuf:"bytes,2,opt,name=flag,proto3" json:"flag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlagRequest) Reset() {
+	*x = GetFlagRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlagRequest) ProtoMessage() {}
+
+func (x *GetFlagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlagRequest.ProtoReflect.Descriptor instead.
+func (*GetFlagRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetFlagRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *GetFlagRequest) GetFlag() string {
+	if x != nil {
+		return x.Flag
+	}
+	return ""
+}
+
+type CreateFlagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Application   string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	FlagType      string                 `protobuf:"bytes,3,opt,name=flag_type,json=flagType,proto3" json:"flag_type,omitempty"` // Defaults to Boolean
+	Variants      []string               `protobuf:"bytes,4,rep,name=variants,proto3" json:"variants,omitempty"`
+	Description   string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	IsPermanent   bool                   `protobuf:"varint,6,opt,name=is_permanent,json=isPermanent,proto3" json:"is_permanent,omitempty"`
+	Labels        []string               `protobuf:"bytes,7,rep,name=labels,proto3" json:"labels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFlagRequest) Reset() {
+	*x = CreateFlagRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFlagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFlagRequest) ProtoMessage() {}
+
+func (x *CreateFlagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFlagRequest.ProtoReflect.Descriptor instead.
+func (*CreateFlagRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CreateFlagRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *CreateFlagRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateFlagRequest) GetFlagType() string {
+	if x != nil {
+		return x.FlagType
+	}
+	return ""
+}
+
+func (x *CreateFlagRequest) GetVariants() []string {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+func (x *CreateFlagRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateFlagRequest) GetIsPermanent() bool {
+	if x != nil {
+		return x.IsPermanent
+	}
+	return false
+}
+
+func (x *CreateFlagRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+type DeleteFlagRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Application   string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	Flag          string                 `protobuf:"bytes,2,opt,name=flag,proto3" json:"flag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFlagRequest) Reset() {
+	*x = DeleteFlagRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFlagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFlagRequest) ProtoMessage() {}
+
+func (x *DeleteFlagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFlagRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFlagRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteFlagRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *DeleteFlagRequest) GetFlag() string {
+	if x != nil {
+		return x.Flag
+	}
+	return ""
+}
+
+type DeleteFlagResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFlagResponse) Reset() {
+	*x = DeleteFlagResponse{}
+	mi := &file_fm_v1_flags_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFlagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFlagResponse) ProtoMessage() {}
+
+func (x *DeleteFlagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFlagResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFlagResponse) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{10}
+}
+
+type GetFlagConfigurationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Application   string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	Flag          string                 `protobuf:"bytes,2,opt,name=flag,proto3" json:"flag,omitempty"`
+	Environment   string                 `protobuf:"bytes,3,opt,name=environment,proto3" json:"environment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFlagConfigurationRequest) Reset() {
+	*x = GetFlagConfigurationRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFlagConfigurationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFlagConfigurationRequest) ProtoMessage() {}
+
+func (x *GetFlagConfigurationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFlagConfigurationRequest.ProtoReflect.Descriptor instead.
+func (*GetFlagConfigurationRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetFlagConfigurationRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *GetFlagConfigurationRequest) GetFlag() string {
+	if x != nil {
+		return x.Flag
+	}
+	return ""
+}
+
+func (x *GetFlagConfigurationRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+type UpdateFlagConfigurationRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Application        string                 `protobuf:"bytes,1,opt,name=application,proto3" json:"application,omitempty"`
+	Flag               string                 `protobuf:"bytes,2,opt,name=flag,proto3" json:"flag,omitempty"`
+	Environment        string                 `protobuf:"bytes,3,opt,name=environment,proto3" json:"environment,omitempty"`
+	Enabled            *bool                  `protobuf:"varint,4,opt,name=enabled,proto3,oneof" json:"enabled,omitempty"`
+	DefaultValue       *structpb.Value        `protobuf:"bytes,5,opt,name=default_value,json=defaultValue,proto3" json:"default_value,omitempty"`
+	Conditions         *structpb.Value        `protobuf:"bytes,6,opt,name=conditions,proto3" json:"conditions,omitempty"`
+	VariantsEnabled    *bool                  `protobuf:"varint,7,opt,name=variants_enabled,json=variantsEnabled,proto3,oneof" json:"variants_enabled,omitempty"`
+	StickinessProperty *string                `protobuf:"bytes,8,opt,name=stickiness_property,json=stickinessProperty,proto3,oneof" json:"stickiness_property,omitempty"`
+	IfMatch            string                 `protobuf:"bytes,9,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *UpdateFlagConfigurationRequest) Reset() {
+	*x = UpdateFlagConfigurationRequest{}
+	mi := &file_fm_v1_flags_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFlagConfigurationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFlagConfigurationRequest) ProtoMessage() {}
+
+func (x *UpdateFlagConfigurationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_fm_v1_flags_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFlagConfigurationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFlagConfigurationRequest) Descriptor() ([]byte, []int) {
+	return file_fm_v1_flags_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateFlagConfigurationRequest) GetApplication() string {
+	if x != nil {
+		return x.Application
+	}
+	return ""
+}
+
+func (x *UpdateFlagConfigurationRequest) GetFlag() string {
+	if x != nil {
+		return x.Flag
+	}
+	return ""
+}
+
+func (x *UpdateFlagConfigurationRequest) GetEnvironment() string {
+	if x != nil {
+		return x.Environment
+	}
+	return ""
+}
+
+func (x *UpdateFlagConfigurationRequest) GetEnabled() bool {
+	if x != nil && x.Enabled != nil {
+		return *x.Enabled
+	}
+	return false
+}
+
+func (x *UpdateFlagConfigurationRequest) GetDefaultValue() *structpb.Value {
+	if x != nil {
+		return x.DefaultValue
+	}
+	return nil
+}
+
+func (x *UpdateFlagConfigurationRequest) GetConditions() *structpb.Value {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *UpdateFlagConfigurationRequest) GetVariantsEnabled() bool {
+	if x != nil && x.VariantsEnabled != nil {
+		return *x.VariantsEnabled
+	}
+	return false
+}
+
+func (x *UpdateFlagConfigurationRequest) GetStickinessProperty() string {
+	if x != nil && x.StickinessProperty != nil {
+		return *x.StickinessProperty
+	}
+	return ""
+}
+
+func (x *UpdateFlagConfigurationRequest) GetIfMatch() string {
+	if x != nil {
+		return x.IfMatch
+	}
+	return ""
+}
+
+var File_fm_v1_flags_proto protoreflect.FileDescriptor
+
+const file_fm_v1_flags_proto_rawDesc = "" +
+	"\n" +
+	"\x11fm/v1/flags.proto\x12\x05fm.v1\x1a\x1cgoogle/protobuf/struct.proto\"t\n" +
+	"\vEnvironment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1f\n" +
+	"\vis_disabled\x18\x04 \x01(\bR\n" +
+	"isDisabled\"\xc0\x01\n" +
+	"\x04Flag\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tflag_type\x18\x03 \x01(\tR\bflagType\x12\x1a\n" +
+	"\bvariants\x18\x04 \x03(\tR\bvariants\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12!\n" +
+	"\fis_permanent\x18\x06 \x01(\bR\visPermanent\x12\x16\n" +
+	"\x06labels\x18\a \x03(\tR\x06labels\"\x9a\x02\n" +
+	"\x11FlagConfiguration\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12;\n" +
+	"\rdefault_value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\fdefaultValue\x126\n" +
+	"\n" +
+	"conditions\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\n" +
+	"conditions\x12)\n" +
+	"\x10variants_enabled\x18\x04 \x01(\bR\x0fvariantsEnabled\x12/\n" +
+	"\x13stickiness_property\x18\x05 \x01(\tR\x12stickinessProperty\x12\x1a\n" +
+	"\brevision\x18\x06 \x01(\tR\brevision\"\x19\n" +
+	"\x17ListEnvironmentsRequest\"R\n" +
+	"\x18ListEnvironmentsResponse\x126\n" +
+	"\fenvironments\x18\x01 \x03(\v2\x12.fm.v1.EnvironmentR\fenvironments\"L\n" +
+	"\x10ListFlagsRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x16\n" +
+	"\x06labels\x18\x02 \x03(\tR\x06labels\"6\n" +
+	"\x11ListFlagsResponse\x12!\n" +
+	"\x05flags\x18\x01 \x03(\v2\v.fm.v1.FlagR\x05flags\"F\n" +
+	"\x0eGetFlagRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x12\n" +
+	"\x04flag\x18\x02 \x01(\tR\x04flag\"\xdf\x01\n" +
+	"\x11CreateFlagRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1b\n" +
+	"\tflag_type\x18\x03 \x01(\tR\bflagType\x12\x1a\n" +
+	"\bvariants\x18\x04 \x03(\tR\bvariants\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12!\n" +
+	"\fis_permanent\x18\x06 \x01(\bR\visPermanent\x12\x16\n" +
+	"\x06labels\x18\a \x03(\tR\x06labels\"I\n" +
+	"\x11DeleteFlagRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x12\n" +
+	"\x04flag\x18\x02 \x01(\tR\x04flag\"\x14\n" +
+	"\x12DeleteFlagResponse\"u\n" +
+	"\x1bGetFlagConfigurationRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x12\n" +
+	"\x04flag\x18\x02 \x01(\tR\x04flag\x12 \n" +
+	"\venvironment\x18\x03 \x01(\tR\venvironment\"\xc6\x03\n" +
+	"\x1eUpdateFlagConfigurationRequest\x12 \n" +
+	"\vapplication\x18\x01 \x01(\tR\vapplication\x12\x12\n" +
+	"\x04flag\x18\x02 \x01(\tR\x04flag\x12 \n" +
+	"\venvironment\x18\x03 \x01(\tR\venvironment\x12\x1d\n" +
+	"\aenabled\x18\x04 \x01(\bH\x00R\aenabled\x88\x01\x01\x12;\n" +
+	"\rdefault_value\x18\x05 \x01(\v2\x16.google.protobuf.ValueR\fdefaultValue\x126\n" +
+	"\n" +
+	"conditions\x18\x06 \x01(\v2\x16.google.protobuf.ValueR\n" +
+	"conditions\x12.\n" +
+	"\x10variants_enabled\x18\a \x01(\bH\x01R\x0fvariantsEnabled\x88\x01\x01\x124\n" +
+	"\x13stickiness_property\x18\b \x01(\tH\x02R\x12stickinessProperty\x88\x01\x01\x12\x19\n" +
+	"\bif_match\x18\t \x01(\tR\aifMatchB\n" +
+	"\n" +
+	"\b_enabledB\x13\n" +
+	"\x11_variants_enabledB\x16\n" +
+	"\x14_stickiness_property2\xfb\x03\n" +
+	"\vFlagService\x12S\n" +
+	"\x10ListEnvironments\x12\x1e.fm.v1.ListEnvironmentsRequest\x1a\x1f.fm.v1.ListEnvironmentsResponse\x12>\n" +
+	"\tListFlags\x12\x17.fm.v1.ListFlagsRequest\x1a\x18.fm.v1.ListFlagsResponse\x12-\n" +
+	"\aGetFlag\x12\x15.fm.v1.GetFlagRequest\x1a\v.fm.v1.Flag\x123\n" +
+	"\n" +
+	"CreateFlag\x12\x18.fm.v1.CreateFlagRequest\x1a\v.fm.v1.Flag\x12A\n" +
+	"\n" +
+	"DeleteFlag\x12\x18.fm.v1.DeleteFlagRequest\x1a\x19.fm.v1.DeleteFlagResponse\x12T\n" +
+	"\x14GetFlagConfiguration\x12\".fm.v1.GetFlagConfigurationRequest\x1a\x18.fm.v1.FlagConfiguration\x12Z\n" +
+	"\x17UpdateFlagConfiguration\x12%.fm.v1.UpdateFlagConfigurationRequest\x1a\x18.fm.v1.FlagConfigurationB]\n" +
+	"\x1aio.cloudbees.fm.actions.v1P\x01Z=github.com/cloudbees-days/fm-actions-container/api/fm/v1;fmv1b\x06proto3"
+
+var (
+	file_fm_v1_flags_proto_rawDescOnce sync.Once
+	file_fm_v1_flags_proto_rawDescData []byte
+)
+
+func file_fm_v1_flags_proto_rawDescGZIP() []byte {
+	file_fm_v1_flags_proto_rawDescOnce.Do(func() {
+		file_fm_v1_flags_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_fm_v1_flags_proto_rawDesc), len(file_fm_v1_flags_proto_rawDesc)))
+	})
+	return file_fm_v1_flags_proto_rawDescData
+}
+
+var file_fm_v1_flags_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_fm_v1_flags_proto_goTypes = []any{
+	(*Environment)(nil),                    // 0: fm.v1.Environment
+	(*Flag)(nil),                           // 1: fm.v1.Flag
+	(*FlagConfiguration)(nil),              // 2: fm.v1.FlagConfiguration
+	(*ListEnvironmentsRequest)(nil),        // 3: fm.v1.ListEnvironmentsRequest
+	(*ListEnvironmentsResponse)(nil),       // 4: fm.v1.ListEnvironmentsResponse
+	(*ListFlagsRequest)(nil),               // 5: fm.v1.ListFlagsRequest
+	(*ListFlagsResponse)(nil),              // 6: fm.v1.ListFlagsResponse
+	(*GetFlagRequest)(nil),                 // 7: fm.v1.GetFlagRequest
+	(*CreateFlagRequest)(nil),              // 8: fm.v1.CreateFlagRequest
+	(*DeleteFlagRequest)(nil),              // 9: fm.v1.DeleteFlagRequest
+	(*DeleteFlagResponse)(nil),             // 10: fm.v1.DeleteFlagResponse
+	(*GetFlagConfigurationRequest)(nil),    // 11: fm.v1.GetFlagConfigurationRequest
+	(*UpdateFlagConfigurationRequest)(nil), // 12: fm.v1.UpdateFlagConfigurationRequest
+	(*structpb.Value)(nil),                 // 13: google.protobuf.Value
+}
+var file_fm_v1_flags_proto_depIdxs = []int32{
+	13, // 0: fm.v1.FlagConfiguration.default_value:type_name -> google.protobuf.Value
+	13, // 1: fm.v1.FlagConfiguration.conditions:type_name -> google.protobuf.Value
+	0,  // 2: fm.v1.ListEnvironmentsResponse.environments:type_name -> fm.v1.Environment
+	1,  // 3: fm.v1.ListFlagsResponse.flags:type_name -> fm.v1.Flag
+	13, // 4: fm.v1.UpdateFlagConfigurationRequest.default_value:type_name -> google.protobuf.Value
+	13, // 5: fm.v1.UpdateFlagConfigurationRequest.conditions:type_name -> google.protobuf.Value
+	3,  // 6: fm.v1.FlagService.ListEnvironments:input_type -> fm.v1.ListEnvironmentsRequest
+	5,  // 7: fm.v1.FlagService.ListFlags:input_type -> fm.v1.ListFlagsRequest
+	7,  // 8: fm.v1.FlagService.GetFlag:input_type -> fm.v1.GetFlagRequest
+	8,  // 9: fm.v1.FlagService.CreateFlag:input_type -> fm.v1.CreateFlagRequest
+	9,  // 10: fm.v1.FlagService.DeleteFlag:input_type -> fm.v1.DeleteFlagRequest
+	11, // 11: fm.v1.FlagService.GetFlagConfiguration:input_type -> fm.v1.GetFlagConfigurationRequest
+	12, // 12: fm.v1.FlagService.UpdateFlagConfiguration:input_type -> fm.v1.UpdateFlagConfigurationRequest
+	4,  // 13: fm.v1.FlagService.ListEnvironments:output_type -> fm.v1.ListEnvironmentsResponse
+	6,  // 14: fm.v1.FlagService.ListFlags:output_type -> fm.v1.ListFlagsResponse
+	1,  // 15: fm.v1.FlagService.GetFlag:output_type -> fm.v1.Flag
+	1,  // 16: fm.v1.FlagService.CreateFlag:output_type -> fm.v1.Flag
+	10, // 17: fm.v1.FlagService.DeleteFlag:output_type -> fm.v1.DeleteFlagResponse
+	2,  // 18: fm.v1.FlagService.GetFlagConfiguration:output_type -> fm.v1.FlagConfiguration
+	2,  // 19: fm.v1.FlagService.UpdateFlagConfiguration:output_type -> fm.v1.FlagConfiguration
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_fm_v1_flags_proto_init() }
+func file_fm_v1_flags_proto_init() {
+	if File_fm_v1_flags_proto != nil {
+		return
+	}
+	file_fm_v1_flags_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_fm_v1_flags_proto_rawDesc), len(file_fm_v1_flags_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_fm_v1_flags_proto_goTypes,
+		DependencyIndexes: file_fm_v1_flags_proto_depIdxs,
+		MessageInfos:      file_fm_v1_flags_proto_msgTypes,
+	}.Build()
+	File_fm_v1_flags_proto = out.File
+	file_fm_v1_flags_proto_goTypes = nil
+	file_fm_v1_flags_proto_depIdxs = nil
+}
@@ -0,0 +1,357 @@
+// Flag operations of fm-actions as a gRPC service. The operations and their semantics match
+// the REST API of `fm-actions serve`: callers authenticate with an "authorization: Bearer <token>"
+// metadata entry holding a CloudBees API token, which is passed through to the platform.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: fm/v1/flags.proto
+
+package fmv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FlagService_ListEnvironments_FullMethodName        = "/fm.v1.FlagService/ListEnvironments"
+	FlagService_ListFlags_FullMethodName               = "/fm.v1.FlagService/ListFlags"
+	FlagService_GetFlag_FullMethodName                 = "/fm.v1.FlagService/GetFlag"
+	FlagService_CreateFlag_FullMethodName              = "/fm.v1.FlagService/CreateFlag"
+	FlagService_DeleteFlag_FullMethodName              = "/fm.v1.FlagService/DeleteFlag"
+	FlagService_GetFlagConfiguration_FullMethodName    = "/fm.v1.FlagService/GetFlagConfiguration"
+	FlagService_UpdateFlagConfiguration_FullMethodName = "/fm.v1.FlagService/UpdateFlagConfiguration"
+)
+
+// FlagServiceClient is the client API for FlagService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FlagServiceClient interface {
+	ListEnvironments(ctx context.Context, in *ListEnvironmentsRequest, opts ...grpc.CallOption) (*ListEnvironmentsResponse, error)
+	ListFlags(ctx context.Context, in *ListFlagsRequest, opts ...grpc.CallOption) (*ListFlagsResponse, error)
+	GetFlag(ctx context.Context, in *GetFlagRequest, opts ...grpc.CallOption) (*Flag, error)
+	CreateFlag(ctx context.Context, in *CreateFlagRequest, opts ...grpc.CallOption) (*Flag, error)
+	DeleteFlag(ctx context.Context, in *DeleteFlagRequest, opts ...grpc.CallOption) (*DeleteFlagResponse, error)
+	GetFlagConfiguration(ctx context.Context, in *GetFlagConfigurationRequest, opts ...grpc.CallOption) (*FlagConfiguration, error)
+	// Applies the fields that are set; fails with ABORTED if if_match does not match the current revision,
+	// with PERMISSION_DENIED if a policy or approval gate rejects the change.
+	UpdateFlagConfiguration(ctx context.Context, in *UpdateFlagConfigurationRequest, opts ...grpc.CallOption) (*FlagConfiguration, error)
+}
+
+type flagServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFlagServiceClient(cc grpc.ClientConnInterface) FlagServiceClient {
+	return &flagServiceClient{cc}
+}
+
+func (c *flagServiceClient) ListEnvironments(ctx context.Context, in *ListEnvironmentsRequest, opts ...grpc.CallOption) (*ListEnvironmentsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEnvironmentsResponse)
+	err := c.cc.Invoke(ctx, FlagService_ListEnvironments_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) ListFlags(ctx context.Context, in *ListFlagsRequest, opts ...grpc.CallOption) (*ListFlagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFlagsResponse)
+	err := c.cc.Invoke(ctx, FlagService_ListFlags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) GetFlag(ctx context.Context, in *GetFlagRequest, opts ...grpc.CallOption) (*Flag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flag)
+	err := c.cc.Invoke(ctx, FlagService_GetFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) CreateFlag(ctx context.Context, in *CreateFlagRequest, opts ...grpc.CallOption) (*Flag, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Flag)
+	err := c.cc.Invoke(ctx, FlagService_CreateFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) DeleteFlag(ctx context.Context, in *DeleteFlagRequest, opts ...grpc.CallOption) (*DeleteFlagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFlagResponse)
+	err := c.cc.Invoke(ctx, FlagService_DeleteFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) GetFlagConfiguration(ctx context.Context, in *GetFlagConfigurationRequest, opts ...grpc.CallOption) (*FlagConfiguration, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlagConfiguration)
+	err := c.cc.Invoke(ctx, FlagService_GetFlagConfiguration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *flagServiceClient) UpdateFlagConfiguration(ctx context.Context, in *UpdateFlagConfigurationRequest, opts ...grpc.CallOption) (*FlagConfiguration, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlagConfiguration)
+	err := c.cc.Invoke(ctx, FlagService_UpdateFlagConfiguration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FlagServiceServer is the server API for FlagService service.
+// All implementations must embed UnimplementedFlagServiceServer
+// for forward compatibility.
+type FlagServiceServer interface {
+	ListEnvironments(context.Context, *ListEnvironmentsRequest) (*ListEnvironmentsResponse, error)
+	ListFlags(context.Context, *ListFlagsRequest) (*ListFlagsResponse, error)
+	GetFlag(context.Context, *GetFlagRequest) (*Flag, error)
+	CreateFlag(context.Context, *CreateFlagRequest) (*Flag, error)
+	DeleteFlag(context.Context, *DeleteFlagRequest) (*DeleteFlagResponse, error)
+	GetFlagConfiguration(context.Context, *GetFlagConfigurationRequest) (*FlagConfiguration, error)
+	// Applies the fields that are set; fails with ABORTED if if_match does not match the current revision,
+	// with PERMISSION_DENIED if a policy or approval gate rejects the change.
+	UpdateFlagConfiguration(context.Context, *UpdateFlagConfigurationRequest) (*FlagConfiguration, error)
+	mustEmbedUnimplementedFlagServiceServer()
+}
+
+// UnimplementedFlagServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFlagServiceServer struct{}
+
+func (UnimplementedFlagServiceServer) ListEnvironments(context.Context, *ListEnvironmentsRequest) (*ListEnvironmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEnvironments not implemented")
+}
+func (UnimplementedFlagServiceServer) ListFlags(context.Context, *ListFlagsRequest) (*ListFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlags not implemented")
+}
+func (UnimplementedFlagServiceServer) GetFlag(context.Context, *GetFlagRequest) (*Flag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlag not implemented")
+}
+func (UnimplementedFlagServiceServer) CreateFlag(context.Context, *CreateFlagRequest) (*Flag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFlag not implemented")
+}
+func (UnimplementedFlagServiceServer) DeleteFlag(context.Context, *DeleteFlagRequest) (*DeleteFlagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFlag not implemented")
+}
+func (UnimplementedFlagServiceServer) GetFlagConfiguration(context.Context, *GetFlagConfigurationRequest) (*FlagConfiguration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlagConfiguration not implemented")
+}
+func (UnimplementedFlagServiceServer) UpdateFlagConfiguration(context.Context, *UpdateFlagConfigurationRequest) (*FlagConfiguration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFlagConfiguration not implemented")
+}
+func (UnimplementedFlagServiceServer) mustEmbedUnimplementedFlagServiceServer() {}
+func (UnimplementedFlagServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeFlagServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FlagServiceServer will
+// result in compilation errors.
+type UnsafeFlagServiceServer interface {
+	mustEmbedUnimplementedFlagServiceServer()
+}
+
+func RegisterFlagServiceServer(s grpc.ServiceRegistrar, srv FlagServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFlagServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FlagService_ServiceDesc, srv)
+}
+
+func _FlagService_ListEnvironments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEnvironmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).ListEnvironments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_ListEnvironments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).ListEnvironments(ctx, req.(*ListEnvironmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_ListFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).ListFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_ListFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).ListFlags(ctx, req.(*ListFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_GetFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).GetFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_GetFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).GetFlag(ctx, req.(*GetFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_CreateFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).CreateFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_CreateFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).CreateFlag(ctx, req.(*CreateFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_DeleteFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).DeleteFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_DeleteFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).DeleteFlag(ctx, req.(*DeleteFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_GetFlagConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlagConfigurationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).GetFlagConfiguration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_GetFlagConfiguration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).GetFlagConfiguration(ctx, req.(*GetFlagConfigurationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FlagService_UpdateFlagConfiguration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFlagConfigurationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FlagServiceServer).UpdateFlagConfiguration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FlagService_UpdateFlagConfiguration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FlagServiceServer).UpdateFlagConfiguration(ctx, req.(*UpdateFlagConfigurationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FlagService_ServiceDesc is the grpc.ServiceDesc for FlagService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FlagService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fm.v1.FlagService",
+	HandlerType: (*FlagServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListEnvironments",
+			Handler:    _FlagService_ListEnvironments_Handler,
+		},
+		{
+			MethodName: "ListFlags",
+			Handler:    _FlagService_ListFlags_Handler,
+		},
+		{
+			MethodName: "GetFlag",
+			Handler:    _FlagService_GetFlag_Handler,
+		},
+		{
+			MethodName: "CreateFlag",
+			Handler:    _FlagService_CreateFlag_Handler,
+		},
+		{
+			MethodName: "DeleteFlag",
+			Handler:    _FlagService_DeleteFlag_Handler,
+		},
+		{
+			MethodName: "GetFlagConfiguration",
+			Handler:    _FlagService_GetFlagConfiguration_Handler,
+		},
+		{
+			MethodName: "UpdateFlagConfiguration",
+			Handler:    _FlagService_UpdateFlagConfiguration_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "fm/v1/flags.proto",
+}
@@ -0,0 +1,5 @@
+// Package fmv1 holds the Go messages and gRPC stubs of the flag service of
+// api/proto/fm/v1/flags.proto, served by fm-actions serve --grpc-addr.
+package fmv1
+
+//go:generate protoc -I ../../proto --go_out=../../.. --go_opt=module=github.com/cloudbees-days/fm-actions-container --go-grpc_out=../../.. --go-grpc_opt=module=github.com/cloudbees-days/fm-actions-container fm/v1/flags.proto
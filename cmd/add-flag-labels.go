@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var addFlagLabelsCmd = &cobra.Command{
+	Use:   "add-flag-labels",
+	Short: "Add labels to a feature flag",
+	Long:  `Add one or more labels to a feature flag, e.g. to tag flags by squad or release train.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateFlagLabels(cmd, func(current, labels []string) []string {
+			result := append([]string{}, current...)
+			for _, label := range labels {
+				if !containsString(result, label) {
+					result = append(result, label)
+				}
+			}
+			return result
+		})
+	},
+}
+
+// updateFlagLabels applies a label change computed by update(currentLabels, requestedLabels) to a flag
+func updateFlagLabels(cmd *cobra.Command, update func(current, labels []string) []string) error {
+	flagName, _ := cmd.Flags().GetString("flag-name")
+	labels, _ := cmd.Flags().GetStringSlice("labels")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	if flagName == "" {
+		return fmt.Errorf("flag-name is required")
+	}
+	if len(labels) == 0 {
+		return fmt.Errorf("at least one label is required")
+	}
+
+	client, ctx, err := commandContext(cmd, flagName, "")
+	if err != nil {
+		return err
+	}
+	application, flag := ctx.Application, ctx.Flag
+
+	newLabels := update(flag.Labels, labels)
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would set labels of flag '%s' to [%s]\n", flag.Name, strings.Join(newLabels, ", "))
+		return nil
+	}
+
+	change := mutation{
+		Operation:   commandName(cmd),
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Changes:     map[string]interface{}{"labels": newLabels},
+		Before:      flag,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	updated, err := client.SetFlagLabels(application.ID, flag.ID, newLabels)
+	change.After = updated
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to update flag labels: %w", err)
+	}
+
+	// Output results
+	labelsJSON, _ := json.Marshal(updated.Labels)
+	cloudbees.WriteOutput("flag-id", flag.ID)
+	cloudbees.WriteOutput("flag-name", flag.Name)
+	cloudbees.WriteOutput("labels", string(labelsJSON))
+	cloudbees.WriteOutput("success", "true")
+
+	if verbose {
+		fmt.Printf("Successfully updated labels of flag: %s (ID: %s)\n", flag.Name, flag.ID)
+		fmt.Printf("Labels: %s\n", strings.Join(updated.Labels, ", "))
+	}
+
+	return nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	rootCmd.AddCommand(addFlagLabelsCmd)
+
+	addFlagLabelsCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	addFlagLabelsCmd.Flags().StringSlice("labels", nil, "Labels to add, comma-separated or repeated (required)")
+	addFlagLabelsCmd.Flags().Bool("dry-run", false, "Show the resulting labels without applying them")
+
+	flagNamesArg(addFlagLabelsCmd)
+
+	addFlagLabelsCmd.MarkFlagRequired("flag-name")
+	addFlagLabelsCmd.MarkFlagRequired("labels")
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// addAllApplicationsFlag registers the --all-applications flag of org-wide commands
+func addAllApplicationsFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("all-applications", false, "Run for every application in the organization concurrently, instead of --application-name")
+}
+
+// targetApplications returns the applications a command runs for: every application in the
+// organization, ordered by name, with --all-applications, otherwise the one given by --application-name
+func targetApplications(cmd *cobra.Command, client cloudbees.API) ([]cloudbees.Application, error) {
+	allApplications, _ := cmd.Flags().GetBool("all-applications")
+	if !allApplications {
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+		application, err := getApplication(cmd, client, applicationName)
+		if err != nil {
+			return nil, err
+		}
+		return []cloudbees.Application{*application}, nil
+	}
+
+	applications, err := client.ListApplications()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	if len(applications) == 0 {
+		return nil, fmt.Errorf("no applications found in the organization")
+	}
+	sort.Slice(applications, func(i, j int) bool { return applications[i].Name < applications[j].Name })
+	return applications, nil
+}
+
+// applicationsErr returns the error of a single application as is, so commands without
+// --all-applications fail as before, or the aggregate error of every failed application
+func applicationsErr[T any](results workerpool.Results[T]) error {
+	if len(results) == 1 {
+		return results[0].Err
+	}
+	return results.Err()
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/actions"
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+// commandContext creates the client and resolves the application of the command, and the flag
+// and environment when they are named
+func commandContext(cmd *cobra.Command, flagName, environmentName string) (cloudbees.API, *actions.CommandContext, error) {
+	client, err := newClient(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+	ctx, err := resolveContext(cmd, client, applicationName, flagName, environmentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, ctx, nil
+}
+
+// resolveContext resolves a flag and an environment of the application called applicationName,
+// or of the application given by --application-id or --use-org-as-app when the name is empty
+func resolveContext(cmd *cobra.Command, client cloudbees.API, applicationName, flagName, environmentName string) (*actions.CommandContext, error) {
+	target, err := commandTarget(cmd)
+	if err != nil {
+		return nil, err
+	}
+	target.ApplicationName = applicationName
+	target.FlagName = flagName
+	target.EnvironmentName = environmentName
+	return actions.NewCommandContext(client, target)
+}
+
+// getApplication returns the application a command works on: the application called name or,
+// when name is empty, the one given by --application-id, or the organization itself for
+// organizations using the legacy flags API (--use-org-as-app)
+func getApplication(cmd *cobra.Command, client cloudbees.API, name string) (*cloudbees.Application, error) {
+	target, err := commandTarget(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return actions.ResolveApplication(client, name, target.ApplicationID)
+}
+
+// commandTarget returns the application options of the command line
+func commandTarget(cmd *cobra.Command) (actions.Target, error) {
+	flags := cmd.Root().PersistentFlags()
+	applicationName, _ := flags.GetString("application-name")
+	applicationID, _ := flags.GetString("application-id")
+	if applicationName != "" && applicationID != "" {
+		return actions.Target{}, fmt.Errorf("application-name and application-id cannot be used together")
+	}
+	return actions.Target{ApplicationName: applicationName, ApplicationID: applicationID}, nil
+}
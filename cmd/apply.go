@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Change the live flags to match a manifest file",
+	Long: `Read a manifest file, such as one written by export, and change the live flags to match it:
+flags missing from the live state are created, and changed descriptions, labels and environment
+configurations are updated. Flags that are not in the manifest are only deleted with --prune.
+The manifest is rendered as a template with --values and --set, and the --overlay files are
+merged into it first. Configuration changes are sent concurrently with --concurrency workers.
+When a change fails, the changes already applied are rolled back unless --no-rollback is set.
+sync-from-git does the same with a manifest committed to a git repository.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		overlays, _ := cmd.Flags().GetStringSlice("overlay")
+		prune, _ := cmd.Flags().GetBool("prune")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		values, err := templateValues(cmd)
+		if err != nil {
+			return err
+		}
+		desired, err := loadManifest(file, "", "", overlays, values)
+		if err != nil {
+			return err
+		}
+		if applicationName != "" {
+			desired.Application = applicationName
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		changes, err := applyManifest(cmd, client, desired, prune, dryRun)
+
+		// Output results, including the changes applied before a failure
+		changesJSON, _ := json.Marshal(changes)
+		cloudbees.WriteOutput("change-count", fmt.Sprintf("%d", len(changes)))
+		cloudbees.WriteOutput("changes", string(changesJSON))
+		writeArtifact(cmd, "changes", changes)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("Flags of '%s' already match the manifest\n", desired.Application)
+		} else if !dryRun {
+			fmt.Printf("Applied %d changes to '%s'\n", len(changes), desired.Application)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().String("file", "", "Manifest file to apply (required)")
+	applyCmd.Flags().Bool("prune", false, "Delete flags that are not in the manifest")
+	applyCmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	applyCmd.Flags().Bool("no-rollback", false, "Keep the changes applied before a failure instead of reverting them")
+	stateFlags(applyCmd)
+	applyCmd.Flags().StringSlice("overlay", nil, "Overlay files merged into the manifest in order, e.g. the patches of one environment")
+	templateFlags(applyCmd)
+
+	applyCmd.MarkFlagRequired("file")
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// flagNameArg lets a command take the flag name as positional argument instead of --flag-name,
+// e.g. fm-actions get-flag-config checkout --environment-name production
+func flagNameArg(cmd *cobra.Command) {
+	cmd.Use += " [flag-name]"
+	cmd.Args = cobra.MaximumNArgs(1)
+	cmd.PreRunE = setFlagNameArg
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeFlagNames(cmd, args, toComplete)
+	}
+}
+
+// flagNamesArg lets a command take one or more flag names as positional arguments. With several
+// names the command runs once per flag, in order, and the outputs describe the last flag.
+func flagNamesArg(cmd *cobra.Command) {
+	run := cmd.RunE
+	cmd.Use += " [flag-name...]"
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.PreRunE = setFlagNameArg
+	cmd.ValidArgsFunction = completeFlagNames
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if len(args) <= 1 {
+			return run(cmd, args)
+		}
+
+		// Flags are processed one at a time because they share the --flag-name option
+		opts := poolOptions(cmd)
+		opts.Concurrency = 1
+		results := workerpool.Run(args, func(name string) string { return name }, opts,
+			func(name string) (bool, error) {
+				if err := cmd.Flags().Set("flag-name", name); err != nil {
+					return false, err
+				}
+				return true, run(cmd, args)
+			})
+		return bulkErr(cmd, results)
+	}
+}
+
+// setFlagNameArg copies the first positional argument to --flag-name, so the option's
+// requirement is satisfied before it is validated
+func setFlagNameArg(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+	if cmd.Flags().Changed("flag-name") {
+		return fmt.Errorf("flag name given both as argument and with --flag-name")
+	}
+	return cmd.Flags().Set("flag-name", args[0])
+}
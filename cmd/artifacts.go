@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// createArtifact creates the result file name.json in the --artifact-dir. It returns nil
+// without --artifact-dir, or with a warning when the file cannot be created: artifacts
+// complement the outputs and never fail a command.
+func createArtifact(cmd *cobra.Command, name string) *os.File {
+	return createArtifactFile(cmd, name+".json")
+}
+
+// createArtifactFile creates a file in the --artifact-dir, like createArtifact, for results
+// in other formats than JSON
+func createArtifactFile(cmd *cobra.Command, filename string) *os.File {
+	dir, _ := cmd.Root().PersistentFlags().GetString("artifact-dir")
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create artifact directory: %v\n", err)
+		return nil
+	}
+	file, err := os.Create(filepath.Join(dir, filename))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to create artifact: %v\n", err)
+		return nil
+	}
+	return file
+}
+
+// writeArtifact writes the full result of a command as indented JSON to the artifact name.json
+func writeArtifact(cmd *cobra.Command, name string, value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write artifact %s.json: %v\n", name, err)
+		return
+	}
+	writeArtifactFile(cmd, name+".json", append(data, '\n'))
+}
+
+// writeArtifactFile writes data to the artifact filename, e.g. a rendered report
+func writeArtifactFile(cmd *cobra.Command, filename string, data []byte) {
+	file := createArtifactFile(cmd, filename)
+	if file == nil {
+		return
+	}
+	_, err := file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write artifact %s: %v\n", file.Name(), err)
+	}
+}
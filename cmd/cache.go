@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// cacheableCommands are the read-only commands that use the response cache with --cache, by
+// command path, e.g. render-k8s for render k8s
+var cacheableCommands = map[string]bool{
+	"list-flags":           true,
+	"list-environments":    true,
+	"get-flag-config":      true,
+	"get-flag-configs":     true,
+	"get-casc":             true,
+	"export":               true,
+	"compare-environments": true,
+	"config-history":       true,
+	"stale-flags":          true,
+	"flag-stats":           true,
+	"experiment-report":    true,
+	"evaluate":             true,
+	"render-helm-values":   true,
+	"render-k8s":           true,
+}
+
+// responseCache returns the disk cache of API responses of an organization. Commands that do
+// not read from it still get it, so that their changes invalidate it.
+func responseCache(cmd *cobra.Command, apiURL, orgID string) *cloudbees.DiskCache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	key := sha256.Sum256([]byte(apiURL + "\x00" + orgID))
+
+	noCache, _ := cmd.Root().PersistentFlags().GetBool("no-cache")
+	ttl := viper.GetDuration("cache-ttl")
+	if !viper.GetBool("cache") || noCache || !cacheableCommands[commandPathName(cmd)] {
+		ttl = 0
+	}
+	return cloudbees.NewDiskCache(filepath.Join(dir, "fm-actions", "api", hex.EncodeToString(key[:8])), ttl)
+}
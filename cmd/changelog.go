@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of changelog entries
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// flagChange is a single changelog entry
+type flagChange struct {
+	Flag        string      `json:"flag"`
+	Kind        string      `json:"kind"`
+	Environment string      `json:"environment,omitempty"`
+	Field       string      `json:"field,omitempty"`
+	From        interface{} `json:"from,omitempty"`
+	To          interface{} `json:"to,omitempty"`
+}
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Render the flag changes between two snapshots as Markdown",
+	Long: `Compare two manifests created with the export command and render the changes
+(flags added or removed, enabled or disabled per environment, default values changed)
+as Markdown release notes. Without --to, the snapshot is compared with the live state.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromFile, _ := cmd.Flags().GetString("from")
+		toFile, _ := cmd.Flags().GetString("to")
+		markdownFile, _ := cmd.Flags().GetString("markdown-file")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		from, err := manifest.Load(fromFile)
+		if err != nil {
+			return err
+		}
+
+		var to *manifest.Manifest
+		toLabel := toFile
+		if toFile != "" {
+			to, err = manifest.Load(toFile)
+			if err != nil {
+				return err
+			}
+		} else {
+			if applicationName == "" {
+				applicationName = from.Application
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			to, err = exportManifest(cmd, client, applicationName, nil)
+			if err != nil {
+				return err
+			}
+			toLabel = "live state"
+		}
+
+		changes := diffManifests(from, to)
+		markdown := changelogMarkdown(to.Application, fromFile, toLabel, changes)
+		if markdownFile != "" {
+			if err := os.WriteFile(markdownFile, []byte(markdown), 0644); err != nil {
+				return fmt.Errorf("failed to write changelog: %w", err)
+			}
+		}
+
+		// Output results
+		changesJSON, _ := json.Marshal(changes)
+		cloudbees.WriteOutput("change-count", fmt.Sprintf("%d", len(changes)))
+		cloudbees.WriteOutput("changes", string(changesJSON))
+		writeArtifact(cmd, "changes", changes)
+		cloudbees.WriteOutput("changelog", markdown)
+
+		if verbose || markdownFile == "" {
+			fmt.Print(markdown)
+		}
+
+		return nil
+	},
+}
+
+// diffManifests returns the changes that turn from into to, ordered by flag name
+func diffManifests(from, to *manifest.Manifest) []flagChange {
+	changes := []flagChange{}
+
+	for _, flag := range from.Flags {
+		if to.Flag(flag.Name) == nil {
+			changes = append(changes, flagChange{Flag: flag.Name, Kind: changeRemoved})
+		}
+	}
+
+	for _, flag := range to.Flags {
+		old := from.Flag(flag.Name)
+		if old == nil {
+			changes = append(changes, flagChange{Flag: flag.Name, Kind: changeAdded, To: flag.Type})
+			continue
+		}
+
+		if old.Description != flag.Description {
+			changes = append(changes, flagChange{Flag: flag.Name, Kind: changeChanged, Field: "description", From: old.Description, To: flag.Description})
+		}
+		if strings.Join(old.Labels, ",") != strings.Join(flag.Labels, ",") {
+			changes = append(changes, flagChange{Flag: flag.Name, Kind: changeChanged, Field: "labels", From: old.Labels, To: flag.Labels})
+		}
+
+		environments := make([]string, 0, len(flag.Environments))
+		for name := range flag.Environments {
+			environments = append(environments, name)
+		}
+		sort.Strings(environments)
+		for _, env := range environments {
+			oldConfig, ok := old.Environments[env]
+			if !ok {
+				continue // Environment not part of the older snapshot
+			}
+			for _, diff := range diffConfigurations(oldConfig, flag.Environments[env]) {
+				changes = append(changes, flagChange{Flag: flag.Name, Kind: changeChanged, Environment: env, Field: diff.Field, From: diff.From, To: diff.To})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Flag < changes[j].Flag })
+	return changes
+}
+
+// describe renders a changelog entry as a sentence
+func (c flagChange) describe() string {
+	switch c.Kind {
+	case changeAdded:
+		return fmt.Sprintf("`%s` added (%v)", c.Flag, c.To)
+	case changeRemoved:
+		return fmt.Sprintf("`%s` removed", c.Flag)
+	}
+
+	if c.Environment == "" {
+		return fmt.Sprintf("`%s` %s changed from %s to %s", c.Flag, c.Field, changelogValue(c.From), changelogValue(c.To))
+	}
+	switch c.Field {
+	case "enabled":
+		if c.To == true {
+			return fmt.Sprintf("`%s` enabled in %s", c.Flag, c.Environment)
+		}
+		return fmt.Sprintf("`%s` disabled in %s", c.Flag, c.Environment)
+	case "defaultValue":
+		return fmt.Sprintf("`%s` default value in %s changed from %s to %s", c.Flag, c.Environment, changelogValue(c.From), changelogValue(c.To))
+	case "conditions":
+		return fmt.Sprintf("`%s` targeting conditions in %s changed", c.Flag, c.Environment)
+	default:
+		return fmt.Sprintf("`%s` %s in %s changed from %s to %s", c.Flag, c.Field, c.Environment, changelogValue(c.From), changelogValue(c.To))
+	}
+}
+
+// changelogValue formats a configuration value as inline code
+func changelogValue(value interface{}) string {
+	if value == nil {
+		return "none"
+	}
+	if s, ok := value.(string); ok {
+		return "`" + s + "`"
+	}
+	data, _ := json.Marshal(value)
+	return "`" + string(data) + "`"
+}
+
+// changelogMarkdown renders the changes as Markdown release notes
+func changelogMarkdown(applicationName, fromLabel, toLabel string, changes []flagChange) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Feature flag changes: %s\n\n", applicationName)
+	fmt.Fprintf(&b, "Changes from %s to %s.\n\n", fromLabel, toLabel)
+	if len(changes) == 0 {
+		b.WriteString("No flag changes.\n")
+		return b.String()
+	}
+
+	sections := []struct {
+		kind  string
+		title string
+	}{
+		{changeAdded, "New flags"},
+		{changeChanged, "Changed flags"},
+		{changeRemoved, "Removed flags"},
+	}
+	for _, section := range sections {
+		var lines []string
+		for _, change := range changes {
+			if change.Kind == section.kind {
+				lines = append(lines, "- "+change.describe())
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "## %s\n\n%s\n\n", section.title, strings.Join(lines, "\n"))
+		}
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(changelogCmd)
+
+	changelogCmd.Flags().String("from", "", "Older snapshot created with export (required)")
+	changelogCmd.Flags().String("to", "", "Newer snapshot (defaults to the live state of the application)")
+	changelogCmd.Flags().String("markdown-file", "", "Write the Markdown changelog to this file")
+
+	changelogCmd.MarkFlagRequired("from")
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of an access check
+const (
+	accessAllowed = "allowed"
+	accessDenied  = "denied"
+	accessUnknown = "unknown"
+)
+
+// accessCheck reports whether an action can be performed
+type accessCheck struct {
+	Action      string `json:"action"`
+	Environment string `json:"environment,omitempty"`
+	Access      string `json:"access"` // read or write
+	Result      string `json:"result"` // allowed, denied or unknown
+	Reason      string `json:"reason,omitempty"`
+}
+
+var checkAccessCmd = &cobra.Command{
+	Use:   "check-access",
+	Short: "Check that the token can perform operations before running them",
+	Long: `Check, before a multi-step release, that the token and settings allow the given commands, so a
+pipeline fails early with a clear message instead of midway. Read-only mode and protected environments
+are checked first. Then a flag configuration (--flag-name, or the first flag of the application) is
+read. Nothing is written: the API cannot check write permissions without making a change, so writes
+are reported as denied when the token cannot read, and as unknown otherwise.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		actions, _ := cmd.Flags().GetStringSlice("action")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		flagName, _ := cmd.Flags().GetString("flag-name")
+
+		if len(actions) == 0 {
+			return fmt.Errorf("at least one action is required")
+		}
+		known := map[string]bool{}
+		var walk func(c *cobra.Command)
+		walk = func(c *cobra.Command) {
+			if c.Runnable() {
+				known[commandPathName(c)] = true
+			}
+			for _, child := range c.Commands() {
+				walk(child)
+			}
+		}
+		walk(cmd.Root())
+		for _, action := range actions {
+			if !known[action] {
+				return fmt.Errorf("unknown action '%s', must be a command name such as set-flag-config", action)
+			}
+		}
+
+		client, ctx, err := commandContext(cmd, flagName, environmentName)
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		// Access is probed in the environment, or the first one linked to the application
+		environment := ctx.Environment
+		if environment == nil && len(application.LinkedEnvironmentIDs) > 0 {
+			environments, err := client.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments: %w", err)
+			}
+			for i := range environments {
+				if environments[i].ID == application.LinkedEnvironmentIDs[0] {
+					environment = &environments[i]
+				}
+			}
+		}
+		flag := ctx.Flag
+		if flag == nil {
+			flags, err := client.ListFlags(application.ID)
+			if err != nil && !isAccessDenied(err) {
+				return fmt.Errorf("failed to list flags: %w", err)
+			}
+			if len(flags) > 0 {
+				flag = &flags[0]
+			}
+		}
+
+		probes := map[string]accessCheck{}
+		probe := func(access string) accessCheck {
+			if check, ok := probes[access]; ok {
+				return check
+			}
+			check := probeAccess(client, application, flag, environment, access)
+			probes[access] = check
+			return check
+		}
+
+		checks := make([]accessCheck, len(actions))
+		for i, action := range actions {
+			check := accessCheck{Action: action, Environment: environmentName, Access: "read"}
+			if mutatingCommands[action] {
+				check.Access = "write"
+				if check.Environment == "" && environment != nil {
+					check.Environment = environment.Name
+				}
+			}
+
+			change := mutation{Operation: action, Application: application.Name, Environment: check.Environment}
+			if flag != nil {
+				change.Flag = flag.Name
+			}
+			protectedErr := checkProtectedEnvironment(cmd, change)
+			switch {
+			case check.Access == "write" && readOnly():
+				check.Result, check.Reason = accessDenied, "read-only mode (--read-only or FM_READ_ONLY)"
+			case check.Access == "write" && protectedErr != nil:
+				check.Result, check.Reason = accessDenied, protectedErr.Error()
+			default:
+				probed := probe(check.Access)
+				check.Result, check.Reason = probed.Result, probed.Reason
+			}
+			checks[i] = check
+		}
+
+		// Output results
+		allowed := "true"
+		var denied []string
+		for _, check := range checks {
+			switch check.Result {
+			case accessDenied:
+				allowed = "false"
+				denied = append(denied, check.Action)
+			case accessUnknown:
+				if allowed == "true" {
+					allowed = "unknown"
+				}
+			}
+		}
+		checksJSON, _ := json.Marshal(checks)
+		cloudbees.WriteOutput("allowed", allowed)
+		cloudbees.WriteOutput("results", string(checksJSON))
+		cloudbees.WriteOutput("denied-actions", strings.Join(denied, ","))
+
+		for _, check := range checks {
+			where := ""
+			if check.Environment != "" {
+				where = fmt.Sprintf(" in '%s'", check.Environment)
+			}
+			if check.Reason != "" {
+				fmt.Printf("- %s%s (%s): %s: %s\n", check.Action, where, check.Access, check.Result, check.Reason)
+			} else {
+				fmt.Printf("- %s%s (%s): %s\n", check.Action, where, check.Access, check.Result)
+			}
+		}
+
+		if len(denied) > 0 {
+			return fmt.Errorf("access denied for %s", strings.Join(denied, ", "))
+		}
+		return nil
+	},
+}
+
+// probeAccess checks read or write access to the flags of an application with the API. The API
+// cannot check write permissions without making a change, so writes are only known to be denied
+// when reads are.
+func probeAccess(client cloudbees.API, application *cloudbees.Application, flag *cloudbees.Flag, environment *cloudbees.Environment, access string) accessCheck {
+	check := accessCheck{Access: access}
+	var err error
+	if flag != nil && environment != nil {
+		_, err = client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+	} else {
+		_, err = client.ListFlags(application.ID)
+	}
+	check = accessResult(check, err)
+	if access == "write" && check.Result == accessAllowed {
+		check.Result, check.Reason = accessUnknown, "the token can read, but the API cannot check write permissions without making a change"
+	}
+	return check
+}
+
+// accessResult sets the result of a check from the error of its probe
+func accessResult(check accessCheck, err error) accessCheck {
+	switch {
+	case err == nil:
+		check.Result = accessAllowed
+	case isAccessDenied(err):
+		check.Result, check.Reason = accessDenied, err.Error()
+	default:
+		check.Result, check.Reason = accessUnknown, err.Error()
+	}
+	return check
+}
+
+// isAccessDenied reports whether the API refused a request because of the token's permissions
+func isAccessDenied(err error) bool {
+	var apiErr *cloudbees.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden)
+}
+
+func init() {
+	rootCmd.AddCommand(checkAccessCmd)
+
+	checkAccessCmd.Flags().StringSlice("action", nil, "Command to check, e.g. set-flag-config (repeatable, required)")
+	checkAccessCmd.Flags().StringP("environment-name", "e", "", "Environment the actions change (defaults to the first environment linked to the application)")
+	checkAccessCmd.Flags().StringP("flag-name", "f", "", "Flag used to probe access (defaults to the first flag of the application)")
+
+	checkAccessCmd.MarkFlagRequired("action")
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var checkPolicyCmd = &cobra.Command{
+	Use:   "check-policy",
+	Short: "Check all flags against lifecycle policy rules",
+	Long: `Evaluate organization lifecycle rules (maximum flag age, naming convention, required description
+and owner, expiry for temporary flags) against every flag and fail with the list of violations.
+With --all-applications, the flags of every application are checked concurrently.
+Rules are read from a YAML file and can be overridden with flags:
+
+  maxAgeDays: 90
+  namePattern: '^[a-z][a-z0-9-]*$'
+  requireDescription: true
+  requireOwner: true
+  temporaryRequiresExpiry: true`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policyFile, _ := cmd.Flags().GetString("policy-file")
+		allApplications, _ := cmd.Flags().GetBool("all-applications")
+
+		var rules policy.Rules
+		if policyFile != "" {
+			var err error
+			rules, err = policy.LoadRules(policyFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Individual flags take precedence over the policy file
+		if cmd.Flags().Changed("max-age-days") {
+			rules.MaxAgeDays, _ = cmd.Flags().GetInt("max-age-days")
+		}
+		if cmd.Flags().Changed("name-pattern") {
+			rules.NamePattern, _ = cmd.Flags().GetString("name-pattern")
+		}
+		if cmd.Flags().Changed("require-description") {
+			rules.RequireDescription, _ = cmd.Flags().GetBool("require-description")
+		}
+		if cmd.Flags().Changed("require-owner") {
+			rules.RequireOwner, _ = cmd.Flags().GetBool("require-owner")
+		}
+		if cmd.Flags().Changed("require-expiry") {
+			rules.TemporaryRequiresExpiry, _ = cmd.Flags().GetBool("require-expiry")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		applications, err := targetApplications(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+			func(application cloudbees.Application) (policyCheck, error) {
+				flags, err := client.ListFlags(application.ID)
+				if err != nil {
+					return policyCheck{}, fmt.Errorf("failed to list flags: %w", err)
+				}
+				violations, err := policy.Evaluate(flags, rules, now)
+				return policyCheck{FlagCount: len(flags), Violations: violations}, err
+			})
+		if err := applicationsErr(results); err != nil {
+			return err
+		}
+
+		flagCount := 0
+		violations := []policy.Violation{}
+		for _, result := range results {
+			flagCount += result.Value.FlagCount
+			for _, violation := range result.Value.Violations {
+				if allApplications {
+					violation.Application = result.Name
+				}
+				violations = append(violations, violation)
+			}
+		}
+
+		// Output results
+		violationsJSON, _ := json.Marshal(violations)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", flagCount))
+		cloudbees.WriteOutput("violation-count", fmt.Sprintf("%d", len(violations)))
+		cloudbees.WriteOutput("violations", string(violationsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", len(violations) == 0))
+
+		if len(violations) == 0 {
+			fmt.Printf("All %d flags comply with the policy\n", flagCount)
+			return nil
+		}
+
+		fmt.Printf("Found %d policy violations:\n", len(violations))
+		printViolations(violations)
+		return fmt.Errorf("%w: %d violations found", policy.ErrViolation, len(violations))
+	},
+}
+
+// policyCheck is the result of checking the flags of one application
+type policyCheck struct {
+	FlagCount  int
+	Violations []policy.Violation
+}
+
+// printViolations lists violations, grouped by application when they are set
+func printViolations(violations []policy.Violation) {
+	application := ""
+	for _, violation := range violations {
+		if violation.Application != application {
+			application = violation.Application
+			fmt.Printf("%s:\n", application)
+		}
+		fmt.Printf("- %s [%s]: %s\n", violation.FlagName, violation.Rule, violation.Message)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(checkPolicyCmd)
+
+	checkPolicyCmd.Flags().String("policy-file", "", "YAML file with policy rules")
+	checkPolicyCmd.Flags().Int("max-age-days", 0, "Maximum age of temporary flags in days (0 disables)")
+	checkPolicyCmd.Flags().String("name-pattern", "", "Regular expression flag names must match")
+	checkPolicyCmd.Flags().Bool("require-description", false, "Require every flag to have a description")
+	checkPolicyCmd.Flags().Bool("require-owner", false, "Require every flag to have an owner")
+	checkPolicyCmd.Flags().Bool("require-expiry", false, "Require temporary flags to have an expiry date")
+	addAllApplicationsFlag(checkPolicyCmd)
+}
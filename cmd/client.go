@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/telemetry"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// activeClients tracks every client created during this invocation for final reporting
+var activeClients []*cloudbees.Client
+
+// telemetryProvider records traces and metrics of API calls when OTEL_EXPORTER_OTLP_ENDPOINT is set
+var telemetryProvider *telemetry.Provider
+
+// httpTrace is the --http-debug-file shared by the clients of this invocation, so the server and
+// multi-organization commands open it once. Execute closes it.
+var (
+	httpTraceMu sync.Mutex
+	httpTrace   io.WriteCloser
+)
+
+// etagCache is shared by the clients of this invocation, so watch loops and the REST server
+// revalidate unchanged resources instead of downloading them again
+var etagCache = cloudbees.NewETagCache()
+
+// clientFactory creates the API client of the commands. Tests replace it to run command logic
+// against a fake API.
+var clientFactory = defaultClientFactory
+
+// newClient creates the API client of a command from the global connection flags
+func newClient(cmd *cobra.Command) (cloudbees.API, error) {
+	return clientFactory(cmd)
+}
+
+// defaultClientFactory creates a CloudBees client from the global connection flags
+func defaultClientFactory(cmd *cobra.Command) (cloudbees.API, error) {
+	token, _ := cmd.Root().PersistentFlags().GetString("token")
+
+	client, err := newClientWithToken(cmd, token)
+	if err != nil {
+		return nil, err
+	}
+
+	activeClients = append(activeClients, client)
+	return client, nil
+}
+
+// newClientWithToken creates a client from the global connection flags that authenticates with token
+func newClientWithToken(cmd *cobra.Command, token string) (*cloudbees.Client, error) {
+	apiURL, err := resolveAPIURL(cmd)
+	if err != nil {
+		return nil, err
+	}
+	orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
+	return newClientForOrg(cmd, apiURL, orgID, token)
+}
+
+// newClientForOrg creates a client for another organization (or API endpoint) than the global
+// connection flags, with the same transport, TLS and rate limiting settings
+func newClientForOrg(cmd *cobra.Command, apiURL, orgID, token string) (*cloudbees.Client, error) {
+	httpDebugFile, _ := cmd.Root().PersistentFlags().GetString("http-debug-file")
+	maxRPS, _ := cmd.Root().PersistentFlags().GetFloat64("max-rps")
+	maxItems, _ := cmd.Root().PersistentFlags().GetInt("max-items")
+	breakerThreshold, _ := cmd.Root().PersistentFlags().GetInt("circuit-breaker-threshold")
+	proxy, _ := cmd.Root().PersistentFlags().GetString("proxy")
+	caCert, _ := cmd.Root().PersistentFlags().GetString("ca-cert")
+	tlsMinVersion, _ := cmd.Root().PersistentFlags().GetString("tls-min-version")
+	insecureSkipVerify, _ := cmd.Root().PersistentFlags().GetBool("insecure-skip-verify")
+	clientCert, _ := cmd.Root().PersistentFlags().GetString("client-cert")
+	clientKey, _ := cmd.Root().PersistentFlags().GetString("client-key")
+
+	verifyEndpoint, _ := cmd.Root().PersistentFlags().GetBool("verify-endpoint")
+
+	if err := validateAPIURL(apiURL); err != nil {
+		return nil, err
+	}
+	useOrgAsApp, err := parseOrgAsAppMode(viper.GetString("use-org-as-app"))
+	if err != nil {
+		return nil, err
+	}
+	client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp == orgAsAppEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CloudBees client: %w", err)
+	}
+	client.SetOrgAsAppFallback(useOrgAsApp == orgAsAppAuto)
+
+	if proxy != "" {
+		if err := client.SetProxy(proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	if caCert != "" || tlsMinVersion != "" || insecureSkipVerify || clientCert != "" || clientKey != "" {
+		err := client.ConfigureTLS(cloudbees.TLSOptions{
+			CACertFile:         caCert,
+			MinVersion:         tlsMinVersion,
+			InsecureSkipVerify: insecureSkipVerify,
+			ClientCertFile:     clientCert,
+			ClientKeyFile:      clientKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if httpDebugFile != "" {
+		if err := enableHTTPTrace(client, httpDebugFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if tokenProvider != nil && token == providedToken {
+		client.SetTokenProvider(tokenProvider)
+	}
+	client.SetReadOnly(readOnly())
+	client.SetMaxRequestsPerSecond(maxRPS)
+	client.SetMaxItems(maxItems)
+	client.SetETagCache(etagCache)
+	if cache := responseCache(cmd, apiURL, orgID); cache != nil {
+		client.SetDiskCache(cache)
+	}
+
+	client.SetCircuitBreaker(breakerThreshold)
+
+	client.SetUserAgent(userAgent())
+	if telemetryProvider != nil {
+		client.SetObserver(telemetryProvider)
+	}
+	if verbose {
+		client.SetRequestLog(os.Stderr)
+	}
+
+	if verifyEndpoint {
+		if err := client.VerifyEndpoint(); err != nil {
+			return nil, err
+		}
+	}
+
+	return client, nil
+}
+
+// userAgent identifies the CLI, its version and platform in API requests
+func userAgent() string {
+	return fmt.Sprintf("fm-actions/%s (%s; %s/%s)", version, runtime.Version(), runtime.GOOS, runtime.GOARCH)
+}
+
+// Modes of --use-org-as-app
+const (
+	orgAsAppDisabled = "false"
+	orgAsAppEnabled  = "true"
+	orgAsAppAuto     = "auto" // Fall back to the legacy mode when the application does not exist
+)
+
+// orgAsAppValue is the value of --use-org-as-app, a boolean option that also accepts auto
+type orgAsAppValue string
+
+func (v *orgAsAppValue) String() string { return string(*v) }
+
+func (v *orgAsAppValue) Set(value string) error {
+	mode, err := parseOrgAsAppMode(value)
+	if err != nil {
+		return err
+	}
+	*v = orgAsAppValue(mode)
+	return nil
+}
+
+func (v *orgAsAppValue) Type() string { return "bool|auto" }
+
+// parseOrgAsAppMode normalizes a --use-org-as-app (or FM_USE_ORG_AS_APP) value
+func parseOrgAsAppMode(value string) (string, error) {
+	switch {
+	case value == "":
+		return orgAsAppDisabled, nil
+	case strings.EqualFold(value, orgAsAppAuto):
+		return orgAsAppAuto, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid use-org-as-app value '%s', must be true, false or auto", value)
+	}
+	return strconv.FormatBool(enabled), nil
+}
+
+// writeClientOutputs writes outputs describing API usage of all clients created during this invocation
+func writeClientOutputs() {
+	if len(activeClients) == 0 {
+		return
+	}
+
+	var rateLimited int64
+	for _, client := range activeClients {
+		rateLimited += client.RateLimitedCount()
+	}
+	cloudbees.WriteOutput("rate-limited-count", fmt.Sprintf("%d", rateLimited))
+	if verbose && rateLimited > 0 {
+		fmt.Printf("Rate limited by the API %d time(s)\n", rateLimited)
+	}
+	if verbose && etagCache.Hits() > 0 {
+		fmt.Printf("%d response(s) not modified since they were cached\n", etagCache.Hits())
+	}
+}
+
+// exportTelemetry ends the trace of the command and exports it with the API call metrics
+func exportTelemetry(cmd *cobra.Command, err error) {
+	if telemetryProvider == nil || cmd == nil {
+		return
+	}
+	if exportErr := telemetryProvider.Shutdown(cmd.CommandPath(), err); exportErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", exportErr)
+	}
+}
+
+// enableHTTPTrace records the HTTP traffic of a client to the trace file of this invocation,
+// opening it for the first client
+func enableHTTPTrace(client *cloudbees.Client, filename string) error {
+	httpTraceMu.Lock()
+	defer httpTraceMu.Unlock()
+	if httpTrace != nil {
+		client.SetHTTPTrace(httpTrace)
+		return nil
+	}
+	trace, err := client.EnableHTTPTrace(filename)
+	if err != nil {
+		return err
+	}
+	httpTrace = trace
+	return nil
+}
+
+// closeHTTPTrace closes the HTTP trace file, if one was opened
+func closeHTTPTrace() error {
+	httpTraceMu.Lock()
+	defer httpTraceMu.Unlock()
+	if httpTrace == nil {
+		return nil
+	}
+	err := httpTrace.Close()
+	httpTrace = nil
+	if err != nil {
+		return fmt.Errorf("failed to close HTTP debug file: %w", err)
+	}
+	return nil
+}
+
+// poolOptions returns worker pool settings for multi-item commands from the global flags
+func poolOptions(cmd *cobra.Command) workerpool.Options {
+	concurrency, _ := cmd.Root().PersistentFlags().GetInt("concurrency")
+	failFast, _ := cmd.Root().PersistentFlags().GetBool("fail-fast")
+
+	return workerpool.Options{
+		Concurrency: concurrency,
+		FailFast:    failFast,
+	}
+}
+
+// bulkErr reports the per-item results of a bulk command in the succeeded, failed, skipped and
+// failures outputs, and returns the failure only when more items failed than --max-failures tolerates
+func bulkErr[T any](cmd *cobra.Command, results workerpool.Results[T]) error {
+	failuresJSON, _ := json.Marshal(results.Failures())
+	cloudbees.WriteOutput("succeeded", fmt.Sprintf("%d", results.Succeeded()))
+	cloudbees.WriteOutput("failed", fmt.Sprintf("%d", results.Failed()))
+	cloudbees.WriteOutput("skipped", fmt.Sprintf("%d", results.Skipped()))
+	cloudbees.WriteOutput("failures", string(failuresJSON))
+
+	err := results.Err()
+	if err == nil {
+		return nil
+	}
+	if results.Failed() > maxFailures(cmd) {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %v (tolerated by --max-failures)\n", err)
+	return nil
+}
+
+// maxFailures returns how many items a bulk command tolerates failing
+func maxFailures(cmd *cobra.Command) int {
+	limit, _ := cmd.Root().PersistentFlags().GetInt("max-failures")
+	return limit
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI serves one application with one flag in one environment. Methods it does not
+// override panic through the nil embedded API.
+type fakeAPI struct {
+	cloudbees.API
+	config cloudbees.FlagConfiguration
+}
+
+func (f *fakeAPI) GetApplicationByName(name string) (*cloudbees.Application, error) {
+	if name != "storefront" {
+		return nil, cloudbees.ErrNotFound
+	}
+	return &cloudbees.Application{ID: "app-1", Name: name}, nil
+}
+
+func (f *fakeAPI) GetFlagByName(applicationID, flagName string) (*cloudbees.Flag, error) {
+	return &cloudbees.Flag{ID: "flag-1", Name: flagName, FlagType: "Boolean"}, nil
+}
+
+func (f *fakeAPI) GetEnvironmentByName(name string) (*cloudbees.Environment, error) {
+	return &cloudbees.Environment{ID: "env-1", Name: name}, nil
+}
+
+func (f *fakeAPI) GetFlagConfiguration(applicationID, flagID, environmentID string) (*cloudbees.FlagConfigurationDetail, error) {
+	return &cloudbees.FlagConfigurationDetail{Configuration: f.config, Revision: "3"}, nil
+}
+
+// useFakeAPI makes the commands use api instead of the platform for the rest of the test
+func useFakeAPI(t *testing.T, api cloudbees.API) {
+	factory := clientFactory
+	clientFactory = func(cmd *cobra.Command) (cloudbees.API, error) { return api, nil }
+	t.Cleanup(func() { clientFactory = factory })
+}
+
+func TestClientFactory(t *testing.T) {
+	outputs := t.TempDir()
+	t.Setenv("CLOUDBEES_OUTPUTS", outputs)
+	useFakeAPI(t, &fakeAPI{config: cloudbees.FlagConfiguration{Enabled: true}})
+
+	rootCmd.SetArgs([]string{"get-flag-config", "checkout", "-e", "production",
+		"--token=test-token", "--org-id=test-org", "--application-name=storefront"})
+	require.NoError(t, rootCmd.Execute())
+
+	enabled, err := os.ReadFile(filepath.Join(outputs, "enabled"))
+	require.NoError(t, err)
+	assert.Equal(t, "true", string(enabled))
+	environmentID, err := os.ReadFile(filepath.Join(outputs, "environment-id"))
+	require.NoError(t, err)
+	assert.Equal(t, "env-1", string(environmentID))
+}
+
+// TestHTTPTraceShared tests that the clients of a run share one trace file, closed at the end
+func TestHTTPTraceShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"environments":[]}`)
+	}))
+	defer server.Close()
+	debugFile := filepath.Join(t.TempDir(), "http-debug.log")
+
+	for _, token := range []string{"first-token", "second-token"} {
+		client, err := cloudbees.NewClientWithOptions(server.URL, token, "test-org", false)
+		require.NoError(t, err)
+		require.NoError(t, enableHTTPTrace(client, debugFile))
+		_, err = client.ListEnvironments()
+		require.NoError(t, err)
+	}
+	trace := httpTrace
+	require.NoError(t, closeHTTPTrace())
+	assert.Nil(t, httpTrace)
+	_, err := trace.Write([]byte("after close"))
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(debugFile)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "GET "+server.URL))
+	assert.NotContains(t, string(data), "-token")
+}
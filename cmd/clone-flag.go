@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var cloneFlagCmd = &cobra.Command{
+	Use:   "clone-flag",
+	Short: "Create a new flag as a copy of an existing flag",
+	Long: `Create a new feature flag with the same type, variants and description as an existing flag,
+optionally copying its configuration in every (or selected) environments.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceName, _ := cmd.Flags().GetString("source")
+		targetName, _ := cmd.Flags().GetString("target")
+		description, _ := cmd.Flags().GetString("description")
+		copyConfig, _ := cmd.Flags().GetBool("copy-config")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if sourceName == "" || targetName == "" {
+			return fmt.Errorf("source and target are required")
+		}
+		if sourceName == targetName {
+			return fmt.Errorf("source and target must be different")
+		}
+
+		client, ctx, err := commandContext(cmd, sourceName, "")
+		if err != nil {
+			return err
+		}
+		application, source := ctx.Application, ctx.Flag
+
+		if description == "" {
+			description = source.Description
+		}
+
+		// Resolve the environments whose configuration is copied
+		var environments []cloudbees.Environment
+		if copyConfig {
+			allEnvironments, err := client.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments: %w", err)
+			}
+			environments, err = selectEnvironments(allEnvironments, environmentNames)
+			if err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would clone flag '%s' to '%s'\n", source.Name, targetName)
+			fmt.Printf("Type: %s\n", source.FlagType)
+			fmt.Printf("Description: %s\n", description)
+			fmt.Printf("Variants: %s\n", strings.Join(source.Variants, ", "))
+			fmt.Printf("Permanent: %t\n", source.IsPermanent)
+			for _, env := range environments {
+				fmt.Printf("Copy configuration: %s\n", env.Name)
+			}
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "clone-flag",
+			Application: application.Name,
+			Flag:        targetName,
+			Labels:      source.Labels,
+			Changes:     map[string]interface{}{"source": source.Name, "description": description},
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		target, err := client.CreateFlag(application.ID, targetName, source.FlagType, description, source.Variants, source.IsPermanent)
+		change.After = target
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to create flag: %w", err)
+		}
+
+		results := workerpool.Run(environments, func(env cloudbees.Environment) string { return env.Name }, poolOptions(cmd),
+			func(env cloudbees.Environment) (bool, error) {
+				config, err := client.GetFlagConfiguration(application.ID, source.ID, env.ID)
+				if err != nil {
+					return false, err
+				}
+				changes := configurationChanges(config.Configuration)
+				change := mutation{
+					Operation:   "clone-flag",
+					Application: application.Name,
+					Flag:        target.Name,
+					Labels:      source.Labels,
+					Environment: env.Name,
+					Changes:     changes,
+					After:       changes,
+				}
+				if err := beforeMutation(cmd, change); err != nil {
+					return false, err
+				}
+				err = client.SetFlagConfiguration(application.ID, target.ID, env.ID, changes)
+				afterMutation(cmd, change, err)
+				if err != nil {
+					return false, err
+				}
+				return true, nil
+			})
+
+		// Output results
+		flagJSON, _ := json.Marshal(target)
+		resultsJSON, _ := json.Marshal(results)
+		cloudbees.WriteOutput("flag-id", target.ID)
+		cloudbees.WriteOutput("flag-name", target.Name)
+		cloudbees.WriteOutput("source-flag-id", source.ID)
+		cloudbees.WriteOutput("flag", string(flagJSON))
+		cloudbees.WriteOutput("copied-environments", string(resultsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+		if verbose {
+			fmt.Printf("Successfully cloned flag: %s -> %s (ID: %s)\n", source.Name, target.Name, target.ID)
+			for _, result := range results {
+				if result.Err == nil {
+					fmt.Printf("Copied configuration: %s\n", result.Name)
+				}
+			}
+		}
+
+		if err := results.Err(); err != nil {
+			return fmt.Errorf("flag created but copying configuration failed: %w", err)
+		}
+
+		return nil
+	},
+}
+
+// selectEnvironments returns the environments matching names, or all enabled environments when names is empty
+func selectEnvironments(environments []cloudbees.Environment, names []string) ([]cloudbees.Environment, error) {
+	if len(names) == 0 {
+		var selected []cloudbees.Environment
+		for _, env := range environments {
+			if !env.IsDisabled {
+				selected = append(selected, env)
+			}
+		}
+		return selected, nil
+	}
+
+	var selected []cloudbees.Environment
+	for _, name := range names {
+		found := false
+		for _, env := range environments {
+			if env.Name == name {
+				selected = append(selected, env)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("environment '%s' not found", name)
+		}
+	}
+	return selected, nil
+}
+
+func init() {
+	rootCmd.AddCommand(cloneFlagCmd)
+
+	cloneFlagCmd.Flags().String("source", "", "Name of the flag to clone (required)")
+	cloneFlagCmd.Flags().String("target", "", "Name of the new flag (required)")
+	cloneFlagCmd.Flags().StringP("description", "d", "", "Description of the new flag (defaults to the source description)")
+	cloneFlagCmd.Flags().Bool("copy-config", false, "Copy the source flag configuration to the new flag")
+	cloneFlagCmd.Flags().StringSlice("environments", nil, "Environments to copy configuration for (defaults to all enabled environments)")
+	cloneFlagCmd.Flags().Bool("dry-run", false, "Show what would be cloned without creating")
+
+	cloneFlagCmd.MarkFlagRequired("source")
+	cloneFlagCmd.MarkFlagRequired("target")
+}
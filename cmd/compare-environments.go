@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// fieldDifference describes a configuration field that differs between two environments
+type fieldDifference struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// flagComparison holds both configurations of a flag and their differences
+type flagComparison struct {
+	FlagID      string                      `json:"flagId"`
+	FlagName    string                      `json:"flagName"`
+	Labels      []string                    `json:"-"`
+	From        cloudbees.FlagConfiguration `json:"-"`
+	To          cloudbees.FlagConfiguration `json:"-"`
+	ToETag      string                      `json:"-"`
+	Differences []fieldDifference           `json:"differences"`
+}
+
+var compareEnvironmentsCmd = &cobra.Command{
+	Use:   "compare-environments",
+	Short: "Compare flag configurations between two environments",
+	Long: `Compare the configuration of every flag in the application between two environments
+and report enabled mismatches, default value drift and conditions drift.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromName, _ := cmd.Flags().GetString("from")
+		toName, _ := cmd.Flags().GetString("to")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+
+		if fromName == "" || toName == "" {
+			return fmt.Errorf("from and to environments are required")
+		}
+		if fromName == toName {
+			return fmt.Errorf("from and to environments must be different")
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		fromEnv, err := client.GetEnvironmentByName(fromName)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+		toEnv, err := client.GetEnvironmentByName(toName)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		allFlags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+
+		var flags []cloudbees.Flag
+		for _, flag := range allFlags {
+			if hasAnyLabel(flag, labels) {
+				flags = append(flags, flag)
+			}
+		}
+
+		results := compareFlags(client, application.ID, flags, fromEnv.ID, toEnv.ID, poolOptions(cmd))
+		if err := results.Err(); err != nil {
+			return fmt.Errorf("failed to compare flags: %w", err)
+		}
+
+		var drifted []flagComparison
+		for _, result := range results {
+			if len(result.Value.Differences) > 0 {
+				drifted = append(drifted, result.Value)
+			}
+		}
+
+		// Output results
+		if drifted == nil {
+			drifted = []flagComparison{}
+		}
+		differencesJSON, _ := json.Marshal(drifted)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(flags)))
+		cloudbees.WriteOutput("difference-count", fmt.Sprintf("%d", len(drifted)))
+		cloudbees.WriteOutput("differences", string(differencesJSON))
+		writeArtifact(cmd, "differences", drifted)
+
+		if len(drifted) == 0 {
+			fmt.Printf("No differences between '%s' and '%s' (%d flags compared)\n", fromName, toName, len(flags))
+			return nil
+		}
+
+		fmt.Printf("%d of %d flags differ between '%s' and '%s':\n", len(drifted), len(flags), fromName, toName)
+		for _, comparison := range drifted {
+			fmt.Printf("- %s\n", comparison.FlagName)
+			for _, diff := range comparison.Differences {
+				fromJSON, _ := json.Marshal(diff.From)
+				toJSON, _ := json.Marshal(diff.To)
+				fmt.Printf("  %s: %s -> %s\n", diff.Field, fromJSON, toJSON)
+			}
+		}
+
+		return nil
+	},
+}
+
+// compareFlags fetches the configuration of each flag in both environments and diffs them
+func compareFlags(client cloudbees.API, applicationID string, flags []cloudbees.Flag, fromEnvID, toEnvID string, opts workerpool.Options) workerpool.Results[flagComparison] {
+	return workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, opts,
+		func(flag cloudbees.Flag) (flagComparison, error) {
+			comparison := flagComparison{FlagID: flag.ID, FlagName: flag.Name, Labels: flag.Labels}
+
+			from, err := client.GetFlagConfiguration(applicationID, flag.ID, fromEnvID)
+			if err != nil {
+				return comparison, err
+			}
+			to, err := client.GetFlagConfiguration(applicationID, flag.ID, toEnvID)
+			if err != nil {
+				return comparison, err
+			}
+
+			comparison.From = from.Configuration
+			comparison.To = to.Configuration
+			comparison.ToETag = to.ETag
+			comparison.Differences = diffConfigurations(from.Configuration, to.Configuration)
+			return comparison, nil
+		})
+}
+
+// diffConfigurations returns the fields that differ between two configurations
+func diffConfigurations(from, to cloudbees.FlagConfiguration) []fieldDifference {
+	var diffs []fieldDifference
+
+	if from.Enabled != to.Enabled {
+		diffs = append(diffs, fieldDifference{Field: "enabled", From: from.Enabled, To: to.Enabled})
+	}
+	if !reflect.DeepEqual(from.DefaultValue, to.DefaultValue) {
+		diffs = append(diffs, fieldDifference{Field: "defaultValue", From: from.DefaultValue, To: to.DefaultValue})
+	}
+	if !reflect.DeepEqual(from.Conditions, to.Conditions) {
+		diffs = append(diffs, fieldDifference{Field: "conditions", From: from.Conditions, To: to.Conditions})
+	}
+	if from.VariantsEnabled != to.VariantsEnabled {
+		diffs = append(diffs, fieldDifference{Field: "variantsEnabled", From: from.VariantsEnabled, To: to.VariantsEnabled})
+	}
+	if from.StickinessProperty != to.StickinessProperty {
+		diffs = append(diffs, fieldDifference{Field: "stickinessProperty", From: from.StickinessProperty, To: to.StickinessProperty})
+	}
+
+	return diffs
+}
+
+func init() {
+	rootCmd.AddCommand(compareEnvironmentsCmd)
+
+	compareEnvironmentsCmd.Flags().String("from", "", "Source environment name (required)")
+	compareEnvironmentsCmd.Flags().String("to", "", "Target environment name (required)")
+	compareEnvironmentsCmd.Flags().StringSlice("label", nil, "Only compare flags with this label (repeatable)")
+
+	compareEnvironmentsCmd.MarkFlagRequired("from")
+	compareEnvironmentsCmd.MarkFlagRequired("to")
+}
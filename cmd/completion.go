@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+// completionCacheTTL is how long values fetched from the API for shell completion are reused
+const completionCacheTTL = 5 * time.Minute
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the shell completion script",
+	Long: `Generate the completion script for bash, zsh, fish or PowerShell. Besides commands and options,
+the script completes flag, environment and application names from the API, using the connection
+options already typed on the command line. Fetched names are cached for 5 minutes.
+
+  bash:       source <(fm-actions completion bash)
+  zsh:        fm-actions completion zsh > "${fpath[1]}/_fm-actions"
+  fish:       fm-actions completion fish > ~/.config/fish/completions/fm-actions.fish
+  powershell: fm-actions completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 && (args[0] == "-h" || args[0] == "--help") {
+			return nil
+		}
+		return cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs)(cmd, args)
+	},
+	// Generating the script needs no connection options, so they are not parsed nor required
+	DisableFlagParsing:    true,
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "-h", "--help":
+			return cmd.Help()
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(os.Stdout)
+		case "fish":
+			return cmd.Root().GenFishCompletion(os.Stdout, true)
+		default:
+			return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+	},
+}
+
+// registerCompletions adds the dynamic completion of flag, environment and application names
+// to every command that has the corresponding option
+func registerCompletions(root *cobra.Command) {
+	root.RegisterFlagCompletionFunc("application-name", completeApplicationNames)
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for name, complete := range map[string]cobra.CompletionFunc{
+			"flag-name":             completeFlagNames,
+			"environment-name":      completeEnvironmentNames,
+			"environments":          completeEnvironmentNames,
+			"to-application":        completeApplicationNames,
+			"linked-to-application": completeApplicationNames,
+			"tier":                  completeTiers,
+		} {
+			if cmd.LocalNonPersistentFlags().Lookup(name) != nil {
+				cmd.RegisterFlagCompletionFunc(name, complete)
+			}
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// completeFlagNames completes the names of the flags of --application-name
+func completeFlagNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+	if applicationName == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeFromAPI(cmd, "flags/"+applicationName, func() ([]string, error) {
+		client, err := newCompletionClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		application, err := client.GetApplicationByName(applicationName)
+		if err != nil {
+			return nil, err
+		}
+		flags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(flags))
+		for _, flag := range flags {
+			names = append(names, flag.Name)
+		}
+		return names, nil
+	})
+}
+
+// completeEnvironmentNames completes the names of the environments of the organization
+func completeEnvironmentNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return completeFromAPI(cmd, "environments", func() ([]string, error) {
+		client, err := newCompletionClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		environments, err := client.ListEnvironments()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(environments))
+		for _, env := range environments {
+			names = append(names, env.Name)
+		}
+		return names, nil
+	})
+}
+
+// completeApplicationNames completes the names of the applications of the organization
+func completeApplicationNames(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return completeFromAPI(cmd, "applications", func() ([]string, error) {
+		client, err := newCompletionClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+		applications, err := client.ListApplications()
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(applications))
+		for _, app := range applications {
+			names = append(names, app.Name)
+		}
+		return names, nil
+	})
+}
+
+// newCompletionClient creates a client from the connection options typed so far. It is not
+// registered for the client outputs, which are meaningless while completing.
+func newCompletionClient(cmd *cobra.Command) (*cloudbees.Client, error) {
+	token, _ := cmd.Root().PersistentFlags().GetString("token")
+	return newClientWithToken(cmd, token)
+}
+
+// completeFromAPI returns the values cached for kind in this organization, or fetches them.
+// Completion fails silently, so a missing token or an API error never breaks the shell.
+func completeFromAPI(cmd *cobra.Command, kind string, fetch func() ([]string, error)) ([]cobra.Completion, cobra.ShellCompDirective) {
+	apiURL, _ := resolveAPIURL(cmd)
+	orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
+	if orgID == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	key := sha256.Sum256([]byte(apiURL + "\x00" + orgID + "\x00" + kind))
+	cacheFile := ""
+	if dir, err := os.UserCacheDir(); err == nil {
+		cacheFile = filepath.Join(dir, "fm-actions", "completion", hex.EncodeToString(key[:8])+".json")
+	}
+
+	var cached struct {
+		Fetched time.Time `json:"fetched"`
+		Values  []string  `json:"values"`
+	}
+	if data, err := os.ReadFile(cacheFile); err == nil && json.Unmarshal(data, &cached) == nil && time.Since(cached.Fetched) < completionCacheTTL {
+		return cached.Values, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	values, err := fetch()
+	if err != nil {
+		cobra.CompDebugln(fmt.Sprintf("failed to complete %s: %v", kind, err), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	cached.Fetched = time.Now()
+	cached.Values = values
+	if data, err := json.Marshal(cached); err == nil && cacheFile != "" {
+		if err := os.MkdirAll(filepath.Dir(cacheFile), 0700); err == nil {
+			os.WriteFile(cacheFile, data, 0600)
+		}
+	}
+	return values, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	rootCmd.AddCommand(completionCmd)
+}
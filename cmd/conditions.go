@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/evaluate"
+)
+
+// whenOperators maps the operators accepted by --when to condition operators
+var whenOperators = map[string]string{
+	"is":           "=",
+	"is-not":       "!=",
+	"in":           "in-array",
+	"not-in":       "not-in-array",
+	"matches":      "regex",
+	"lt":           "<",
+	"lte":          "<=",
+	"gt":           ">",
+	"gte":          ">=",
+	"is-true":      "is-true",
+	"is-false":     "is-false",
+	"is-undefined": "is-undefined",
+	"semver-eq":    "semver-eq",
+	"semver-gt":    "semver-gt",
+	"semver-gte":   "semver-gte",
+	"semver-lt":    "semver-lt",
+	"semver-lte":   "semver-lte",
+}
+
+// operatorPropertyTypes lists the property types each condition operator applies to
+var operatorPropertyTypes = map[string][]string{
+	"=":            {cloudbees.PropertyTypeString, cloudbees.PropertyTypeNumber, cloudbees.PropertyTypeSemver},
+	"!=":           {cloudbees.PropertyTypeString, cloudbees.PropertyTypeNumber, cloudbees.PropertyTypeSemver},
+	"in-array":     {cloudbees.PropertyTypeString, cloudbees.PropertyTypeNumber},
+	"not-in-array": {cloudbees.PropertyTypeString, cloudbees.PropertyTypeNumber},
+	"regex":        {cloudbees.PropertyTypeString},
+	"<":            {cloudbees.PropertyTypeNumber},
+	"<=":           {cloudbees.PropertyTypeNumber},
+	">":            {cloudbees.PropertyTypeNumber},
+	">=":           {cloudbees.PropertyTypeNumber},
+	"is-true":      {cloudbees.PropertyTypeBoolean},
+	"is-false":     {cloudbees.PropertyTypeBoolean},
+	"is-undefined": {cloudbees.PropertyTypeBoolean, cloudbees.PropertyTypeString, cloudbees.PropertyTypeNumber, cloudbees.PropertyTypeSemver},
+	"semver-eq":    {cloudbees.PropertyTypeSemver},
+	"semver-gt":    {cloudbees.PropertyTypeSemver},
+	"semver-gte":   {cloudbees.PropertyTypeSemver},
+	"semver-lt":    {cloudbees.PropertyTypeSemver},
+	"semver-lte":   {cloudbees.PropertyTypeSemver},
+}
+
+// parseWhen builds a condition from a --when expression, e.g.
+// "property=plan op=in value=enterprise,team serve=true". Values with spaces can be quoted.
+// Without a flag, as in an offline dry run, the serve variant is kept as given.
+func parseWhen(expr string, flag *cloudbees.Flag) (evaluate.Condition, error) {
+	fields, err := splitQuoted(expr)
+	if err != nil {
+		return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': %w", expr, err)
+	}
+
+	values := map[string]string{}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': '%s' is not key=value", expr, field)
+		}
+		switch key {
+		case "property", "op", "value", "serve":
+			values[key] = value
+		default:
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': unknown key '%s' (use property, op, value and serve)", expr, key)
+		}
+	}
+	for _, key := range []string{"property", "op", "serve"} {
+		if values[key] == "" {
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': %s is required", expr, key)
+		}
+	}
+
+	operator, ok := whenOperators[values["op"]]
+	if !ok {
+		return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': unknown operator '%s'", expr, values["op"])
+	}
+	property := &evaluate.Property{Name: values["property"], Operator: operator}
+
+	value, hasValue := values["value"]
+	switch operator {
+	case "is-true", "is-false", "is-undefined":
+		if hasValue {
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': %s takes no value", expr, values["op"])
+		}
+	case "in-array", "not-in-array":
+		var operands []interface{}
+		for _, item := range strings.Split(value, ",") {
+			operands = append(operands, item)
+		}
+		property.Operand = operands
+	case "<", "<=", ">", ">=":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': %s needs a number", expr, values["op"])
+		}
+		property.Operand = number
+	default:
+		if !hasValue {
+			return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': value is required", expr)
+		}
+		property.Operand = value
+	}
+
+	if flag == nil {
+		return evaluate.Condition{Property: property, Value: values["serve"]}, nil
+	}
+	serve, err := variantValue(flag, values["serve"])
+	if err != nil {
+		return evaluate.Condition{}, fmt.Errorf("invalid condition '%s': %w", expr, err)
+	}
+	return evaluate.Condition{Property: property, Value: serve}, nil
+}
+
+// validateConditionProperties checks that the conditions only use existing properties, with operators
+// matching their type. Validation is skipped with a warning when the API does not list properties.
+func validateConditionProperties(client cloudbees.API, applicationID string, conditions []evaluate.Condition) error {
+	properties, err := client.ListProperties(applicationID)
+	if errors.Is(err, cloudbees.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Warning: properties are not available, conditions are not validated against them\n")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list properties: %w", err)
+	}
+
+	types := map[string]string{}
+	names := make([]string, 0, len(properties))
+	for _, property := range properties {
+		types[property.Name] = property.Type
+		names = append(names, property.Name)
+	}
+	sort.Strings(names)
+
+	for _, condition := range conditions {
+		property := condition.Property
+		propertyType, ok := types[property.Name]
+		if !ok {
+			return fmt.Errorf("unknown property '%s' (properties: %s)", property.Name, strings.Join(names, ", "))
+		}
+		if !containsString(operatorPropertyTypes[property.Operator], propertyType) {
+			return fmt.Errorf("operator %s cannot be used with %s property '%s'", property.Operator, propertyType, property.Name)
+		}
+	}
+	return nil
+}
+
+// splitQuoted splits a string on whitespace, keeping single or double quoted parts together
+func splitQuoted(s string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	var quote rune
+	inField := false
+	for _, r := range s {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote, inField = r, true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
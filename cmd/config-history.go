@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "config-history",
+	Short: "List previous revisions of a flag configuration",
+	Long: `List the revisions of a flag's configuration in an environment, newest first, with when and by
+whom each was made. Inspect a revision with get-flag-config --revision and restore it with
+rollback-flag-config.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		client, ctx, err := commandContext(cmd, flagName, environmentName)
+		if err != nil {
+			return err
+		}
+		application, flag, environment := ctx.Application, ctx.Flag, ctx.Environment
+
+		revisions, err := client.ListFlagConfigurationRevisions(application.ID, flag.ID, environment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list configuration revisions: %w", err)
+		}
+		if limit > 0 && len(revisions) > limit {
+			revisions = revisions[:limit]
+		}
+
+		// Output results
+		if revisions == nil {
+			revisions = []cloudbees.ConfigurationRevision{}
+		}
+		revisionsJSON, _ := json.Marshal(revisions)
+		cloudbees.WriteOutput("revisions", string(revisionsJSON))
+		cloudbees.WriteOutput("revision-count", fmt.Sprintf("%d", len(revisions)))
+
+		fmt.Printf("Configuration history of flag '%s' in '%s':\n", flag.Name, environmentName)
+		for _, revision := range revisions {
+			fmt.Printf("- %s  %s  %s  %s\n", revision.Revision, revision.Timestamp, revision.Actor, revisionSummary(revision.Configuration))
+		}
+		return nil
+	},
+}
+
+// revisionSummary describes a configuration in one line, e.g. enabled=true default=false conditions=2
+func revisionSummary(config cloudbees.FlagConfiguration) string {
+	defaultJSON, _ := json.Marshal(config.DefaultValue)
+	summary := fmt.Sprintf("enabled=%t default=%s", config.Enabled, defaultJSON)
+	if conditions, ok := config.Conditions.([]interface{}); ok && len(conditions) > 0 {
+		summary += fmt.Sprintf(" conditions=%d", len(conditions))
+	}
+	return summary
+}
+
+func init() {
+	rootCmd.AddCommand(configHistoryCmd)
+
+	configHistoryCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	configHistoryCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	configHistoryCmd.Flags().Int("limit", 20, "Maximum number of revisions to list (0 for all)")
+
+	flagNameArg(configHistoryCmd)
+
+	configHistoryCmd.MarkFlagRequired("flag-name")
+	configHistoryCmd.MarkFlagRequired("environment-name")
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// confirmAction guards a destructive action. It proceeds with --confirm (on commands that have it)
+// or the global --yes, asks y/N on an interactive terminal, and fails otherwise so pipelines never
+// block on a prompt.
+func confirmAction(cmd *cobra.Command, action string) error {
+	confirm, _ := cmd.Flags().GetBool("confirm")
+	yes, _ := cmd.Root().PersistentFlags().GetBool("yes")
+	if confirm || yes {
+		return nil
+	}
+
+	if !isTerminal(os.Stdin) || os.Getenv("CI") == "true" {
+		option := "--yes"
+		if cmd.Flags().Lookup("confirm") != nil {
+			option = "--confirm"
+		}
+		if cmd.Flags().Lookup("dry-run") != nil {
+			return fmt.Errorf("this action will %s. Use %s to proceed or --dry-run to preview", action, option)
+		}
+		return fmt.Errorf("this action will %s. Use %s to proceed", action, option)
+	}
+
+	fmt.Fprintf(os.Stderr, "This action will %s. Continue? [y/N] ", action)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("aborted")
+	}
+}
+
+// isTerminal reports whether f is an interactive terminal, i.e. a character device other than /dev/null
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
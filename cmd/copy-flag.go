@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var copyFlagCmd = &cobra.Command{
+	Use:   "copy-flag",
+	Short: "Copy or move a flag to another application",
+	Long: `Recreate a feature flag with the same type, variants, description and labels under another
+application of the organization, optionally copying its configuration in every (or selected)
+environments, e.g. when a service is extracted from a monolith. With --move the flag is deleted
+from the source application once it was copied.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		targetAppName, _ := cmd.Flags().GetString("to-application")
+		newName, _ := cmd.Flags().GetString("new-name")
+		copyConfig, _ := cmd.Flags().GetBool("copy-config")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		move, _ := cmd.Flags().GetBool("move")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if flagName == "" || targetAppName == "" {
+			return fmt.Errorf("flag-name and to-application are required")
+		}
+		if targetAppName == applicationName {
+			return fmt.Errorf("to-application must be different from the application, use clone-flag to copy a flag within an application")
+		}
+		if move && !dryRun {
+			if err := confirmAction(cmd, fmt.Sprintf("permanently delete the flag '%s' from '%s'", flagName, applicationName)); err != nil {
+				return err
+			}
+		}
+		if newName == "" {
+			newName = flagName
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+		targetApp, err := client.GetApplicationByName(targetAppName)
+		if err != nil {
+			return fmt.Errorf("failed to get application '%s': %w", targetAppName, err)
+		}
+
+		source, err := client.GetFlagByName(application.ID, flagName)
+		if err != nil {
+			return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+		}
+
+		targetFlags, err := client.ListFlags(targetApp.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags of '%s': %w", targetApp.Name, err)
+		}
+		for _, flag := range targetFlags {
+			if flag.Name == newName {
+				return fmt.Errorf("flag '%s' already exists in application '%s'", newName, targetApp.Name)
+			}
+		}
+
+		// Resolve the environments whose configuration is copied
+		var environments []cloudbees.Environment
+		if copyConfig {
+			allEnvironments, err := client.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments: %w", err)
+			}
+			environments, err = selectEnvironments(allEnvironments, environmentNames)
+			if err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would copy flag '%s' from '%s' to '%s/%s'\n", source.Name, application.Name, targetApp.Name, newName)
+			fmt.Printf("Type: %s\n", source.FlagType)
+			fmt.Printf("Variants: %s\n", strings.Join(source.Variants, ", "))
+			fmt.Printf("Permanent: %t\n", source.IsPermanent)
+			for _, env := range environments {
+				fmt.Printf("Copy configuration: %s\n", env.Name)
+			}
+			if move {
+				fmt.Printf("Would delete flag '%s' from '%s'\n", source.Name, application.Name)
+			}
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "copy-flag",
+			Application: targetApp.Name,
+			Flag:        newName,
+			Labels:      source.Labels,
+			Changes:     map[string]interface{}{"source": source.Name, "sourceApplication": application.Name},
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		target, err := client.CreateFlagFromRequest(targetApp.ID, cloudbees.CreateFlagRequest{
+			Name:        newName,
+			FlagType:    source.FlagType,
+			Variants:    source.Variants,
+			Description: source.Description,
+			IsPermanent: source.IsPermanent,
+			Labels:      source.Labels,
+		})
+		change.After = target
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to create flag: %w", err)
+		}
+
+		results := workerpool.Run(environments, func(env cloudbees.Environment) string { return env.Name }, poolOptions(cmd),
+			func(env cloudbees.Environment) (bool, error) {
+				config, err := client.GetFlagConfiguration(application.ID, source.ID, env.ID)
+				if err != nil {
+					return false, err
+				}
+				changes := configurationChanges(config.Configuration)
+				change := mutation{
+					Operation:   "copy-flag",
+					Application: targetApp.Name,
+					Flag:        target.Name,
+					Labels:      source.Labels,
+					Environment: env.Name,
+					Changes:     changes,
+					After:       changes,
+				}
+				if err := beforeMutation(cmd, change); err != nil {
+					return false, err
+				}
+				err = client.SetFlagConfiguration(targetApp.ID, target.ID, env.ID, changes)
+				afterMutation(cmd, change, err)
+				if err != nil {
+					return false, err
+				}
+				return true, nil
+			})
+
+		// Output results
+		flagJSON, _ := json.Marshal(target)
+		resultsJSON, _ := json.Marshal(results)
+		cloudbees.WriteOutput("flag-id", target.ID)
+		cloudbees.WriteOutput("flag-name", target.Name)
+		cloudbees.WriteOutput("application-id", targetApp.ID)
+		cloudbees.WriteOutput("source-flag-id", source.ID)
+		cloudbees.WriteOutput("flag", string(flagJSON))
+		cloudbees.WriteOutput("copied-environments", string(resultsJSON))
+
+		if err := results.Err(); err != nil {
+			cloudbees.WriteOutput("moved", "false")
+			cloudbees.WriteOutput("success", "false")
+			return fmt.Errorf("flag created but copying configuration failed: %w", err)
+		}
+
+		if verbose {
+			for _, result := range results {
+				fmt.Printf("Copied configuration: %s\n", result.Name)
+			}
+		}
+		fmt.Printf("Flag '%s' copied to '%s/%s' (ID: %s)\n", source.Name, targetApp.Name, target.Name, target.ID)
+
+		if move {
+			change := mutation{
+				Operation:   "delete-flag",
+				Application: application.Name,
+				Flag:        source.Name,
+				Labels:      source.Labels,
+				Before:      source,
+			}
+			if err := beforeMutation(cmd, change); err != nil {
+				return err
+			}
+			err = client.DeleteFlag(application.ID, source.ID)
+			afterMutation(cmd, change, err)
+			if err != nil {
+				cloudbees.WriteOutput("moved", "false")
+				cloudbees.WriteOutput("success", "false")
+				return fmt.Errorf("flag copied but deleting it from '%s' failed: %w", application.Name, err)
+			}
+			fmt.Printf("Flag '%s' deleted from '%s'\n", source.Name, application.Name)
+		}
+
+		cloudbees.WriteOutput("moved", fmt.Sprintf("%t", move))
+		cloudbees.WriteOutput("success", "true")
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(copyFlagCmd)
+
+	copyFlagCmd.Flags().StringP("flag-name", "f", "", "Name of the flag to copy (required)")
+	copyFlagCmd.Flags().String("to-application", "", "Application to copy the flag to (required)")
+	copyFlagCmd.Flags().String("new-name", "", "Name of the flag in the target application (defaults to the same name)")
+	copyFlagCmd.Flags().Bool("copy-config", false, "Copy the flag configuration to the target application")
+	copyFlagCmd.Flags().StringSlice("environments", nil, "Environments to copy configuration for (defaults to all enabled environments)")
+	copyFlagCmd.Flags().Bool("move", false, "Delete the flag from the source application after copying it")
+	copyFlagCmd.Flags().Bool("confirm", false, "Confirm that you want to delete the source flag (required with --move unless using dry-run)")
+	copyFlagCmd.Flags().Bool("dry-run", false, "Preview the copy without applying it")
+
+	flagNameArg(copyFlagCmd)
+
+	copyFlagCmd.MarkFlagRequired("flag-name")
+	copyFlagCmd.MarkFlagRequired("to-application")
+}
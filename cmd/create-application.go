@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var createApplicationCmd = &cobra.Command{
+	Use:   "create-application",
+	Short: "Create an application",
+	Long: `Create the application given by --application-name, optionally linked to environments, so a
+project bootstrap pipeline can set up feature management end-to-end. With --if-not-exists an
+existing application with the same name is reused instead of failing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		description, _ := cmd.Flags().GetString("description")
+		repositoryURL, _ := cmd.Flags().GetString("repository-url")
+		defaultBranch, _ := cmd.Flags().GetString("default-branch")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		ifNotExists, _ := cmd.Flags().GetBool("if-not-exists")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if applicationName == "" {
+			return fmt.Errorf("application-name is required")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		existing, err := client.GetApplicationByName(applicationName)
+		if err != nil && !errors.Is(err, cloudbees.ErrNotFound) {
+			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+		}
+		if existing != nil {
+			if !ifNotExists {
+				return fmt.Errorf("application '%s' already exists (ID: %s)", applicationName, existing.ID)
+			}
+			fmt.Printf("Application '%s' already exists (ID: %s)\n", existing.Name, existing.ID)
+			writeApplicationOutputs(existing)
+			cloudbees.WriteOutput("created", "false")
+			cloudbees.WriteOutput("success", "true")
+			return nil
+		}
+
+		var environmentIDs []string
+		if len(environmentNames) > 0 {
+			allEnvironments, err := client.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments: %w", err)
+			}
+			environments, err := selectEnvironments(allEnvironments, environmentNames)
+			if err != nil {
+				return err
+			}
+			for _, env := range environments {
+				environmentIDs = append(environmentIDs, env.ID)
+			}
+		}
+
+		request := cloudbees.Application{
+			Name:                 applicationName,
+			Description:          description,
+			RepositoryURL:        repositoryURL,
+			DefaultBranch:        defaultBranch,
+			LinkedEnvironmentIDs: environmentIDs,
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would create application '%s'\n", applicationName)
+			if description != "" {
+				fmt.Printf("Description: %s\n", description)
+			}
+			if repositoryURL != "" {
+				fmt.Printf("Repository: %s\n", repositoryURL)
+			}
+			for _, name := range environmentNames {
+				fmt.Printf("Link environment: %s\n", name)
+			}
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "create-application",
+			Application: applicationName,
+			Changes: map[string]interface{}{
+				"name":                 applicationName,
+				"description":          description,
+				"repositoryUrl":        repositoryURL,
+				"defaultBranch":        defaultBranch,
+				"linkedEnvironmentIds": environmentIDs,
+			},
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		application, err := client.CreateApplication(request)
+		change.After = application
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+
+		// Output results
+		writeApplicationOutputs(application)
+		cloudbees.WriteOutput("created", "true")
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully created application: %s (ID: %s)\n", application.Name, application.ID)
+		} else {
+			fmt.Printf("Application '%s' created successfully\n", application.Name)
+		}
+
+		return nil
+	},
+}
+
+// writeApplicationOutputs writes the outputs describing an application
+func writeApplicationOutputs(application *cloudbees.Application) {
+	linkedJSON, _ := json.Marshal(application.LinkedEnvironmentIDs)
+	cloudbees.WriteOutput("application-id", application.ID)
+	cloudbees.WriteOutput("application-name", application.Name)
+	cloudbees.WriteOutput("linked-environment-ids", string(linkedJSON))
+}
+
+func init() {
+	rootCmd.AddCommand(createApplicationCmd)
+
+	createApplicationCmd.Flags().String("description", "", "Description of the application")
+	createApplicationCmd.Flags().String("repository-url", "", "Source repository of the application")
+	createApplicationCmd.Flags().String("default-branch", "", "Default branch of the repository")
+	createApplicationCmd.Flags().StringSlice("environments", nil, "Environments to link to the application (repeatable)")
+	createApplicationCmd.Flags().Bool("if-not-exists", false, "Reuse an existing application with the same name instead of failing")
+	createApplicationCmd.Flags().Bool("dry-run", false, "Preview the application without creating it")
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var createEnvironmentCmd = &cobra.Command{
+	Use:   "create-environment",
+	Short: "Create an environment",
+	Long: `Create an environment in the organization, e.g. for a preview deployment of a pull request.
+With --if-not-exists an existing environment with the same name is reused instead of failing.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		description, _ := cmd.Flags().GetString("description")
+		disabled, _ := cmd.Flags().GetBool("disabled")
+		ifNotExists, _ := cmd.Flags().GetBool("if-not-exists")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		existing, err := client.GetEnvironmentByName(environmentName)
+		if err != nil && !errors.Is(err, cloudbees.ErrNotFound) {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+		if existing != nil {
+			if !ifNotExists {
+				return fmt.Errorf("environment '%s' already exists (ID: %s)", environmentName, existing.ID)
+			}
+			fmt.Printf("Environment '%s' already exists (ID: %s)\n", existing.Name, existing.ID)
+			writeEnvironmentOutputs(existing)
+			cloudbees.WriteOutput("created", "false")
+			cloudbees.WriteOutput("success", "true")
+			return nil
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would create environment '%s'\n", environmentName)
+			if description != "" {
+				fmt.Printf("Description: %s\n", description)
+			}
+			fmt.Printf("Disabled: %t\n", disabled)
+			return nil
+		}
+
+		environment, err := createEnvironment(cmd, client, cloudbees.CreateEnvironmentRequest{
+			Name:        environmentName,
+			Description: description,
+			IsDisabled:  disabled,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Output results
+		writeEnvironmentOutputs(environment)
+		cloudbees.WriteOutput("created", "true")
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully created environment: %s (ID: %s)\n", environment.Name, environment.ID)
+		} else {
+			fmt.Printf("Environment '%s' created successfully\n", environment.Name)
+		}
+
+		return nil
+	},
+}
+
+// createEnvironment creates an environment, running the guardrails and recording the change
+func createEnvironment(cmd *cobra.Command, client cloudbees.API, request cloudbees.CreateEnvironmentRequest) (*cloudbees.Environment, error) {
+	change := mutation{
+		Operation:   "create-environment",
+		Environment: request.Name,
+		Changes: map[string]interface{}{
+			"name":        request.Name,
+			"description": request.Description,
+			"isDisabled":  request.IsDisabled,
+		},
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return nil, err
+	}
+
+	environment, err := client.CreateEnvironment(request)
+	change.After = environment
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create environment: %w", err)
+	}
+	return environment, nil
+}
+
+// writeEnvironmentOutputs writes the outputs describing an environment
+func writeEnvironmentOutputs(environment *cloudbees.Environment) {
+	cloudbees.WriteOutput("environment-id", environment.ID)
+	cloudbees.WriteOutput("environment-name", environment.Name)
+	cloudbees.WriteOutput("disabled", fmt.Sprintf("%t", environment.IsDisabled))
+}
+
+func init() {
+	rootCmd.AddCommand(createEnvironmentCmd)
+
+	createEnvironmentCmd.Flags().StringP("environment-name", "e", "", "Name of the environment to create (required)")
+	createEnvironmentCmd.Flags().String("description", "", "Description of the environment")
+	createEnvironmentCmd.Flags().Bool("disabled", false, "Create the environment disabled")
+	createEnvironmentCmd.Flags().Bool("if-not-exists", false, "Reuse an existing environment with the same name instead of failing")
+	createEnvironmentCmd.Flags().Bool("dry-run", false, "Preview the environment without creating it")
+
+	createEnvironmentCmd.MarkFlagRequired("environment-name")
+}
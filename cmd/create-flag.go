@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -13,13 +19,26 @@ import (
 var createFlagCmd = &cobra.Command{
 	Use:   "create-flag",
 	Short: "Create a new feature flag",
-	Long:  `Create a new feature flag with the specified name, type, and configuration.`,
+	Long: `Create a new feature flag with the specified name, type, and configuration.
+
+With --initial-config or --enable-in the flag is also configured in the given environments. When
+configuring it fails in any of them, the flag is deleted again, so the command can be retried.
+
+With naming rules (--naming-file or the rule flags of lint-flags), a name that violates them prints a
+warning, or fails the command with --enforce-naming.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagName, _ := cmd.Flags().GetString("flag-name")
 		flagType, _ := cmd.Flags().GetString("flag-type")
 		description, _ := cmd.Flags().GetString("description")
 		variantsStr, _ := cmd.Flags().GetString("variants")
 		isPermanent, _ := cmd.Flags().GetBool("is-permanent")
+		owner, _ := cmd.Flags().GetString("owner")
+		expiresStr, _ := cmd.Flags().GetString("expires")
+		initialConfigYAML, _ := cmd.Flags().GetString("initial-config")
+		enableIn, _ := cmd.Flags().GetStringSlice("enable-in")
+		requires, _ := cmd.Flags().GetStringSlice("requires")
+		skipPrerequisites, _ := cmd.Flags().GetBool("skip-prerequisites")
+		enforceNaming, _ := cmd.Flags().GetBool("enforce-naming")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 		if flagName == "" {
@@ -29,6 +48,21 @@ var createFlagCmd = &cobra.Command{
 			return fmt.Errorf("flag-type is required")
 		}
 
+		// Names are checked before anything else, so a bad name fails fast even with --dry-run
+		rules, err := namingRules(cmd)
+		if err != nil {
+			return err
+		}
+		if enforceNaming && rules.Empty() {
+			return fmt.Errorf("enforce-naming requires naming rules, use --naming-file or the rule flags")
+		}
+		if err := checkFlagName(rules, flagName); err != nil {
+			if enforceNaming || !errors.Is(err, policy.ErrViolation) {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+
 		// Parse variants - try YAML first, fallback to comma-separated
 		var variants []string
 		if variantsStr != "" {
@@ -60,44 +94,166 @@ var createFlagCmd = &cobra.Command{
 			}
 		}
 
+		var expires time.Time
+		if expiresStr != "" {
+			var err error
+			expires, err = cloudbees.ParseExpiry(expiresStr, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+		labels := cloudbees.WithMetadataLabels(nil, owner, expires)
+		if len(requires) > 0 {
+			labels = cloudbees.WithPrerequisiteLabels(labels, requires)
+		}
+
+		// Configuration per environment name, set once the flag is created
+		initialConfigs := map[string]map[string]interface{}{}
+		if initialConfigYAML != "" {
+			if err := yaml.Unmarshal([]byte(initialConfigYAML), &initialConfigs); err != nil {
+				return fmt.Errorf("failed to parse initial-config YAML: %w", err)
+			}
+		}
+		for _, name := range enableIn {
+			if initialConfigs[name] == nil {
+				initialConfigs[name] = map[string]interface{}{}
+			}
+			initialConfigs[name]["enabled"] = true
+		}
+		environmentNames := make([]string, 0, len(initialConfigs))
+		for name, config := range initialConfigs {
+			if config == nil {
+				return fmt.Errorf("initial configuration of '%s' is empty", name)
+			}
+			if value, ok := config["defaultValue"]; ok {
+				if err := validateDefaultValue(&cloudbees.Flag{Name: flagName, FlagType: flagType, Variants: variants}, value); err != nil {
+					return fmt.Errorf("invalid initial configuration of '%s': %w", name, err)
+				}
+			}
+			environmentNames = append(environmentNames, name)
+		}
+		sort.Strings(environmentNames)
+
 		if dryRun {
 			fmt.Printf("DRY RUN: Would create flag '%s'\n", flagName)
 			fmt.Printf("Type: %s\n", flagType)
 			fmt.Printf("Description: %s\n", description)
 			fmt.Printf("Variants: %s\n", strings.Join(variants, ", "))
 			fmt.Printf("Permanent: %t\n", isPermanent)
+			if len(labels) > 0 {
+				fmt.Printf("Labels: %s\n", strings.Join(labels, ", "))
+			}
+			for _, name := range environmentNames {
+				configJSON, _ := json.Marshal(initialConfigs[name])
+				fmt.Printf("Configuration in %s: %s\n", name, configJSON)
+			}
 			return nil
 		}
 
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
-		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
-
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
+		// First, get the application to retrieve its ID
+		client, ctx, err := commandContext(cmd, "", "")
 		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
+			return err
 		}
+		application := ctx.Application
 
-		// First, get the application to retrieve its ID
-		application, err := client.GetApplicationByName(applicationName)
-		if err != nil {
-			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+		// Resolve the environments first, so an unknown one fails before the flag is created
+		var environments []cloudbees.Environment
+		if len(environmentNames) > 0 {
+			allEnvironments, err := client.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments: %w", err)
+			}
+			if environments, err = selectEnvironments(allEnvironments, environmentNames); err != nil {
+				return err
+			}
 		}
 
-		flag, err := client.CreateFlag(application.ID, flagName, flagType, description, variants, isPermanent)
+		// Prerequisites must exist, and be enabled where the flag is enabled
+		if !skipPrerequisites {
+			newFlag := cloudbees.Flag{Name: flagName, Labels: labels}
+			if err := validatePrerequisites(client, application.ID, newFlag); err != nil {
+				return err
+			}
+			for i := range environments {
+				if enabling, _ := initialConfigs[environments[i].Name]["enabled"].(bool); enabling {
+					if err := checkPrerequisites(client, application.ID, &newFlag, &environments[i]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		change := mutation{
+			Operation:   "create-flag",
+			Application: application.Name,
+			Flag:        flagName,
+			Labels:      labels,
+			Changes: map[string]interface{}{
+				"flagType":    flagType,
+				"variants":    variants,
+				"description": description,
+				"isPermanent": isPermanent,
+			},
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		flag, err := client.CreateFlagFromRequest(application.ID, cloudbees.CreateFlagRequest{
+			Name:        flagName,
+			FlagType:    flagType,
+			Variants:    variants,
+			Description: description,
+			IsPermanent: isPermanent,
+			Labels:      labels,
+		})
+		change.After = flag
+		afterMutation(cmd, change, err)
 		if err != nil {
 			return fmt.Errorf("failed to create flag: %w", err)
 		}
 
+		results := workerpool.Run(environments, func(env cloudbees.Environment) string { return env.Name }, poolOptions(cmd),
+			func(env cloudbees.Environment) (bool, error) {
+				change := mutation{
+					Operation:   "create-flag",
+					Application: application.Name,
+					Flag:        flag.Name,
+					Labels:      flag.Labels,
+					Environment: env.Name,
+					Changes:     initialConfigs[env.Name],
+					After:       initialConfigs[env.Name],
+				}
+				if err := beforeMutation(cmd, change); err != nil {
+					return false, err
+				}
+				err := client.SetFlagConfiguration(application.ID, flag.ID, env.ID, initialConfigs[env.Name])
+				afterMutation(cmd, change, err)
+				if err != nil {
+					return false, err
+				}
+				return true, nil
+			})
+		if err := results.Err(); err != nil {
+			// Delete the flag again, so a failed run can simply be retried
+			cloudbees.WriteOutput("success", "false")
+			if deleteErr := deleteCreatedFlag(cmd, client, application, flag); deleteErr != nil {
+				return fmt.Errorf("failed to configure flag: %w (deleting the created flag also failed: %v)", err, deleteErr)
+			}
+			return fmt.Errorf("failed to configure flag, the created flag was deleted: %w", err)
+		}
+
 		// Output results
 		flagJSON, _ := json.Marshal(flag)
 		cloudbees.WriteOutput("flag-id", flag.ID)
 		cloudbees.WriteOutput("flag-name", flag.Name)
 		cloudbees.WriteOutput("flag-type", flag.FlagType)
 		cloudbees.WriteOutput("flag", string(flagJSON))
+		if len(environments) > 0 {
+			configuredJSON, _ := json.Marshal(environmentNames)
+			cloudbees.WriteOutput("configured-environments", string(configuredJSON))
+		}
 		cloudbees.WriteOutput("success", "true")
 
 		if verbose {
@@ -108,12 +264,38 @@ var createFlagCmd = &cobra.Command{
 			}
 			fmt.Printf("Variants: %s\n", strings.Join(flag.Variants, ", "))
 			fmt.Printf("Permanent: %t\n", flag.IsPermanent)
+			if owner := flag.Owner(); owner != "" {
+				fmt.Printf("Owner: %s\n", owner)
+			}
+			if expires, ok := flag.Expires(); ok {
+				fmt.Printf("Expires: %s\n", expires.Format(cloudbees.ExpiryDateFormat))
+			}
+			for _, name := range environmentNames {
+				fmt.Printf("Configured in: %s\n", name)
+			}
 		}
 
 		return nil
 	},
 }
 
+// deleteCreatedFlag deletes a flag that was just created, when setting it up failed
+func deleteCreatedFlag(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flag *cloudbees.Flag) error {
+	change := mutation{
+		Operation:   "delete-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Before:      flag,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+	err := client.DeleteFlag(application.ID, flag.ID)
+	afterMutation(cmd, change, err)
+	return err
+}
+
 func init() {
 	rootCmd.AddCommand(createFlagCmd)
 
@@ -122,8 +304,17 @@ func init() {
 	createFlagCmd.Flags().StringP("description", "d", "", "Description of the flag")
 	createFlagCmd.Flags().String("variants", "", "Variants as YAML array or comma-separated list (defaults based on type)")
 	createFlagCmd.Flags().Bool("is-permanent", false, "Whether the flag is permanent")
+	createFlagCmd.Flags().String("owner", "", "Owner of the flag (team or person), stored as an owner: label")
+	createFlagCmd.Flags().String("expires", "", "Expiry date (YYYY-MM-DD) or duration (90d, 6w), stored as an expires: label")
+	createFlagCmd.Flags().String("initial-config", "", "Configuration per environment as YAML, e.g. '{staging: {enabled: true}, production: {enabled: false}}'")
+	createFlagCmd.Flags().StringSlice("enable-in", nil, "Environments in which the flag is enabled once created")
+	createFlagCmd.Flags().StringSlice("requires", nil, "Flags that must be enabled before this flag, stored as requires: labels (repeatable)")
+	createFlagCmd.Flags().Bool("skip-prerequisites", false, "Do not check that the required flags exist and are enabled in the --enable-in environments")
+	createFlagCmd.Flags().Bool("enforce-naming", false, "Fail when the flag name violates the naming rules, instead of warning")
 	createFlagCmd.Flags().Bool("dry-run", false, "Validate flag details without creating")
+	namingFlags(createFlagCmd)
+
+	flagNameArg(createFlagCmd)
 
 	createFlagCmd.MarkFlagRequired("flag-name")
-	createFlagCmd.MarkPersistentFlagRequired("application-name")
 }
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+)
+
+// validateDefaultValue checks a default value against the type and variants of a flag before it
+// is sent, as the API rejects mismatches with unhelpful errors or accepts them silently. The value
+// is either served as is or a percentage split, a list of {option, percentage} entries whose
+// percentages sum to 100.
+func validateDefaultValue(flag *cloudbees.Flag, value interface{}) error {
+	split, ok := value.([]interface{})
+	if !ok {
+		return validateServedValue(flag, value)
+	}
+	total := 0.0
+	for _, entry := range split {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("invalid percentage split entry %v for flag '%s', must have an option and a percentage", entry, flag.Name)
+		}
+		option, ok := fields["option"]
+		if !ok {
+			return fmt.Errorf("invalid percentage split entry %v for flag '%s', must have an option and a percentage", entry, flag.Name)
+		}
+		if err := validateServedValue(flag, option); err != nil {
+			return err
+		}
+		percentage, ok := toFloat(fields["percentage"])
+		if !ok || percentage < 0 || percentage > 100 {
+			return fmt.Errorf("invalid percentage %#v for option %v of flag '%s', must be a number between 0 and 100", fields["percentage"], option, flag.Name)
+		}
+		total += percentage
+	}
+	if math.Abs(total-100) > 0.001 {
+		return fmt.Errorf("the percentage split of flag '%s' sums to %g, must be 100", flag.Name, total)
+	}
+	return nil
+}
+
+// normalizeSplit scales the percentages of a percentage split default value to sum to 100
+func normalizeSplit(value interface{}) error {
+	split, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	percentages := make([]float64, len(split))
+	for i, entry := range split {
+		fields, _ := entry.(map[string]interface{})
+		percentage, ok := toFloat(fields["percentage"])
+		if !ok {
+			return fmt.Errorf("invalid percentage split entry %v, must have a numeric percentage", entry)
+		}
+		percentages[i] = percentage
+	}
+	normalized, err := normalizePercentages(percentages)
+	if err != nil {
+		return err
+	}
+	for i, entry := range split {
+		entry.(map[string]interface{})["percentage"] = normalized[i]
+	}
+	return nil
+}
+
+// normalizePercentages scales percentages to sum to 100, rounded to hundredths, with the rounding
+// difference given to the last non-zero percentage
+func normalizePercentages(percentages []float64) ([]float64, error) {
+	total := 0.0
+	last := -1
+	for i, percentage := range percentages {
+		if percentage < 0 {
+			return nil, fmt.Errorf("invalid percentage %g, must not be negative", percentage)
+		}
+		if percentage > 0 {
+			last = i
+		}
+		total += percentage
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("percentages sum to 0, cannot be normalized")
+	}
+
+	normalized := make([]float64, len(percentages))
+	sum := 0.0
+	for i, percentage := range percentages {
+		if i == last {
+			continue
+		}
+		normalized[i] = math.Round(percentage/total*10000) / 100
+		sum += normalized[i]
+	}
+	normalized[last] = math.Round((100-sum)*100) / 100
+	return normalized, nil
+}
+
+// validateServedValue checks that a value has the type of a flag and is one of its variants
+func validateServedValue(flag *cloudbees.Flag, value interface{}) error {
+	var variant string
+	switch strings.ToLower(flag.FlagType) {
+	case "boolean":
+		enabled, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("invalid value %#v for Boolean flag '%s', must be true or false", value, flag.Name)
+		}
+		variant = strconv.FormatBool(enabled)
+	case "number":
+		number, ok := toFloat(value)
+		if !ok {
+			return fmt.Errorf("invalid value %#v for Number flag '%s', must be a number", value, flag.Name)
+		}
+		variant = strconv.FormatFloat(number, 'f', -1, 64)
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("invalid value %#v for %s flag '%s', must be a string", value, flag.FlagType, flag.Name)
+		}
+		variant = text
+	}
+
+	if len(flag.Variants) > 0 && !containsString(flag.Variants, variant) {
+		return fmt.Errorf("flag '%s' has no variant '%s' (variants: %v)", flag.Name, variant, flag.Variants)
+	}
+	return nil
+}
+
+// toFloat returns a number decoded from JSON or YAML as a float64
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case int:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case uint64:
+		return float64(number), true
+	}
+	return 0, false
+}
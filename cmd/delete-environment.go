@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var deleteEnvironmentCmd = &cobra.Command{
+	Use:   "delete-environment",
+	Short: "Delete an environment",
+	Long: `Delete an environment and the flag configurations scoped to it. This action cannot be undone.
+With --if-exists a missing environment is not an error, so cleanup jobs can be re-run safely.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		ifExists, _ := cmd.Flags().GetBool("if-exists")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+
+		if !dryRun {
+			if err := confirmAction(cmd, fmt.Sprintf("permanently delete the environment '%s'", environmentName)); err != nil {
+				return err
+			}
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		environment, err := client.GetEnvironmentByName(environmentName)
+		if errors.Is(err, cloudbees.ErrNotFound) && ifExists {
+			fmt.Printf("Environment '%s' does not exist, nothing to delete\n", environmentName)
+			cloudbees.WriteOutput("environment-name", environmentName)
+			cloudbees.WriteOutput("deleted", "false")
+			cloudbees.WriteOutput("success", "true")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would delete environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+			return nil
+		}
+
+		if err := deleteEnvironment(cmd, client, environment); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("environment-id", environment.ID)
+		cloudbees.WriteOutput("environment-name", environment.Name)
+		cloudbees.WriteOutput("deleted", "true")
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully deleted environment: %s (ID: %s)\n", environment.Name, environment.ID)
+		} else {
+			fmt.Printf("Environment '%s' deleted successfully\n", environment.Name)
+		}
+
+		return nil
+	},
+}
+
+// deleteEnvironment deletes an environment, running the guardrails and recording the change
+func deleteEnvironment(cmd *cobra.Command, client cloudbees.API, environment *cloudbees.Environment) error {
+	change := mutation{
+		Operation:   "delete-environment",
+		Environment: environment.Name,
+		Before:      environment,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	err := client.DeleteEnvironment(environment.ID)
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete environment: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(deleteEnvironmentCmd)
+
+	deleteEnvironmentCmd.Flags().StringP("environment-name", "e", "", "Name of the environment to delete (required)")
+	deleteEnvironmentCmd.Flags().Bool("if-exists", false, "Succeed without changes when the environment does not exist")
+	deleteEnvironmentCmd.Flags().Bool("dry-run", false, "Preview the deletion without actually deleting")
+	deleteEnvironmentCmd.Flags().Bool("confirm", false, "Confirm that you want to delete the environment (required unless using dry-run)")
+
+	deleteEnvironmentCmd.MarkFlagRequired("environment-name")
+}
@@ -14,33 +14,22 @@ var deleteFlagCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagName, _ := cmd.Flags().GetString("flag-name")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		confirm, _ := cmd.Flags().GetBool("confirm")
 
 		if flagName == "" {
 			return fmt.Errorf("flag-name is required")
 		}
 
-		if !confirm && !dryRun {
-			return fmt.Errorf("this action will permanently delete the flag. Use --confirm to proceed or --dry-run to preview")
-		}
-
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
-		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
-
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
-		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
+		if !dryRun {
+			if err := confirmAction(cmd, fmt.Sprintf("permanently delete the flag '%s'", flagName)); err != nil {
+				return err
+			}
 		}
 
-		// First, get the application to retrieve its ID
-		application, err := client.GetApplicationByName(applicationName)
+		client, ctx, err := commandContext(cmd, "", "")
 		if err != nil {
-			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+			return err
 		}
+		application := ctx.Application
 
 		// Get the flag to retrieve its ID and verify it exists
 		flag, err := client.GetFlagByName(application.ID, flagName)
@@ -58,8 +47,20 @@ var deleteFlagCmd = &cobra.Command{
 			return nil
 		}
 
+		change := mutation{
+			Operation:   "delete-flag",
+			Application: application.Name,
+			Flag:        flag.Name,
+			Labels:      flag.Labels,
+			Before:      flag,
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
 		// Delete the flag
 		err = client.DeleteFlag(application.ID, flag.ID)
+		afterMutation(cmd, change, err)
 		if err != nil {
 			return fmt.Errorf("failed to delete flag: %w", err)
 		}
@@ -87,6 +88,7 @@ func init() {
 	deleteFlagCmd.Flags().Bool("dry-run", false, "Preview the deletion without actually deleting")
 	deleteFlagCmd.Flags().Bool("confirm", false, "Confirm that you want to delete the flag (required unless using dry-run)")
 
+	flagNamesArg(deleteFlagCmd)
+
 	deleteFlagCmd.MarkFlagRequired("flag-name")
-	deleteFlagCmd.MarkPersistentFlagRequired("application-name")
 }
@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/gitrepo"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/cloudbees-days/fm-actions-container/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// driftCheck is the result of one comparison of the live state with the manifest
+type driftCheck struct {
+	Drift      []flagChange `json:"drift"`
+	Remediated int          `json:"remediated"`
+}
+
+var driftWatchCmd = &cobra.Command{
+	Use:   "drift-watch",
+	Short: "Watch for flag state that diverges from a manifest",
+	Long: `Periodically compare the live state of the application's flags with a manifest created
+with the export command, from a local file or a git repository, and report every divergence
+(for example a production flag changed in the UI). New divergences are sent to the configured
+notifiers as flag.drift.detected events. With --remediate, drifted environment configurations
+are set back to the manifest; flags missing from or unknown to the manifest are only reported.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		gitURL, _ := cmd.Flags().GetString("git-url")
+		gitRef, _ := cmd.Flags().GetString("git-ref")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		remediate, _ := cmd.Flags().GetBool("remediate")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		heartbeatFile, _ := cmd.Flags().GetString("heartbeat-file")
+		overlays, _ := cmd.Flags().GetStringSlice("overlay")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if interval <= 0 {
+			return fmt.Errorf("interval must be positive")
+		}
+		values, err := templateValues(cmd)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Divergences already reported, so a long-lived drift is not reported on every check
+		reported := map[string]bool{}
+		check := func() (*driftCheck, error) {
+			desired, err := loadManifest(manifestPath, gitURL, gitRef, overlays, values)
+			if err != nil {
+				return nil, err
+			}
+			name := applicationName
+			if name == "" {
+				name = desired.Application
+			}
+			return checkDrift(cmd, client, name, desired, environmentNames, remediate, reported)
+		}
+
+		if once {
+			result, err := check()
+			if err != nil {
+				return err
+			}
+
+			// Output results
+			driftJSON, _ := json.Marshal(result.Drift)
+			cloudbees.WriteOutput("drift-count", fmt.Sprintf("%d", len(result.Drift)))
+			cloudbees.WriteOutput("drift", string(driftJSON))
+			writeArtifact(cmd, "drift", result.Drift)
+			cloudbees.WriteOutput("remediated-count", fmt.Sprintf("%d", result.Remediated))
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(os.Stderr, "Watching for drift every %s\n", interval)
+		for {
+			if _, err := check(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: drift check failed: %v\n", err)
+			}
+			if heartbeatFile != "" {
+				if err := writeHeartbeat(heartbeatFile); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(interval):
+			}
+		}
+	},
+}
+
+// loadManifest reads a manifest from a file, or from a file in a git repository when gitURL is set,
+// and applies the overlays, from the same repository. The manifest and overlays are rendered as
+// templates with values.
+func loadManifest(path, gitURL, gitRef string, overlays []string, values map[string]interface{}) (*manifest.Manifest, error) {
+	dir := ""
+	if gitURL != "" {
+		repo, err := gitrepo.Clone(gitURL, gitRef)
+		if err != nil {
+			return nil, err
+		}
+		defer repo.Close()
+		dir = repo.Dir
+	}
+
+	m, err := manifest.LoadTemplate(filepath.Join(dir, path), values)
+	if err != nil {
+		return nil, err
+	}
+	return applyOverlays(m, dir, overlays, values)
+}
+
+// applyOverlays applies the overlay files, relative to dir, to a manifest in order
+func applyOverlays(m *manifest.Manifest, dir string, overlays []string, values map[string]interface{}) (*manifest.Manifest, error) {
+	for _, overlay := range overlays {
+		var err error
+		if m, err = manifest.LoadOverlay(m, filepath.Join(dir, overlay), values); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// checkDrift compares the live state with the desired manifest, reports new divergences and,
+// if requested, sets drifted configurations back to the manifest
+func checkDrift(cmd *cobra.Command, client cloudbees.API, applicationName string, desired *manifest.Manifest,
+	environmentNames []string, remediate bool, reported map[string]bool) (*driftCheck, error) {
+	live, err := exportManifest(cmd, client, applicationName, environmentNames)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &driftCheck{Drift: diffManifests(desired, live)}
+	if len(result.Drift) == 0 {
+		if verbose {
+			fmt.Printf("No drift in %d flags\n", len(live.Flags))
+		}
+		for key := range reported {
+			delete(reported, key)
+		}
+		return result, nil
+	}
+
+	// Group divergences by flag and environment, so each produces one event and one update
+	type divergence struct {
+		flag, environment string
+		manifest, live    map[string]interface{}
+		new               bool
+	}
+	var divergences []*divergence
+	current := map[string]bool{}
+	for _, change := range result.Drift {
+		fmt.Printf("Drift: %s\n", change.describe())
+
+		if len(divergences) == 0 || divergences[len(divergences)-1].flag != change.Flag ||
+			divergences[len(divergences)-1].environment != change.Environment {
+			divergences = append(divergences, &divergence{
+				flag:        change.Flag,
+				environment: change.Environment,
+				manifest:    map[string]interface{}{},
+				live:        map[string]interface{}{},
+			})
+		}
+		d := divergences[len(divergences)-1]
+
+		field := change.Field
+		switch change.Kind {
+		case changeAdded:
+			field, change.From, change.To = "exists", false, true
+		case changeRemoved:
+			field, change.From, change.To = "exists", true, false
+		}
+		d.manifest[field] = change.From
+		d.live[field] = change.To
+
+		toJSON, _ := json.Marshal(change.To)
+		key := fmt.Sprintf("%s/%s/%s=%s", change.Flag, change.Environment, field, toJSON)
+		current[key] = true
+		if !reported[key] {
+			d.new = true
+		}
+	}
+	for key := range reported {
+		if !current[key] {
+			delete(reported, key)
+		}
+	}
+	for key := range current {
+		reported[key] = true
+	}
+
+	targets := configuredNotifiers()
+	var remediationErr error
+	for _, d := range divergences {
+		if d.new && len(targets) > 0 {
+			sendEvent(targets, notify.Event{
+				ID:          notify.NewEventID(),
+				Type:        notify.TypeDriftDetected,
+				Time:        time.Now().UTC(),
+				Operation:   "drift-watch",
+				Application: live.Application,
+				Flag:        d.flag,
+				Environment: d.environment,
+				Changes:     d.live,
+				Before:      d.manifest,
+				Principal:   auditPrincipal(),
+				RunURL:      runURL(),
+			})
+		}
+
+		if !remediate || d.environment == "" {
+			continue
+		}
+		if _, err := updateFlagConfiguration(cmd, client, live.Application, d.flag, d.environment, d.manifest, "", false); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remediate %s in %s: %v\n", d.flag, d.environment, err)
+			if remediationErr == nil {
+				remediationErr = fmt.Errorf("failed to remediate drift of '%s' in '%s': %w", d.flag, d.environment, err)
+			}
+			continue
+		}
+		result.Remediated++
+		fmt.Printf("Remediated: %s in %s set back to the manifest\n", d.flag, d.environment)
+	}
+
+	return result, remediationErr
+}
+
+func init() {
+	rootCmd.AddCommand(driftWatchCmd)
+
+	driftWatchCmd.Flags().String("manifest", "", "Manifest with the desired flag state, relative to the repository root with --git-url (required)")
+	driftWatchCmd.Flags().String("git-url", "", "Git repository containing the manifest")
+	driftWatchCmd.Flags().String("git-ref", "", "Branch or tag of the git repository (defaults to the default branch)")
+	driftWatchCmd.Flags().Duration("interval", 5*time.Minute, "Time between drift checks")
+	driftWatchCmd.Flags().Bool("once", false, "Check once and exit instead of watching")
+	driftWatchCmd.Flags().Bool("remediate", false, "Set drifted environment configurations back to the manifest")
+	driftWatchCmd.Flags().StringSlice("environments", nil, "Environments to check (defaults to all enabled environments)")
+	driftWatchCmd.Flags().String("heartbeat-file", "", "Touch this file after every check, for healthcheck --heartbeat-file")
+	driftWatchCmd.Flags().StringSlice("overlay", nil, "Overlays merged into the manifest in order, e.g. the patches of one environment")
+	templateFlags(driftWatchCmd)
+
+	driftWatchCmd.MarkFlagRequired("manifest")
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// apiRegions maps the --region presets to the API URL of each region
+var apiRegions = map[string]string{
+	"us": "https://api.cloudbees.io",
+	"eu": "https://api.eu.cloudbees.io",
+}
+
+// resolveAPIURL returns the API URL given by --api-url, --region or the --profile of the config
+// file, in this order of precedence, or the default API URL
+func resolveAPIURL(cmd *cobra.Command) (string, error) {
+	flags := cmd.Root().PersistentFlags()
+	apiURL, _ := flags.GetString("api-url")
+	region := viper.GetString("region")
+	profile := viper.GetString("profile")
+
+	if flags.Changed("api-url") {
+		if flags.Changed("region") {
+			return "", fmt.Errorf("api-url and region cannot be used together")
+		}
+		return apiURL, validateAPIURL(apiURL)
+	}
+
+	if region == "" && profile != "" {
+		settings := viper.Sub("profiles." + profile)
+		if settings == nil {
+			return "", fmt.Errorf("profile '%s' not found in the config file", profile)
+		}
+		if profileURL := settings.GetString("api-url"); profileURL != "" {
+			return profileURL, validateAPIURL(profileURL)
+		}
+		region = settings.GetString("region")
+	}
+
+	if region != "" {
+		regionURL, ok := apiRegions[strings.ToLower(region)]
+		if !ok {
+			return "", fmt.Errorf("unknown region '%s', expected one of: %s", region, strings.Join(regionNames(), ", "))
+		}
+		return regionURL, nil
+	}
+
+	return apiURL, validateAPIURL(apiURL)
+}
+
+// validateAPIURL rejects API URLs that cannot be the base URL of the platform API
+func validateAPIURL(apiURL string) error {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return fmt.Errorf("invalid API URL '%s': %w", apiURL, err)
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" || parsed.Host == "" {
+		return fmt.Errorf("invalid API URL '%s': expected https://<host>, e.g. %s", apiURL, apiRegions["us"])
+	}
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" {
+		return fmt.Errorf("invalid API URL '%s': expected the base URL without a path, e.g. %s://%s", apiURL, parsed.Scheme, parsed.Host)
+	}
+	return nil
+}
+
+// regionNames returns the --region presets in alphabetical order
+func regionNames() []string {
+	names := make([]string, 0, len(apiRegions))
+	for name := range apiRegions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var envBootstrapCmd = &cobra.Command{
+	Use:   "env-bootstrap",
+	Short: "Create a preview environment seeded from a template environment",
+	Long: `Create an environment (or reuse it if it exists), link it to the application, and copy the
+configuration of every flag (optionally filtered by label or name prefix) from a template environment,
+e.g. for the preview deployment of a pull request. Re-running the command resets the flag
+configurations to the template.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		templateName, _ := cmd.Flags().GetString("template")
+		description, _ := cmd.Flags().GetString("description")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if environmentName == "" || templateName == "" {
+			return fmt.Errorf("environment-name and template are required")
+		}
+		if environmentName == templateName {
+			return fmt.Errorf("environment and template must be different")
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		template, err := client.GetEnvironmentByName(templateName)
+		if err != nil {
+			return fmt.Errorf("failed to get template environment: %w", err)
+		}
+
+		environment, err := client.GetEnvironmentByName(environmentName)
+		if err != nil && !errors.Is(err, cloudbees.ErrNotFound) {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		allFlags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+		var flags []cloudbees.Flag
+		for _, flag := range allFlags {
+			if strings.HasPrefix(flag.Name, prefix) && hasAnyLabel(flag, labels) {
+				flags = append(flags, flag)
+			}
+		}
+
+		if dryRun {
+			if environment == nil {
+				fmt.Printf("DRY RUN: Would create environment '%s'\n", environmentName)
+			} else {
+				fmt.Printf("DRY RUN: Would reuse environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+			}
+			if environment == nil || !application.HasEnvironment(environment.ID) {
+				fmt.Printf("Would link it to application '%s'\n", application.Name)
+			}
+			fmt.Printf("Would copy the configuration of %d flags from '%s'\n", len(flags), template.Name)
+			for _, flag := range flags {
+				fmt.Printf("- %s\n", flag.Name)
+			}
+			return nil
+		}
+
+		created := environment == nil
+		if created {
+			environment, err = createEnvironment(cmd, client, cloudbees.CreateEnvironmentRequest{
+				Name:        environmentName,
+				Description: description,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Created environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+		} else {
+			fmt.Printf("Reusing environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+		}
+
+		linked := !application.HasEnvironment(environment.ID)
+		if linked {
+			if _, err := linkEnvironment(cmd, client, application, environment); err != nil {
+				return err
+			}
+			fmt.Printf("Linked environment '%s' to application '%s'\n", environment.Name, application.Name)
+		}
+
+		results := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (bool, error) {
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, template.ID)
+				if err != nil {
+					return false, err
+				}
+				changes := configurationChanges(config.Configuration)
+				change := mutation{
+					Operation:   "env-bootstrap",
+					Application: application.Name,
+					Flag:        flag.Name,
+					Labels:      flag.Labels,
+					Environment: environment.Name,
+					Changes:     changes,
+					After:       changes,
+				}
+				if err := beforeMutation(cmd, change); err != nil {
+					return false, err
+				}
+				err = client.SetFlagConfiguration(application.ID, flag.ID, environment.ID, changes)
+				afterMutation(cmd, change, err)
+				if err != nil {
+					return false, err
+				}
+				return true, nil
+			})
+
+		// Output results
+		resultsJSON, _ := json.Marshal(results)
+		writeEnvironmentOutputs(environment)
+		cloudbees.WriteOutput("created", fmt.Sprintf("%t", created))
+		cloudbees.WriteOutput("linked", fmt.Sprintf("%t", linked))
+		cloudbees.WriteOutput("seeded-count", fmt.Sprintf("%d", results.Succeeded()))
+		cloudbees.WriteOutput("failed-count", fmt.Sprintf("%d", results.Failed()))
+		cloudbees.WriteOutput("results", string(resultsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+		if verbose {
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+				} else if !result.Skipped {
+					fmt.Printf("- %s: seeded\n", result.Name)
+				}
+			}
+		}
+		fmt.Printf("Seeded %d of %d flags in '%s' from '%s'\n", results.Succeeded(), len(flags), environment.Name, template.Name)
+
+		if err := results.Err(); err != nil {
+			return fmt.Errorf("environment ready but seeding flag configurations failed: %w", err)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envBootstrapCmd)
+
+	envBootstrapCmd.Flags().StringP("environment-name", "e", "", "Name of the environment to create or reuse (required)")
+	envBootstrapCmd.Flags().String("template", "", "Environment whose flag configurations are copied (required)")
+	envBootstrapCmd.Flags().String("description", "", "Description of the environment when it is created")
+	envBootstrapCmd.Flags().StringSlice("label", nil, "Only seed flags with this label (repeatable)")
+	envBootstrapCmd.Flags().String("prefix", "", "Only seed flags whose name starts with this prefix")
+	envBootstrapCmd.Flags().Bool("dry-run", false, "Print the bootstrap plan without applying it")
+
+	envBootstrapCmd.MarkFlagRequired("environment-name")
+	envBootstrapCmd.MarkFlagRequired("template")
+}
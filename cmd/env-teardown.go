@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var envTeardownCmd = &cobra.Command{
+	Use:   "env-teardown",
+	Short: "Disable all flags in an environment and delete it",
+	Long: `Tear down a preview environment when its pull request closes: disable every flag of the
+application in the environment, remove the flag configurations scoped to it, unlink it from the
+application and delete it. The environment is only deleted when every flag was cleaned up.
+This action cannot be undone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		ifExists, _ := cmd.Flags().GetBool("if-exists")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+
+		if !dryRun {
+			if err := confirmAction(cmd, fmt.Sprintf("permanently delete the environment '%s'", environmentName)); err != nil {
+				return err
+			}
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		environment, err := client.GetEnvironmentByName(environmentName)
+		if errors.Is(err, cloudbees.ErrNotFound) && ifExists {
+			fmt.Printf("Environment '%s' does not exist, nothing to tear down\n", environmentName)
+			cloudbees.WriteOutput("environment-name", environmentName)
+			cloudbees.WriteOutput("deleted", "false")
+			cloudbees.WriteOutput("success", "true")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		flags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would tear down environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+			fmt.Printf("Would disable and remove the configuration of %d flags\n", len(flags))
+			for _, flag := range flags {
+				fmt.Printf("- %s\n", flag.Name)
+			}
+			if application.HasEnvironment(environment.ID) {
+				fmt.Printf("Would unlink it from application '%s'\n", application.Name)
+			}
+			fmt.Printf("Would delete environment '%s'\n", environment.Name)
+			return nil
+		}
+
+		results := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (bool, error) {
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+				if err != nil {
+					return false, err
+				}
+				changes := map[string]interface{}{"enabled": false}
+				change := mutation{
+					Operation:   "env-teardown",
+					Application: application.Name,
+					Flag:        flag.Name,
+					Labels:      flag.Labels,
+					Environment: environment.Name,
+					Changes:     changes,
+					Before:      config.Configuration,
+				}
+				if err := beforeMutation(cmd, change); err != nil {
+					return false, err
+				}
+				// Disable first, so SDKs still connected to the environment stop serving the flag
+				// even if removing the configuration fails
+				if config.Configuration.Enabled {
+					err = client.SetFlagConfiguration(application.ID, flag.ID, environment.ID, changes)
+				}
+				if err == nil {
+					err = client.DeleteFlagConfiguration(application.ID, flag.ID, environment.ID)
+				}
+				afterMutation(cmd, change, err)
+				if err != nil {
+					return false, err
+				}
+				return true, nil
+			})
+
+		resultsJSON, _ := json.Marshal(results)
+		cloudbees.WriteOutput("environment-id", environment.ID)
+		cloudbees.WriteOutput("environment-name", environment.Name)
+		cloudbees.WriteOutput("cleaned-count", fmt.Sprintf("%d", results.Succeeded()))
+		cloudbees.WriteOutput("failed-count", fmt.Sprintf("%d", results.Failed()))
+		cloudbees.WriteOutput("results", string(resultsJSON))
+
+		if verbose {
+			for _, result := range results {
+				if result.Err != nil {
+					fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+				} else if !result.Skipped {
+					fmt.Printf("- %s: disabled and removed\n", result.Name)
+				}
+			}
+		}
+		fmt.Printf("Cleaned up %d of %d flags in '%s'\n", results.Succeeded(), len(flags), environment.Name)
+
+		if err := results.Err(); err != nil {
+			cloudbees.WriteOutput("deleted", "false")
+			cloudbees.WriteOutput("success", "false")
+			return fmt.Errorf("environment not deleted because cleaning up flags failed: %w", err)
+		}
+
+		if application.HasEnvironment(environment.ID) {
+			if _, err := unlinkEnvironment(cmd, client, application, environment); err != nil {
+				return err
+			}
+		}
+
+		if err := deleteEnvironment(cmd, client, environment); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("deleted", "true")
+		cloudbees.WriteOutput("success", "true")
+
+		fmt.Printf("Environment '%s' torn down successfully\n", environment.Name)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envTeardownCmd)
+
+	envTeardownCmd.Flags().StringP("environment-name", "e", "", "Name of the environment to tear down (required)")
+	envTeardownCmd.Flags().Bool("if-exists", false, "Succeed without changes when the environment does not exist")
+	envTeardownCmd.Flags().Bool("dry-run", false, "Print the teardown plan without applying it")
+	envTeardownCmd.Flags().Bool("confirm", false, "Confirm that you want to delete the environment (required unless using dry-run)")
+
+	envTeardownCmd.MarkFlagRequired("environment-name")
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/evaluate"
+	"github.com/spf13/cobra"
+)
+
+var evaluateCmd = &cobra.Command{
+	Use:   "evaluate",
+	Short: "Simulate the evaluation of a flag for a context",
+	Long: `Fetch the configuration of a flag in an environment and compute the value a user with the given
+context attributes would receive, e.g. --attr plan=enterprise --attr country=DE. Conditions are evaluated
+locally in order; percentage splits bucket the context by its stickiness property attribute (or id).
+Bucketing is deterministic but only an approximation: it does not use the hashing of the SDKs, so a
+user may get another split value in applications. Values picked from a split are labeled as
+approximate in the printed result and in the approximate output. Target groups are not supported.
+
+With --contexts-file, every row of a CSV file (attribute names in the header row) is evaluated and the
+distribution of values is reported, e.g. to validate a split before enabling it. --attr attributes
+apply to every row that does not set them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		attrs, _ := cmd.Flags().GetStringArray("attr")
+		contextsFile, _ := cmd.Flags().GetString("contexts-file")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		ctx, err := parseAttributes(attrs)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		flag, config, err := fetchFlagConfiguration(cmd, client, applicationName, flagName, environmentName)
+		if err != nil {
+			return err
+		}
+
+		if contextsFile != "" {
+			return evaluateContexts(flag, environmentName, config.Configuration, contextsFile, ctx)
+		}
+
+		result, err := evaluate.Evaluate(flag.Name, flag.FlagType, config.Configuration, ctx)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate flag '%s': %w", flagName, err)
+		}
+
+		// Output results
+		valueJSON, _ := json.Marshal(result.Value)
+		cloudbees.WriteOutput("value", string(valueJSON))
+		cloudbees.WriteOutput("reason", result.Reason)
+		cloudbees.WriteOutput("condition", fmt.Sprintf("%d", result.Condition))
+		cloudbees.WriteOutput("approximate", fmt.Sprintf("%t", result.Approximate))
+
+		fmt.Printf("Flag '%s' in '%s' serves: %s\n", flag.Name, environmentName, result)
+		return nil
+	},
+}
+
+// evaluateContexts evaluates a flag for every context of a CSV file and reports the distribution of values
+func evaluateContexts(flag *cloudbees.Flag, environmentName string, config cloudbees.FlagConfiguration, contextsFile string, defaults evaluate.Context) error {
+	contexts, err := evaluate.LoadContexts(contextsFile)
+	if err != nil {
+		return err
+	}
+	if len(contexts) == 0 {
+		return fmt.Errorf("no contexts in '%s'", contextsFile)
+	}
+
+	results := make([]evaluate.Result, 0, len(contexts))
+	for i, ctx := range contexts {
+		for name, value := range defaults {
+			if _, ok := ctx[name]; !ok {
+				ctx[name] = value
+			}
+		}
+		result, err := evaluate.Evaluate(flag.Name, flag.FlagType, config, ctx)
+		if err != nil {
+			// Row 1 is the header
+			return fmt.Errorf("failed to evaluate flag '%s' for row %d: %w", flag.Name, i+2, err)
+		}
+		results = append(results, result)
+	}
+	shares := evaluate.Distribution(results)
+
+	// Output results
+	distributionJSON, _ := json.Marshal(shares)
+	cloudbees.WriteOutput("context-count", fmt.Sprintf("%d", len(contexts)))
+	cloudbees.WriteOutput("distribution", string(distributionJSON))
+
+	fmt.Printf("Flag '%s' in '%s' for %d contexts:\n", flag.Name, environmentName, len(contexts))
+	approximate := false
+	for _, share := range shares {
+		value := "coded default"
+		if share.Value != nil {
+			data, _ := json.Marshal(share.Value)
+			value = string(data)
+		}
+		fmt.Printf("  %s: %d (%.1f%%)\n", value, share.Count, share.Percentage)
+		approximate = approximate || share.Approximate
+	}
+	if approximate {
+		fmt.Println("Split values are an approximation, the SDK bucketing may assign contexts differently")
+	}
+	return nil
+}
+
+// parseAttributes parses name=value context attributes
+func parseAttributes(attrs []string) (evaluate.Context, error) {
+	ctx := evaluate.Context{}
+	for _, attr := range attrs {
+		name, value, ok := strings.Cut(attr, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid attribute '%s', use name=value", attr)
+		}
+		ctx[name] = value
+	}
+	return ctx, nil
+}
+
+// fetchFlagConfiguration resolves a flag and an environment by name and gets the flag configuration
+func fetchFlagConfiguration(cmd *cobra.Command, client cloudbees.API, applicationName, flagName, environmentName string) (*cloudbees.Flag, *cloudbees.FlagConfigurationDetail, error) {
+	ctx, err := resolveContext(cmd, client, applicationName, flagName, environmentName)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := client.GetFlagConfiguration(ctx.Application.ID, ctx.Flag.ID, ctx.Environment.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get flag configuration: %w", err)
+	}
+	return ctx.Flag, config, nil
+}
+
+func init() {
+	rootCmd.AddCommand(evaluateCmd)
+
+	evaluateCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	evaluateCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	evaluateCmd.Flags().StringArray("attr", nil, "Context attribute as name=value (repeatable)")
+	evaluateCmd.Flags().String("contexts-file", "", "CSV file of contexts (attribute names in the header row) to report the distribution of values")
+
+	flagNameArg(evaluateCmd)
+
+	evaluateCmd.MarkFlagRequired("flag-name")
+	evaluateCmd.MarkFlagRequired("environment-name")
+}
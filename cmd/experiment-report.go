@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+// variantReport holds the results of a variant in an experiment
+type variantReport struct {
+	Variant        string   `json:"variant"`
+	Weight         float64  `json:"weight"`                   // Configured percentage of the split
+	Impressions    int64    `json:"impressions"`              // Evaluations serving the variant during the experiment
+	Share          float64  `json:"share"`                    // Observed percentage of the impressions
+	Conversions    *int64   `json:"conversions,omitempty"`    // Conversions given with --conversions
+	ConversionRate *float64 `json:"conversionRate,omitempty"` // Conversions per impression, in percent
+	Delta          *float64 `json:"delta,omitempty"`          // Relative change of the conversion rate against the control, in percent
+}
+
+var experimentReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report the distribution and conversions of an experiment",
+	Long: `Report the configured weight and the observed impressions of each variant of an experiment since
+it started. With --conversions true=120,false=100 (conversion counts from your analytics), the conversion
+rate of each variant and its change against the --control variant are reported too.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		conversions, _ := cmd.Flags().GetStringToString("conversions")
+		control, _ := cmd.Flags().GetString("control")
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		application, flag := ctx.Application, ctx.Flag
+		experiment, ok := flag.Experiment()
+		if !ok {
+			return fmt.Errorf("no experiment was run on flag '%s'", flag.Name)
+		}
+		environment, err := client.GetEnvironmentByName(experiment.Environment)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		if control == "" && len(flag.Variants) > 0 {
+			control = flag.Variants[0]
+			if flag.FlagType == "Boolean" {
+				control = "false"
+			}
+		}
+		if len(conversions) > 0 && !containsString(flag.Variants, control) {
+			return fmt.Errorf("flag '%s' has no variant '%s' (variants: %v)", flag.Name, control, flag.Variants)
+		}
+
+		reports := make([]variantReport, 0, len(flag.Variants))
+		byVariant := map[string]*variantReport{}
+		for _, variant := range flag.Variants {
+			reports = append(reports, variantReport{Variant: variant})
+		}
+		for i := range reports {
+			byVariant[reports[i].Variant] = &reports[i]
+		}
+
+		config, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get flag configuration: %w", err)
+		}
+		if split, ok := config.Configuration.DefaultValue.([]interface{}); ok {
+			for _, entry := range split {
+				option, _ := entry.(map[string]interface{})
+				if report, ok := byVariant[fmt.Sprint(option["option"])]; ok {
+					report.Weight, _ = option["percentage"].(float64)
+				}
+			}
+		}
+
+		from, to := experimentWindow(experiment)
+		impressions, err := client.ListImpressions(application.ID, cloudbees.ImpressionsQuery{
+			EnvironmentID: environment.ID, FlagID: flag.ID, From: from, To: to,
+		})
+		statsAvailable := true
+		if errors.Is(err, cloudbees.ErrNotFound) {
+			statsAvailable = false
+		} else if err != nil {
+			return fmt.Errorf("failed to list impressions: %w", err)
+		}
+
+		var total int64
+		for _, impression := range impressions {
+			if report, ok := byVariant[impression.Variant]; ok {
+				report.Impressions += impression.Count
+				total += impression.Count
+			}
+		}
+		for i := range reports {
+			if total > 0 {
+				reports[i].Share = float64(reports[i].Impressions) / float64(total) * 100
+			}
+		}
+
+		for variant, value := range conversions {
+			report, ok := byVariant[variant]
+			if !ok {
+				return fmt.Errorf("flag '%s' has no variant '%s' (variants: %v)", flag.Name, variant, flag.Variants)
+			}
+			count, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || count < 0 {
+				return fmt.Errorf("invalid conversion count '%s' for variant '%s'", value, variant)
+			}
+			report.Conversions = &count
+			if report.Impressions > 0 {
+				rate := float64(count) / float64(report.Impressions) * 100
+				report.ConversionRate = &rate
+			}
+		}
+		if baseline := byVariant[control]; baseline != nil && baseline.ConversionRate != nil && *baseline.ConversionRate > 0 {
+			for i := range reports {
+				if reports[i].ConversionRate != nil && reports[i].Variant != control {
+					delta := (*reports[i].ConversionRate - *baseline.ConversionRate) / *baseline.ConversionRate * 100
+					reports[i].Delta = &delta
+				}
+			}
+		}
+
+		// Output results
+		reportJSON, _ := json.Marshal(reports)
+		cloudbees.WriteOutput("report", string(reportJSON))
+		cloudbees.WriteOutput("impression-count", fmt.Sprintf("%d", total))
+		cloudbees.WriteOutput("running", fmt.Sprintf("%t", experiment.Running()))
+
+		status := "running"
+		if !experiment.Running() {
+			status = fmt.Sprintf("stopped %s, winner %s", experiment.Stopped.Format(time.RFC3339), experiment.Winner)
+		}
+		fmt.Printf("Experiment on flag '%s' in '%s' (started %s, %s):\n", flag.Name, experiment.Environment, experiment.Started.Format(time.RFC3339), status)
+		if !statsAvailable {
+			fmt.Println("No impression statistics are available")
+		}
+		for _, report := range reports {
+			line := fmt.Sprintf("- %s: weight %g%%, %d impressions (%.1f%%)", report.Variant, report.Weight, report.Impressions, report.Share)
+			if report.ConversionRate != nil {
+				line += fmt.Sprintf(", %d conversions (%.2f%%)", *report.Conversions, *report.ConversionRate)
+			}
+			if report.Delta != nil {
+				line += fmt.Sprintf(", %+.1f%% vs %s", *report.Delta, control)
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+func init() {
+	experimentCmd.AddCommand(experimentReportCmd)
+
+	experimentReportCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	experimentReportCmd.Flags().StringToString("conversions", nil, "Conversions of each variant, e.g. true=120,false=100")
+	experimentReportCmd.Flags().String("control", "", "Variant conversion rates are compared against (defaults to false for Boolean flags, otherwise the first variant)")
+
+	flagNameArg(experimentReportCmd)
+
+	experimentReportCmd.MarkFlagRequired("flag-name")
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var experimentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start an experiment by splitting a flag between its variants",
+	Long: `Enable the flag in the environment with a percentage split between its variants (evenly, or by
+--weights true=50,false=50), bucketed by the stickiness property so users keep their variant, and
+record the start of the experiment in the flag labels.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		weights, _ := cmd.Flags().GetStringToString("weights")
+		stickinessProperty, _ := cmd.Flags().GetString("stickiness-property")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		flag := ctx.Flag
+		if experiment, ok := flag.Experiment(); ok && experiment.Running() {
+			return fmt.Errorf("an experiment on flag '%s' is already running in '%s', stop it first", flag.Name, experiment.Environment)
+		}
+
+		split, err := percentageSplit(flag, weights)
+		if err != nil {
+			return err
+		}
+		changes := map[string]interface{}{
+			"enabled":            true,
+			"defaultValue":       split,
+			"stickinessProperty": stickinessProperty,
+		}
+
+		splitJSON, _ := json.Marshal(split)
+		if dryRun {
+			fmt.Printf("DRY RUN: Would start an experiment on flag '%s' in '%s' with split %s\n", flag.Name, environmentName, splitJSON)
+			return nil
+		}
+
+		update, err := updateFlagConfiguration(cmd, client, applicationName, flag.Name, environmentName, changes, "", false)
+		if err != nil {
+			return err
+		}
+
+		experiment := cloudbees.Experiment{Environment: environmentName, Started: time.Now().UTC().Truncate(time.Second)}
+		if err := setExperimentLabels(cmd, client, update.Application, update.Flag, "experiment-start", experiment); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-name", flag.Name)
+		cloudbees.WriteOutput("environment-name", environmentName)
+		cloudbees.WriteOutput("split", string(splitJSON))
+		cloudbees.WriteOutput("started", experiment.Started.Format(time.RFC3339))
+
+		fmt.Printf("Started experiment on flag '%s' in '%s' with split %s\n", flag.Name, environmentName, splitJSON)
+		return nil
+	},
+}
+
+func init() {
+	experimentCmd.AddCommand(experimentStartCmd)
+
+	experimentStartCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	experimentStartCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	experimentStartCmd.Flags().StringToString("weights", nil, "Percentage of each variant, e.g. true=50,false=50 (defaults to an even split)")
+	experimentStartCmd.Flags().String("stickiness-property", "", "Context property used to bucket users, e.g. userId (required)")
+	experimentStartCmd.Flags().Bool("dry-run", false, "Show the split without applying it")
+
+	flagNameArg(experimentStartCmd)
+
+	experimentStartCmd.MarkFlagRequired("flag-name")
+	experimentStartCmd.MarkFlagRequired("environment-name")
+	experimentStartCmd.MarkFlagRequired("stickiness-property")
+}
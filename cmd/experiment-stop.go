@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var experimentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop an experiment by serving the winning variant",
+	Long: `Collapse the percentage split of a running experiment to the winning variant, in the
+environment the experiment runs in, and record the end of the experiment in the flag labels.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		winner, _ := cmd.Flags().GetString("winner")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		flag := ctx.Flag
+		experiment, ok := flag.Experiment()
+		if !ok || !experiment.Running() {
+			return fmt.Errorf("no experiment is running on flag '%s'", flag.Name)
+		}
+
+		value, err := variantValue(flag, winner)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would stop the experiment on flag '%s' in '%s' and serve %s\n", flag.Name, experiment.Environment, winner)
+			return nil
+		}
+
+		update, err := updateFlagConfiguration(cmd, client, applicationName, flag.Name, experiment.Environment,
+			map[string]interface{}{"defaultValue": value}, "", false)
+		if err != nil {
+			return err
+		}
+
+		experiment.Stopped = time.Now().UTC().Truncate(time.Second)
+		experiment.Winner = winner
+		if err := setExperimentLabels(cmd, client, update.Application, update.Flag, "experiment-stop", experiment); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-name", flag.Name)
+		cloudbees.WriteOutput("environment-name", experiment.Environment)
+		cloudbees.WriteOutput("winner", winner)
+		cloudbees.WriteOutput("stopped", experiment.Stopped.Format(time.RFC3339))
+
+		fmt.Printf("Stopped experiment on flag '%s' in '%s', serving %s\n", flag.Name, experiment.Environment, winner)
+		return nil
+	},
+}
+
+func init() {
+	experimentCmd.AddCommand(experimentStopCmd)
+
+	experimentStopCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	experimentStopCmd.Flags().String("winner", "", "Variant to serve to everyone (required)")
+	experimentStopCmd.Flags().Bool("dry-run", false, "Show the change without applying it")
+
+	flagNameArg(experimentStopCmd)
+
+	experimentStopCmd.MarkFlagRequired("flag-name")
+	experimentStopCmd.MarkFlagRequired("winner")
+}
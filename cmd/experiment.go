@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var experimentCmd = &cobra.Command{
+	Use:   "experiment",
+	Short: "Run A/B tests through percentage splits of a flag",
+	Long: `Start an experiment by splitting a flag between its variants, report the distribution and
+conversions of each variant, and stop it by serving the winning variant. The experiment is recorded
+in the flag labels (experiment:<environment>, experiment-started:<time> and, once stopped,
+experiment-stopped:<time> and experiment-winner:<variant>).`,
+}
+
+// variantValue returns the value served for a variant of a flag, typed as the flag: true/false for
+// Boolean flags, a number for Number flags and the string otherwise
+func variantValue(flag *cloudbees.Flag, variant string) (interface{}, error) {
+	if !containsString(flag.Variants, variant) {
+		return nil, fmt.Errorf("flag '%s' has no variant '%s' (variants: %v)", flag.Name, variant, flag.Variants)
+	}
+	switch flag.FlagType {
+	case "Boolean":
+		return strconv.ParseBool(variant)
+	case "Number":
+		return strconv.ParseFloat(variant, 64)
+	}
+	return variant, nil
+}
+
+// percentageSplit builds the percentage split default value of a flag from variant weights,
+// in the order of the flag variants. Without weights, traffic is split evenly between the variants.
+func percentageSplit(flag *cloudbees.Flag, weights map[string]string) ([]interface{}, error) {
+	if len(flag.Variants) == 0 {
+		return nil, fmt.Errorf("flag '%s' has no variants", flag.Name)
+	}
+	if len(weights) == 0 {
+		weights = map[string]string{}
+		even := math.Floor(10000/float64(len(flag.Variants))) / 100
+		for i, variant := range flag.Variants {
+			weight := even
+			if i == len(flag.Variants)-1 {
+				weight = math.Round((100-even*float64(len(flag.Variants)-1))*100) / 100
+			}
+			weights[variant] = strconv.FormatFloat(weight, 'f', -1, 64)
+		}
+	}
+
+	total := 0.0
+	for variant, weight := range weights {
+		if !containsString(flag.Variants, variant) {
+			return nil, fmt.Errorf("flag '%s' has no variant '%s' (variants: %v)", flag.Name, variant, flag.Variants)
+		}
+		percentage, err := strconv.ParseFloat(weight, 64)
+		if err != nil || percentage < 0 || percentage > 100 {
+			return nil, fmt.Errorf("invalid weight '%s' for variant '%s', must be a percentage between 0 and 100", weight, variant)
+		}
+		total += percentage
+	}
+	if math.Abs(total-100) > 0.001 {
+		return nil, fmt.Errorf("weights sum to %g, must be 100", total)
+	}
+
+	split := []interface{}{}
+	for _, variant := range flag.Variants {
+		weight, ok := weights[variant]
+		if !ok {
+			continue
+		}
+		percentage, _ := strconv.ParseFloat(weight, 64)
+		if percentage == 0 {
+			continue
+		}
+		value, err := variantValue(flag, variant)
+		if err != nil {
+			return nil, err
+		}
+		split = append(split, map[string]interface{}{"option": value, "percentage": percentage})
+	}
+	return split, nil
+}
+
+// setExperimentLabels records an experiment in the labels of a flag
+func setExperimentLabels(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flag *cloudbees.Flag,
+	operation string, experiment cloudbees.Experiment) error {
+	labels := cloudbees.WithExperimentLabels(flag.Labels, experiment)
+	change := mutation{
+		Operation:   operation,
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Environment: experiment.Environment,
+		Changes:     map[string]interface{}{"labels": labels},
+		Before:      flag,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	updated, err := client.SetFlagLabels(application.ID, flag.ID, labels)
+	change.After = updated
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to record experiment in flag labels: %w", err)
+	}
+	return nil
+}
+
+// experimentWindow returns the time window of an experiment: from its start until it was stopped, or now
+func experimentWindow(experiment cloudbees.Experiment) (time.Time, time.Time) {
+	if experiment.Running() {
+		return experiment.Started, time.Now()
+	}
+	return experiment.Started, experiment.Stopped
+}
+
+func init() {
+	rootCmd.AddCommand(experimentCmd)
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/backstage"
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/cloudbees-days/fm-actions-container/internal/openfeature"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// Export formats
+const (
+	formatManifest    = "manifest"
+	formatOpenFeature = "openfeature" // flagd flag definitions of one environment
+	formatBackstage   = "backstage"   // Backstage catalog entities
+	formatCasc        = "casc"        // Configuration-as-code documents, one per flag
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export flags and their configurations to a manifest file",
+	Long: `Export every flag of the application with its configuration in each environment
+to a JSON or YAML manifest. Manifests are snapshots of flag state that can be compared
+with the changelog command. With --format openfeature, the flags of one environment are written
+as flagd flag definitions for local evaluation with OpenFeature. With --format backstage, each flag
+is written as a Backstage catalog Resource entity with its owner, lifecycle and labels. With
+--format casc, each flag is written as a configuration-as-code YAML document, which can be read
+back wherever a manifest is accepted.
+With --all-applications, every application is exported concurrently to its own file in --output-dir.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		format, _ := cmd.Flags().GetString("format")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		backstageOwner, _ := cmd.Flags().GetString("backstage-owner")
+		backstageSystem, _ := cmd.Flags().GetString("backstage-system")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		allApplications, _ := cmd.Flags().GetBool("all-applications")
+
+		switch format {
+		case formatManifest, formatBackstage, formatCasc:
+		case formatOpenFeature:
+			if len(environmentNames) != 1 {
+				return fmt.Errorf("the %s format requires exactly one environment in --environments", format)
+			}
+		default:
+			return fmt.Errorf("invalid format '%s', must be %s, %s, %s or %s", format, formatManifest, formatOpenFeature, formatBackstage, formatCasc)
+		}
+		if allApplications && outputDir == "" {
+			return fmt.Errorf("--all-applications requires --output-dir")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		applications, err := targetApplications(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		encode := func(m *manifest.Manifest, filename string) ([]byte, error) {
+			switch format {
+			case formatOpenFeature:
+				document, warnings := openfeature.FromManifest(m, environmentNames[0])
+				for _, warning := range warnings {
+					fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+				}
+				data, err := json.MarshalIndent(document, "", "  ")
+				return append(data, '\n'), err
+			case formatBackstage:
+				entities := backstage.FromManifest(m, backstage.Options{Owner: backstageOwner, System: backstageSystem}, time.Now())
+				return backstage.Marshal(entities)
+			case formatCasc:
+				return m.MarshalCasc()
+			default:
+				return m.Marshal(filename)
+			}
+		}
+
+		// JSON manifests are written flag by flag as they are read; the other formats need every flag
+		// first. The JSON manifest is also the artifact of the export.
+		export := func(application *cloudbees.Application, filename string, w io.Writer) (int, error) {
+			if format == formatManifest && !manifest.IsYAML(filename) {
+				if artifact := createArtifact(cmd, "manifest-"+application.Name); artifact != nil {
+					defer artifact.Close()
+					w = io.MultiWriter(w, artifact)
+				}
+				return streamApplicationManifest(cmd, client, application, environmentNames, w)
+			}
+			m, err := exportApplicationManifest(cmd, client, application, environmentNames)
+			if err != nil {
+				return 0, err
+			}
+			writeArtifact(cmd, "manifest-"+application.Name, m)
+			data, err := encode(m, filename)
+			if err != nil {
+				return 0, err
+			}
+			_, err = w.Write(data)
+			return len(m.Flags), err
+		}
+
+		if allApplications {
+			return exportAllApplications(cmd, applications, outputDir, format, export)
+		}
+
+		if file == "" {
+			count, err := export(&applications[0], file, os.Stdout)
+			if err != nil {
+				return err
+			}
+			cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", count))
+			return nil
+		}
+
+		count, err := writeExportFile(file, func(w io.Writer) (int, error) { return export(&applications[0], file, w) })
+		if err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", count))
+		cloudbees.WriteOutput("file", file)
+		fmt.Printf("Exported %d flags to %s\n", count, file)
+
+		return nil
+	},
+}
+
+// writeExportFile writes an export to a temporary file that replaces filename once it is complete,
+// so a failed export never leaves a truncated file behind
+func writeExportFile(filename string, write func(io.Writer) (int, error)) (int, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".fm-actions-export-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	count, err := write(tmp)
+	if closeErr := tmp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write %s: %w", filename, closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+	os.Chmod(tmp.Name(), 0644)
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return count, nil
+}
+
+// exportAllApplications exports every application to its own file in outputDir, named after the application
+func exportAllApplications(cmd *cobra.Command, applications []cloudbees.Application, outputDir, format string,
+	export func(*cloudbees.Application, string, io.Writer) (int, error)) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outputDir, err)
+	}
+	extension := ".json"
+	if format == formatBackstage || format == formatCasc {
+		extension = ".yaml"
+	}
+
+	results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+		func(application cloudbees.Application) (int, error) {
+			filename := filepath.Join(outputDir, application.Name+extension)
+			return writeExportFile(filename, func(w io.Writer) (int, error) { return export(&application, filename, w) })
+		})
+
+	// Output results
+	total := 0
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		if result.Err == nil && !result.Skipped {
+			counts[result.Name] = result.Value
+			total += result.Value
+		}
+	}
+	countsJSON, _ := json.Marshal(counts)
+	cloudbees.WriteOutput("application-count", fmt.Sprintf("%d", results.Succeeded()))
+	cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", total))
+	cloudbees.WriteOutput("flags-by-application", string(countsJSON))
+	cloudbees.WriteOutput("output-dir", outputDir)
+
+	for _, result := range results {
+		if result.Err == nil && !result.Skipped {
+			fmt.Printf("%s: %d flags\n", result.Name, result.Value)
+		}
+	}
+	fmt.Printf("Exported %d flags of %d applications to %s\n", total, results.Succeeded(), outputDir)
+
+	if err := results.Err(); err != nil {
+		return fmt.Errorf("failed to export applications: %w", err)
+	}
+	return nil
+}
+
+// exportManifest reads the live state of the application's flags in the given environments
+// (all enabled environments when none are given)
+func exportManifest(cmd *cobra.Command, client cloudbees.API, applicationName string, environmentNames []string) (*manifest.Manifest, error) {
+	application, err := getApplication(cmd, client, applicationName)
+	if err != nil {
+		return nil, err
+	}
+	return exportApplicationManifest(cmd, client, application, environmentNames)
+}
+
+// exportApplicationManifest is exportManifest for an application that was already looked up
+func exportApplicationManifest(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, environmentNames []string) (*manifest.Manifest, error) {
+	allEnvironments, err := client.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	environments, err := selectEnvironments(allEnvironments, environmentNames)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest.Manifest{
+		Application: application.Name,
+		ExportedAt:  time.Now().UTC().Format(time.RFC3339),
+		Flags:       []manifest.Flag{},
+	}
+	err = exportFlags(cmd, client, application, environments, func(flag manifest.Flag) error {
+		m.Flags = append(m.Flags, flag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// streamApplicationManifest writes the JSON manifest of an application to w flag by flag and
+// returns the number of flags
+func streamApplicationManifest(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, environmentNames []string, w io.Writer) (int, error) {
+	allEnvironments, err := client.ListEnvironments()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list environments: %w", err)
+	}
+	environments, err := selectEnvironments(allEnvironments, environmentNames)
+	if err != nil {
+		return 0, err
+	}
+
+	encoder, err := manifest.NewEncoder(w, application.Name, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	if err := exportFlags(cmd, client, application, environments, encoder.Encode); err != nil {
+		return 0, err
+	}
+	return encoder.Count(), encoder.Close()
+}
+
+// exportChunkSize is the number of flags whose configurations are read before they are passed on,
+// which bounds the memory used to stream the export of a large application
+const exportChunkSize = 100
+
+// exportFlags reads every flag of the application with its configuration in the environments and
+// calls fn for each, in name order
+func exportFlags(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, environments []cloudbees.Environment, fn func(manifest.Flag) error) error {
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list flags: %w", err)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+	for start := 0; start < len(flags); start += exportChunkSize {
+		chunk := flags[start:min(start+exportChunkSize, len(flags))]
+		results := workerpool.Run(chunk, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (manifest.Flag, error) {
+				exported := manifest.Flag{
+					Name:         flag.Name,
+					Type:         flag.FlagType,
+					Description:  flag.Description,
+					Variants:     flag.Variants,
+					Labels:       flag.Labels,
+					IsPermanent:  flag.IsPermanent,
+					Environments: make(map[string]cloudbees.FlagConfiguration, len(environments)),
+				}
+				for _, env := range environments {
+					config, err := client.GetFlagConfiguration(application.ID, flag.ID, env.ID)
+					if err != nil {
+						return exported, err
+					}
+					exported.Environments[env.Name] = config.Configuration
+				}
+				return exported, nil
+			})
+		if err := results.Err(); err != nil {
+			return fmt.Errorf("failed to export flags: %w", err)
+		}
+		for _, result := range results {
+			if err := fn(result.Value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().String("file", "", "Write the manifest to this file (.json, .yaml or .yml) instead of stdout")
+	exportCmd.Flags().String("format", formatManifest, "Output format: manifest, openfeature (flagd JSON), backstage (catalog YAML) or casc (configuration-as-code YAML)")
+	exportCmd.Flags().StringSlice("environments", nil, "Environments to export (defaults to all enabled environments)")
+	exportCmd.Flags().String("backstage-owner", "unknown", "Backstage owner of flags without an owner label")
+	exportCmd.Flags().String("backstage-system", "", "Backstage system the flag entities belong to")
+	exportCmd.Flags().String("output-dir", "", "Directory for the per-application files written with --all-applications")
+	addAllApplicationsFlag(exportCmd)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// graphProblem is an inconsistency of the flag dependencies
+type graphProblem struct {
+	Flag    string `json:"flag"`
+	Problem string `json:"problem"`
+}
+
+var flagGraphCmd = &cobra.Command{
+	Use:   "flag-graph",
+	Short: "Render and validate the dependencies between flags",
+	Long: `Render the prerequisites of the flags of the application, declared with requires:<flag> labels
+(create-flag --requires, update-flag --requires or add-flag-labels), as a Graphviz DOT or Mermaid graph.
+An arrow goes from a flag to each flag it requires. The command fails when a prerequisite is not a flag
+of the application or flags require each other in a cycle. With --environment-name, the flags are
+colored by their state in the environment, and enabled flags with a disabled prerequisite also fail
+the command.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		outputFile, _ := cmd.Flags().GetString("output-file")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+
+		if format != "dot" && format != "mermaid" {
+			return fmt.Errorf("invalid format '%s', must be dot or mermaid", format)
+		}
+
+		client, ctx, err := commandContext(cmd, "", environmentName)
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		flags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+		graph := buildFlagGraph(flags)
+
+		problems := []graphProblem{}
+		for _, flag := range flags {
+			for _, name := range graph.Missing[flag.Name] {
+				problems = append(problems, graphProblem{Flag: flag.Name, Problem: fmt.Sprintf("requires '%s', which is not a flag of the application", name)})
+			}
+		}
+		for _, cycle := range graph.cycles() {
+			problems = append(problems, graphProblem{Flag: cycle[0], Problem: "dependency cycle " + strings.Join(cycle, " -> ")})
+		}
+
+		// State of the flags in the environment
+		var enabled map[string]bool
+		if ctx.Environment != nil {
+			flagsByName := map[string]cloudbees.Flag{}
+			for _, flag := range flags {
+				flagsByName[flag.Name] = flag
+			}
+			configs := workerpool.Run(graph.Nodes, func(name string) string { return name }, poolOptions(cmd),
+				func(name string) (bool, error) {
+					config, err := client.GetFlagConfiguration(application.ID, flagsByName[name].ID, ctx.Environment.ID)
+					if err != nil {
+						return false, err
+					}
+					return config.Configuration.Enabled, nil
+				})
+			if err := configs.Err(); err != nil {
+				return fmt.Errorf("failed to get flag configurations: %w", err)
+			}
+			enabled = map[string]bool{}
+			for _, result := range configs {
+				enabled[result.Name] = result.Value
+			}
+			for _, name := range graph.Nodes {
+				for _, prerequisite := range graph.Edges[name] {
+					if enabled[name] && !enabled[prerequisite] {
+						problems = append(problems, graphProblem{Flag: name, Problem: fmt.Sprintf("enabled in '%s', but requires '%s', which is disabled", ctx.Environment.Name, prerequisite)})
+					}
+				}
+			}
+		}
+
+		var rendered string
+		if format == "dot" {
+			rendered = flagGraphDOT(graph, enabled)
+		} else {
+			rendered = flagGraphMermaid(graph, enabled)
+		}
+		if outputFile != "" {
+			if err := os.WriteFile(outputFile, []byte(rendered), 0644); err != nil {
+				return fmt.Errorf("failed to write graph: %w", err)
+			}
+		}
+
+		// Output results
+		edgeCount := 0
+		for _, prerequisites := range graph.Edges {
+			edgeCount += len(prerequisites)
+		}
+		problemsJSON, _ := json.Marshal(problems)
+		cloudbees.WriteOutput("graph", rendered)
+		cloudbees.WriteOutput("edge-count", fmt.Sprintf("%d", edgeCount))
+		cloudbees.WriteOutput("problems", string(problemsJSON))
+		cloudbees.WriteOutput("valid", fmt.Sprintf("%t", len(problems) == 0))
+
+		if outputFile == "" || verbose {
+			fmt.Print(rendered)
+		} else {
+			fmt.Printf("Wrote the dependencies of %d flags to '%s'\n", len(graph.Nodes), outputFile)
+		}
+
+		if len(problems) > 0 {
+			fmt.Fprintf(os.Stderr, "Found %d dependency problems:\n", len(problems))
+			for _, problem := range problems {
+				fmt.Fprintf(os.Stderr, "- %s: %s\n", problem.Flag, problem.Problem)
+			}
+			return fmt.Errorf("%d dependency problems found", len(problems))
+		}
+		return nil
+	},
+}
+
+// flagGraphDOT renders the graph in the Graphviz DOT language, with the flags colored by their
+// state when enabled is set
+func flagGraphDOT(graph flagGraph, enabled map[string]bool) string {
+	var b strings.Builder
+	b.WriteString("digraph flags {\n  rankdir=LR;\n  node [shape=box, style=rounded];\n")
+	for _, name := range graph.Nodes {
+		if enabled == nil {
+			fmt.Fprintf(&b, "  %q;\n", name)
+		} else if enabled[name] {
+			fmt.Fprintf(&b, "  %q [style=\"rounded,filled\", fillcolor=palegreen];\n", name)
+		} else {
+			fmt.Fprintf(&b, "  %q [style=\"rounded,filled\", fillcolor=lightgray];\n", name)
+		}
+	}
+	for _, name := range graph.Nodes {
+		for _, prerequisite := range graph.Edges[name] {
+			fmt.Fprintf(&b, "  %q -> %q;\n", name, prerequisite)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// flagGraphMermaid renders the graph as a Mermaid flowchart, with the flags colored by their
+// state when enabled is set
+func flagGraphMermaid(graph flagGraph, enabled map[string]bool) string {
+	// Flag names are not valid Mermaid identifiers, so nodes are numbered
+	ids := map[string]string{}
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, name := range graph.Nodes {
+		ids[name] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[name], strings.ReplaceAll(name, `"`, "#quot;"))
+	}
+	for _, name := range graph.Nodes {
+		for _, prerequisite := range graph.Edges[name] {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[name], ids[prerequisite])
+		}
+	}
+	if enabled != nil {
+		b.WriteString("  classDef enabled fill:#c8f7c5\n  classDef disabled fill:#e0e0e0\n")
+		for _, name := range graph.Nodes {
+			class := "disabled"
+			if enabled[name] {
+				class = "enabled"
+			}
+			fmt.Fprintf(&b, "  class %s %s\n", ids[name], class)
+		}
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(flagGraphCmd)
+
+	flagGraphCmd.Flags().String("format", "mermaid", "Graph format: dot or mermaid")
+	flagGraphCmd.Flags().String("output-file", "", "Write the graph to this file")
+	flagGraphCmd.Flags().StringP("environment-name", "e", "", "Color the flags by their state in this environment and check their prerequisites are enabled")
+}
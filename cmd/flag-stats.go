@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+// flagStats holds the evaluation counts of a flag in an environment
+type flagStats struct {
+	FlagName    string           `json:"flagName"`
+	Environment string           `json:"environment"`
+	Total       int64            `json:"total"`
+	Variants    map[string]int64 `json:"variants"`
+	LastSeen    string           `json:"lastSeen,omitempty"`
+}
+
+var flagStatsCmd = &cobra.Command{
+	Use:   "flag-stats",
+	Short: "Report flag evaluation counts per variant and environment",
+	Long: `Report how many times each flag was evaluated, per variant and environment, over a time window
+(--since 30d by default). Flags without evaluations in the window are listed as unused, which helps
+to decide on stale flags and to monitor experiments.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		since, _ := cmd.Flags().GetString("since")
+		until, _ := cmd.Flags().GetString("until")
+
+		now := time.Now()
+		from, err := parseTimeWindow(since, now)
+		if err != nil {
+			return err
+		}
+		to := now
+		if until != "" {
+			if to, err = parseTimeWindow(until, now); err != nil {
+				return err
+			}
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("--since must be before --until")
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		allEnvironments, err := client.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		environments, err := selectEnvironments(allEnvironments, environmentNames)
+		if err != nil {
+			return err
+		}
+
+		var flags []cloudbees.Flag
+		if flagName != "" {
+			flag, err := client.GetFlagByName(application.ID, flagName)
+			if err != nil {
+				return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+			}
+			flags = []cloudbees.Flag{*flag}
+		} else if flags, err = client.ListFlags(application.ID); err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+
+		stats := []flagStats{}
+		var total int64
+		evaluated := map[string]bool{}
+		for _, env := range environments {
+			query := cloudbees.ImpressionsQuery{EnvironmentID: env.ID, From: from, To: to}
+			if flagName != "" {
+				query.FlagID = flags[0].ID
+			}
+			impressions, err := client.ListImpressions(application.ID, query)
+			if err != nil {
+				return fmt.Errorf("failed to list impressions of environment '%s': %w", env.Name, err)
+			}
+
+			byFlag := map[string]*flagStats{}
+			for _, impression := range impressions {
+				entry, ok := byFlag[impression.FlagName]
+				if !ok {
+					entry = &flagStats{FlagName: impression.FlagName, Environment: env.Name, Variants: map[string]int64{}}
+					byFlag[impression.FlagName] = entry
+				}
+				entry.Variants[impression.Variant] += impression.Count
+				entry.Total += impression.Count
+				if impression.LastSeen > entry.LastSeen {
+					entry.LastSeen = impression.LastSeen
+				}
+			}
+			for _, entry := range byFlag {
+				stats = append(stats, *entry)
+				total += entry.Total
+				if entry.Total > 0 {
+					evaluated[entry.FlagName] = true
+				}
+			}
+		}
+		sort.Slice(stats, func(i, j int) bool {
+			if stats[i].FlagName != stats[j].FlagName {
+				return stats[i].FlagName < stats[j].FlagName
+			}
+			return stats[i].Environment < stats[j].Environment
+		})
+
+		unused := []string{}
+		for _, flag := range flags {
+			if !evaluated[flag.Name] {
+				unused = append(unused, flag.Name)
+			}
+		}
+		sort.Strings(unused)
+
+		// Output results
+		statsJSON, _ := json.Marshal(stats)
+		unusedJSON, _ := json.Marshal(unused)
+		cloudbees.WriteOutput("stats", string(statsJSON))
+		cloudbees.WriteOutput("total-evaluations", fmt.Sprintf("%d", total))
+		cloudbees.WriteOutput("unused-flags", string(unusedJSON))
+		cloudbees.WriteOutput("unused-count", fmt.Sprintf("%d", len(unused)))
+
+		fmt.Printf("Evaluations from %s to %s:\n", from.UTC().Format(time.RFC3339), to.UTC().Format(time.RFC3339))
+		for _, entry := range stats {
+			variants := make([]string, 0, len(entry.Variants))
+			for variant := range entry.Variants {
+				variants = append(variants, variant)
+			}
+			sort.Strings(variants)
+			counts := make([]string, 0, len(variants))
+			for _, variant := range variants {
+				counts = append(counts, fmt.Sprintf("%s=%d", variant, entry.Variants[variant]))
+			}
+			fmt.Printf("- %s (%s): %d [%s]\n", entry.FlagName, entry.Environment, entry.Total, strings.Join(counts, ", "))
+		}
+		if len(unused) > 0 {
+			fmt.Printf("Unused flags: %s\n", strings.Join(unused, ", "))
+		}
+
+		return nil
+	},
+}
+
+// parseTimeWindow parses a window boundary given as a date (2025-06-01), an RFC 3339 timestamp,
+// or a duration before now (30d, 2w, 12h)
+func parseTimeWindow(value string, now time.Time) (time.Time, error) {
+	if date, err := time.Parse(cloudbees.ExpiryDateFormat, value); err == nil {
+		return date, nil
+	}
+	if timestamp, err := time.Parse(time.RFC3339, value); err == nil {
+		return timestamp, nil
+	}
+	if len(value) > 1 {
+		if amount, err := strconv.Atoi(value[:len(value)-1]); err == nil && amount > 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -amount), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*amount), nil
+			}
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+		return now.Add(-duration), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s', use a date (YYYY-MM-DD), a timestamp or a duration (30d, 2w, 12h)", value)
+}
+
+func init() {
+	rootCmd.AddCommand(flagStatsCmd)
+
+	flagStatsCmd.Flags().StringP("flag-name", "f", "", "Only report this flag")
+	flagStatsCmd.Flags().StringSlice("environments", nil, "Environments to report (defaults to all enabled environments)")
+	flagStatsCmd.Flags().String("since", "30d", "Start of the time window: a date, a timestamp or a duration before now (30d, 2w, 12h)")
+	flagStatsCmd.Flags().String("until", "", "End of the time window (defaults to now)")
+
+	flagNameArg(flagStatsCmd)
+}
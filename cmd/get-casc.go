@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var getCascCmd = &cobra.Command{
+	Use:   "get-casc",
+	Short: "Fetch the configuration-as-code document of a flag or application",
+	Long: `Fetch the configuration-as-code document that CloudBees serves at the cascUrl of a flag.
+Without --flag-name, the documents of every flag of the application are fetched and joined into
+one multi-document YAML file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		file, _ := cmd.Flags().GetString("file")
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		var flags []cloudbees.Flag
+		if flag := ctx.Flag; flag != nil {
+			if flag.CascURL == "" {
+				return fmt.Errorf("flag '%s' has no configuration-as-code document", flag.Name)
+			}
+			flags = []cloudbees.Flag{*flag}
+		} else {
+			allFlags, err := client.ListFlags(application.ID)
+			if err != nil {
+				return fmt.Errorf("failed to list flags: %w", err)
+			}
+			for _, flag := range allFlags {
+				if flag.CascURL == "" {
+					fmt.Fprintf(os.Stderr, "Warning: flag '%s' has no configuration-as-code document\n", flag.Name)
+					continue
+				}
+				flags = append(flags, flag)
+			}
+		}
+
+		results := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (string, error) {
+				data, err := client.GetCasc(flag.CascURL)
+				if err != nil {
+					return "", fmt.Errorf("failed to fetch configuration-as-code document: %w", err)
+				}
+				return string(data), nil
+			})
+		if err := results.Err(); err != nil {
+			return err
+		}
+
+		var documents []string
+		for _, result := range results {
+			documents = append(documents, strings.TrimSuffix(result.Value, "\n")+"\n")
+		}
+		casc := strings.Join(documents, "---\n")
+
+		// Output results
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(documents)))
+		cloudbees.WriteOutput("casc", casc)
+		if file == "" {
+			fmt.Print(casc)
+			return nil
+		}
+
+		if err := os.WriteFile(file, []byte(casc), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		cloudbees.WriteOutput("file", file)
+		fmt.Printf("Fetched %d configuration-as-code documents to %s\n", len(documents), file)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCascCmd)
+
+	getCascCmd.Flags().StringP("flag-name", "f", "", "Name of the flag (defaults to every flag of the application)")
+	getCascCmd.Flags().String("file", "", "Write the documents to this file instead of stdout")
+
+	flagNameArg(getCascCmd)
+}
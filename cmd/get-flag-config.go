@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
 	"github.com/spf13/cobra"
@@ -11,10 +12,12 @@ import (
 var getFlagConfigCmd = &cobra.Command{
 	Use:   "get-flag-config",
 	Short: "Get feature flag configuration",
-	Long:  `Get the current feature flag configuration for a specific flag in a given environment.`,
+	Long: `Get the current feature flag configuration for a specific flag in a given environment,
+or a previous revision of it with --revision (see config-history).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagName, _ := cmd.Flags().GetString("flag-name")
 		environmentName, _ := cmd.Flags().GetString("environment-name")
+		revisionID, _ := cmd.Flags().GetString("revision")
 
 		if flagName == "" {
 			return fmt.Errorf("flag-name is required")
@@ -23,53 +26,30 @@ var getFlagConfigCmd = &cobra.Command{
 			return fmt.Errorf("environment-name is required")
 		}
 
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
-		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
-
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
-		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
-		}
-
-		// First, get the application to retrieve its ID
-		application, err := client.GetApplicationByName(applicationName)
-		if err != nil {
-			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
-		}
-
-		// Get the flag to retrieve its ID
-		flag, err := client.GetFlagByName(application.ID, flagName)
+		client, ctx, err := commandContext(cmd, flagName, environmentName)
 		if err != nil {
-			return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+			return err
 		}
+		application, flag, environmentID := ctx.Application, ctx.Flag, ctx.Environment.ID
 
-		// Get all environments to find the one that matches the name
-		environments, err := client.ListEnvironments()
+		// Get flag configuration
+		config, err := client.GetFlagConfiguration(application.ID, flag.ID, environmentID)
 		if err != nil {
-			return fmt.Errorf("failed to list environments: %w", err)
+			return fmt.Errorf("failed to get flag configuration: %w", err)
 		}
 
-		var environmentID string
-		for _, env := range environments {
-			if env.Name == environmentName {
-				environmentID = env.ID
-				break
+		var revision *cloudbees.ConfigurationRevision
+		if revisionID != "" {
+			revision, err = client.GetFlagConfigurationRevision(application.ID, flag.ID, environmentID, revisionID)
+			if err != nil {
+				return fmt.Errorf("failed to get revision '%s': %w", revisionID, err)
 			}
+			config.Configuration = revision.Configuration
+			config.Revision = revision.Revision
+			config.ETag = ""
 		}
 
-		if environmentID == "" {
-			return fmt.Errorf("environment '%s' not found", environmentName)
-		}
-
-		// Get flag configuration
-		config, err := client.GetFlagConfiguration(application.ID, flag.ID, environmentID)
-		if err != nil {
-			return fmt.Errorf("failed to get flag configuration: %w", err)
-		}
+		completeConfigurationDetail(config, flag)
 
 		// Output results
 		configJSON, _ := json.Marshal(config)
@@ -77,6 +57,25 @@ var getFlagConfigCmd = &cobra.Command{
 		cloudbees.WriteOutput("flag-id", flag.ID)
 		cloudbees.WriteOutput("environment-id", environmentID)
 		cloudbees.WriteOutput("enabled", fmt.Sprintf("%t", config.Configuration.Enabled))
+		cloudbees.WriteOutput("revision", config.Revision)
+		cloudbees.WriteOutput("flag-name", config.FlagName)
+		cloudbees.WriteOutput("created", config.Created)
+		cloudbees.WriteOutput("updated", config.Updated)
+
+		labels := config.Labels
+		if labels == nil {
+			labels = []string{}
+		}
+		labelsJSON, _ := json.Marshal(labels)
+		cloudbees.WriteOutput("labels", string(labelsJSON))
+
+		conditionsJSON, _ := json.Marshal(config.Configuration.Conditions)
+		cloudbees.WriteOutput("conditions", string(conditionsJSON))
+
+		if revision != nil {
+			cloudbees.WriteOutput("revision-timestamp", revision.Timestamp)
+			cloudbees.WriteOutput("revision-actor", revision.Actor)
+		}
 
 		// Output default-value as JSON string
 		if config.Configuration.DefaultValue != nil {
@@ -98,19 +97,54 @@ var getFlagConfigCmd = &cobra.Command{
 			if config.Configuration.StickinessProperty != "" {
 				fmt.Printf("Stickiness Property: %s\n", config.Configuration.StickinessProperty)
 			}
+			if config.Configuration.Conditions != nil {
+				fmt.Printf("Conditions: %s\n", string(conditionsJSON))
+			}
+			if len(config.Labels) > 0 {
+				fmt.Printf("Labels: %s\n", strings.Join(config.Labels, ", "))
+			}
+			if config.Created != "" {
+				fmt.Printf("Created: %s\n", config.Created)
+			}
+			if config.Updated != "" {
+				fmt.Printf("Updated: %s\n", config.Updated)
+			}
+			fmt.Printf("Revision: %s\n", config.Revision)
 		}
 
 		return nil
 	},
 }
 
+// completeConfigurationDetail fills the details that older API versions do not return with the
+// configuration from the flag
+func completeConfigurationDetail(config *cloudbees.FlagConfigurationDetail, flag *cloudbees.Flag) {
+	if config.FlagName == "" {
+		config.FlagName = flag.Name
+	}
+	if config.Description == "" {
+		config.Description = flag.Description
+	}
+	if config.Labels == nil {
+		config.Labels = flag.Labels
+	}
+	if config.Created == "" {
+		config.Created = flag.Created
+	}
+	if config.Updated == "" {
+		config.Updated = flag.Updated
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(getFlagConfigCmd)
 
 	getFlagConfigCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
 	getFlagConfigCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	getFlagConfigCmd.Flags().String("revision", "", "Get this previous revision of the configuration (from config-history)")
+
+	flagNameArg(getFlagConfigCmd)
 
 	getFlagConfigCmd.MarkFlagRequired("flag-name")
 	getFlagConfigCmd.MarkFlagRequired("environment-name")
-	getFlagConfigCmd.MarkPersistentFlagRequired("application-name")
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var getFlagConfigsCmd = &cobra.Command{
+	Use:   "get-flag-configs",
+	Short: "Get the configuration of several feature flags",
+	Long: `Get the current configuration of several flags in an environment with one command, fetched
+concurrently, e.g. for a workflow that gates on several flags. The configurations are printed
+and written to the flag-configs output as one JSON document keyed by flag name, and the names
+of the enabled flags to the enabled-flags output.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagNames, _ := cmd.Flags().GetStringSlice("flag-names")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+
+		if len(flagNames) == 0 {
+			return fmt.Errorf("flag-names is required")
+		}
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+
+		client, ctx, err := commandContext(cmd, "", environmentName)
+		if err != nil {
+			return err
+		}
+		application, environment := ctx.Application, ctx.Environment
+
+		results := workerpool.Run(flagNames, func(name string) string { return name }, poolOptions(cmd),
+			func(name string) (*cloudbees.FlagConfigurationDetail, error) {
+				flag, err := client.GetFlagByName(application.ID, name)
+				if err != nil {
+					return nil, err
+				}
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+				if err != nil {
+					return nil, err
+				}
+				completeConfigurationDetail(config, flag)
+				return config, nil
+			})
+
+		configs := map[string]*cloudbees.FlagConfigurationDetail{}
+		enabled := []string{}
+		for _, result := range results {
+			if result.Err != nil || result.Value == nil {
+				continue
+			}
+			configs[result.Name] = result.Value
+			if result.Value.Configuration.Enabled {
+				enabled = append(enabled, result.Name)
+			}
+		}
+
+		// Output results
+		configsJSON, _ := json.Marshal(configs)
+		enabledJSON, _ := json.Marshal(enabled)
+		cloudbees.WriteOutput("flag-configs", string(configsJSON))
+		writeArtifact(cmd, "flag-configs", configs)
+		cloudbees.WriteOutput("enabled-flags", string(enabledJSON))
+		cloudbees.WriteOutput("environment-id", environment.ID)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(configs)))
+
+		if err := bulkErr(cmd, results); err != nil {
+			return fmt.Errorf("failed to get flag configurations: %w", err)
+		}
+
+		document, _ := json.MarshalIndent(configs, "", "  ")
+		fmt.Println(string(document))
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getFlagConfigsCmd)
+
+	getFlagConfigsCmd.Flags().StringSlice("flag-names", nil, "Names of the flags (required)")
+	getFlagConfigsCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+
+	getFlagConfigsCmd.MarkFlagRequired("flag-names")
+	getFlagConfigsCmd.MarkFlagRequired("environment-name")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Help groups of the root command
+const (
+	resourceGroupID   = "resources"
+	deprecatedGroupID = "deprecated"
+)
+
+// commandGroup is a resource noun whose verbs are the flat commands it replaces
+type commandGroup struct {
+	name    string
+	aliases []string
+	short   string
+	verbs   []commandVerb
+}
+
+type commandVerb struct {
+	name    string
+	command *cobra.Command
+}
+
+// commandGroups maps the resource commands (fm-actions flag create) to the flat commands
+// (fm-actions create-flag) that are kept as deprecated aliases
+var commandGroups = []commandGroup{
+	{name: "flag", short: "Manage feature flags", verbs: []commandVerb{
+		{"create", createFlagCmd},
+		{"delete", deleteFlagCmd},
+		{"list", listFlagsCmd},
+		{"update", updateFlagCmd},
+		{"rename", renameFlagCmd},
+		{"clone", cloneFlagCmd},
+		{"copy", copyFlagCmd},
+		{"migrate", migrateFlagsCmd},
+		{"add-labels", addFlagLabelsCmd},
+		{"remove-labels", removeFlagLabelsCmd},
+		{"stale", staleFlagsCmd},
+		{"stats", flagStatsCmd},
+		{"casc", getCascCmd},
+	}},
+	{name: "env", aliases: []string{"environment"}, short: "Manage environments", verbs: []commandVerb{
+		{"list", listEnvironmentsCmd},
+		{"create", createEnvironmentCmd},
+		{"update", updateEnvironmentCmd},
+		{"delete", deleteEnvironmentCmd},
+		{"compare", compareEnvironmentsCmd},
+		{"promote", promoteEnvironmentCmd},
+		{"bootstrap", envBootstrapCmd},
+		{"teardown", envTeardownCmd},
+	}},
+	{name: "config", short: "Manage flag configurations", verbs: []commandVerb{
+		{"get", getFlagConfigCmd},
+		{"set", setFlagConfigCmd},
+		{"history", configHistoryCmd},
+		{"rollback", rollbackFlagConfigCmd},
+		{"set-weights", setVariantWeightsCmd},
+	}},
+	{name: "app", aliases: []string{"application"}, short: "Manage applications", verbs: []commandVerb{
+		{"create", createApplicationCmd},
+		{"update", updateApplicationCmd},
+		{"link-environment", linkEnvironmentCmd},
+	}},
+}
+
+// buildCommandGroups adds the resource commands to root and marks the flat commands they
+// replace as deprecated. The flat commands stay listed so existing workflows keep working.
+func buildCommandGroups(root *cobra.Command) {
+	if root.ContainsGroup(resourceGroupID) {
+		return
+	}
+	root.AddGroup(
+		&cobra.Group{ID: resourceGroupID, Title: "Resource Commands:"},
+		&cobra.Group{ID: deprecatedGroupID, Title: "Deprecated Commands (use the resource commands above):"},
+	)
+
+	for _, group := range commandGroups {
+		parent := &cobra.Command{
+			Use:     group.name,
+			Aliases: group.aliases,
+			Short:   group.short,
+			GroupID: resourceGroupID,
+		}
+		for _, verb := range group.verbs {
+			parent.AddCommand(groupedCommand(verb.name, verb.command))
+			deprecateCommand(verb.command, group.name+" "+verb.name)
+		}
+		root.AddCommand(parent)
+	}
+}
+
+// groupedCommand creates the resource verb of a flat command. The options are shared with the
+// flat command, so they have the same defaults, requirements and completions.
+func groupedCommand(verb string, flat *cobra.Command) *cobra.Command {
+	use := verb
+	if _, args, ok := strings.Cut(flat.Use, " "); ok {
+		use += " " + args
+	}
+	grouped := &cobra.Command{
+		Use:               use,
+		Short:             flat.Short,
+		Long:              flat.Long,
+		Args:              flat.Args,
+		ValidArgs:         flat.ValidArgs,
+		ValidArgsFunction: flat.ValidArgsFunction,
+		PreRunE:           flat.PreRunE,
+		RunE:              flat.RunE,
+		Annotations:       map[string]string{"operation": flat.Name()},
+	}
+	grouped.Flags().AddFlagSet(flat.Flags())
+	return grouped
+}
+
+// deprecateCommand warns that a flat command was replaced by a resource command
+func deprecateCommand(flat *cobra.Command, replacement string) {
+	run := flat.RunE
+	flat.GroupID = deprecatedGroupID
+	flat.Short += fmt.Sprintf(" (deprecated, use %s)", replacement)
+	flat.RunE = func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintf(os.Stderr, "Warning: '%s %s' is deprecated, use '%s %s' instead\n", cmd.Root().Name(), cmd.Name(), cmd.Root().Name(), replacement)
+		return run(cmd, args)
+	}
+}
+
+// commandName is the flat name of a command, used for operations and metrics so they do not
+// depend on whether the resource or the deprecated command was run
+func commandName(cmd *cobra.Command) string {
+	if operation, ok := cmd.Annotations["operation"]; ok {
+		return operation
+	}
+	return cmd.Name()
+}
@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	fmv1 "github.com/cloudbees-days/fm-actions-container/api/fm/v1"
+	"github.com/cloudbees-days/fm-actions-container/internal/approval"
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// grpcService serves the flag operations of api/proto/fm/v1/flags.proto, with the semantics of
+// the REST API
+type grpcService struct {
+	fmv1.UnimplementedFlagServiceServer
+	cmd           *cobra.Command
+	fallbackToken string
+}
+
+// newGRPCServer returns a gRPC server with the flag service
+func newGRPCServer(cmd *cobra.Command, fallbackToken string) *grpc.Server {
+	server := grpc.NewServer()
+	fmv1.RegisterFlagServiceServer(server, &grpcService{cmd: cmd, fallbackToken: fallbackToken})
+	return server
+}
+
+// client creates a client authenticated with the caller's bearer token, from the authorization metadata
+func (s *grpcService) client(ctx context.Context) (cloudbees.API, error) {
+	authorization := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	return bearerClient(s.cmd, authorization, s.fallbackToken)
+}
+
+func (s *grpcService) ListEnvironments(ctx context.Context, request *fmv1.ListEnvironmentsRequest) (*fmv1.ListEnvironmentsResponse, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	environments, err := client.ListEnvironments()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := &fmv1.ListEnvironmentsResponse{}
+	for _, environment := range environments {
+		response.Environments = append(response.Environments, &fmv1.Environment{
+			Id:          environment.ID,
+			Name:        environment.Name,
+			Description: environment.Description,
+			IsDisabled:  environment.IsDisabled,
+		})
+	}
+	return response, nil
+}
+
+func (s *grpcService) ListFlags(ctx context.Context, request *fmv1.ListFlagsRequest) (*fmv1.ListFlagsResponse, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	application, err := client.GetApplicationByName(request.GetApplication())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	response := &fmv1.ListFlagsResponse{}
+	for _, flag := range flags {
+		if hasAnyLabel(flag, request.GetLabels()) {
+			response.Flags = append(response.Flags, protoFlag(&flag))
+		}
+	}
+	return response, nil
+}
+
+func (s *grpcService) GetFlag(ctx context.Context, request *fmv1.GetFlagRequest) (*fmv1.Flag, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	application, err := client.GetApplicationByName(request.GetApplication())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	flag, err := client.GetFlagByName(application.ID, request.GetFlag())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return protoFlag(flag), nil
+}
+
+func (s *grpcService) CreateFlag(ctx context.Context, request *fmv1.CreateFlagRequest) (*fmv1.Flag, error) {
+	if request.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	createRequest := cloudbees.CreateFlagRequest{
+		Name:        request.GetName(),
+		FlagType:    request.GetFlagType(),
+		Variants:    request.GetVariants(),
+		Description: request.GetDescription(),
+		IsPermanent: request.GetIsPermanent(),
+		Labels:      request.GetLabels(),
+	}
+	if createRequest.FlagType == "" {
+		createRequest.FlagType = "Boolean"
+	}
+	if len(createRequest.Variants) == 0 && createRequest.FlagType == "Boolean" {
+		createRequest.Variants = []string{"true", "false"}
+	}
+
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	application, err := client.GetApplicationByName(request.GetApplication())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	change := mutation{
+		Operation:   "create-flag",
+		Application: application.Name,
+		Flag:        createRequest.Name,
+		Labels:      createRequest.Labels,
+		Changes: map[string]interface{}{
+			"flagType":    createRequest.FlagType,
+			"variants":    createRequest.Variants,
+			"description": createRequest.Description,
+			"isPermanent": createRequest.IsPermanent,
+		},
+	}
+	if err := beforeMutation(s.cmd, change); err != nil {
+		return nil, grpcError(err)
+	}
+
+	flag, err := client.CreateFlagFromRequest(application.ID, createRequest)
+	change.After = flag
+	afterMutation(s.cmd, change, err)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return protoFlag(flag), nil
+}
+
+func (s *grpcService) DeleteFlag(ctx context.Context, request *fmv1.DeleteFlagRequest) (*fmv1.DeleteFlagResponse, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	application, err := client.GetApplicationByName(request.GetApplication())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	flag, err := client.GetFlagByName(application.ID, request.GetFlag())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	change := mutation{
+		Operation:   "delete-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Before:      flag,
+	}
+	if err := beforeMutation(s.cmd, change); err != nil {
+		return nil, grpcError(err)
+	}
+
+	err = client.DeleteFlag(application.ID, flag.ID)
+	afterMutation(s.cmd, change, err)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &fmv1.DeleteFlagResponse{}, nil
+}
+
+func (s *grpcService) GetFlagConfiguration(ctx context.Context, request *fmv1.GetFlagConfigurationRequest) (*fmv1.FlagConfiguration, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resolved, err := resolveContext(s.cmd, client, request.GetApplication(), request.GetFlag(), request.GetEnvironment())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	config, err := client.GetFlagConfiguration(resolved.Application.ID, resolved.Flag.ID, resolved.Environment.ID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return protoConfiguration(config.Configuration, config.Revision)
+}
+
+func (s *grpcService) UpdateFlagConfiguration(ctx context.Context, request *fmv1.UpdateFlagConfigurationRequest) (*fmv1.FlagConfiguration, error) {
+	// Only the fields that are set are changed
+	changes := map[string]interface{}{}
+	if request.Enabled != nil {
+		changes["enabled"] = request.GetEnabled()
+	}
+	if request.DefaultValue != nil {
+		changes["defaultValue"] = request.GetDefaultValue().AsInterface()
+	}
+	if request.Conditions != nil {
+		changes["conditions"] = request.GetConditions().AsInterface()
+	}
+	if request.VariantsEnabled != nil {
+		changes["variantsEnabled"] = request.GetVariantsEnabled()
+	}
+	if request.StickinessProperty != nil {
+		changes["stickinessProperty"] = request.GetStickinessProperty()
+	}
+	if len(changes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no configuration changes specified")
+	}
+
+	client, err := s.client(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	update, err := updateFlagConfiguration(s.cmd, client, request.GetApplication(), request.GetFlag(),
+		request.GetEnvironment(), changes, request.GetIfMatch(), false)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	var after cloudbees.FlagConfiguration
+	data, _ := json.Marshal(update.After)
+	if err := json.Unmarshal(data, &after); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read updated configuration: %v", err)
+	}
+	return protoConfiguration(after, "")
+}
+
+// protoFlag converts a flag to its protobuf message
+func protoFlag(flag *cloudbees.Flag) *fmv1.Flag {
+	return &fmv1.Flag{
+		Id:          flag.ID,
+		Name:        flag.Name,
+		FlagType:    flag.FlagType,
+		Variants:    flag.Variants,
+		Description: flag.Description,
+		IsPermanent: flag.IsPermanent,
+		Labels:      flag.Labels,
+	}
+}
+
+// protoConfiguration converts a flag configuration to its protobuf message. The revision of
+// an update is unknown until the configuration is read again.
+func protoConfiguration(config cloudbees.FlagConfiguration, revision string) (*fmv1.FlagConfiguration, error) {
+	message := &fmv1.FlagConfiguration{
+		Enabled:            config.Enabled,
+		VariantsEnabled:    config.VariantsEnabled,
+		StickinessProperty: config.StickinessProperty,
+		Revision:           revision,
+	}
+	var err error
+	if config.DefaultValue != nil {
+		if message.DefaultValue, err = structpb.NewValue(normalizeJSON(config.DefaultValue)); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert default value: %v", err)
+		}
+	}
+	if config.Conditions != nil {
+		if message.Conditions, err = structpb.NewValue(normalizeJSON(config.Conditions)); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert conditions: %v", err)
+		}
+	}
+	return message, nil
+}
+
+// grpcError returns the gRPC status matching err, as writeError does for HTTP
+func grpcError(err error) error {
+	code := codes.Unavailable
+	var apiErr *cloudbees.APIError
+	switch {
+	case errors.Is(err, errUnauthorized):
+		code = codes.Unauthenticated
+	case errors.Is(err, cloudbees.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, cloudbees.ErrConflict):
+		code = codes.Aborted
+	case errors.Is(err, policy.ErrViolation), errors.Is(err, approval.ErrNotApproved), errors.Is(err, cloudbees.ErrReadOnly):
+		code = codes.PermissionDenied
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusForbidden:
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
+}
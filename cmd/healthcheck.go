@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var healthcheckCmd = &cobra.Command{
+	Use:   "healthcheck",
+	Short: "Check API connectivity and the liveness of a long-running command",
+	Long: `Exit with status 0 when the container is healthy and 1 otherwise, for a Docker HEALTHCHECK or a
+Kubernetes liveness/readiness probe. The API is reached with the connection options unless
+--skip-api is set, in which case --token and --org-id are not required. With --url, a serve endpoint such as http://localhost:8080/healthz must answer
+200. With --heartbeat-file, the file touched by drift-watch after every check must be younger
+than --max-age, which proves the watch loop is still running.`,
+	Annotations: map[string]string{connectionOptionalAnnotation: "skip-api"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		skipAPI, _ := cmd.Flags().GetBool("skip-api")
+		url, _ := cmd.Flags().GetString("url")
+		heartbeatFile, _ := cmd.Flags().GetString("heartbeat-file")
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		var failures []string
+		report := func(check string, err error) {
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", check, err))
+				return
+			}
+			if verbose {
+				fmt.Printf("%s: ok\n", check)
+			}
+		}
+
+		if !skipAPI {
+			report("api", checkAPIHealth(cmd))
+		}
+		if url != "" {
+			report("url", checkURLHealth(url, timeout))
+		}
+		if heartbeatFile != "" {
+			report("heartbeat", checkHeartbeat(heartbeatFile, maxAge))
+		}
+
+		// Output results
+		cloudbees.WriteOutput("healthy", fmt.Sprintf("%t", len(failures) == 0))
+		if len(failures) > 0 {
+			return fmt.Errorf("unhealthy:\n  %s", strings.Join(failures, "\n  "))
+		}
+		fmt.Println("healthy")
+
+		return nil
+	},
+}
+
+// checkAPIHealth verifies that the API is reachable and accepts the credentials
+func checkAPIHealth(cmd *cobra.Command) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	_, err = client.ListEnvironments()
+	return err
+}
+
+// checkURLHealth verifies that url answers 200 within timeout
+func checkURLHealth(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// checkHeartbeat verifies that filename was touched less than maxAge ago
+func checkHeartbeat(filename string, maxAge time.Duration) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read heartbeat: %w", err)
+	}
+	if age := time.Since(info.ModTime()); age > maxAge {
+		return fmt.Errorf("last heartbeat %s ago, more than %s", age.Round(time.Second), maxAge)
+	}
+	return nil
+}
+
+// writeHeartbeat records that a long-running loop is alive, for healthcheck --heartbeat-file
+func writeHeartbeat(filename string) error {
+	if err := os.WriteFile(filename, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write heartbeat: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(healthcheckCmd)
+
+	healthcheckCmd.Flags().Bool("skip-api", false, "Do not check the API connectivity")
+	healthcheckCmd.Flags().String("url", "", "URL that must answer 200, e.g. the /healthz endpoint of serve")
+	healthcheckCmd.Flags().String("heartbeat-file", "", "Heartbeat file written by drift-watch --heartbeat-file")
+	healthcheckCmd.Flags().Duration("max-age", 15*time.Minute, "Maximum age of the heartbeat, e.g. three times the drift-watch interval")
+	healthcheckCmd.Flags().Duration("timeout", 5*time.Second, "Timeout of the --url check")
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importFlagsmithCmd = &cobra.Command{
+	Use:   "flagsmith",
+	Short: "Import flags from Flagsmith",
+	Long: `Import the flags of a Flagsmith project from the environment documents of its environments,
+read from files or from the Flagsmith API with server-side environment keys. Enabled state, values
+and multivariate splits are imported. Segment and identity overrides are listed in the mapping report.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, _ := cmd.Flags().GetStringSlice("file")
+		apiURL, _ := cmd.Flags().GetString("flagsmith-api-url")
+		keys, _ := cmd.Flags().GetStringSlice("environment-key")
+
+		if len(keys) == 0 && os.Getenv("FLAGSMITH_ENVIRONMENT_KEYS") != "" {
+			keys = strings.Split(os.Getenv("FLAGSMITH_ENVIRONMENT_KEYS"), ",")
+		}
+
+		return runImport(cmd, &importer.Flagsmith{Files: files, APIURL: apiURL, EnvironmentKeys: keys})
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importFlagsmithCmd)
+
+	importFlagsmithCmd.Flags().StringSlice("file", nil, "Flagsmith environment document (JSON), one per environment (repeatable)")
+	importFlagsmithCmd.Flags().StringSlice("environment-key", nil, "Server-side environment key, to import from the API (repeatable, or FLAGSMITH_ENVIRONMENT_KEYS)")
+	importFlagsmithCmd.Flags().String("flagsmith-api-url", importer.FlagsmithAPIURL, "Flagsmith API URL")
+	addImportFlags(importFlagsmithCmd)
+}
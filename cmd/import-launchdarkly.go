@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importLaunchDarklyCmd = &cobra.Command{
+	Use:   "launchdarkly",
+	Short: "Import flags from LaunchDarkly",
+	Long: `Import the flags of a LaunchDarkly project from an export file (the response of
+GET /api/v2/flags/{project}?summary=0) or directly from the LaunchDarkly API.
+On/off state, variations and the default rule (including percentage rollouts) are imported.
+Individual targets, targeting rules and prerequisites are listed in the mapping report.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		apiToken, _ := cmd.Flags().GetString("ld-api-token")
+		apiURL, _ := cmd.Flags().GetString("ld-api-url")
+		project, _ := cmd.Flags().GetString("project")
+
+		if apiToken == "" {
+			apiToken = os.Getenv("LD_API_TOKEN")
+		}
+
+		return runImport(cmd, &importer.LaunchDarkly{File: file, APIURL: apiURL, Token: apiToken, Project: project})
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importLaunchDarklyCmd)
+
+	importLaunchDarklyCmd.Flags().String("file", "", "LaunchDarkly flags export (JSON)")
+	importLaunchDarklyCmd.Flags().String("project", "", "LaunchDarkly project key, to import from the API")
+	importLaunchDarklyCmd.Flags().String("ld-api-token", "", "LaunchDarkly API access token (or LD_API_TOKEN)")
+	importLaunchDarklyCmd.Flags().String("ld-api-url", importer.LaunchDarklyAPIURL, "LaunchDarkly API URL")
+	addImportFlags(importLaunchDarklyCmd)
+}
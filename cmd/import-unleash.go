@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importUnleashCmd = &cobra.Command{
+	Use:   "unleash",
+	Short: "Import feature toggles from Unleash",
+	Long: `Import the feature toggles of an Unleash project from an export file (the response of
+POST /api/admin/features-batch/export) or directly from the Unleash API, one export per environment.
+Enabled state, a single default or gradual rollout strategy, and variants are imported.
+Constraints, segments and other strategies are listed in the mapping report.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		url, _ := cmd.Flags().GetString("unleash-url")
+		apiToken, _ := cmd.Flags().GetString("unleash-api-token")
+		project, _ := cmd.Flags().GetString("project")
+		environments, _ := cmd.Flags().GetStringSlice("unleash-environments")
+
+		if apiToken == "" {
+			apiToken = os.Getenv("UNLEASH_API_TOKEN")
+		}
+
+		return runImport(cmd, &importer.Unleash{File: file, URL: url, Token: apiToken, Project: project, Environments: environments})
+	},
+}
+
+func init() {
+	importCmd.AddCommand(importUnleashCmd)
+
+	importUnleashCmd.Flags().String("file", "", "Unleash feature export (JSON)")
+	importUnleashCmd.Flags().String("unleash-url", "", "Unleash URL, to import from the API")
+	importUnleashCmd.Flags().String("unleash-api-token", "", "Unleash admin API token (or UNLEASH_API_TOKEN)")
+	importUnleashCmd.Flags().String("project", "default", "Unleash project")
+	importUnleashCmd.Flags().StringSlice("unleash-environments", []string{"development", "production"}, "Unleash environments to export from the API")
+	addImportFlags(importUnleashCmd)
+}
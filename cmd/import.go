@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import flags from other feature flag tools",
+	Long: `Create the flags of another feature flag tool, with their variants and per-environment
+configuration, in the application. Constructs without an equivalent are listed in a mapping report.
+Flags that already exist are updated to match the source.`,
+}
+
+// importEnvironments maps source environment keys to CloudBees environment names. Sources are
+// mapped to the environment with the same name unless mapped explicitly with --environment-map.
+func importEnvironments(client cloudbees.API, mappings map[string]string) (map[string]string, error) {
+	environments, err := client.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+
+	result := map[string]string{}
+	for _, env := range environments {
+		if !env.IsDisabled {
+			result[env.Name] = env.Name
+		}
+	}
+	for source, target := range mappings {
+		if _, ok := result[target]; !ok {
+			return nil, fmt.Errorf("environment '%s' not found", target)
+		}
+		result[source] = target
+	}
+	return result, nil
+}
+
+// runImport imports the flags of a provider into the application and writes the mapping report
+func runImport(cmd *cobra.Command, provider importer.Provider) error {
+	mappings, _ := cmd.Flags().GetStringToString("environment-map")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	reportFile, _ := cmd.Flags().GetString("report-file")
+	applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	environments, err := importEnvironments(client, mappings)
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.Import(provider, applicationName, environments)
+	if err != nil {
+		return err
+	}
+
+	source := provider.Name()
+	report := result.ReportMarkdown(source)
+	if reportFile != "" {
+		if err := os.WriteFile(reportFile, []byte(report), 0644); err != nil {
+			return fmt.Errorf("failed to write import report: %w", err)
+		}
+	}
+
+	skipped := result.Skipped()
+	if len(skipped) > 0 {
+		fmt.Printf("Skipping %d flags that cannot be imported: %s\n", len(skipped), strings.Join(skipped, ", "))
+	}
+
+	changes, err := applyManifest(cmd, client, result.Manifest, false, dryRun)
+
+	// Output results, including the changes applied before a failure
+	issuesJSON, _ := json.Marshal(result.Issues)
+	changesJSON, _ := json.Marshal(changes)
+	cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(result.Manifest.Flags)))
+	cloudbees.WriteOutput("skipped-count", fmt.Sprintf("%d", len(skipped)))
+	cloudbees.WriteOutput("issue-count", fmt.Sprintf("%d", len(result.Issues)))
+	cloudbees.WriteOutput("issues", string(issuesJSON))
+	cloudbees.WriteOutput("changes", string(changesJSON))
+	cloudbees.WriteOutput("report", report)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would import %d flags from %s\n", len(result.Manifest.Flags), source)
+	} else {
+		fmt.Printf("Imported %d flags from %s (%d changes)\n", len(result.Manifest.Flags), source, len(changes))
+	}
+	if len(result.Issues) > 0 {
+		fmt.Printf("%d constructs could not be mapped", len(result.Issues))
+		if reportFile != "" {
+			fmt.Printf(", see %s", reportFile)
+		}
+		fmt.Println()
+	}
+	if verbose && reportFile == "" {
+		fmt.Print(report)
+	}
+	return nil
+}
+
+// addImportFlags registers the flags shared by the import commands
+func addImportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringToString("environment-map", nil, "Map source environments to CloudBees environments, e.g. test=development (defaults to the same name)")
+	cmd.Flags().String("report-file", "", "Write the Markdown mapping report to this file")
+	cmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	cmd.Flags().Bool("no-rollback", false, "Keep the changes applied before a failure instead of reverting them")
+	stateFlags(cmd)
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
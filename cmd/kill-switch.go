@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// killSwitchSnapshot holds the configurations of the flags a kill switch disabled, as they were
+// before, so they can be restored once the incident is over
+type killSwitchSnapshot struct {
+	Application   string                   `json:"application"`
+	Environment   string                   `json:"environment"`
+	EnvironmentID string                   `json:"environmentId"`
+	Labels        []string                 `json:"labels"`
+	CreatedAt     time.Time                `json:"createdAt"`
+	Flags         []killSwitchSnapshotFlag `json:"flags"`
+}
+
+// killSwitchSnapshotFlag is the configuration of one flag before it was disabled
+type killSwitchSnapshotFlag struct {
+	FlagID        string                      `json:"flagId"`
+	FlagName      string                      `json:"flagName"`
+	Labels        []string                    `json:"labels,omitempty"`
+	Configuration cloudbees.FlagConfiguration `json:"configuration"`
+	ETag          string                      `json:"-"`
+}
+
+var killSwitchCmd = &cobra.Command{
+	Use:   "kill-switch",
+	Short: "Disable every flag with a label in an environment",
+	Long: `Disable at once, in parallel, every flag of the application that has one of the labels in an
+environment, e.g. during an incident. The configurations of the flags are first saved to a snapshot
+file (--snapshot-file), and kill-switch --restore <snapshot> sets them back.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		snapshotFile, _ := cmd.Flags().GetString("snapshot-file")
+		restoreFile, _ := cmd.Flags().GetString("restore")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if restoreFile != "" {
+			return restoreKillSwitch(cmd, restoreFile, dryRun)
+		}
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+		if len(labels) == 0 {
+			return fmt.Errorf("at least one label is required")
+		}
+
+		client, ctx, err := commandContext(cmd, "", environmentName)
+		if err != nil {
+			return err
+		}
+		application, environment := ctx.Application, ctx.Environment
+
+		allFlags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+		var flags []cloudbees.Flag
+		for _, flag := range allFlags {
+			if hasAnyLabel(flag, labels) {
+				flags = append(flags, flag)
+			}
+		}
+
+		configs := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (killSwitchSnapshotFlag, error) {
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+				if err != nil {
+					return killSwitchSnapshotFlag{}, err
+				}
+				return killSwitchSnapshotFlag{FlagID: flag.ID, FlagName: flag.Name, Labels: flag.Labels, Configuration: config.Configuration, ETag: config.ETag}, nil
+			})
+		if err := configs.Err(); err != nil {
+			return fmt.Errorf("failed to get flag configurations: %w", err)
+		}
+
+		// Only the enabled flags are disabled, and saved to be restored
+		snapshot := killSwitchSnapshot{
+			Application:   application.Name,
+			Environment:   environment.Name,
+			EnvironmentID: environment.ID,
+			Labels:        labels,
+			CreatedAt:     time.Now().UTC(),
+			Flags:         []killSwitchSnapshotFlag{},
+		}
+		for _, result := range configs {
+			if result.Value.Configuration.Enabled {
+				snapshot.Flags = append(snapshot.Flags, result.Value)
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would disable %d of %d flags labeled %v in '%s'\n", len(snapshot.Flags), len(flags), labels, environment.Name)
+			for _, flag := range snapshot.Flags {
+				fmt.Printf("- %s\n", flag.FlagName)
+			}
+			return nil
+		}
+		if len(snapshot.Flags) == 0 {
+			fmt.Printf("No enabled flag labeled %v in '%s', nothing to disable\n", labels, environment.Name)
+			cloudbees.WriteOutput("disabled-count", "0")
+			cloudbees.WriteOutput("disabled-flags", "[]")
+			cloudbees.WriteOutput("success", "true")
+			return nil
+		}
+
+		if err := confirmAction(cmd, fmt.Sprintf("disable %d flags in '%s'", len(snapshot.Flags), environment.Name)); err != nil {
+			return err
+		}
+
+		// The snapshot is the way back, so nothing is changed when it cannot be saved
+		if snapshotFile == "" {
+			snapshotFile = fmt.Sprintf("kill-switch-%s-%s.json", environment.Name, snapshot.CreatedAt.Format("20060102T150405Z"))
+		}
+		snapshotJSON, _ := json.MarshalIndent(snapshot, "", "  ")
+		if err := os.WriteFile(snapshotFile, append(snapshotJSON, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to save snapshot: %w", err)
+		}
+		writeArtifact(cmd, "kill-switch-snapshot", snapshot)
+		fmt.Printf("Saved the configuration of %d flags to '%s'\n", len(snapshot.Flags), snapshotFile)
+
+		pending := make([]pendingConfiguration, len(snapshot.Flags))
+		for i, flag := range snapshot.Flags {
+			changes := map[string]interface{}{"enabled": false}
+			pending[i] = pendingConfiguration{
+				update: cloudbees.ConfigurationUpdate{FlagID: flag.FlagID, EnvironmentID: environment.ID, Configuration: changes, IfMatch: flag.ETag},
+				change: mutation{
+					Operation:   "kill-switch",
+					Application: application.Name,
+					Flag:        flag.FlagName,
+					Labels:      flag.Labels,
+					Environment: environment.Name,
+					Changes:     changes,
+					Before:      flag.Configuration,
+					After:       changes,
+				},
+			}
+		}
+		results := applyConfigurations(cmd, client, application.ID, pending, nil)
+
+		// Output results
+		disabled := []string{}
+		for _, result := range results {
+			if result.Err == nil && !result.Skipped {
+				disabled = append(disabled, result.Name)
+			}
+		}
+		disabledJSON, _ := json.Marshal(disabled)
+		cloudbees.WriteOutput("environment-name", environment.Name)
+		cloudbees.WriteOutput("snapshot-file", snapshotFile)
+		cloudbees.WriteOutput("disabled-count", fmt.Sprintf("%d", len(disabled)))
+		cloudbees.WriteOutput("disabled-flags", string(disabledJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+		for _, result := range results {
+			switch {
+			case result.Err != nil:
+				fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+			case result.Skipped:
+				fmt.Printf("- %s: skipped\n", result.Name)
+			default:
+				fmt.Printf("- %s: disabled\n", result.Name)
+			}
+		}
+		fmt.Printf("Disabled %d of %d flags in '%s', restore them with: kill-switch --restore %s\n", len(disabled), len(pending), environment.Name, snapshotFile)
+
+		return bulkErr(cmd, results)
+	},
+}
+
+// restoreKillSwitch sets the flags of a kill switch snapshot back to their saved configurations
+func restoreKillSwitch(cmd *cobra.Command, filename string, dryRun bool) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snapshot killSwitchSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse snapshot '%s': %w", filename, err)
+	}
+
+	client, ctx, err := commandContext(cmd, "", snapshot.Environment)
+	if err != nil {
+		return err
+	}
+	application, environment := ctx.Application, ctx.Environment
+	if snapshot.Application != application.Name {
+		return fmt.Errorf("snapshot '%s' is of application '%s', not '%s'", filename, snapshot.Application, application.Name)
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would restore %d flags in '%s' from '%s'\n", len(snapshot.Flags), environment.Name, filename)
+		for _, flag := range snapshot.Flags {
+			fmt.Printf("- %s: %s\n", flag.FlagName, revisionSummary(flag.Configuration))
+		}
+		return nil
+	}
+
+	pending := make([]pendingConfiguration, len(snapshot.Flags))
+	for i, flag := range snapshot.Flags {
+		// Conditions are always set, so conditions added since the snapshot are removed
+		changes := configurationChanges(flag.Configuration)
+		changes["conditions"] = flag.Configuration.Conditions
+		pending[i] = pendingConfiguration{
+			update: cloudbees.ConfigurationUpdate{FlagID: flag.FlagID, EnvironmentID: environment.ID, Configuration: changes},
+			change: mutation{
+				Operation:   "kill-switch-restore",
+				Application: application.Name,
+				Flag:        flag.FlagName,
+				Labels:      flag.Labels,
+				Environment: environment.Name,
+				Changes:     changes,
+				After:       changes,
+			},
+		}
+	}
+	results := applyConfigurations(cmd, client, application.ID, pending, nil)
+
+	// Output results
+	cloudbees.WriteOutput("environment-name", environment.Name)
+	cloudbees.WriteOutput("restored-count", fmt.Sprintf("%d", results.Succeeded()))
+	cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+		case result.Skipped:
+			fmt.Printf("- %s: skipped\n", result.Name)
+		default:
+			fmt.Printf("- %s: restored\n", result.Name)
+		}
+	}
+	fmt.Printf("Restored %d of %d flags in '%s'\n", results.Succeeded(), len(pending), environment.Name)
+
+	return bulkErr(cmd, results)
+}
+
+func init() {
+	rootCmd.AddCommand(killSwitchCmd)
+
+	killSwitchCmd.Flags().StringP("environment-name", "e", "", "Environment name (required unless using restore)")
+	killSwitchCmd.Flags().StringSlice("label", nil, "Disable the flags with this label (repeatable, required unless using restore)")
+	killSwitchCmd.Flags().String("snapshot-file", "", "File the configurations are saved to before disabling the flags (default kill-switch-<environment>-<time>.json)")
+	killSwitchCmd.Flags().String("restore", "", "Restore the flags saved in this snapshot file instead of disabling flags")
+	killSwitchCmd.Flags().Bool("dry-run", false, "Print the flags that would be disabled or restored without changing them")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var linkEnvironmentCmd = &cobra.Command{
+	Use:   "link-environment",
+	Short: "Link environments to an application",
+	Long: `Link environments to an application, so its flags can be configured in them. Environments
+that are already linked are left unchanged. Use --unlink to remove the link instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		unlink, _ := cmd.Flags().GetBool("unlink")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if len(environmentNames) == 0 {
+			return fmt.Errorf("environments are required")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		application, err := client.GetApplicationByName(applicationName)
+		if err != nil {
+			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+		}
+
+		allEnvironments, err := client.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		environments, err := selectEnvironments(allEnvironments, environmentNames)
+		if err != nil {
+			return err
+		}
+
+		// Only environments whose link changes are updated
+		var pending []cloudbees.Environment
+		for _, env := range environments {
+			if application.HasEnvironment(env.ID) == unlink {
+				pending = append(pending, env)
+			}
+		}
+
+		action := "link"
+		if unlink {
+			action = "unlink"
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would %s %d environments for application '%s'\n", action, len(pending), application.Name)
+			for _, env := range pending {
+				fmt.Printf("- %s\n", env.Name)
+			}
+			return nil
+		}
+
+		for i := range pending {
+			if unlink {
+				application, err = unlinkEnvironment(cmd, client, application, &pending[i])
+			} else {
+				application, err = linkEnvironment(cmd, client, application, &pending[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Output results
+		writeApplicationOutputs(application)
+		cloudbees.WriteOutput("changed-count", fmt.Sprintf("%d", len(pending)))
+		cloudbees.WriteOutput("success", "true")
+
+		for _, env := range pending {
+			fmt.Printf("- %s: %sed\n", env.Name, action)
+		}
+		fmt.Printf("Application '%s' has %d linked environments\n", application.Name, len(application.LinkedEnvironmentIDs))
+
+		return nil
+	},
+}
+
+// linkEnvironment links an environment to an application, running the guardrails and recording the change
+func linkEnvironment(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, environment *cloudbees.Environment) (*cloudbees.Application, error) {
+	change := mutation{
+		Operation:   "link-environment",
+		Application: application.Name,
+		Environment: environment.Name,
+		Changes:     map[string]interface{}{"linkedEnvironmentIds": append(append([]string{}, application.LinkedEnvironmentIDs...), environment.ID)},
+		Before:      application.LinkedEnvironmentIDs,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return nil, err
+	}
+
+	updated, err := client.LinkEnvironment(application, environment.ID)
+	if updated != nil {
+		change.After = updated.LinkedEnvironmentIDs
+	}
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link environment '%s' to application '%s': %w", environment.Name, application.Name, err)
+	}
+	return updated, nil
+}
+
+// unlinkEnvironment unlinks an environment from an application, running the guardrails and recording the change
+func unlinkEnvironment(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, environment *cloudbees.Environment) (*cloudbees.Application, error) {
+	change := mutation{
+		Operation:   "unlink-environment",
+		Application: application.Name,
+		Environment: environment.Name,
+		Before:      application.LinkedEnvironmentIDs,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return nil, err
+	}
+
+	updated, err := client.UnlinkEnvironment(application, environment.ID)
+	if updated != nil {
+		change.After = updated.LinkedEnvironmentIDs
+	}
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlink environment '%s' from application '%s': %w", environment.Name, application.Name, err)
+	}
+	return updated, nil
+}
+
+func init() {
+	rootCmd.AddCommand(linkEnvironmentCmd)
+
+	linkEnvironmentCmd.Flags().StringSliceP("environments", "e", nil, "Environments to link (required, repeatable)")
+	linkEnvironmentCmd.Flags().Bool("unlink", false, "Unlink the environments instead")
+	linkEnvironmentCmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+
+	linkEnvironmentCmd.MarkFlagRequired("environments")
+}
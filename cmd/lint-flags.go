@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+var lintFlagsCmd = &cobra.Command{
+	Use:   "lint-flags",
+	Short: "Check flag names against naming conventions",
+	Long: `Check the names of the existing flags against the naming conventions of the organization and fail
+with the list of violations, so flags stay searchable by name. With --all-applications, the flags of
+every application are checked concurrently. create-flag --enforce-naming applies the same rules to
+new flags. Rules are read from a YAML file and can be overridden with flags:
+
+  kebabCase: true
+  maxLength: 40
+  forbiddenWords: [tmp, test, new]
+  requireTeamPrefix: true
+  teams:
+    - team: payments
+      prefix: pay-
+      pattern: '^pay-(checkout|billing)-[a-z0-9-]+$'
+    - team: search
+      prefix: search-`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		allApplications, _ := cmd.Flags().GetBool("all-applications")
+
+		rules, err := namingRules(cmd)
+		if err != nil {
+			return err
+		}
+		if rules.Empty() {
+			return fmt.Errorf("no naming rules given, use --naming-file or the rule flags")
+		}
+		// Invalid patterns fail before any API call
+		if _, err := policy.LintNames(nil, rules); err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		applications, err := targetApplications(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+			func(application cloudbees.Application) (policyCheck, error) {
+				flags, err := client.ListFlags(application.ID)
+				if err != nil {
+					return policyCheck{}, fmt.Errorf("failed to list flags: %w", err)
+				}
+				violations, err := policy.LintNames(flags, rules)
+				return policyCheck{FlagCount: len(flags), Violations: violations}, err
+			})
+		if err := applicationsErr(results); err != nil {
+			return err
+		}
+
+		flagCount := 0
+		violations := []policy.Violation{}
+		for _, result := range results {
+			flagCount += result.Value.FlagCount
+			for _, violation := range result.Value.Violations {
+				if allApplications {
+					violation.Application = result.Name
+				}
+				violations = append(violations, violation)
+			}
+		}
+
+		// Output results
+		violationsJSON, _ := json.Marshal(violations)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", flagCount))
+		cloudbees.WriteOutput("violation-count", fmt.Sprintf("%d", len(violations)))
+		cloudbees.WriteOutput("violations", string(violationsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", len(violations) == 0))
+
+		if len(violations) == 0 {
+			fmt.Printf("All %d flag names follow the naming rules\n", flagCount)
+			return nil
+		}
+
+		fmt.Printf("Found %d naming violations:\n", len(violations))
+		printViolations(violations)
+		return fmt.Errorf("%w: %d naming violations found", policy.ErrViolation, len(violations))
+	},
+}
+
+// checkFlagName fails when the name of a new flag violates the naming rules
+func checkFlagName(rules policy.NamingRules, flagName string) error {
+	violations, err := policy.LintNames([]cloudbees.Flag{{Name: flagName}}, rules)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Errorf("%w: flag name '%s' violates the naming rules: %s", policy.ErrViolation, flagName, strings.Join(messages, "; "))
+}
+
+func init() {
+	rootCmd.AddCommand(lintFlagsCmd)
+
+	namingFlags(lintFlagsCmd)
+	addAllApplicationsFlag(lintFlagsCmd)
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/cloudbees-days/fm-actions-container/internal/actions"
 	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
 	"github.com/spf13/cobra"
 )
@@ -11,28 +13,55 @@ import (
 var listEnvironmentsCmd = &cobra.Command{
 	Use:   "list-environments",
 	Short: "List all environments in the organization",
-	Long:  `List all environments in the organization for feature flag targeting and configuration.`,
+	Long: `List all environments in the organization for feature flag targeting and configuration.
+--include-disabled=false, --name-contains and --linked-to-application select only some of them,
+e.g. the environments a pipeline deploys the application to.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
+		includeDisabled, _ := cmd.Flags().GetBool("include-disabled")
+		nameContains, _ := cmd.Flags().GetString("name-contains")
+		linkedTo, _ := cmd.Flags().GetString("linked-to-application")
 
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
+		client, err := newClient(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
+			return err
 		}
 
-		environments, err := client.ListEnvironments()
+		allEnvironments, err := client.ListEnvironments()
 		if err != nil {
 			return fmt.Errorf("failed to list environments: %w", err)
 		}
 
+		var linked map[string]bool
+		if linkedTo != "" {
+			application, err := actions.ResolveApplication(client, linkedTo, "")
+			if err != nil {
+				return err
+			}
+			linked = make(map[string]bool, len(application.LinkedEnvironmentIDs))
+			for _, id := range application.LinkedEnvironmentIDs {
+				linked[id] = true
+			}
+		}
+
+		environments := []cloudbees.Environment{}
+		for _, env := range allEnvironments {
+			if env.IsDisabled && !includeDisabled {
+				continue
+			}
+			if nameContains != "" && !strings.Contains(strings.ToLower(env.Name), strings.ToLower(nameContains)) {
+				continue
+			}
+			if linked != nil && !linked[env.ID] {
+				continue
+			}
+			environments = append(environments, env)
+		}
+
 		if len(environments) == 0 {
 			fmt.Println("No environments found")
 			cloudbees.WriteOutput("environment-count", "0")
 			cloudbees.WriteOutput("environments", "[]")
+			writeArtifact(cmd, "environments", environments)
 			return nil
 		}
 
@@ -40,6 +69,7 @@ var listEnvironmentsCmd = &cobra.Command{
 		environmentsJSON, _ := json.Marshal(environments)
 		cloudbees.WriteOutput("environment-count", fmt.Sprintf("%d", len(environments)))
 		cloudbees.WriteOutput("environments", string(environmentsJSON))
+		writeArtifact(cmd, "environments", environments)
 
 		if verbose {
 			fmt.Printf("Found %d environments:\n", len(environments))
@@ -58,4 +88,8 @@ var listEnvironmentsCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(listEnvironmentsCmd)
+
+	listEnvironmentsCmd.Flags().Bool("include-disabled", true, "Include disabled environments")
+	listEnvironmentsCmd.Flags().String("name-contains", "", "Only list environments whose name contains this text (case-insensitive)")
+	listEnvironmentsCmd.Flags().String("linked-to-application", "", "Only list the environments linked to this application")
 }
@@ -3,43 +3,68 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
 	"github.com/spf13/cobra"
 )
 
 var listFlagsCmd = &cobra.Command{
 	Use:   "list-flags",
 	Short: "List all feature flags in the organization",
-	Long:  `List all feature flags in the organization with their metadata and current status.`,
+	Long: `List all feature flags in the organization with their metadata and current status.
+With --all-applications the flags of every application are listed, grouped by application.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
-		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
-
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		expiredOnly, _ := cmd.Flags().GetBool("expired")
+		allApplications, _ := cmd.Flags().GetBool("all-applications")
+
+		client, err := newClient(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
+			return err
 		}
 
-		// First, get the application to retrieve its ID
-		application, err := client.GetApplicationByName(applicationName)
+		applications, err := targetApplications(cmd, client)
 		if err != nil {
-			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+			return err
 		}
 
-		flags, err := client.ListFlags(application.ID)
-		if err != nil {
-			return fmt.Errorf("failed to list flags: %w", err)
+		now := time.Now()
+		results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+			func(application cloudbees.Application) ([]cloudbees.Flag, error) {
+				allFlags, err := client.ListFlags(application.ID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list flags: %w", err)
+				}
+
+				flags := []cloudbees.Flag{}
+				for _, flag := range allFlags {
+					if !hasAnyLabel(flag, labels) {
+						continue
+					}
+					if expiredOnly && !flag.IsExpired(now) {
+						continue
+					}
+					flags = append(flags, flag)
+				}
+				return flags, nil
+			})
+		if err := applicationsErr(results); err != nil {
+			return err
+		}
+
+		if allApplications {
+			return writeFlagsByApplication(cmd, results)
 		}
 
+		flags := results[0].Value
 		if len(flags) == 0 {
 			fmt.Println("No flags found")
 			cloudbees.WriteOutput("flag-count", "0")
 			cloudbees.WriteOutput("flags", "[]")
+			writeArtifact(cmd, "flags", flags)
 			return nil
 		}
 
@@ -47,26 +72,71 @@ var listFlagsCmd = &cobra.Command{
 		flagsJSON, _ := json.Marshal(flags)
 		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(flags)))
 		cloudbees.WriteOutput("flags", string(flagsJSON))
+		writeArtifact(cmd, "flags", flags)
 
 		if verbose {
 			fmt.Printf("Found %d flags:\n", len(flags))
-			for _, flag := range flags {
-				permanent := "temporary"
-				if flag.IsPermanent {
-					permanent = "permanent"
-				}
-				fmt.Printf("- %s (ID: %s, Type: %s, %s)\n", flag.Name, flag.ID, flag.FlagType, permanent)
-				if flag.Description != "" {
-					fmt.Printf("  Description: %s\n", flag.Description)
-				}
-			}
+			printFlags(flags)
 		}
 
 		return nil
 	},
 }
 
+// writeFlagsByApplication writes the flags of every application, grouped by application name
+func writeFlagsByApplication(cmd *cobra.Command, results workerpool.Results[[]cloudbees.Flag]) error {
+	grouped := make(map[string][]cloudbees.Flag, len(results))
+	total := 0
+	for _, result := range results {
+		grouped[result.Name] = result.Value
+		total += len(result.Value)
+	}
+
+	// Output results
+	groupedJSON, _ := json.Marshal(grouped)
+	cloudbees.WriteOutput("application-count", fmt.Sprintf("%d", len(results)))
+	cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", total))
+	cloudbees.WriteOutput("flags-by-application", string(groupedJSON))
+	writeArtifact(cmd, "flags-by-application", grouped)
+
+	fmt.Printf("Found %d flags in %d applications\n", total, len(results))
+	for _, result := range results {
+		fmt.Printf("%s: %d flags\n", result.Name, len(result.Value))
+		if verbose {
+			printFlags(result.Value)
+		}
+	}
+
+	return nil
+}
+
+// printFlags prints the details of each flag
+func printFlags(flags []cloudbees.Flag) {
+	for _, flag := range flags {
+		permanent := "temporary"
+		if flag.IsPermanent {
+			permanent = "permanent"
+		}
+		fmt.Printf("- %s (ID: %s, Type: %s, %s)\n", flag.Name, flag.ID, flag.FlagType, permanent)
+		if flag.Description != "" {
+			fmt.Printf("  Description: %s\n", flag.Description)
+		}
+		if owner := flag.Owner(); owner != "" {
+			fmt.Printf("  Owner: %s\n", owner)
+		}
+		if expires, ok := flag.Expires(); ok {
+			fmt.Printf("  Expires: %s\n", expires.Format(cloudbees.ExpiryDateFormat))
+		}
+		if len(flag.Labels) > 0 {
+			fmt.Printf("  Labels: %s\n", strings.Join(flag.Labels, ", "))
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(listFlagsCmd)
-	listFlagsCmd.MarkPersistentFlagRequired("application-name")
+
+	listFlagsCmd.Flags().StringSlice("label", nil, "Only list flags with this label (repeatable)")
+	listFlagsCmd.Flags().Bool("expired", false, "Only list flags whose expires: date has passed")
+	addAllApplicationsFlag(listFlagsCmd)
 }
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/keyring"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// keyringService is the keyring service the tokens of fm-actions login are stored under, one
+// account per profile
+const keyringService = "fm-actions"
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store an API token in the OS keyring",
+	Long: `Store an API token in the keyring of the operating system (the macOS keychain, or the Secret
+Service through secret-tool on Linux), for the --profile or the default profile. Commands run without
+--token then use it, so tokens stay out of shell history and .env files. The token is read from
+standard input, without echo on a terminal. With --org-id, the token is checked before it is stored.
+Windows is not supported; use --token-file or --token-command there.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		orgID, _ := cmd.Flags().GetString("org-id")
+		if cmd.Flags().Changed("token") {
+			return fmt.Errorf("the token is read from standard input, not --token")
+		}
+
+		token, err := readToken()
+		if err != nil {
+			return err
+		}
+		if orgID != "" {
+			apiURL, err := resolveAPIURL(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newClientForOrg(cmd, apiURL, orgID, token)
+			if err != nil {
+				return err
+			}
+			if _, err := client.ListEnvironments(); err != nil {
+				return fmt.Errorf("failed to verify token: %w", err)
+			}
+		}
+
+		if err := keyring.Set(keyringService, keyringAccount(), token); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		fmt.Printf("Stored the API token of profile '%s' in the keyring\n", keyringAccount())
+		return nil
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the API token stored by login from the OS keyring",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := keyring.Delete(keyringService, keyringAccount())
+		if errors.Is(err, keyring.ErrNotFound) {
+			fmt.Printf("No API token of profile '%s' in the keyring\n", keyringAccount())
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove token: %w", err)
+		}
+		fmt.Printf("Removed the API token of profile '%s' from the keyring\n", keyringAccount())
+		return nil
+	},
+}
+
+// keyringAccount returns the keyring account of the --profile
+func keyringAccount() string {
+	if profile := viper.GetString("profile"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+// loadKeyringToken sets --token to the token stored by login for the profile, when the command
+// needs a token and none was given. Runs in CI never read the keyring.
+func loadKeyringToken(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	if flags.Changed("token") || cmd.Flags().Lookup("token") != flags.Lookup("token") || os.Getenv("CI") == "true" {
+		return nil
+	}
+	token, err := keyring.Get(keyringService, keyringAccount())
+	if errors.Is(err, keyring.ErrNotFound) || errors.Is(err, keyring.ErrUnsupported) {
+		// The command fails as usual without a token
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to read the API token from the keyring: %v\n", err)
+		return nil
+	}
+	if verbose {
+		fmt.Fprintf(os.Stderr, "Using the API token of profile '%s' from the keyring\n", keyringAccount())
+	}
+	return flags.Set("token", token)
+}
+
+// readToken reads a token from standard input, prompting without echo on a terminal
+func readToken() (string, error) {
+	if isTerminal(os.Stdin) {
+		fmt.Fprint(os.Stderr, "API token: ")
+		if setEcho(false) == nil {
+			defer func() {
+				setEcho(true)
+				fmt.Fprintln(os.Stderr)
+			}()
+		}
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	token := strings.TrimSpace(line)
+	if token == "" {
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return "", fmt.Errorf("no token given on standard input")
+	}
+	return token, nil
+}
+
+// setEcho turns the echo of the terminal on standard input on or off
+func setEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+	stty := exec.Command("stty", mode)
+	stty.Stdin = os.Stdin
+	return stty.Run()
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(logoutCmd)
+
+	// Shadow the required global flags: the token is read from standard input, and the
+	// organization is optional
+	loginCmd.Flags().String("token", "", "")
+	loginCmd.Flags().MarkHidden("token")
+	loginCmd.Flags().String("org-id", "", "Organization ID used to check the token before it is stored (optional)")
+	logoutCmd.Flags().String("token", "", "")
+	logoutCmd.Flags().MarkHidden("token")
+	logoutCmd.Flags().String("org-id", "", "")
+	logoutCmd.Flags().MarkHidden("org-id")
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/mcp"
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server exposing flag operations to AI assistants",
+	Long: `Serve flag operations as Model Context Protocol tools over stdio, so AI assistants can
+answer questions such as "is checkout-v2 enabled in production?" and propose changes.
+set_flag_config only previews changes unless called with dryRun set to false, and changes
+are subject to the same policy, approval and audit settings as the CLI.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		server := newMCPServer(cmd, client)
+
+		// The protocol owns stdout: route any other output to stderr for the rest of the process
+		protocolOut := os.Stdout
+		os.Stdout = os.Stderr
+
+		return server.Serve(os.Stdin, protocolOut)
+	},
+}
+
+// newMCPServer registers the flag tools
+func newMCPServer(cmd *cobra.Command, client cloudbees.API) *mcp.Server {
+	defaultApplication, _ := cmd.Root().PersistentFlags().GetString("application-name")
+	server := &mcp.Server{Name: "fm-actions", Version: "1.0.0"}
+
+	applicationName := func(args map[string]interface{}) string {
+		if name := stringArg(args, "application"); name != "" {
+			return name
+		}
+		return defaultApplication
+	}
+	application := func(args map[string]interface{}) (*cloudbees.Application, error) {
+		return getApplication(cmd, client, applicationName(args))
+	}
+
+	flagConfiguration := func(args map[string]interface{}) (*cloudbees.Flag, *cloudbees.Environment, *cloudbees.FlagConfigurationDetail, error) {
+		ctx, err := resolveContext(cmd, client, applicationName(args), stringArg(args, "flag"), stringArg(args, "environment"))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		config, err := client.GetFlagConfiguration(ctx.Application.ID, ctx.Flag.ID, ctx.Environment.ID)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to get flag configuration: %w", err)
+		}
+		return ctx.Flag, ctx.Environment, config, nil
+	}
+
+	applicationSchema := map[string]interface{}{"type": "string", "description": "Application name (defaults to the configured application)"}
+	readOnly := map[string]interface{}{"readOnlyHint": true}
+
+	server.AddTool(mcp.Tool{
+		Name:        "list_environments",
+		Description: "List the environments of the organization",
+		InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
+		Annotations: readOnly,
+		Handler: func(args map[string]interface{}) (string, error) {
+			environments, err := client.ListEnvironments()
+			if err != nil {
+				return "", err
+			}
+			return toolJSON(environments)
+		},
+	})
+
+	server.AddTool(mcp.Tool{
+		Name:        "list_flags",
+		Description: "List the feature flags of an application with their type, description and labels",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"application": applicationSchema,
+				"label":       map[string]interface{}{"type": "string", "description": "Only list flags with this label"},
+			},
+		},
+		Annotations: readOnly,
+		Handler: func(args map[string]interface{}) (string, error) {
+			app, err := application(args)
+			if err != nil {
+				return "", err
+			}
+			flags, err := client.ListFlags(app.ID)
+			if err != nil {
+				return "", err
+			}
+
+			var labels []string
+			if label := stringArg(args, "label"); label != "" {
+				labels = []string{label}
+			}
+			selected := []cloudbees.Flag{}
+			for _, flag := range flags {
+				if hasAnyLabel(flag, labels) {
+					selected = append(selected, flag)
+				}
+			}
+			return toolJSON(selected)
+		},
+	})
+
+	server.AddTool(mcp.Tool{
+		Name:        "get_flag_config",
+		Description: "Get the configuration of a flag in an environment: whether it is enabled, its default value, targeting conditions and revision",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"application": applicationSchema,
+				"flag":        map[string]interface{}{"type": "string", "description": "Flag name"},
+				"environment": map[string]interface{}{"type": "string", "description": "Environment name"},
+			},
+			"required": []string{"flag", "environment"},
+		},
+		Annotations: readOnly,
+		Handler: func(args map[string]interface{}) (string, error) {
+			flag, environment, config, err := flagConfiguration(args)
+			if err != nil {
+				return "", err
+			}
+			return toolJSON(map[string]interface{}{
+				"flag":          flag.Name,
+				"environment":   environment.Name,
+				"configuration": config.Configuration,
+				"revision":      config.Revision,
+			})
+		},
+	})
+
+	server.AddTool(mcp.Tool{
+		Name: "set_flag_config",
+		Description: "Change the configuration of a flag in an environment. By default this is a dry run that returns " +
+			"the configuration before and after the change; set dryRun to false only after the user confirmed the change.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"application":        applicationSchema,
+				"flag":               map[string]interface{}{"type": "string", "description": "Flag name"},
+				"environment":        map[string]interface{}{"type": "string", "description": "Environment name"},
+				"enabled":            map[string]interface{}{"type": "boolean"},
+				"defaultValue":       map[string]interface{}{"description": "Default value, or a percentage split such as [{\"option\": \"true\", \"percentage\": 20}]"},
+				"variantsEnabled":    map[string]interface{}{"type": "boolean"},
+				"stickinessProperty": map[string]interface{}{"type": "string"},
+				"ifMatch":            map[string]interface{}{"type": "string", "description": "Revision from get_flag_config; the change fails if the flag changed since"},
+				"dryRun":             map[string]interface{}{"type": "boolean", "default": true},
+			},
+			"required": []string{"flag", "environment"},
+		},
+		Annotations: map[string]interface{}{"destructiveHint": true, "idempotentHint": true},
+		Handler: func(args map[string]interface{}) (string, error) {
+			changes := map[string]interface{}{}
+			for _, field := range []string{"enabled", "defaultValue", "variantsEnabled", "stickinessProperty"} {
+				if value, ok := args[field]; ok {
+					changes[field] = value
+				}
+			}
+			if len(changes) == 0 {
+				return "", fmt.Errorf("no configuration changes specified")
+			}
+
+			if dryRun, ok := args["dryRun"].(bool); !ok || dryRun {
+				_, _, current, err := flagConfiguration(args)
+				if err != nil {
+					return "", err
+				}
+				return toolJSON(map[string]interface{}{
+					"dryRun":   true,
+					"before":   current.Configuration,
+					"after":    mergeConfiguration(current.Configuration, changes),
+					"revision": current.Revision,
+				})
+			}
+
+			app, err := application(args)
+			if err != nil {
+				return "", err
+			}
+			update, err := updateFlagConfiguration(cmd, client, app.Name, stringArg(args, "flag"), stringArg(args, "environment"),
+				changes, stringArg(args, "ifMatch"), false)
+			if err != nil {
+				return "", err
+			}
+			return toolJSON(map[string]interface{}{
+				"applied": true,
+				"before":  update.Before,
+				"after":   update.After,
+			})
+		},
+	})
+
+	return server
+}
+
+// stringArg returns a string tool argument, or an empty string
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+// toolJSON formats a tool result
+func toolJSON(value interface{}) (string, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	return string(data), err
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// runMetrics counts the changes made during this invocation for --metrics-file
+var runMetrics = struct {
+	mu         sync.Mutex
+	start      time.Time
+	operations int
+	failures   int
+	flags      map[string]bool
+}{start: time.Now(), flags: map[string]bool{}}
+
+// recordOperation counts a change and the flag it touched
+func recordOperation(m mutation, opErr error) {
+	runMetrics.mu.Lock()
+	defer runMetrics.mu.Unlock()
+
+	runMetrics.operations++
+	if opErr != nil {
+		runMetrics.failures++
+	}
+	if m.Flag != "" {
+		runMetrics.flags[m.Application+"/"+m.Flag] = true
+	}
+}
+
+// writeMetricsFile writes the run metrics in the Prometheus text format, for the node-exporter textfile collector.
+// The file is replaced atomically so the collector never reads a partial file.
+func writeMetricsFile(cmd *cobra.Command, commandErr error) error {
+	if cmd == nil {
+		return nil
+	}
+	filename, _ := rootCmd.PersistentFlags().GetString("metrics-file")
+	if filename == "" {
+		return nil
+	}
+
+	runMetrics.mu.Lock()
+	defer runMetrics.mu.Unlock()
+
+	success := 1
+	if commandErr != nil {
+		success = 0
+	}
+	labels := fmt.Sprintf(`{command=%q}`, commandName(cmd))
+
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, help, kind string
+		value            interface{}
+	}{
+		{"fm_actions_operations_total", "Flag changes attempted by the run", "counter", runMetrics.operations},
+		{"fm_actions_failures_total", "Flag changes that failed", "counter", runMetrics.failures},
+		{"fm_actions_flags_processed", "Distinct flags changed by the run", "gauge", len(runMetrics.flags)},
+		{"fm_actions_duration_seconds", "Duration of the run", "gauge", fmt.Sprintf("%.3f", time.Since(runMetrics.start).Seconds())},
+		{"fm_actions_success", "Whether the run succeeded (1) or failed (0)", "gauge", success},
+		{"fm_actions_last_run_timestamp_seconds", "Time the run finished", "gauge", time.Now().Unix()},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s%s %v\n", metric.name, metric.help, metric.name, metric.kind, metric.name, labels, metric.value)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".fm-actions-metrics-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	tmp.Close()
+	os.Chmod(tmp.Name(), 0644)
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// Outcomes of migrating a single flag
+const (
+	migrationCreated  = "created"  // Created in the target
+	migrationExists   = "exists"   // Already in the target with the same type and variants
+	migrationConflict = "conflict" // In the target with a different type or variants, left unchanged
+)
+
+// flagMigration reports the outcome of migrating a single flag
+type flagMigration struct {
+	FlagName     string   `json:"flagName"`
+	Status       string   `json:"status"`
+	Reason       string   `json:"reason,omitempty"`
+	Environments []string `json:"environments,omitempty"` // Environments whose configuration was copied
+}
+
+var migrateFlagsCmd = &cobra.Command{
+	Use:   "migrate-flags",
+	Short: "Copy flags between organizations",
+	Long: `Copy the flags of an application (with their type, variants, description and labels, and
+optionally their configuration per environment) from one organization to another, e.g. after a
+reorganization or tenant split. Each organization uses its own token. Flags that already exist in
+the target with a different type or variants are not changed and listed in the conflict report.
+Configurations are copied to the environments with the same name in the target organization.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceOrg, _ := cmd.Flags().GetString("source-org")
+		targetOrg, _ := cmd.Flags().GetString("target-org")
+		sourceToken, _ := cmd.Flags().GetString("source-token")
+		targetToken, _ := cmd.Flags().GetString("target-token")
+		targetAPIURL, _ := cmd.Flags().GetString("target-api-url")
+		sourceAppName, _ := cmd.Flags().GetString("source-application")
+		targetAppName, _ := cmd.Flags().GetString("target-application")
+		copyConfig, _ := cmd.Flags().GetBool("copy-config")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+		globalToken, _ := cmd.Root().PersistentFlags().GetString("token")
+
+		if sourceOrg == "" || targetOrg == "" {
+			return fmt.Errorf("source-org and target-org are required")
+		}
+		apiURL, err := resolveAPIURL(cmd)
+		if err != nil {
+			return err
+		}
+		if sourceToken == "" {
+			sourceToken = firstNonEmpty(os.Getenv("FM_SOURCE_TOKEN"), globalToken)
+		}
+		if targetToken == "" {
+			targetToken = firstNonEmpty(os.Getenv("FM_TARGET_TOKEN"), globalToken)
+		}
+		if targetAPIURL == "" {
+			targetAPIURL = apiURL
+		}
+		if sourceAppName == "" {
+			sourceAppName = applicationName
+		}
+		if targetAppName == "" {
+			targetAppName = sourceAppName
+		}
+		if sourceAppName == "" {
+			return fmt.Errorf("source-application or application-name is required")
+		}
+		if sourceOrg == targetOrg && sourceAppName == targetAppName && targetAPIURL == apiURL {
+			return fmt.Errorf("source and target must be different")
+		}
+
+		source, err := newClientForOrg(cmd, apiURL, sourceOrg, sourceToken)
+		if err != nil {
+			return fmt.Errorf("source organization: %w", err)
+		}
+		target, err := newClientForOrg(cmd, targetAPIURL, targetOrg, targetToken)
+		if err != nil {
+			return fmt.Errorf("target organization: %w", err)
+		}
+		activeClients = append(activeClients, source, target)
+
+		sourceApp, err := source.GetApplicationByName(sourceAppName)
+		if err != nil {
+			return fmt.Errorf("failed to get application '%s' in organization '%s': %w", sourceAppName, sourceOrg, err)
+		}
+		targetApp, err := target.GetApplicationByName(targetAppName)
+		if err != nil {
+			return fmt.Errorf("failed to get application '%s' in organization '%s': %w", targetAppName, targetOrg, err)
+		}
+
+		// Pair the source environments with the target environments of the same name
+		var environmentPairs [][2]cloudbees.Environment
+		var missingEnvironments []string
+		if copyConfig {
+			sourceEnvironments, err := source.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments of organization '%s': %w", sourceOrg, err)
+			}
+			sourceEnvironments, err = selectEnvironments(sourceEnvironments, environmentNames)
+			if err != nil {
+				return err
+			}
+			targetEnvironments, err := target.ListEnvironments()
+			if err != nil {
+				return fmt.Errorf("failed to list environments of organization '%s': %w", targetOrg, err)
+			}
+			for _, env := range sourceEnvironments {
+				match, err := selectEnvironments(targetEnvironments, []string{env.Name})
+				if err != nil {
+					missingEnvironments = append(missingEnvironments, env.Name)
+					continue
+				}
+				environmentPairs = append(environmentPairs, [2]cloudbees.Environment{env, match[0]})
+			}
+		}
+
+		sourceFlags, err := source.ListFlags(sourceApp.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags of organization '%s': %w", sourceOrg, err)
+		}
+		targetFlags, err := target.ListFlags(targetApp.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags of organization '%s': %w", targetOrg, err)
+		}
+		existing := make(map[string]cloudbees.Flag, len(targetFlags))
+		for _, flag := range targetFlags {
+			existing[flag.Name] = flag
+		}
+
+		var flags []cloudbees.Flag
+		for _, flag := range sourceFlags {
+			if strings.HasPrefix(flag.Name, prefix) && hasAnyLabel(flag, labels) {
+				flags = append(flags, flag)
+			}
+		}
+		sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would migrate %d flags from '%s/%s' to '%s/%s'\n", len(flags), sourceOrg, sourceApp.Name, targetOrg, targetApp.Name)
+			for _, flag := range flags {
+				migration := planMigration(flag, existing)
+				line := fmt.Sprintf("- %s: %s", flag.Name, migrationAction(migration.Status))
+				if migration.Reason != "" {
+					line += " (" + migration.Reason + ")"
+				}
+				fmt.Println(line)
+			}
+			for _, env := range missingEnvironments {
+				fmt.Printf("Environment '%s' does not exist in '%s', its configuration would not be copied\n", env, targetOrg)
+			}
+			return nil
+		}
+
+		results := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+			func(flag cloudbees.Flag) (flagMigration, error) {
+				migration := planMigration(flag, existing)
+				if migration.Status == migrationConflict {
+					return migration, nil
+				}
+
+				targetFlag, ok := existing[flag.Name]
+				if !ok {
+					change := mutation{
+						Operation:   "migrate-flags",
+						Application: targetApp.Name,
+						Flag:        flag.Name,
+						Labels:      flag.Labels,
+						Changes:     map[string]interface{}{"sourceOrg": sourceOrg, "sourceApplication": sourceApp.Name},
+					}
+					if err := beforeMutation(cmd, change); err != nil {
+						return migration, err
+					}
+					created, err := target.CreateFlagFromRequest(targetApp.ID, cloudbees.CreateFlagRequest{
+						Name:        flag.Name,
+						FlagType:    flag.FlagType,
+						Variants:    flag.Variants,
+						Description: flag.Description,
+						IsPermanent: flag.IsPermanent,
+						Labels:      flag.Labels,
+					})
+					change.After = created
+					afterMutation(cmd, change, err)
+					if err != nil {
+						return migration, fmt.Errorf("failed to create flag: %w", err)
+					}
+					targetFlag = *created
+				}
+
+				for _, pair := range environmentPairs {
+					config, err := source.GetFlagConfiguration(sourceApp.ID, flag.ID, pair[0].ID)
+					if err != nil {
+						return migration, err
+					}
+					changes := configurationChanges(config.Configuration)
+					change := mutation{
+						Operation:   "migrate-flags",
+						Application: targetApp.Name,
+						Flag:        flag.Name,
+						Labels:      flag.Labels,
+						Environment: pair[1].Name,
+						Changes:     changes,
+						After:       changes,
+					}
+					if err := beforeMutation(cmd, change); err != nil {
+						return migration, err
+					}
+					err = target.SetFlagConfiguration(targetApp.ID, targetFlag.ID, pair[1].ID, changes)
+					afterMutation(cmd, change, err)
+					if err != nil {
+						return migration, fmt.Errorf("failed to copy configuration of '%s': %w", pair[1].Name, err)
+					}
+					migration.Environments = append(migration.Environments, pair[1].Name)
+				}
+				return migration, nil
+			})
+
+		counts := map[string]int{}
+		var conflicts []flagMigration
+		for _, result := range results {
+			if result.Err == nil && !result.Skipped {
+				counts[result.Value.Status]++
+				if result.Value.Status == migrationConflict {
+					conflicts = append(conflicts, result.Value)
+				}
+			}
+		}
+		if conflicts == nil {
+			conflicts = []flagMigration{}
+		}
+
+		// Output results
+		resultsJSON, _ := json.Marshal(results)
+		conflictsJSON, _ := json.Marshal(conflicts)
+		missingJSON, _ := json.Marshal(missingEnvironments)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(flags)))
+		cloudbees.WriteOutput("created-count", fmt.Sprintf("%d", counts[migrationCreated]))
+		cloudbees.WriteOutput("existing-count", fmt.Sprintf("%d", counts[migrationExists]))
+		cloudbees.WriteOutput("conflict-count", fmt.Sprintf("%d", len(conflicts)))
+		cloudbees.WriteOutput("failed-count", fmt.Sprintf("%d", results.Failed()))
+		cloudbees.WriteOutput("conflicts", string(conflictsJSON))
+		cloudbees.WriteOutput("missing-environments", string(missingJSON))
+		cloudbees.WriteOutput("results", string(resultsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+		for _, result := range results {
+			switch {
+			case result.Err != nil:
+				fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+			case result.Skipped:
+				fmt.Printf("- %s: skipped\n", result.Name)
+			case verbose || result.Value.Status != migrationExists:
+				fmt.Printf("- %s: %s\n", result.Name, result.Value.Status)
+			}
+		}
+		fmt.Printf("Migrated %d flags from '%s/%s' to '%s/%s': %d created, %d already existed, %d conflicts\n",
+			counts[migrationCreated]+counts[migrationExists], sourceOrg, sourceApp.Name, targetOrg, targetApp.Name,
+			counts[migrationCreated], counts[migrationExists], len(conflicts))
+
+		if len(conflicts) > 0 {
+			fmt.Printf("Conflicts (left unchanged in '%s'):\n", targetOrg)
+			for _, conflict := range conflicts {
+				fmt.Printf("- %s: %s\n", conflict.FlagName, conflict.Reason)
+			}
+		}
+		for _, env := range missingEnvironments {
+			fmt.Fprintf(os.Stderr, "Warning: environment '%s' does not exist in '%s', its configuration was not copied\n", env, targetOrg)
+		}
+
+		return bulkErr(cmd, results)
+	},
+}
+
+// planMigration decides how a flag is migrated, given the flags that already exist in the target by name
+func planMigration(flag cloudbees.Flag, existing map[string]cloudbees.Flag) flagMigration {
+	migration := flagMigration{FlagName: flag.Name, Status: migrationCreated}
+	current, ok := existing[flag.Name]
+	if !ok {
+		return migration
+	}
+
+	switch {
+	case current.FlagType != flag.FlagType:
+		migration.Status = migrationConflict
+		migration.Reason = fmt.Sprintf("type is %s in the target, %s in the source", current.FlagType, flag.FlagType)
+	case !sameVariants(current.Variants, flag.Variants):
+		migration.Status = migrationConflict
+		migration.Reason = fmt.Sprintf("variants are [%s] in the target, [%s] in the source",
+			strings.Join(current.Variants, ", "), strings.Join(flag.Variants, ", "))
+	default:
+		migration.Status = migrationExists
+	}
+	return migration
+}
+
+// migrationAction describes what a dry run would do with a flag
+func migrationAction(status string) string {
+	switch status {
+	case migrationCreated:
+		return "create"
+	case migrationExists:
+		return "exists"
+	default:
+		return "conflict, skip"
+	}
+}
+
+// sameVariants reports whether two flags have the same variants, in any order
+func sameVariants(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	return strings.Join(sortedA, "\x00") == strings.Join(sortedB, "\x00")
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(migrateFlagsCmd)
+
+	migrateFlagsCmd.Flags().String("source-org", "", "Organization ID to copy flags from (required)")
+	migrateFlagsCmd.Flags().String("target-org", "", "Organization ID to copy flags to (required)")
+	migrateFlagsCmd.Flags().String("source-token", "", "API token of the source organization (or FM_SOURCE_TOKEN, defaults to --token)")
+	migrateFlagsCmd.Flags().String("target-token", "", "API token of the target organization (or FM_TARGET_TOKEN, defaults to --token)")
+	migrateFlagsCmd.Flags().String("target-api-url", "", "API URL of the target organization (defaults to --api-url)")
+	migrateFlagsCmd.Flags().String("source-application", "", "Application to copy flags from (defaults to --application-name)")
+	migrateFlagsCmd.Flags().String("target-application", "", "Application to copy flags to (defaults to the source application name)")
+	migrateFlagsCmd.Flags().Bool("copy-config", false, "Copy flag configurations to the environments with the same name")
+	migrateFlagsCmd.Flags().StringSlice("environments", nil, "Environments to copy configuration for (defaults to all enabled environments)")
+	migrateFlagsCmd.Flags().StringSlice("label", nil, "Only migrate flags with this label (repeatable)")
+	migrateFlagsCmd.Flags().String("prefix", "", "Only migrate flags whose name starts with this prefix")
+	migrateFlagsCmd.Flags().Bool("dry-run", false, "Print the migration plan without applying it")
+
+	migrateFlagsCmd.MarkFlagRequired("source-org")
+	migrateFlagsCmd.MarkFlagRequired("target-org")
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/approval"
+	"github.com/cloudbees-days/fm-actions-container/internal/audit"
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// approvalPollInterval is how often a pending approval request is polled
+const approvalPollInterval = 5 * time.Second
+
+// auditSigningKeyEnv holds the HMAC key used to sign audit records
+const auditSigningKeyEnv = "FM_AUDIT_SIGNING_KEY"
+
+var (
+	auditOnce sync.Once
+	auditMu   sync.Mutex
+	auditLog  *audit.Logger
+	auditErr  error // First error writing the audit trail, returned by Execute
+
+	protectedMu      sync.Mutex
+	protectedTouched = map[string]bool{} // Protected environments changed by the command
+)
+
+// mutation describes a change the CLI is about to make, passed to guardrails before it is applied
+type mutation struct {
+	Operation   string                 `json:"operation"` // Command performing the change, e.g. set-flag-config
+	Application string                 `json:"application,omitempty"`
+	Flag        string                 `json:"flag,omitempty"`
+	Labels      []string               `json:"labels"` // Labels of the flag before the change
+	Environment string                 `json:"environment,omitempty"`
+	Changes     map[string]interface{} `json:"changes,omitempty"`
+	Before      interface{}            `json:"before,omitempty"` // State before the change, when known
+	After       interface{}            `json:"after,omitempty"`  // State after the change, when known
+	CI          bool                   `json:"ci"`               // Running in a CI pipeline (CI environment variable set)
+}
+
+// beforeMutation runs the configured guardrails for a change and returns an error if it must not be applied
+func beforeMutation(cmd *cobra.Command, m mutation) error {
+	if m.Labels == nil {
+		m.Labels = []string{}
+	}
+	m.CI = os.Getenv("CI") != ""
+
+	if readOnly() {
+		return fmt.Errorf("%w: %s refused (--read-only or FM_READ_ONLY)", cloudbees.ErrReadOnly, m.Operation)
+	}
+
+	policyDir, _ := cmd.Root().PersistentFlags().GetString("policy-dir")
+	if policyDir != "" {
+		opaPath, _ := cmd.Root().PersistentFlags().GetString("opa-path")
+		denials, err := policy.EvaluateRego(opaPath, policyDir, m)
+		if err != nil {
+			return err
+		}
+		if len(denials) > 0 {
+			return fmt.Errorf("%w: %s denied by policy: %s", policy.ErrViolation, m.Operation, strings.Join(denials, "; "))
+		}
+	}
+
+	if err := checkProtectedEnvironment(cmd, m); err != nil {
+		return err
+	}
+
+	requireApproval, _ := cmd.Root().PersistentFlags().GetBool("require-approval")
+	if requireApproval {
+		if err := awaitApproval(cmd, m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// protectedEnvironments returns the patterns of the protected environments, from
+// --protected-environments, the config file or FM_PROTECTED_ENVIRONMENTS (comma-separated)
+func protectedEnvironments() []string {
+	var patterns []string
+	for _, value := range viper.GetStringSlice("protected-environments") {
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				patterns = append(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+// checkProtectedEnvironment refuses a change to a protected environment unless --allow-protected
+// is set or the change goes through the approval gate
+func checkProtectedEnvironment(cmd *cobra.Command, m mutation) error {
+	if m.Environment == "" || !approval.MatchesEnvironment(m.Environment, protectedEnvironments()) {
+		return nil
+	}
+	allowProtected, _ := cmd.Root().PersistentFlags().GetBool("allow-protected")
+	if allowProtected {
+		return nil
+	}
+	requireApproval, _ := cmd.Root().PersistentFlags().GetBool("require-approval")
+	approvalPatterns, _ := cmd.Root().PersistentFlags().GetStringSlice("approval-environments")
+	if requireApproval && approval.MatchesEnvironment(m.Environment, approvalPatterns) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s of flag '%s' changes the protected environment '%s', use --allow-protected or --require-approval",
+		policy.ErrViolation, m.Operation, m.Flag, m.Environment)
+}
+
+// recordProtectedChange annotates the outputs when a change was applied to a protected environment
+func recordProtectedChange(m mutation) {
+	if m.Environment == "" || !approval.MatchesEnvironment(m.Environment, protectedEnvironments()) {
+		return
+	}
+	protectedMu.Lock()
+	defer protectedMu.Unlock()
+	if protectedTouched[m.Environment] {
+		return
+	}
+	protectedTouched[m.Environment] = true
+	fmt.Fprintf(os.Stderr, "Warning: changed the protected environment '%s'\n", m.Environment)
+
+	touched := make([]string, 0, len(protectedTouched))
+	for environment := range protectedTouched {
+		touched = append(touched, environment)
+	}
+	sort.Strings(touched)
+	touchedJSON, _ := json.Marshal(touched)
+	cloudbees.WriteOutput("protected-environment-changed", "true")
+	cloudbees.WriteOutput("protected-environments", string(touchedJSON))
+}
+
+// awaitApproval blocks until a change to a protected environment is approved
+func awaitApproval(cmd *cobra.Command, m mutation) error {
+	patterns, _ := cmd.Root().PersistentFlags().GetStringSlice("approval-environments")
+	if m.Environment == "" || !approval.MatchesEnvironment(m.Environment, patterns) {
+		return nil
+	}
+
+	webhookURL, _ := cmd.Root().PersistentFlags().GetString("approval-webhook-url")
+	if webhookURL == "" {
+		return fmt.Errorf("approval-webhook-url is required with --require-approval")
+	}
+	timeout, _ := cmd.Root().PersistentFlags().GetDuration("approval-timeout")
+
+	gate := &approval.Gate{
+		WebhookURL:   webhookURL,
+		Token:        os.Getenv("APPROVAL_WEBHOOK_TOKEN"),
+		Timeout:      timeout,
+		PollInterval: approvalPollInterval,
+	}
+
+	summary := fmt.Sprintf("%s: flag '%s' in environment '%s'", m.Operation, m.Flag, m.Environment)
+	fmt.Printf("Waiting for approval of %s (timeout %s)\n", summary, timeout)
+
+	decision, err := gate.Await(approval.Request{Summary: summary, Change: m})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Change approved by %s\n", decision.Approver)
+	return nil
+}
+
+// afterMutation records the outcome of a change in the audit trail and sends notifications about it.
+// Audit failures do not interrupt the command but make it exit with an error.
+func afterMutation(cmd *cobra.Command, m mutation, opErr error) {
+	recordOperation(m, opErr)
+	notifyChange(m, opErr)
+	if opErr == nil {
+		recordProtectedChange(m)
+	}
+
+	destination, _ := cmd.Root().PersistentFlags().GetString("audit-log")
+	if destination == "" {
+		return
+	}
+
+	auditOnce.Do(func() {
+		auditLog, auditErr = audit.Open(destination, os.Getenv(auditSigningKeyEnv))
+	})
+	if auditLog == nil {
+		return
+	}
+
+	record := audit.Record{
+		Principal:   auditPrincipal(),
+		Command:     cmd.CommandPath(),
+		Inputs:      auditInputs(cmd),
+		Operation:   m.Operation,
+		Application: m.Application,
+		Flag:        m.Flag,
+		Environment: m.Environment,
+		Before:      m.Before,
+		After:       m.After,
+		Changes:     m.Changes,
+		Status:      "success",
+	}
+	if opErr != nil {
+		record.Status = "failed"
+		record.Error = opErr.Error()
+		record.After = nil
+	}
+
+	if err := auditLog.Write(record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		auditMu.Lock()
+		defer auditMu.Unlock()
+		if auditErr == nil {
+			auditErr = err
+		}
+	}
+}
+
+// closeAuditLog flushes the audit trail and returns the first error writing it
+func closeAuditLog() error {
+	if auditLog != nil {
+		if err := auditLog.Close(); err != nil && auditErr == nil {
+			auditErr = fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return auditErr
+}
+
+// auditPrincipal identifies who ran the CLI, preferring CI actor variables over the local user
+func auditPrincipal() string {
+	for _, name := range []string{"CLOUDBEES_ACTOR", "GITHUB_ACTOR", "GITLAB_USER_LOGIN", "USER"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// sensitiveFlags are the flags whose values are credentials, or URLs that are credentials
+// themselves, and are never written to the audit log
+var sensitiveFlags = map[string]bool{
+	"token":                 true,
+	"token-command":         true,
+	"source-token":          true,
+	"target-token":          true,
+	"github-token":          true,
+	"ld-api-token":          true,
+	"unleash-api-token":     true,
+	"datadog-api-key":       true,
+	"pagerduty-routing-key": true,
+	"environment-key":       true,
+	"client-key":            true,
+	"slack-webhook":         true,
+	"teams-webhook":         true,
+}
+
+// auditInputs returns the flags set on the command line, with credentials redacted
+func auditInputs(cmd *cobra.Command) map[string]string {
+	inputs := map[string]string{}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		value := f.Value.String()
+		if sensitiveFlags[f.Name] {
+			value = "[REDACTED]"
+		} else {
+			value = redactURL(value)
+		}
+		inputs[f.Name] = value
+	})
+	return inputs
+}
+
+// redactURL strips the user info and query string, which may carry credentials, from a URL
+// value such as --proxy or --notify-url; other values are returned unchanged
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value
+	}
+	if u.User == nil && u.RawQuery == "" && u.Fragment == "" {
+		return value
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/spf13/cobra"
+)
+
+// namingFlags adds the flags setting the flag naming rules to a command
+func namingFlags(cmd *cobra.Command) {
+	cmd.Flags().String("naming-file", "", "YAML file with flag naming rules")
+	cmd.Flags().Bool("kebab-case", false, "Require flag names in kebab-case")
+	cmd.Flags().Int("max-length", 0, "Longest flag name allowed (0 disables)")
+	cmd.Flags().StringSlice("forbidden-words", nil, "Words flag names cannot contain, e.g. tmp,test")
+	cmd.Flags().StringArray("team-pattern", nil, "Regular expression the flags with a team prefix must match, as prefix=regex (repeatable)")
+	cmd.Flags().Bool("require-team-prefix", false, "Require flag names to start with the prefix of a --team-pattern")
+}
+
+// namingRules returns the naming rules of --naming-file, overridden by the individual flags
+func namingRules(cmd *cobra.Command) (policy.NamingRules, error) {
+	namingFile, _ := cmd.Flags().GetString("naming-file")
+
+	var rules policy.NamingRules
+	if namingFile != "" {
+		var err error
+		rules, err = policy.LoadNamingRules(namingFile)
+		if err != nil {
+			return rules, err
+		}
+	}
+
+	if cmd.Flags().Changed("kebab-case") {
+		rules.KebabCase, _ = cmd.Flags().GetBool("kebab-case")
+	}
+	if cmd.Flags().Changed("max-length") {
+		rules.MaxLength, _ = cmd.Flags().GetInt("max-length")
+	}
+	if cmd.Flags().Changed("forbidden-words") {
+		rules.ForbiddenWords, _ = cmd.Flags().GetStringSlice("forbidden-words")
+	}
+	if cmd.Flags().Changed("team-pattern") {
+		teamPatterns, _ := cmd.Flags().GetStringArray("team-pattern")
+		rules.Teams = nil
+		for _, teamPattern := range teamPatterns {
+			prefix, pattern, ok := strings.Cut(teamPattern, "=")
+			if !ok || prefix == "" {
+				return rules, fmt.Errorf("invalid team-pattern '%s', expected prefix=regex", teamPattern)
+			}
+			rules.Teams = append(rules.Teams, policy.TeamNaming{Prefix: prefix, Pattern: pattern})
+		}
+	}
+	if cmd.Flags().Changed("require-team-prefix") {
+		rules.RequireTeamPrefix, _ = cmd.Flags().GetBool("require-team-prefix")
+	}
+	return rules, nil
+}
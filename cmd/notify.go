@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/notify"
+	"github.com/spf13/viper"
+)
+
+var (
+	notifiersOnce sync.Once
+	notifiers     []notify.Notifier
+)
+
+// configuredNotifiers returns the notifiers enabled by flags or the config file
+func configuredNotifiers() []notify.Notifier {
+	notifiersOnce.Do(func() {
+		if url := viper.GetString("notify-url"); url != "" {
+			notifiers = append(notifiers, &notify.Webhook{URL: url, Secret: viper.GetString("notify-secret")})
+		}
+		if url := viper.GetString("slack-webhook"); url != "" {
+			notifiers = append(notifiers, &notify.Slack{WebhookURL: url, Channel: viper.GetString("slack-channel")})
+		}
+		if url := viper.GetString("teams-webhook"); url != "" {
+			notifiers = append(notifiers, &notify.Teams{WebhookURL: url})
+		}
+		if apiKey := viper.GetString("datadog-api-key"); apiKey != "" {
+			notifiers = append(notifiers, &notify.Datadog{APIKey: apiKey, Site: viper.GetString("datadog-site")})
+		}
+		if url := viper.GetString("grafana-url"); url != "" {
+			notifiers = append(notifiers, &notify.Grafana{
+				URL:          url,
+				Token:        viper.GetString("grafana-token"),
+				DashboardUID: viper.GetString("grafana-dashboard-uid"),
+				Tags:         viper.GetStringSlice("grafana-tags"),
+			})
+		}
+		if routingKey := viper.GetString("pagerduty-routing-key"); routingKey != "" {
+			notifiers = append(notifiers, &notify.PagerDuty{
+				RoutingKey:   routingKey,
+				URL:          viper.GetString("pagerduty-url"),
+				Environments: viper.GetStringSlice("pagerduty-environments"),
+			})
+		}
+		if sink := viper.GetString("cloudevents-sink"); sink != "" {
+			notifiers = append(notifiers, &notify.CloudEvents{Sink: sink})
+		}
+	})
+	return notifiers
+}
+
+// notifyChange sends an event for a change to every configured notifier; failed changes are only
+// sent to notifiers that report failures. Delivery failures are reported as warnings.
+func notifyChange(m mutation, opErr error) {
+	targets := configuredNotifiers()
+	if len(targets) == 0 {
+		return
+	}
+
+	event := notify.Event{
+		ID:          notify.NewEventID(),
+		Type:        eventType(m),
+		Time:        time.Now().UTC(),
+		Operation:   m.Operation,
+		Application: m.Application,
+		Flag:        m.Flag,
+		Environment: m.Environment,
+		Changes:     m.Changes,
+		Before:      m.Before,
+		After:       m.After,
+		Principal:   auditPrincipal(),
+		RunURL:      runURL(),
+	}
+	if opErr != nil {
+		event.After = nil
+		event.Error = opErr.Error()
+	}
+
+	sendEvent(targets, event)
+}
+
+// sendEvent delivers an event to the notifiers, skipping those that do not report failures
+// when the event is about a failed operation
+func sendEvent(targets []notify.Notifier, event notify.Event) {
+	for _, notifier := range targets {
+		if failures, ok := notifier.(notify.FailureNotifier); event.Error != "" && (!ok || !failures.ReportsFailures()) {
+			continue
+		}
+		if err := notifier.Notify(event); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send %s notification: %v\n", notifier.Name(), err)
+		}
+	}
+}
+
+// eventType classifies a change for notifications
+func eventType(m mutation) string {
+	switch {
+	case m.Operation == "create-environment":
+		return notify.TypeEnvironmentCreated
+	case m.Operation == "update-environment":
+		return notify.TypeEnvironmentUpdated
+	case m.Operation == "delete-environment":
+		return notify.TypeEnvironmentDeleted
+	case m.Operation == "create-application":
+		return notify.TypeApplicationCreated
+	case m.Operation == "update-application" || m.Operation == "link-environment" || m.Operation == "unlink-environment":
+		return notify.TypeApplicationUpdated
+	case m.Environment != "":
+		return notify.TypeConfigUpdated
+	case m.Operation == "rollback" && m.Before == nil:
+		return notify.TypeFlagCreated
+	case m.Operation == "rollback" && m.After == nil:
+		return notify.TypeFlagDeleted
+	case m.Operation == "create-flag" || m.Operation == "clone-flag" || m.Operation == "copy-flag" || m.Operation == "migrate-flags":
+		return notify.TypeFlagCreated
+	case m.Operation == "delete-flag":
+		return notify.TypeFlagDeleted
+	default:
+		return notify.TypeFlagUpdated
+	}
+}
+
+// runURL links to the CI run executing the CLI, if it can be determined
+func runURL() string {
+	if url := os.Getenv("CLOUDBEES_RUN_URL"); url != "" {
+		return url
+	}
+	if os.Getenv("GITHUB_RUN_ID") != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	}
+	return os.Getenv("CI_PIPELINE_URL")
+}
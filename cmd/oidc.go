@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/oidc"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// oidcExchangePath is the token exchange endpoint of the API, used without --oidc-exchange-url
+const oidcExchangePath = "/v1/oauth/token"
+
+// oidcTokenProvider returns a provider of API tokens exchanged for the OIDC token of the CI job,
+// read from --oidc-token-file or requested from the GitHub Actions runtime with
+// --oidc-github-actions, or nil without them. The OIDC token is obtained and exchanged again
+// when the API token expires.
+func oidcTokenProvider(cmd *cobra.Command) (cloudbees.TokenProvider, error) {
+	tokenFile := viper.GetString("oidc-token-file")
+	gitHubActions := viper.GetBool("oidc-github-actions")
+	if tokenFile == "" && !gitHubActions {
+		return nil, nil
+	}
+
+	apiURL, err := resolveAPIURL(cmd)
+	if err != nil {
+		return nil, err
+	}
+	audience := viper.GetString("oidc-audience")
+	if audience == "" {
+		audience = apiURL
+	}
+	exchangeURL := viper.GetString("oidc-exchange-url")
+	if exchangeURL == "" {
+		exchangeURL = strings.TrimSuffix(apiURL, "/") + oidcExchangePath
+	}
+	exchanger := &oidc.Exchanger{URL: exchangeURL, Audience: audience}
+
+	return cloudbees.NewRefreshingToken(func() (string, time.Time, error) {
+		var subjectToken string
+		var err error
+		if tokenFile != "" {
+			subjectToken, err = oidc.ReadTokenFile(tokenFile)
+		} else {
+			subjectToken, err = oidc.GitHubActionsToken(nil, audience)
+		}
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		token, err := exchanger.Exchange(subjectToken)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if verbose {
+			if token.ExpiresAt.IsZero() {
+				fmt.Fprintln(os.Stderr, "Exchanged the OIDC token for an API token")
+			} else {
+				fmt.Fprintf(os.Stderr, "Exchanged the OIDC token for an API token valid until %s\n", token.ExpiresAt.Format("15:04:05"))
+			}
+		}
+		return token.AccessToken, token.ExpiresAt, nil
+	}), nil
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// propagationPollInterval is how often --wait reads a changed configuration back
+const propagationPollInterval = 2 * time.Second
+
+// configurationUpdate is the result of updateFlagConfiguration
+type configurationUpdate struct {
+	Application *cloudbees.Application
+	Flag        *cloudbees.Flag
+	Environment *cloudbees.Environment
+	Before      cloudbees.FlagConfiguration
+	After       map[string]interface{}
+}
+
+// updateFlagConfiguration applies partial configuration changes to a flag in an environment,
+// running the guardrails and recording the change. Unless force is set, the update fails with
+// cloudbees.ErrConflict if the configuration changed since it was read, or if it is not at revision ifMatch.
+func updateFlagConfiguration(cmd *cobra.Command, client cloudbees.API, applicationName, flagName, environmentName string,
+	changes map[string]interface{}, ifMatch string, force bool) (*configurationUpdate, error) {
+	ctx, err := resolveContext(cmd, client, applicationName, flagName, environmentName)
+	if err != nil {
+		return nil, err
+	}
+	application, flag, environment := ctx.Application, ctx.Flag, ctx.Environment
+
+	// Read the current revision so the update fails if another pipeline changed the flag meanwhile
+	current, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current flag configuration: %w", err)
+	}
+	etag := ""
+	if !force {
+		if ifMatch != "" && ifMatch != current.Revision {
+			return nil, fmt.Errorf("%w: expected revision %s but found %s (use --force to override)",
+				cloudbees.ErrConflict, ifMatch, current.Revision)
+		}
+		etag = current.ETag
+	}
+
+	update := &configurationUpdate{
+		Application: application,
+		Flag:        flag,
+		Environment: environment,
+		Before:      current.Configuration,
+		After:       mergeConfiguration(current.Configuration, changes),
+	}
+
+	change := mutation{
+		Operation:   "set-flag-config",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Environment: environment.Name,
+		Changes:     changes,
+		Before:      update.Before,
+		After:       update.After,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return nil, err
+	}
+
+	// Set flag configuration using PUT with only specified fields
+	err = client.SetFlagConfigurationIfMatch(application.ID, flag.ID, environment.ID, changes, etag)
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set flag configuration: %w", err)
+	}
+
+	return update, nil
+}
+
+// waitForConfiguration reads the configuration of an updated flag back until it reflects changes,
+// as updates are eventually consistent. It returns false if the changes were not observed within timeout.
+func waitForConfiguration(client cloudbees.API, update *configurationUpdate, changes map[string]interface{}, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		current, err := client.GetFlagConfiguration(update.Application.ID, update.Flag.ID, update.Environment.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to read flag configuration: %w", err)
+		}
+		if configurationContains(current.Configuration, changes) {
+			return true, nil
+		}
+		if time.Now().Add(propagationPollInterval).After(deadline) {
+			return false, nil
+		}
+		time.Sleep(propagationPollInterval)
+	}
+}
+
+// configurationContains reports whether config has the values of changes. Fields that are not
+// part of a flag configuration are ignored.
+func configurationContains(config cloudbees.FlagConfiguration, changes map[string]interface{}) bool {
+	current := map[string]interface{}{
+		"enabled":            config.Enabled,
+		"defaultValue":       config.DefaultValue,
+		"variantsEnabled":    config.VariantsEnabled,
+		"conditions":         config.Conditions,
+		"stickinessProperty": config.StickinessProperty,
+	}
+
+	for key, value := range changes {
+		currentValue, known := current[key]
+		if !known {
+			continue
+		}
+		if !reflect.DeepEqual(normalizeJSON(currentValue), normalizeJSON(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeJSON converts a value to its generic JSON form, so structs compare equal to maps
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// pendingConfiguration is a flag configuration change applied by applyConfigurations
+type pendingConfiguration struct {
+	update cloudbees.ConfigurationUpdate
+	change mutation
+}
+
+// applyConfigurations applies configuration changes to flags of an application. Every change runs
+// the guardrails and is recorded on its own, but the accepted changes are sent to the bulk
+// configuration endpoint in chunks of --batch-size. When the API does not support bulk updates,
+// or with --batch-size 0, they are sent as concurrent single updates instead. With --fail-fast,
+// the changes not sent yet are skipped after the first failure. Results are named after the
+// flags, in the order of pending. Applied changes are recorded in state.
+func applyConfigurations(cmd *cobra.Command, client cloudbees.API, applicationID string, pending []pendingConfiguration, state *bulkState) workerpool.Results[bool] {
+	opts := poolOptions(cmd)
+	results := workerpool.Run(pending, func(p pendingConfiguration) string { return p.change.Flag }, opts,
+		func(p pendingConfiguration) (bool, error) {
+			return false, beforeMutation(cmd, p.change)
+		})
+
+	var accepted []int
+	for i, result := range results {
+		if result.Err == nil && !result.Skipped {
+			accepted = append(accepted, i)
+		}
+	}
+	skipAccepted := func() {
+		for _, i := range accepted {
+			results[i].Skipped = true
+		}
+		accepted = nil
+	}
+	if opts.FailFast && results.Failed() > 0 {
+		skipAccepted()
+	}
+
+	batchSize, _ := cmd.Root().PersistentFlags().GetInt("batch-size")
+	for batchSize > 0 && len(accepted) > 0 {
+		chunk := accepted[:min(batchSize, len(accepted))]
+		updates := make([]cloudbees.ConfigurationUpdate, len(chunk))
+		for j, i := range chunk {
+			updates[j] = pending[i].update
+		}
+
+		errs, err := client.SetFlagConfigurations(applicationID, updates)
+		if errors.Is(err, cloudbees.ErrBatchUnsupported) {
+			if verbose {
+				fmt.Println("Bulk configuration updates are not supported by the API, updating flags one by one")
+			}
+			break
+		}
+		for j, i := range chunk {
+			itemErr := err
+			if err == nil {
+				itemErr = errs[j]
+			}
+			setResult(&results[i], itemErr)
+			afterMutation(cmd, pending[i].change, itemErr)
+			if itemErr == nil {
+				state.complete(configurationKey(pending[i]))
+			}
+		}
+		accepted = accepted[len(chunk):]
+		if opts.FailFast && results.Failed() > 0 {
+			skipAccepted()
+		}
+	}
+
+	single := workerpool.Run(accepted, func(i int) string { return results[i].Name }, opts,
+		func(i int) (bool, error) {
+			update := pending[i].update
+			err := client.SetFlagConfigurationIfMatch(applicationID, update.FlagID, update.EnvironmentID, update.Configuration, update.IfMatch)
+			afterMutation(cmd, pending[i].change, err)
+			if err == nil {
+				state.complete(configurationKey(pending[i]))
+			}
+			return err == nil, err
+		})
+	for j, i := range accepted {
+		results[i] = single[j]
+	}
+	return results
+}
+
+// setResult records the outcome of an item processed outside of a worker pool
+func setResult(result *workerpool.Result[bool], err error) {
+	result.Value = err == nil
+	result.Err = err
+	if err != nil {
+		result.Error = err.Error()
+	}
+}
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// orgsFile lists the organizations a command runs in with --orgs-file:
+//
+//	orgs:
+//	  - name: payments
+//	    org-id: 1a2b3c
+//	    token-env: PAYMENTS_TOKEN     # or token-file, or a profile with a keyring token
+//	  - name: search-eu
+//	    org-id: 4d5e6f
+//	    profile: eu                   # API URL, tiers and keyring token of the profile
+//	    application-name: search      # instead of --application-name
+type orgsFile struct {
+	Orgs []orgEntry `yaml:"orgs"`
+}
+
+// orgEntry is an organization of an orgs file and how to authenticate with it. Settings left
+// empty are those of the command line.
+type orgEntry struct {
+	Name            string `yaml:"name"`
+	OrgID           string `yaml:"org-id"`
+	Profile         string `yaml:"profile"`
+	APIURL          string `yaml:"api-url"`
+	TokenFile       string `yaml:"token-file"`
+	TokenEnv        string `yaml:"token-env"`
+	ApplicationName string `yaml:"application-name"`
+}
+
+// orgResult is the outcome of a command in one organization
+type orgResult struct {
+	Name     string            `json:"name"`
+	OrgID    string            `json:"orgId"`
+	Success  bool              `json:"success"`
+	ExitCode int               `json:"exitCode"`
+	Error    string            `json:"error,omitempty"`
+	Outputs  map[string]string `json:"outputs"`
+	output   []byte
+}
+
+// setupOrgsFanOut makes the command run once per organization of --orgs-file instead of once,
+// and returns whether it does. Each run is a separate process with the settings of its
+// organization and its own outputs, aggregated per organization afterwards.
+func setupOrgsFanOut(cmd *cobra.Command) (bool, error) {
+	filename, _ := cmd.Root().PersistentFlags().GetString("orgs-file")
+	if filename == "" {
+		return false, nil
+	}
+	if cmd.Flags().Changed("org-id") {
+		return false, fmt.Errorf("org-id cannot be used with orgs-file")
+	}
+	orgs, err := loadOrgsFile(filename)
+	if err != nil {
+		return false, err
+	}
+
+	// The organizations provide the required connection flags
+	relaxConnectionFlags(cmd)
+	// PreRunE still runs, so a flag name given as argument satisfies --flag-name
+	cmd.Run = nil
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return runAcrossOrgs(cmd, orgs)
+	}
+	return true, nil
+}
+
+// loadOrgsFile reads and validates an orgs file
+func loadOrgsFile(filename string) ([]orgEntry, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read orgs file: %w", err)
+	}
+	var file orgsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse orgs file '%s': %w", filename, err)
+	}
+	if len(file.Orgs) == 0 {
+		return nil, fmt.Errorf("orgs file '%s' lists no organization", filename)
+	}
+
+	names := map[string]bool{}
+	for i, org := range file.Orgs {
+		if org.OrgID == "" {
+			return nil, fmt.Errorf("organization %d of '%s' has no org-id", i+1, filename)
+		}
+		if org.Name == "" {
+			file.Orgs[i].Name = org.OrgID
+		}
+		if names[file.Orgs[i].Name] {
+			return nil, fmt.Errorf("organization '%s' is listed twice in '%s'", file.Orgs[i].Name, filename)
+		}
+		names[file.Orgs[i].Name] = true
+		if org.TokenFile != "" && org.TokenEnv != "" {
+			return nil, fmt.Errorf("organization '%s' sets both token-file and token-env", file.Orgs[i].Name)
+		}
+	}
+	return file.Orgs, nil
+}
+
+// runAcrossOrgs runs the command line in every organization, and reports the results of each in
+// the orgs output
+func runAcrossOrgs(cmd *cobra.Command, orgs []orgEntry) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the fm-actions executable: %w", err)
+	}
+	baseArgs := withoutFlag(os.Args[1:], "orgs-file")
+
+	results := workerpool.Run(orgs, func(org orgEntry) string { return org.Name }, poolOptions(cmd),
+		func(org orgEntry) (orgResult, error) {
+			result, err := runInOrg(executable, baseArgs, org)
+			if err == nil && !result.Success {
+				err = errors.New(result.Error)
+			}
+			return result, err
+		})
+
+	// Output results
+	orgResults := make([]orgResult, len(results))
+	for i, result := range results {
+		orgResults[i] = result.Value
+		orgResults[i].Name, orgResults[i].OrgID = orgs[i].Name, orgs[i].OrgID
+		if result.Skipped {
+			orgResults[i].Error = "skipped"
+		} else if result.Err != nil && orgResults[i].Error == "" {
+			orgResults[i].Error = result.Err.Error()
+		}
+		if orgResults[i].Outputs == nil {
+			orgResults[i].Outputs = map[string]string{}
+		}
+	}
+	orgsJSON, _ := json.Marshal(orgResults)
+	cloudbees.WriteOutput("orgs", string(orgsJSON))
+
+	for _, result := range orgResults {
+		fmt.Printf("=== %s (%s) ===\n", result.Name, result.OrgID)
+		os.Stdout.Write(result.output)
+		if len(result.output) > 0 && !bytes.HasSuffix(result.output, []byte("\n")) {
+			fmt.Println()
+		}
+	}
+	fmt.Printf("Ran %s in %d of %d organizations\n", commandPathName(cmd), results.Succeeded(), len(orgs))
+	for _, result := range orgResults {
+		if !result.Success {
+			fmt.Printf("- %s: FAILED: %s\n", result.Name, result.Error)
+		}
+	}
+
+	return bulkErr(cmd, results)
+}
+
+// runInOrg runs the command line in an organization, in a separate process with its own outputs
+func runInOrg(executable string, baseArgs []string, org orgEntry) (orgResult, error) {
+	result := orgResult{Name: org.Name, OrgID: org.OrgID, Outputs: map[string]string{}}
+
+	tempDir, err := os.MkdirTemp("", "fm-actions-org-*")
+	if err != nil {
+		return result, err
+	}
+	defer os.RemoveAll(tempDir)
+	outputsDir := filepath.Join(tempDir, "outputs")
+	if err := os.Mkdir(outputsDir, 0700); err != nil {
+		return result, err
+	}
+
+	args := append([]string{}, baseArgs...)
+	tokenFile := org.TokenFile
+	if org.TokenEnv != "" {
+		// Passed in a file rather than the arguments, which other users of the host can see
+		token := os.Getenv(org.TokenEnv)
+		if token == "" {
+			return result, fmt.Errorf("environment variable %s of organization '%s' is not set", org.TokenEnv, org.Name)
+		}
+		tokenFile = filepath.Join(tempDir, "token")
+		if err := os.WriteFile(tokenFile, []byte(token), 0600); err != nil {
+			return result, err
+		}
+	}
+	if tokenFile != "" {
+		args = append(withoutFlag(args, "token"), "--token-file", tokenFile)
+	}
+	if org.Profile != "" {
+		args = append(withoutFlag(args, "profile"), "--profile", org.Profile)
+	}
+	if org.APIURL != "" {
+		args = append(withoutFlag(args, "api-url"), "--api-url", org.APIURL)
+	}
+	if org.ApplicationName != "" {
+		args = append(withoutFlag(args, "application-name"), "--application-name", org.ApplicationName)
+	}
+	args = append(args, "--org-id", org.OrgID)
+
+	command := exec.Command(executable, args...)
+	command.Env = append(os.Environ(), "CLOUDBEES_OUTPUTS="+outputsDir)
+	output, err := command.CombinedOutput()
+	result.output = output
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		result.Success = true
+	case errors.As(err, &exitErr):
+		result.ExitCode = exitErr.ExitCode()
+		result.Error = lastError(output, result.ExitCode)
+	default:
+		return result, fmt.Errorf("failed to run in organization '%s': %w", org.Name, err)
+	}
+
+	entries, _ := os.ReadDir(outputsDir)
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == cloudbees.OutputsManifestFile {
+			continue
+		}
+		if value, err := os.ReadFile(filepath.Join(outputsDir, entry.Name())); err == nil {
+			result.Outputs[entry.Name()] = string(value)
+		}
+	}
+	return result, nil
+}
+
+// lastError returns the error a run printed last, or its exit code
+func lastError(output []byte, exitCode int) string {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if message, ok := strings.CutPrefix(lines[i], "Error: "); ok {
+			return message
+		}
+	}
+	return fmt.Sprintf("exit code %d", exitCode)
+}
+
+// withoutFlag removes a long flag and its value from command line arguments
+func withoutFlag(args []string, name string) []string {
+	var kept []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--"+name:
+			i++
+		case strings.HasPrefix(args[i], "--"+name+"="):
+		default:
+			kept = append(kept, args[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+)
+
+// flagGraph is the dependency graph of the flags of an application: an edge goes from a flag to
+// each of its prerequisites
+type flagGraph struct {
+	Nodes   []string            // Flags with prerequisites or dependents, sorted by name
+	Edges   map[string][]string // Prerequisites by flag name
+	Missing map[string][]string // Prerequisites that are not flags of the application, by flag name
+}
+
+// buildFlagGraph returns the dependency graph declared by the requires labels of flags
+func buildFlagGraph(flags []cloudbees.Flag) flagGraph {
+	graph := flagGraph{Edges: map[string][]string{}, Missing: map[string][]string{}}
+	exists := map[string]bool{}
+	for _, flag := range flags {
+		exists[flag.Name] = true
+	}
+
+	nodes := map[string]bool{}
+	for _, flag := range flags {
+		for _, prerequisite := range flag.Prerequisites() {
+			if !exists[prerequisite] {
+				graph.Missing[flag.Name] = append(graph.Missing[flag.Name], prerequisite)
+				continue
+			}
+			graph.Edges[flag.Name] = append(graph.Edges[flag.Name], prerequisite)
+			nodes[flag.Name], nodes[prerequisite] = true, true
+		}
+	}
+	for node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Strings(graph.Nodes)
+	return graph
+}
+
+// cycles returns the dependency cycles of the graph, each as the path of flags back to its start
+func (g flagGraph) cycles() [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var cycles [][]string
+
+	var visit func(node string)
+	visit = func(node string) {
+		state[node] = visiting
+		path = append(path, node)
+		for _, next := range g.Edges[node] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				// The cycle is the end of the path from the first visit of next
+				for i := range path {
+					if path[i] == next {
+						cycle := append(append([]string{}, path[i:]...), next)
+						cycles = append(cycles, cycle)
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[node] = visited
+	}
+	for _, node := range g.Nodes {
+		if state[node] == unvisited {
+			visit(node)
+		}
+	}
+	return cycles
+}
+
+// checkPrerequisites refuses to enable a flag in an environment where one of its prerequisites
+// is disabled
+func checkPrerequisites(client cloudbees.API, applicationID string, flag *cloudbees.Flag, environment *cloudbees.Environment) error {
+	var disabled []string
+	for _, name := range flag.Prerequisites() {
+		prerequisite, err := client.GetFlagByName(applicationID, name)
+		if err != nil {
+			return fmt.Errorf("failed to get prerequisite '%s' of flag '%s': %w", name, flag.Name, err)
+		}
+		config, err := client.GetFlagConfiguration(applicationID, prerequisite.ID, environment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get configuration of prerequisite '%s': %w", name, err)
+		}
+		if !config.Configuration.Enabled {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) > 0 {
+		return fmt.Errorf("%w: flag '%s' requires %s, disabled in '%s' (enable them first or use --skip-prerequisites)",
+			policy.ErrViolation, flag.Name, quotedList(disabled), environment.Name)
+	}
+	return nil
+}
+
+// quotedList returns names quoted and separated by commas
+func quotedList(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// validatePrerequisites checks that the prerequisites of a flag being created or updated are
+// flags of the application and do not depend on the flag themselves
+func validatePrerequisites(client cloudbees.API, applicationID string, flag cloudbees.Flag) error {
+	if len(flag.Prerequisites()) == 0 {
+		return nil
+	}
+	flags, err := client.ListFlags(applicationID)
+	if err != nil {
+		return fmt.Errorf("failed to list flags: %w", err)
+	}
+	replaced := false
+	for i := range flags {
+		if flags[i].Name == flag.Name {
+			flags[i], replaced = flag, true
+		}
+	}
+	if !replaced {
+		flags = append(flags, flag)
+	}
+
+	graph := buildFlagGraph(flags)
+	if missing := graph.Missing[flag.Name]; len(missing) > 0 {
+		return fmt.Errorf("prerequisite flags not found: %s", quotedList(missing))
+	}
+	for _, cycle := range graph.cycles() {
+		for _, name := range cycle {
+			if name == flag.Name {
+				return fmt.Errorf("prerequisites of flag '%s' would create the dependency cycle %s", flag.Name, strings.Join(cycle, " -> "))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// promotionResult reports the outcome of promoting a single flag
+type promotionResult struct {
+	FlagName    string            `json:"flagName"`
+	FlagID      string            `json:"flagId"`
+	Differences []fieldDifference `json:"differences"`
+	Promoted    bool              `json:"promoted"`
+}
+
+var promoteEnvironmentCmd = &cobra.Command{
+	Use:   "promote-environment",
+	Short: "Copy flag configurations from one environment to another",
+	Long: `Copy the configuration of every flag (optionally filtered by label or name prefix) from one
+environment to another. Only flags whose configuration differs are updated. Use --dry-run to
+print the promotion plan without applying it. When a flag fails to be promoted, the flags already
+promoted are reverted, unless --no-rollback is set.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fromName, _ := cmd.Flags().GetString("from")
+		toName, _ := cmd.Flags().GetString("to")
+		labels, _ := cmd.Flags().GetStringSlice("label")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if fromName == "" || toName == "" {
+			return fmt.Errorf("from and to environments are required")
+		}
+		if fromName == toName {
+			return fmt.Errorf("from and to environments must be different")
+		}
+
+		client, ctx, err := commandContext(cmd, "", "")
+		if err != nil {
+			return err
+		}
+		application := ctx.Application
+
+		fromEnv, err := client.GetEnvironmentByName(fromName)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+		toEnv, err := client.GetEnvironmentByName(toName)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		allFlags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+
+		var flags []cloudbees.Flag
+		for _, flag := range allFlags {
+			if strings.HasPrefix(flag.Name, prefix) && hasAnyLabel(flag, labels) {
+				flags = append(flags, flag)
+			}
+		}
+
+		opts := poolOptions(cmd)
+		comparisons := compareFlags(client, application.ID, flags, fromEnv.ID, toEnv.ID, opts)
+		if err := comparisons.Err(); err != nil {
+			return fmt.Errorf("failed to compare flags: %w", err)
+		}
+
+		var plan []flagComparison
+		for _, result := range comparisons {
+			if len(result.Value.Differences) > 0 {
+				plan = append(plan, result.Value)
+			}
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would promote %d of %d flags from '%s' to '%s'\n", len(plan), len(flags), fromName, toName)
+			for _, comparison := range plan {
+				fmt.Printf("- %s\n", comparison.FlagName)
+				for _, diff := range comparison.Differences {
+					fromJSON, _ := json.Marshal(diff.From)
+					toJSON, _ := json.Marshal(diff.To)
+					fmt.Printf("  %s: %s -> %s\n", diff.Field, toJSON, fromJSON)
+				}
+			}
+			return nil
+		}
+
+		state, err := loadBulkState(cmd)
+		if err != nil {
+			return err
+		}
+		var remaining []flagComparison
+		for _, c := range plan {
+			if state.done(c.FlagID + "/" + toEnv.ID) {
+				fmt.Printf("Skipping '%s', promoted by a previous run\n", c.FlagName)
+				continue
+			}
+			remaining = append(remaining, c)
+		}
+		plan = remaining
+
+		pending := make([]pendingConfiguration, len(plan))
+		for i, c := range plan {
+			changes := configurationChanges(c.From)
+			pending[i] = pendingConfiguration{
+				update: cloudbees.ConfigurationUpdate{FlagID: c.FlagID, EnvironmentID: toEnv.ID, Configuration: changes, IfMatch: c.ToETag},
+				change: mutation{
+					Operation:   "promote-environment",
+					Application: application.Name,
+					Flag:        c.FlagName,
+					Labels:      c.Labels,
+					Environment: toName,
+					Changes:     changes,
+					Before:      c.To,
+					After:       changes,
+				},
+			}
+		}
+		applied := applyConfigurations(cmd, client, application.ID, pending, state)
+
+		// Revert the promoted flags when more than --max-failures others failed, so the target
+		// environment is not left half promoted
+		rolledBack := false
+		var failure error
+		if err := applied.Err(); err != nil && applied.Failed() > maxFailures(cmd) {
+			tx := &transaction{}
+			var promoted []pendingConfiguration
+			for i, result := range applied {
+				if result.Err == nil && !result.Skipped {
+					promoted = append(promoted, pending[i])
+				}
+			}
+			if len(promoted) > 0 {
+				tx.record(fmt.Sprintf("restore %d flag configurations in '%s'", len(promoted), toName),
+					revertConfigurations(cmd, client, application.ID, promoted))
+			}
+			rolledBack, failure = tx.rollback(cmd, err)
+		} else if applied.Failed() == 0 {
+			state.finish()
+		}
+
+		results := make(workerpool.Results[promotionResult], len(plan))
+		for i, c := range plan {
+			results[i] = workerpool.Result[promotionResult]{
+				Name:    applied[i].Name,
+				Value:   promotionResult{FlagName: c.FlagName, FlagID: c.FlagID, Differences: c.Differences, Promoted: applied[i].Value && !rolledBack},
+				Err:     applied[i].Err,
+				Error:   applied[i].Error,
+				Skipped: applied[i].Skipped,
+			}
+		}
+
+		// Output results
+		resultsJSON, _ := json.Marshal(results)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(flags)))
+		promotedCount := results.Succeeded()
+		if rolledBack {
+			promotedCount = 0
+		}
+		cloudbees.WriteOutput("promoted-count", fmt.Sprintf("%d", promotedCount))
+		cloudbees.WriteOutput("failed-count", fmt.Sprintf("%d", results.Failed()))
+		cloudbees.WriteOutput("results", string(resultsJSON))
+		cloudbees.WriteOutput("success", fmt.Sprintf("%t", results.Failed() == 0))
+
+		for _, result := range results {
+			switch {
+			case result.Err != nil:
+				fmt.Printf("- %s: FAILED: %v\n", result.Name, result.Err)
+			case result.Skipped:
+				fmt.Printf("- %s: skipped\n", result.Name)
+			case rolledBack:
+				fmt.Printf("- %s: rolled back\n", result.Name)
+			default:
+				fmt.Printf("- %s: promoted\n", result.Name)
+			}
+		}
+		fmt.Printf("Promoted %d of %d flags from '%s' to '%s'\n", promotedCount, len(plan), fromName, toName)
+
+		if err := bulkErr(cmd, results); failure == nil {
+			failure = err
+		}
+		return failure
+	},
+}
+
+// hasAnyLabel reports whether the flag has at least one of the labels; an empty list matches every flag
+func hasAnyLabel(flag cloudbees.Flag, labels []string) bool {
+	if len(labels) == 0 {
+		return true
+	}
+	for _, want := range labels {
+		for _, label := range flag.Labels {
+			if label == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configurationChanges converts a configuration into the field map accepted by SetFlagConfiguration
+func configurationChanges(config cloudbees.FlagConfiguration) map[string]interface{} {
+	changes := map[string]interface{}{
+		"enabled":         config.Enabled,
+		"defaultValue":    config.DefaultValue,
+		"variantsEnabled": config.VariantsEnabled,
+	}
+	if config.Conditions != nil {
+		changes["conditions"] = config.Conditions
+	}
+	if config.StickinessProperty != "" {
+		changes["stickinessProperty"] = config.StickinessProperty
+	}
+	return changes
+}
+
+// mergeConfiguration returns the configuration that results from applying partial changes to config
+func mergeConfiguration(config cloudbees.FlagConfiguration, changes map[string]interface{}) map[string]interface{} {
+	merged := configurationChanges(config)
+	for key, value := range changes {
+		merged[key] = value
+	}
+	return merged
+}
+
+func init() {
+	rootCmd.AddCommand(promoteEnvironmentCmd)
+
+	promoteEnvironmentCmd.Flags().String("from", "", "Source environment name (required)")
+	promoteEnvironmentCmd.Flags().String("to", "", "Target environment name (required)")
+	promoteEnvironmentCmd.Flags().StringSlice("label", nil, "Only promote flags with this label (repeatable)")
+	promoteEnvironmentCmd.Flags().String("prefix", "", "Only promote flags whose name starts with this prefix")
+	promoteEnvironmentCmd.Flags().Bool("dry-run", false, "Print the promotion plan without applying changes")
+	promoteEnvironmentCmd.Flags().Bool("no-rollback", false, "Keep the flags promoted before a failure instead of reverting them")
+	stateFlags(promoteEnvironmentCmd)
+
+	promoteEnvironmentCmd.MarkFlagRequired("from")
+	promoteEnvironmentCmd.MarkFlagRequired("to")
+}
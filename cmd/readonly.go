@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// mutatingCommands are the commands that change flags, environments or applications, refused
+// in read-only mode unless they only preview with --dry-run
+var mutatingCommands = map[string]bool{
+	"create-flag":          true,
+	"delete-flag":          true,
+	"update-flag":          true,
+	"rename-flag":          true,
+	"clone-flag":           true,
+	"copy-flag":            true,
+	"migrate-flags":        true,
+	"add-flag-labels":      true,
+	"remove-flag-labels":   true,
+	"set-flag-config":      true,
+	"set-variant-weights":  true,
+	"rollback-flag-config": true,
+	"kill-switch":          true,
+	"promote-environment":  true,
+	"create-environment":   true,
+	"update-environment":   true,
+	"delete-environment":   true,
+	"env-bootstrap":        true,
+	"env-teardown":         true,
+	"create-application":   true,
+	"update-application":   true,
+	"link-environment":     true,
+	"seed":                 true,
+	"unseed":               true,
+	"sync-from-git":        true,
+	"apply":                true,
+	"import-launchdarkly":  true,
+	"import-unleash":       true,
+	"import-flagsmith":     true,
+	"experiment-start":     true,
+	"experiment-stop":      true,
+}
+
+// readOnly reports whether changes are refused, with --read-only, read-only in the config file
+// or FM_READ_ONLY
+func readOnly() bool {
+	return viper.GetBool("read-only")
+}
+
+// checkReadOnly refuses to run a command that changes data in read-only mode. Commands that only
+// change data on request, such as drift-watch --remediate, and the servers are refused at the
+// first change instead, by beforeMutation and the client.
+func checkReadOnly(cmd *cobra.Command) error {
+	if !readOnly() {
+		return nil
+	}
+	name := commandPathName(cmd)
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	remediate, _ := cmd.Flags().GetBool("remediate")
+	if (mutatingCommands[name] && !dryRun) || remediate {
+		return fmt.Errorf("%w: %s changes data and is disabled (--read-only or FM_READ_ONLY)", cloudbees.ErrReadOnly, name)
+	}
+	return nil
+}
+
+// commandPathName returns the name of a command including its parents, e.g. import-unleash, and
+// the flat name of resource commands
+func commandPathName(cmd *cobra.Command) string {
+	if operation, ok := cmd.Annotations["operation"]; ok {
+		return operation
+	}
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	return strings.ReplaceAll(path, " ", "-")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var removeFlagLabelsCmd = &cobra.Command{
+	Use:   "remove-flag-labels",
+	Short: "Remove labels from a feature flag",
+	Long:  `Remove one or more labels from a feature flag. Labels the flag does not have are ignored.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateFlagLabels(cmd, func(current, labels []string) []string {
+			result := []string{}
+			for _, label := range current {
+				if !containsString(labels, label) {
+					result = append(result, label)
+				}
+			}
+			return result
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(removeFlagLabelsCmd)
+
+	removeFlagLabelsCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	removeFlagLabelsCmd.Flags().StringSlice("labels", nil, "Labels to remove, comma-separated or repeated (required)")
+	removeFlagLabelsCmd.Flags().Bool("dry-run", false, "Show the resulting labels without applying them")
+
+	flagNamesArg(removeFlagLabelsCmd)
+
+	removeFlagLabelsCmd.MarkFlagRequired("flag-name")
+	removeFlagLabelsCmd.MarkFlagRequired("labels")
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var renameFlagCmd = &cobra.Command{
+	Use:   "rename-flag",
+	Short: "Rename a feature flag",
+	Long: `Rename a feature flag. With --scan-dir, the source tree is searched for references to the
+old name first and the rename is refused if code still uses it.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		newName, _ := cmd.Flags().GetString("new-name")
+		scanDir, _ := cmd.Flags().GetString("scan-dir")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if flagName == "" {
+			return fmt.Errorf("flag-name is required")
+		}
+		if newName == "" {
+			return fmt.Errorf("new-name is required")
+		}
+		if flagName == newName {
+			return fmt.Errorf("new-name must be different from flag-name")
+		}
+
+		// Refuse to rename while code still references the old name
+		if scanDir != "" {
+			references, err := scan.FindReferences(scanDir, []string{flagName})
+			if err != nil {
+				return fmt.Errorf("failed to scan '%s': %w", scanDir, err)
+			}
+			if refs := references[flagName]; len(refs) > 0 {
+				referencesJSON, _ := json.Marshal(refs)
+				cloudbees.WriteOutput("references", string(referencesJSON))
+				cloudbees.WriteOutput("reference-count", fmt.Sprintf("%d", len(refs)))
+
+				fmt.Printf("Flag '%s' is still referenced in %s:\n", flagName, scanDir)
+				for _, ref := range refs {
+					fmt.Printf("  %s:%d: %s\n", ref.File, ref.Line, ref.Text)
+				}
+				return fmt.Errorf("flag '%s' is still referenced %d time(s), update the code before renaming", flagName, len(refs))
+			}
+		}
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		application, flag := ctx.Application, ctx.Flag
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would rename flag '%s' (ID: %s) to '%s'\n", flag.Name, flag.ID, newName)
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "rename-flag",
+			Application: application.Name,
+			Flag:        flag.Name,
+			Labels:      flag.Labels,
+			Changes:     map[string]interface{}{"name": newName},
+			Before:      flag,
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		renamed, err := client.RenameFlag(application.ID, flag.ID, newName)
+		change.After = renamed
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to rename flag: %w", err)
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-id", flag.ID)
+		cloudbees.WriteOutput("old-flag-name", flagName)
+		cloudbees.WriteOutput("flag-name", renamed.Name)
+		cloudbees.WriteOutput("reference-count", "0")
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully renamed flag: %s -> %s (ID: %s)\n", flagName, renamed.Name, flag.ID)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(renameFlagCmd)
+
+	renameFlagCmd.Flags().StringP("flag-name", "f", "", "Current name of the flag (required)")
+	renameFlagCmd.Flags().String("new-name", "", "New name of the flag (required)")
+	renameFlagCmd.Flags().String("scan-dir", "", "Source directory to check for references to the old name")
+	renameFlagCmd.Flags().Bool("dry-run", false, "Show what would be renamed without renaming")
+
+	flagNameArg(renameFlagCmd)
+
+	renameFlagCmd.MarkFlagRequired("flag-name")
+	renameFlagCmd.MarkFlagRequired("new-name")
+}
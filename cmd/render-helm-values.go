@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var renderHelmValuesCmd = &cobra.Command{
+	Use:   "helm-values",
+	Short: "Render mapped flag values of an environment as a Helm values file",
+	Long: `Render the values of selected flags in an environment into a values.yaml fragment, so charts
+can template behavior off the flag state determined at deploy time. The mapping file lists the
+values paths and the flags setting them:
+
+  values:
+    checkout.newFlow: checkout-v2
+    ui.theme:
+      flag: theme
+      default: light   # Used when the flag is disabled or serves a percentage split
+
+Pass the fragment to helm with -f after the chart's own values files.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		mappingFile, _ := cmd.Flags().GetString("mapping-file")
+		file, _ := cmd.Flags().GetString("file")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		mapping, err := render.LoadHelmMapping(mappingFile)
+		if err != nil {
+			return err
+		}
+
+		states, err := environmentStates(cmd, applicationName, environmentName)
+		if err != nil {
+			return err
+		}
+
+		data, warnings, err := render.HelmValues(mapping, states)
+		if err != nil {
+			return err
+		}
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		if err := writeRendered(file, data); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("value-count", fmt.Sprintf("%d", len(mapping.Values)-len(warnings)))
+		if file != "" {
+			cloudbees.WriteOutput("file", file)
+			fmt.Printf("Rendered %d values of '%s' to %s\n", len(mapping.Values)-len(warnings), environmentName, file)
+		}
+		return nil
+	},
+}
+
+func init() {
+	renderCmd.AddCommand(renderHelmValuesCmd)
+
+	renderHelmValuesCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	renderHelmValuesCmd.Flags().String("mapping-file", "", "YAML file mapping values paths to flags (required)")
+	renderHelmValuesCmd.Flags().String("file", "", "Write the values to this file instead of stdout")
+
+	renderHelmValuesCmd.MarkFlagRequired("environment-name")
+	renderHelmValuesCmd.MarkFlagRequired("mapping-file")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var renderK8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Render the flag states of an environment as a Kubernetes ConfigMap",
+	Long: `Render the flag states of an environment as a ConfigMap (or Secret) manifest. Each flag is a key
+holding the value it serves: true or false for Boolean flags, the value otherwise. Disabled non-Boolean
+flags and percentage splits have no key. flags.json holds the full state of every flag.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		name, _ := cmd.Flags().GetString("name")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		kind, _ := cmd.Flags().GetString("kind")
+		file, _ := cmd.Flags().GetString("file")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if kind != render.KindConfigMap && kind != render.KindSecret {
+			return fmt.Errorf("invalid kind '%s', must be %s or %s", kind, render.KindConfigMap, render.KindSecret)
+		}
+
+		states, err := environmentStates(cmd, applicationName, environmentName)
+		if err != nil {
+			return err
+		}
+
+		data, err := render.K8s(render.K8sObject{
+			Kind:      kind,
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/managed-by": "fm-actions"},
+			Annotations: map[string]string{
+				"fm-actions/application": applicationName,
+				"fm-actions/environment": environmentName,
+				"fm-actions/rendered-at": time.Now().UTC().Format(time.RFC3339),
+			},
+		}, states)
+		if err != nil {
+			return err
+		}
+		if err := writeRendered(file, data); err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(states)))
+		if file != "" {
+			cloudbees.WriteOutput("file", file)
+			fmt.Printf("Rendered %d flags of '%s' to %s\n", len(states), environmentName, file)
+		}
+		return nil
+	},
+}
+
+func init() {
+	renderCmd.AddCommand(renderK8sCmd)
+
+	renderK8sCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	renderK8sCmd.Flags().String("name", "feature-flags", "Name of the ConfigMap")
+	renderK8sCmd.Flags().String("namespace", "", "Namespace of the ConfigMap (omitted by default)")
+	renderK8sCmd.Flags().String("kind", render.KindConfigMap, "Kind of object to render: ConfigMap or Secret")
+	renderK8sCmd.Flags().String("file", "", "Write the manifest to this file instead of stdout")
+
+	renderK8sCmd.MarkFlagRequired("environment-name")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/render"
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render flag states into deployment artifacts",
+	Long: `Fetch the flag states of an environment and bake them into deployment artifacts, so workloads
+without SDK connectivity can consume a snapshot taken at deploy time.`,
+}
+
+// environmentStates fetches the state of every flag of the application in an environment.
+// Flags whose value cannot be baked (percentage splits) are reported as warnings.
+func environmentStates(cmd *cobra.Command, applicationName, environmentName string) ([]render.FlagState, error) {
+	client, err := newClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := exportManifest(cmd, client, applicationName, []string{environmentName})
+	if err != nil {
+		return nil, err
+	}
+
+	states := render.States(m, environmentName)
+	for _, state := range states {
+		if state.Enabled && !state.Static() {
+			fmt.Fprintf(os.Stderr, "Warning: %s serves a percentage split in %s, which cannot be baked\n", state.Name, environmentName)
+		}
+	}
+	return states, nil
+}
+
+// writeRendered writes a rendered artifact to a file, or stdout when file is empty
+func writeRendered(file string, data []byte) error {
+	if file == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(renderCmd)
+}
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// ageBuckets group flags by the number of days since they were created
+var ageBuckets = []struct {
+	Name    string
+	MaxDays int
+}{
+	{"< 30 days", 30},
+	{"30-90 days", 90},
+	{"90-180 days", 180},
+	{"180-365 days", 365},
+	{"> 1 year", -1},
+}
+
+// ageUnknown is the age bucket of flags without a creation date
+const ageUnknown = "unknown"
+
+// governanceReport is the content of the report command
+type governanceReport struct {
+	GeneratedAt   time.Time           `json:"generatedAt"`
+	Environments  []string            `json:"environments"`
+	Applications  []applicationReport `json:"applications"`
+	FlagCount     int                 `json:"flagCount"`
+	Permanent     int                 `json:"permanent"`
+	Temporary     int                 `json:"temporary"`
+	ByType        []reportCount       `json:"byType"`
+	ByAge         []reportCount       `json:"byAge"`
+	ByLabel       []reportCount       `json:"byLabel"`
+	MinAgeDays    int                 `json:"minAgeDays"`
+	Stale         []staleFlag         `json:"stale"`
+	PolicyChecked bool                `json:"policyChecked"`
+	Violations    []policy.Violation  `json:"violations"`
+}
+
+// applicationReport holds the flag counts of one application
+type applicationReport struct {
+	Name      string         `json:"name"`
+	FlagCount int            `json:"flagCount"`
+	Enabled   map[string]int `json:"enabledByEnvironment"` // Number of enabled flags by environment name
+}
+
+// reportCount is the number of flags in a group
+type reportCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// applicationData is what the report collects about one application
+type applicationData struct {
+	Flags      []cloudbees.Flag
+	Enabled    map[string]int
+	Stale      []staleFlag
+	Violations []policy.Violation
+}
+
+var reportCmd = &cobra.Command{
+	Use:     "report",
+	Aliases: []string{"flag-report"},
+	Short:   "Governance report of the flags, as HTML and Markdown",
+	Long: `Generate a governance report for leadership reviews: flag counts by type, age and label, a heatmap
+of the flags enabled in each environment, the stale cleanup candidates (see stale-flags) and the
+policy violations (see check-policy, with --policy-file). With --all-applications, the report covers
+every application of the organization. The report is written as HTML and Markdown to --html-file and
+--markdown-file, and as flag-report.html, flag-report.md and flag-report.json to --artifact-dir.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minAgeDays, _ := cmd.Flags().GetInt("min-age-days")
+		policyFile, _ := cmd.Flags().GetString("policy-file")
+		htmlFile, _ := cmd.Flags().GetString("html-file")
+		markdownFile, _ := cmd.Flags().GetString("markdown-file")
+		topLabels, _ := cmd.Flags().GetInt("top-labels")
+
+		var rules *policy.Rules
+		if policyFile != "" {
+			loaded, err := policy.LoadRules(policyFile)
+			if err != nil {
+				return err
+			}
+			rules = &loaded
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+		applications, err := targetApplications(cmd, client)
+		if err != nil {
+			return err
+		}
+		environments, err := client.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		environments, _ = selectEnvironments(environments, nil)
+
+		now := time.Now()
+		results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+			func(application cloudbees.Application) (applicationData, error) {
+				return collectApplicationData(cmd, client, application, environments, rules, minAgeDays, now)
+			})
+		if err := applicationsErr(results); err != nil {
+			return err
+		}
+
+		report := buildGovernanceReport(results, environments, rules != nil, minAgeDays, topLabels, now)
+		markdown := governanceMarkdown(report)
+		html, err := governanceHTML(report)
+		if err != nil {
+			return fmt.Errorf("failed to render HTML report: %w", err)
+		}
+
+		if htmlFile != "" {
+			if err := os.WriteFile(htmlFile, html, 0644); err != nil {
+				return fmt.Errorf("failed to write HTML report: %w", err)
+			}
+		}
+		if markdownFile != "" {
+			if err := os.WriteFile(markdownFile, []byte(markdown), 0644); err != nil {
+				return fmt.Errorf("failed to write markdown report: %w", err)
+			}
+		}
+
+		// Output results
+		writeArtifactFile(cmd, "flag-report.html", html)
+		writeArtifactFile(cmd, "flag-report.md", []byte(markdown))
+		writeArtifact(cmd, "flag-report", report)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", report.FlagCount))
+		cloudbees.WriteOutput("stale-count", fmt.Sprintf("%d", len(report.Stale)))
+		cloudbees.WriteOutput("violation-count", fmt.Sprintf("%d", len(report.Violations)))
+		cloudbees.WriteOutput("report-markdown", markdown)
+		if htmlFile != "" {
+			cloudbees.WriteOutput("html-file", htmlFile)
+		}
+		if markdownFile != "" {
+			cloudbees.WriteOutput("markdown-file", markdownFile)
+		}
+
+		if verbose || (htmlFile == "" && markdownFile == "") {
+			fmt.Print(markdown)
+		} else {
+			fmt.Printf("Reported %d flags of %d applications: %d stale, %d policy violations\n",
+				report.FlagCount, len(report.Applications), len(report.Stale), len(report.Violations))
+		}
+		return nil
+	},
+}
+
+// collectApplicationData reads the flags of an application and their configuration in every
+// environment
+func collectApplicationData(cmd *cobra.Command, client cloudbees.API, application cloudbees.Application, environments []cloudbees.Environment,
+	rules *policy.Rules, minAgeDays int, now time.Time) (applicationData, error) {
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		return applicationData{}, fmt.Errorf("failed to list flags: %w", err)
+	}
+
+	configs := workerpool.Run(flags, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+		func(flag cloudbees.Flag) ([]cloudbees.FlagConfiguration, error) {
+			configs := make([]cloudbees.FlagConfiguration, 0, len(environments))
+			for _, env := range environments {
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, env.ID)
+				if err != nil {
+					return nil, err
+				}
+				configs = append(configs, config.Configuration)
+			}
+			return configs, nil
+		})
+	if err := configs.Err(); err != nil {
+		return applicationData{}, fmt.Errorf("failed to get flag configurations: %w", err)
+	}
+
+	data := applicationData{Flags: flags, Enabled: map[string]int{}}
+	threshold := now.AddDate(0, 0, -minAgeDays)
+	for i, flag := range flags {
+		for j, config := range configs[i].Value {
+			if config.Enabled {
+				data.Enabled[environments[j].Name]++
+			}
+		}
+		if !flag.IsPermanent {
+			if stale := evaluateStaleness(flag, configs[i].Value, threshold, now); stale != nil {
+				stale.Application = application.Name
+				data.Stale = append(data.Stale, *stale)
+			}
+		}
+	}
+	if rules != nil {
+		violations, err := policy.Evaluate(flags, *rules, now)
+		if err != nil {
+			return applicationData{}, err
+		}
+		for _, violation := range violations {
+			violation.Application = application.Name
+			data.Violations = append(data.Violations, violation)
+		}
+	}
+	return data, nil
+}
+
+// buildGovernanceReport aggregates the data of every application
+func buildGovernanceReport(results workerpool.Results[applicationData], environments []cloudbees.Environment, policyChecked bool,
+	minAgeDays, topLabels int, now time.Time) governanceReport {
+	report := governanceReport{
+		GeneratedAt:   now.UTC(),
+		Environments:  []string{},
+		Applications:  []applicationReport{},
+		MinAgeDays:    minAgeDays,
+		Stale:         []staleFlag{},
+		PolicyChecked: policyChecked,
+		Violations:    []policy.Violation{},
+	}
+	for _, env := range environments {
+		report.Environments = append(report.Environments, env.Name)
+	}
+
+	byType := map[string]int{}
+	byAge := map[string]int{}
+	byLabel := map[string]int{}
+	for _, result := range results {
+		data := result.Value
+		report.Applications = append(report.Applications, applicationReport{Name: result.Name, FlagCount: len(data.Flags), Enabled: data.Enabled})
+		report.Stale = append(report.Stale, data.Stale...)
+		report.Violations = append(report.Violations, data.Violations...)
+
+		for _, flag := range data.Flags {
+			report.FlagCount++
+			if flag.IsPermanent {
+				report.Permanent++
+			} else {
+				report.Temporary++
+			}
+			byType[flag.FlagType]++
+			byAge[ageBucket(flag, now)]++
+			for _, label := range flag.Labels {
+				byLabel[label]++
+			}
+		}
+	}
+
+	report.ByType = sortedCounts(byType, 0)
+	for _, bucket := range ageBuckets {
+		report.ByAge = append(report.ByAge, reportCount{Name: bucket.Name, Count: byAge[bucket.Name]})
+	}
+	if byAge[ageUnknown] > 0 {
+		report.ByAge = append(report.ByAge, reportCount{Name: ageUnknown, Count: byAge[ageUnknown]})
+	}
+	report.ByLabel = sortedCounts(byLabel, topLabels)
+	sort.SliceStable(report.Stale, func(i, j int) bool {
+		if report.Stale[i].Priority != report.Stale[j].Priority {
+			return report.Stale[i].Priority == priorityHigh
+		}
+		return report.Stale[i].AgeDays > report.Stale[j].AgeDays
+	})
+	return report
+}
+
+// ageBucket returns the age bucket of a flag
+func ageBucket(flag cloudbees.Flag, now time.Time) string {
+	created, err := time.Parse(time.RFC3339, flag.Created)
+	if err != nil {
+		return ageUnknown
+	}
+	days := int(now.Sub(created).Hours() / 24)
+	for _, bucket := range ageBuckets {
+		if bucket.MaxDays < 0 || days < bucket.MaxDays {
+			return bucket.Name
+		}
+	}
+	return ageUnknown
+}
+
+// sortedCounts returns counts by decreasing count then name, at most limit of them (0 for all)
+func sortedCounts(counts map[string]int, limit int) []reportCount {
+	sorted := []reportCount{}
+	for name, count := range counts {
+		sorted = append(sorted, reportCount{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+// enabledCell renders the number of enabled flags of an application in an environment
+func enabledCell(application applicationReport, environment string) string {
+	if application.FlagCount == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d", application.Enabled[environment], application.FlagCount)
+}
+
+// governanceMarkdown renders the report as Markdown
+func governanceMarkdown(report governanceReport) string {
+	var b strings.Builder
+	b.WriteString("# Feature flag governance report\n\n")
+	fmt.Fprintf(&b, "Generated on %s: %d flags in %d applications (%d temporary, %d permanent), %d stale, ",
+		report.GeneratedAt.Format("2006-01-02"), report.FlagCount, len(report.Applications), report.Temporary, report.Permanent, len(report.Stale))
+	if report.PolicyChecked {
+		fmt.Fprintf(&b, "%d policy violations.\n\n", len(report.Violations))
+	} else {
+		b.WriteString("policy not checked.\n\n")
+	}
+
+	countsTable := func(title, column string, counts []reportCount) {
+		fmt.Fprintf(&b, "## %s\n\n", title)
+		if len(counts) == 0 {
+			b.WriteString("None.\n\n")
+			return
+		}
+		fmt.Fprintf(&b, "| %s | Flags |\n|---|---|\n", column)
+		for _, count := range counts {
+			fmt.Fprintf(&b, "| %s | %d |\n", count.Name, count.Count)
+		}
+		b.WriteString("\n")
+	}
+	countsTable("Flags by type", "Type", report.ByType)
+	countsTable("Flags by age", "Age", report.ByAge)
+	countsTable("Flags by label", "Label", report.ByLabel)
+
+	b.WriteString("## Enabled flags by environment\n\n")
+	b.WriteString("| Application |")
+	for _, env := range report.Environments {
+		fmt.Fprintf(&b, " %s |", env)
+	}
+	b.WriteString("\n|---|" + strings.Repeat("---|", len(report.Environments)) + "\n")
+	for _, application := range report.Applications {
+		fmt.Fprintf(&b, "| %s |", application.Name)
+		for _, env := range report.Environments {
+			fmt.Fprintf(&b, " %s |", enabledCell(application, env))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "## Stale flags\n\n%d temporary flags unchanged for %d days are cleanup candidates.\n\n", len(report.Stale), report.MinAgeDays)
+	if len(report.Stale) > 0 {
+		b.WriteString("| Priority | Application | Flag | Owner | Age (days) | Reasons |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, flag := range report.Stale {
+			owner := flag.Owner
+			if owner == "" {
+				owner = "-"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %d | %s |\n", flag.Priority, flag.Application, flag.FlagName, owner, flag.AgeDays, strings.Join(flag.Reasons, "; "))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Policy violations\n\n")
+	switch {
+	case !report.PolicyChecked:
+		b.WriteString("Not checked (no --policy-file).\n")
+	case len(report.Violations) == 0:
+		b.WriteString("All flags comply with the policy.\n")
+	default:
+		b.WriteString("| Application | Flag | Rule | Message |\n|---|---|---|---|\n")
+		for _, violation := range report.Violations {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", violation.Application, violation.FlagName, violation.Rule, violation.Message)
+		}
+	}
+	return b.String()
+}
+
+// governanceTemplate is the HTML report, a single page without external resources
+var governanceTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell": enabledCell,
+	"counts": func(column string, counts []reportCount) map[string]interface{} {
+		return map[string]interface{}{"Column": column, "Counts": counts}
+	},
+	"heat": func(application applicationReport, environment string) template.CSS {
+		if application.FlagCount == 0 {
+			return "background: #f4f4f4"
+		}
+		ratio := float64(application.Enabled[environment]) / float64(application.FlagCount)
+		return template.CSS(fmt.Sprintf("background: hsl(140, 55%%, %.0f%%)", 96-50*ratio))
+	},
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	"join": strings.Join,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Feature flag governance report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ddd; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+td.heat { text-align: center; }
+.summary { font-size: 1.1em; margin-bottom: 2em; }
+</style>
+</head>
+<body>
+<h1>Feature flag governance report</h1>
+<p class="summary">Generated on {{date .GeneratedAt}}: <b>{{.FlagCount}}</b> flags in {{len .Applications}} applications
+({{.Temporary}} temporary, {{.Permanent}} permanent), <b>{{len .Stale}}</b> stale,
+{{if .PolicyChecked}}<b>{{len .Violations}}</b> policy violations{{else}}policy not checked{{end}}.</p>
+
+{{define "counts"}}<table><tr><th>{{.Column}}</th><th>Flags</th></tr>
+{{range .Counts}}<tr><td>{{.Name}}</td><td>{{.Count}}</td></tr>
+{{else}}<tr><td colspan="2">None</td></tr>
+{{end}}</table>{{end}}
+<h2>Flags by type</h2>
+{{template "counts" (counts "Type" .ByType)}}
+<h2>Flags by age</h2>
+{{template "counts" (counts "Age" .ByAge)}}
+<h2>Flags by label</h2>
+{{template "counts" (counts "Label" .ByLabel)}}
+
+<h2>Enabled flags by environment</h2>
+<table>
+<tr><th>Application</th>{{range .Environments}}<th>{{.}}</th>{{end}}</tr>
+{{$environments := .Environments}}{{range $application := .Applications}}<tr><td>{{$application.Name}}</td>{{range $environments}}<td class="heat" style="{{heat $application .}}">{{cell $application .}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Stale flags</h2>
+<p>{{len .Stale}} temporary flags unchanged for {{.MinAgeDays}} days are cleanup candidates.</p>
+{{if .Stale}}<table>
+<tr><th>Priority</th><th>Application</th><th>Flag</th><th>Owner</th><th>Age (days)</th><th>Reasons</th></tr>
+{{range .Stale}}<tr><td>{{.Priority}}</td><td>{{.Application}}</td><td>{{.FlagName}}</td><td>{{or .Owner "-"}}</td><td>{{.AgeDays}}</td><td>{{join .Reasons "; "}}</td></tr>
+{{end}}</table>{{end}}
+
+<h2>Policy violations</h2>
+{{if not .PolicyChecked}}<p>Not checked (no --policy-file).</p>
+{{else if not .Violations}}<p>All flags comply with the policy.</p>
+{{else}}<table>
+<tr><th>Application</th><th>Flag</th><th>Rule</th><th>Message</th></tr>
+{{range .Violations}}<tr><td>{{.Application}}</td><td>{{.FlagName}}</td><td>{{.Rule}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
+
+// governanceHTML renders the report as an HTML page
+func governanceHTML(report governanceReport) ([]byte, error) {
+	var b bytes.Buffer
+	if err := governanceTemplate.Execute(&b, report); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().Int("min-age-days", 30, "Report temporary flags created and last changed at least this many days ago as stale")
+	reportCmd.Flags().String("policy-file", "", "YAML file with the policy rules to check (see check-policy)")
+	reportCmd.Flags().String("html-file", "", "Write the HTML report to this file")
+	reportCmd.Flags().String("markdown-file", "", "Write the Markdown report to this file")
+	reportCmd.Flags().Int("top-labels", 20, "Number of labels in the flags by label table (0 for all)")
+	addAllApplicationsFlag(reportCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var rollbackFlagConfigCmd = &cobra.Command{
+	Use:   "rollback-flag-config",
+	Short: "Restore a previous revision of a flag configuration",
+	Long: `Restore the configuration of a flag in an environment to a revision listed by config-history.
+The restored configuration is applied as a new revision, through the same guardrails as set-flag-config.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		revisionID, _ := cmd.Flags().GetString("revision")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		force, _ := cmd.Flags().GetBool("force")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		client, ctx, err := commandContext(cmd, flagName, environmentName)
+		if err != nil {
+			return err
+		}
+		application, flag, environment := ctx.Application, ctx.Flag, ctx.Environment
+
+		revision, err := client.GetFlagConfigurationRevision(application.ID, flag.ID, environment.ID, revisionID)
+		if err != nil {
+			return fmt.Errorf("failed to get revision '%s': %w", revisionID, err)
+		}
+
+		// Conditions are always set, so a revision without conditions removes the current ones
+		changes := configurationChanges(revision.Configuration)
+		changes["conditions"] = revision.Configuration.Conditions
+
+		if dryRun {
+			changesJSON, _ := json.MarshalIndent(changes, "", "  ")
+			fmt.Printf("DRY RUN: Would restore flag '%s' in '%s' to revision %s (%s by %s)\n", flag.Name, environmentName, revision.Revision, revision.Timestamp, revision.Actor)
+			fmt.Printf("Configuration:\n%s\n", changesJSON)
+			return nil
+		}
+
+		update, err := updateFlagConfiguration(cmd, client, applicationName, flag.Name, environmentName, changes, "", force)
+		if err != nil {
+			return err
+		}
+
+		// Output results
+		configJSON, _ := json.Marshal(changes)
+		cloudbees.WriteOutput("flag-id", update.Flag.ID)
+		cloudbees.WriteOutput("flag-name", update.Flag.Name)
+		cloudbees.WriteOutput("environment-id", update.Environment.ID)
+		cloudbees.WriteOutput("environment-name", environmentName)
+		cloudbees.WriteOutput("restored-revision", revision.Revision)
+		cloudbees.WriteOutput("configuration", string(configJSON))
+		cloudbees.WriteOutput("success", "true")
+
+		fmt.Printf("Restored flag '%s' in '%s' to revision %s: %s\n", flag.Name, environmentName, revision.Revision, revisionSummary(revision.Configuration))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackFlagConfigCmd)
+
+	rollbackFlagConfigCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	rollbackFlagConfigCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	rollbackFlagConfigCmd.Flags().String("revision", "", "Revision to restore, from config-history (required)")
+	rollbackFlagConfigCmd.Flags().Bool("dry-run", false, "Show the configuration that would be restored without applying it")
+	rollbackFlagConfigCmd.Flags().Bool("force", false, "Skip the concurrent modification check and overwrite remote changes")
+
+	flagNameArg(rollbackFlagConfigCmd)
+
+	rollbackFlagConfigCmd.MarkFlagRequired("flag-name")
+	rollbackFlagConfigCmd.MarkFlagRequired("environment-name")
+	rollbackFlagConfigCmd.MarkFlagRequired("revision")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var scanCodeCmd = &cobra.Command{
+	Use:   "scan-code",
+	Short: "Find references to feature flags in source code",
+	Long: `Walk source trees looking for flag names used as string literals and in SDK calls
+(CloudBees Rox containers, OpenFeature clients) and report which files use each flag.
+Flag names are read from the application unless --flag-names is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths, _ := cmd.Flags().GetStringSlice("path")
+		flagNames, _ := cmd.Flags().GetStringSlice("flag-names")
+
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+
+		if len(flagNames) == 0 {
+			client, ctx, err := commandContext(cmd, "", "")
+			if err != nil {
+				return err
+			}
+			application := ctx.Application
+
+			flags, err := client.ListFlags(application.ID)
+			if err != nil {
+				return fmt.Errorf("failed to list flags: %w", err)
+			}
+			for _, flag := range flags {
+				flagNames = append(flagNames, flag.Name)
+			}
+		}
+
+		result, err := scan.Scan(paths, flagNames)
+		if err != nil {
+			return fmt.Errorf("failed to scan source code: %w", err)
+		}
+
+		// Map each flag to the files using it; unreferenced flags map to an empty list
+		flagFiles := make(map[string][]string, len(flagNames))
+		unreferenced := []string{}
+		for _, name := range flagNames {
+			files := scan.Files(result.References[name])
+			if files == nil {
+				files = []string{}
+				unreferenced = append(unreferenced, name)
+			}
+			flagFiles[name] = files
+		}
+		unknown := []string{}
+		for name := range result.Unknown {
+			unknown = append(unknown, name)
+		}
+		sort.Strings(unreferenced)
+		sort.Strings(unknown)
+
+		// Output results
+		flagFilesJSON, _ := json.Marshal(flagFiles)
+		referencesJSON, _ := json.Marshal(result.References)
+		unreferencedJSON, _ := json.Marshal(unreferenced)
+		unknownJSON, _ := json.Marshal(unknown)
+		cloudbees.WriteOutput("flag-files", string(flagFilesJSON))
+		cloudbees.WriteOutput("references", string(referencesJSON))
+		cloudbees.WriteOutput("referenced-count", fmt.Sprintf("%d", len(flagNames)-len(unreferenced)))
+		cloudbees.WriteOutput("unreferenced-flags", string(unreferencedJSON))
+		cloudbees.WriteOutput("unknown-flags", string(unknownJSON))
+
+		fmt.Printf("Scanned %d flags: %d referenced, %d unreferenced\n", len(flagNames), len(flagNames)-len(unreferenced), len(unreferenced))
+		if verbose {
+			names := make([]string, 0, len(flagFiles))
+			for name := range flagFiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Printf("- %s (%d files)\n", name, len(flagFiles[name]))
+				for _, ref := range result.References[name] {
+					fmt.Printf("  %s:%d [%s] %s\n", ref.File, ref.Line, ref.Kind, ref.Text)
+				}
+			}
+			for _, name := range unknown {
+				fmt.Printf("- %s (SDK usage of unknown flag)\n", name)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCodeCmd)
+
+	scanCodeCmd.Flags().StringSlice("path", nil, "Directory or file to scan (repeatable, defaults to the current directory)")
+	scanCodeCmd.Flags().StringSlice("flag-names", nil, "Flag names to look for instead of reading them from the application")
+}
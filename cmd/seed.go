@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/cloudbees-days/fm-actions-container/internal/seed"
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Create example flags for demos and workshops",
+	Long: `Create a set of example flags with realistic configurations in the environments of the
+application, from the bundled workshop manifest or a manifest file. Seeded flags are labeled, so
+unseed can remove them again. Re-running the command resets the example flags to the manifest.
+Environments of the manifest that do not exist in the organization are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		desired, err := loadSeedManifest(cmd)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		environments, err := client.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		var skipped []string
+		for _, name := range manifestEnvironments(desired) {
+			if _, err := selectEnvironments(environments, []string{name}); err == nil {
+				continue
+			}
+			skipped = append(skipped, name)
+			for i := range desired.Flags {
+				delete(desired.Flags[i].Environments, name)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: environment '%s' does not exist, its configurations are skipped\n", name)
+		}
+
+		changes, err := applyManifest(cmd, client, desired, false, dryRun)
+
+		// Output results, including the changes applied before a failure
+		names := make([]string, 0, len(desired.Flags))
+		for _, flag := range desired.Flags {
+			names = append(names, flag.Name)
+		}
+		namesJSON, _ := json.Marshal(names)
+		changesJSON, _ := json.Marshal(changes)
+		skippedJSON, _ := json.Marshal(skipped)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", len(names)))
+		cloudbees.WriteOutput("flags", string(namesJSON))
+		cloudbees.WriteOutput("change-count", fmt.Sprintf("%d", len(changes)))
+		cloudbees.WriteOutput("changes", string(changesJSON))
+		cloudbees.WriteOutput("skipped-environments", string(skippedJSON))
+		if err != nil {
+			return err
+		}
+
+		if !dryRun {
+			fmt.Printf("Seeded %d example flags in '%s' (%d changes)\n", len(names), desired.Application, len(changes))
+		}
+		return nil
+	},
+}
+
+// loadSeedManifest returns the example flags to seed or unseed: the bundled workshop manifest or
+// --manifest, patched with the --overlay files, with --prefix applied to the flag names and the
+// seed label added
+func loadSeedManifest(cmd *cobra.Command) (*manifest.Manifest, error) {
+	manifestPath, _ := cmd.Flags().GetString("manifest")
+	overlays, _ := cmd.Flags().GetStringSlice("overlay")
+	prefix, _ := cmd.Flags().GetString("prefix")
+	applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+	values, err := templateValues(cmd)
+	if err != nil {
+		return nil, err
+	}
+	var m *manifest.Manifest
+	if manifestPath == "" {
+		m, err = seed.Workshop()
+	} else {
+		m, err = manifest.LoadTemplate(manifestPath, values)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m, err = applyOverlays(m, "", overlays, values); err != nil {
+		return nil, err
+	}
+
+	if applicationName != "" {
+		m.Application = applicationName
+	}
+	for i := range m.Flags {
+		m.Flags[i].Name = prefix + m.Flags[i].Name
+		if !containsString(m.Flags[i].Labels, seed.Label) {
+			m.Flags[i].Labels = append(m.Flags[i].Labels, seed.Label)
+		}
+	}
+	return m, nil
+}
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().String("manifest", "", "Manifest file with the flags to create (defaults to the bundled workshop flags)")
+	seedCmd.Flags().String("prefix", "", "Prefix added to the flag names, e.g. one per workshop attendee")
+	seedCmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	seedCmd.Flags().Bool("no-rollback", false, "Keep the changes applied before a failure instead of reverting them")
+	stateFlags(seedCmd)
+	seedCmd.Flags().StringSlice("overlay", nil, "Overlays merged into the manifest in order, e.g. the patches of one environment")
+	templateFlags(seedCmd)
+}
@@ -0,0 +1,425 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/approval"
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/policy"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// shutdownTimeout is how long in-flight requests may take to finish when the server stops
+const shutdownTimeout = 30 * time.Second
+
+// errUnauthorized is returned for requests without credentials
+var errUnauthorized = errors.New("missing bearer token")
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP server exposing flag operations as a REST API",
+	Long: `Run a REST API wrapping the flag operations, for internal tools and ChatOps bots.
+Each request authenticates with "Authorization: Bearer <CloudBees API token>", which is passed
+through to the platform, so callers only see what their own token allows.
+
+  GET    /healthz
+  GET    /v1/environments
+  GET    /v1/applications/{application}/flags
+  POST   /v1/applications/{application}/flags
+  GET    /v1/applications/{application}/flags/{flag}
+  DELETE /v1/applications/{application}/flags/{flag}
+  GET    /v1/applications/{application}/flags/{flag}/environments/{environment}
+  PATCH  /v1/applications/{application}/flags/{flag}/environments/{environment}
+
+Configuration changes honor If-Match with the revision (ETag) returned by GET. Policy, approval,
+audit and notification settings apply to changes made through the server.
+
+With --grpc-addr, the same operations are also served over gRPC, as the FlagService of
+api/proto/fm/v1/flags.proto, with the token in the "authorization" metadata.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		grpcAddr, _ := cmd.Flags().GetString("grpc-addr")
+		fallbackToken, _ := cmd.Flags().GetString("token")
+		fallbackTokenProvider, _ := cmd.Flags().GetBool("fallback-token-provider")
+		if fallbackTokenProvider {
+			if fallbackToken != "" {
+				return fmt.Errorf("token cannot be used with fallback-token-provider")
+			}
+			if tokenProvider == nil {
+				return fmt.Errorf("fallback-token-provider requires token-file, token-command, oidc-token-file or oidc-github-actions")
+			}
+			// Renewed with --token-file, --token-command or the OIDC token exchange
+			fallbackToken = providedToken
+		}
+		if fallbackToken != "" {
+			fmt.Fprintln(os.Stderr, "Warning: requests without an Authorization header run with the token of the server")
+		}
+
+		server := &http.Server{
+			Addr:              addr,
+			Handler:           newRESTHandler(cmd, fallbackToken),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		var grpcServer *grpc.Server
+		var grpcListener net.Listener
+		if grpcAddr != "" {
+			var err error
+			if grpcListener, err = net.Listen("tcp", grpcAddr); err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
+			}
+			grpcServer = newGRPCServer(cmd, fallbackToken)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		errs := make(chan error, 2)
+		go func() {
+			fmt.Printf("Serving flag API on %s\n", addr)
+			errs <- server.ListenAndServe()
+		}()
+		if grpcServer != nil {
+			go func() {
+				fmt.Printf("Serving flag gRPC API on %s\n", grpcListener.Addr())
+				errs <- grpcServer.Serve(grpcListener)
+			}()
+		}
+
+		select {
+		case err := <-errs:
+			if grpcServer != nil {
+				grpcServer.Stop()
+			}
+			return err
+		case <-ctx.Done():
+			fmt.Println("Shutting down")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if grpcServer != nil {
+				stopped := make(chan struct{})
+				go func() {
+					grpcServer.GracefulStop()
+					close(stopped)
+				}()
+				select {
+				case <-stopped:
+				case <-shutdownCtx.Done():
+					grpcServer.Stop()
+				}
+			}
+			return server.Shutdown(shutdownCtx)
+		}
+	},
+}
+
+// restHandler serves the REST API
+type restHandler struct {
+	cmd           *cobra.Command
+	fallbackToken string
+}
+
+// newRESTHandler returns the REST API routes
+func newRESTHandler(cmd *cobra.Command, fallbackToken string) http.Handler {
+	h := &restHandler{cmd: cmd, fallbackToken: fallbackToken}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /v1/environments", h.listEnvironments)
+	mux.HandleFunc("GET /v1/applications/{application}/flags", h.listFlags)
+	mux.HandleFunc("POST /v1/applications/{application}/flags", h.createFlag)
+	mux.HandleFunc("GET /v1/applications/{application}/flags/{flag}", h.getFlag)
+	mux.HandleFunc("DELETE /v1/applications/{application}/flags/{flag}", h.deleteFlag)
+	mux.HandleFunc("GET /v1/applications/{application}/flags/{flag}/environments/{environment}", h.getFlagConfig)
+	mux.HandleFunc("PATCH /v1/applications/{application}/flags/{flag}/environments/{environment}", h.setFlagConfig)
+	return mux
+}
+
+// client creates a client authenticated with the caller's bearer token
+func (h *restHandler) client(r *http.Request) (cloudbees.API, error) {
+	return bearerClient(h.cmd, r.Header.Get("Authorization"), h.fallbackToken)
+}
+
+// bearerClient creates a client authenticated with the token of an Authorization value, or with
+// fallbackToken when there is none
+func bearerClient(cmd *cobra.Command, authorization, fallbackToken string) (cloudbees.API, error) {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == "" || token == authorization {
+		token = fallbackToken
+	}
+	if token == "" {
+		return nil, errUnauthorized
+	}
+	return newClientWithToken(cmd, token)
+}
+
+func (h *restHandler) listEnvironments(w http.ResponseWriter, r *http.Request) {
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	environments, err := client.ListEnvironments()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"environments": environments})
+}
+
+func (h *restHandler) listFlags(w http.ResponseWriter, r *http.Request) {
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	application, err := client.GetApplicationByName(r.PathValue("application"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	labels := r.URL.Query()["label"]
+	selected := []cloudbees.Flag{}
+	for _, flag := range flags {
+		if hasAnyLabel(flag, labels) {
+			selected = append(selected, flag)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flags": selected})
+}
+
+func (h *restHandler) getFlag(w http.ResponseWriter, r *http.Request) {
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	application, err := client.GetApplicationByName(r.PathValue("application"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flag, err := client.GetFlagByName(application.ID, r.PathValue("flag"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flag": flag})
+}
+
+func (h *restHandler) createFlag(w http.ResponseWriter, r *http.Request) {
+	var request cloudbees.CreateFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if request.Name == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
+		return
+	}
+	if request.FlagType == "" {
+		request.FlagType = "Boolean"
+	}
+	if len(request.Variants) == 0 && request.FlagType == "Boolean" {
+		request.Variants = []string{"true", "false"}
+	}
+
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	application, err := client.GetApplicationByName(r.PathValue("application"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	change := mutation{
+		Operation:   "create-flag",
+		Application: application.Name,
+		Flag:        request.Name,
+		Labels:      request.Labels,
+		Changes: map[string]interface{}{
+			"flagType":    request.FlagType,
+			"variants":    request.Variants,
+			"description": request.Description,
+			"isPermanent": request.IsPermanent,
+		},
+	}
+	if err := beforeMutation(h.cmd, change); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flag, err := client.CreateFlagFromRequest(application.ID, request)
+	change.After = flag
+	afterMutation(h.cmd, change, err)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"flag": flag})
+}
+
+func (h *restHandler) deleteFlag(w http.ResponseWriter, r *http.Request) {
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	application, err := client.GetApplicationByName(r.PathValue("application"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flag, err := client.GetFlagByName(application.ID, r.PathValue("flag"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	change := mutation{
+		Operation:   "delete-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Before:      flag,
+	}
+	if err := beforeMutation(h.cmd, change); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	err = client.DeleteFlag(application.ID, flag.ID)
+	afterMutation(h.cmd, change, err)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *restHandler) getFlagConfig(w http.ResponseWriter, r *http.Request) {
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	application, err := client.GetApplicationByName(r.PathValue("application"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	flag, err := client.GetFlagByName(application.ID, r.PathValue("flag"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	environment, err := client.GetEnvironmentByName(r.PathValue("environment"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	config, err := client.GetFlagConfiguration(application.ID, flag.ID, environment.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", config.Revision)
+	writeJSON(w, http.StatusOK, config)
+}
+
+func (h *restHandler) setFlagConfig(w http.ResponseWriter, r *http.Request) {
+	var changes map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+		return
+	}
+	if len(changes) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no configuration changes specified"})
+		return
+	}
+
+	client, err := h.client(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	update, err := updateFlagConfiguration(h.cmd, client, r.PathValue("application"), r.PathValue("flag"),
+		r.PathValue("environment"), changes, r.Header.Get("If-Match"), false)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"configuration": update.After})
+}
+
+// writeError responds with the HTTP status matching err
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusBadGateway
+	var apiErr *cloudbees.APIError
+	switch {
+	case errors.Is(err, errUnauthorized):
+		status = http.StatusUnauthorized
+	case errors.Is(err, cloudbees.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, cloudbees.ErrConflict):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, policy.ErrViolation), errors.Is(err, approval.ErrNotApproved), errors.Is(err, cloudbees.ErrReadOnly):
+		status = http.StatusForbidden
+	case errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden):
+		status = apiErr.StatusCode
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeJSON writes value as a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("addr", ":8080", "Address to listen on")
+	serveCmd.Flags().String("grpc-addr", "", "Also serve the flag operations over gRPC (api/proto/fm/v1/flags.proto) on this address, e.g. :9090")
+	// Shadows the required global flag: tokens normally come from each request
+	serveCmd.Flags().String("token", "", "API token for requests without an Authorization header (optional)")
+	serveCmd.Flags().Bool("fallback-token-provider", false, "Use the token of --token-file, --token-command or the OIDC token exchange for requests without an Authorization header")
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"os"
 	"strconv"
+	"time"
 
 	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/evaluate"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -13,7 +17,13 @@ import (
 var setFlagConfigCmd = &cobra.Command{
 	Use:   "set-flag-config",
 	Short: "Set feature flag configuration",
-	Long:  `Set feature flag configuration (enable/disable flag, set default value) for a target environment.`,
+	Long: `Set feature flag configuration (enable/disable flag, set default value) for a target environment.
+
+Targeting conditions can be built with --when, once per condition in evaluation order, e.g.
+  --when 'property=plan op=in value=enterprise,team serve=true'
+Operators: is, is-not, in, not-in, matches (regex), lt, lte, gt, gte, is-true, is-false, is-undefined,
+and semver-eq, semver-gt, semver-gte, semver-lt, semver-lte. serve must be a variant of the flag.
+The properties must exist in the application, and --when replaces the existing conditions.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		flagName, _ := cmd.Flags().GetString("flag-name")
 		environmentName, _ := cmd.Flags().GetString("environment-name")
@@ -23,6 +33,15 @@ var setFlagConfigCmd = &cobra.Command{
 		stickinessProperty, _ := cmd.Flags().GetString("stickiness-property")
 		configYAML, _ := cmd.Flags().GetString("config")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		validate, _ := cmd.Flags().GetBool("validate")
+		ifMatch, _ := cmd.Flags().GetString("if-match")
+		force, _ := cmd.Flags().GetBool("force")
+		when, _ := cmd.Flags().GetStringArray("when")
+		wait, _ := cmd.Flags().GetBool("wait")
+		waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+		skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+		normalizeWeights, _ := cmd.Flags().GetBool("normalize-weights")
+		skipPrerequisites, _ := cmd.Flags().GetBool("skip-prerequisites")
 
 		if flagName == "" {
 			return fmt.Errorf("flag-name is required")
@@ -31,24 +50,27 @@ var setFlagConfigCmd = &cobra.Command{
 			return fmt.Errorf("environment-name is required")
 		}
 
-		// Get authentication parameters from root command
-		apiURL, _ := cmd.Root().PersistentFlags().GetString("api-url")
-		token, _ := cmd.Root().PersistentFlags().GetString("token")
-		orgID, _ := cmd.Root().PersistentFlags().GetString("org-id")
 		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
-		useOrgAsApp, _ := cmd.Root().PersistentFlags().GetBool("use-org-as-app")
 
-		client, err := cloudbees.NewClientWithOptions(apiURL, token, orgID, useOrgAsApp)
+		client, err := newClient(cmd)
 		if err != nil {
-			return fmt.Errorf("failed to create CloudBees client: %w", err)
+			return err
 		}
 
 		// Build configuration map with only the fields that were specified
 		configChanges := make(map[string]interface{})
 
-		// Parse and apply configuration from YAML if provided
+		// Render, parse and apply configuration from YAML if provided
 		if configYAML != "" {
-			if err := yaml.Unmarshal([]byte(configYAML), &configChanges); err != nil {
+			values, err := templateValues(cmd)
+			if err != nil {
+				return err
+			}
+			rendered, err := manifest.Render("config", []byte(configYAML), values)
+			if err != nil {
+				return err
+			}
+			if err := yaml.Unmarshal(rendered, &configChanges); err != nil {
 				return fmt.Errorf("failed to parse config YAML: %w", err)
 			}
 		}
@@ -84,6 +106,57 @@ var setFlagConfigCmd = &cobra.Command{
 			configChanges["stickinessProperty"] = stickinessProperty
 		}
 
+		// The flag is needed to build conditions and to validate the default value. Dry runs stay
+		// offline unless --validate is set, and keep the serve variants of conditions as given.
+		_, hasDefaultValue := configChanges["defaultValue"]
+		if hasDefaultValue && normalizeWeights {
+			if err := normalizeSplit(configChanges["defaultValue"]); err != nil {
+				return err
+			}
+		}
+		var application *cloudbees.Application
+		var flag *cloudbees.Flag
+		if (!dryRun || validate) && (len(when) > 0 || (hasDefaultValue && !skipValidation)) {
+			application, err = getApplication(cmd, client, applicationName)
+			if err != nil {
+				return err
+			}
+			flag, err = client.GetFlagByName(application.ID, flagName)
+			if err != nil {
+				return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+			}
+
+			if hasDefaultValue && !skipValidation {
+				// --default-value is parsed as JSON, but 42 for a String flag means the text "42"
+				if flag.FlagType == "String" && defaultValue != "" {
+					switch configChanges["defaultValue"].(type) {
+					case bool, float64:
+						configChanges["defaultValue"] = defaultValue
+					}
+				}
+				if err := validateDefaultValue(flag, configChanges["defaultValue"]); err != nil {
+					return fmt.Errorf("%w (use --skip-validation to send it anyway)", err)
+				}
+			}
+		}
+
+		if len(when) > 0 {
+			conditions := make([]evaluate.Condition, 0, len(when))
+			for _, expr := range when {
+				condition, err := parseWhen(expr, flag)
+				if err != nil {
+					return err
+				}
+				conditions = append(conditions, condition)
+			}
+			if flag != nil {
+				if err := validateConditionProperties(client, application.ID, conditions); err != nil {
+					return err
+				}
+			}
+			configChanges["conditions"] = conditions
+		}
+
 		// Ensure we have at least one field to update
 		if len(configChanges) == 0 {
 			return fmt.Errorf("no configuration changes specified")
@@ -97,41 +170,32 @@ var setFlagConfigCmd = &cobra.Command{
 			return nil
 		}
 
-		// Only do API calls for real execution (not dry-run)
-		// First, get the application to retrieve its ID
-		application, err := client.GetApplicationByName(applicationName)
-		if err != nil {
-			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
-		}
-
-		// Get the flag to retrieve its ID
-		flag, err := client.GetFlagByName(application.ID, flagName)
-		if err != nil {
-			return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+		// Prerequisites must be enabled before a flag is enabled
+		if enabling, _ := configChanges["enabled"].(bool); enabling && !skipPrerequisites {
+			ctx, err := resolveContext(cmd, client, applicationName, flagName, environmentName)
+			if err != nil {
+				return err
+			}
+			if err := checkPrerequisites(client, ctx.Application.ID, ctx.Flag, ctx.Environment); err != nil {
+				return err
+			}
 		}
 
-		// Get all environments to find the one that matches the name
-		environments, err := client.ListEnvironments()
+		update, err := updateFlagConfiguration(cmd, client, applicationName, flagName, environmentName, configChanges, ifMatch, force)
 		if err != nil {
-			return fmt.Errorf("failed to list environments: %w", err)
+			return err
 		}
+		application, flag, environmentID := update.Application, update.Flag, update.Environment.ID
 
-		var environmentID string
-		for _, env := range environments {
-			if env.Name == environmentName {
-				environmentID = env.ID
-				break
+		propagated := false
+		if wait {
+			propagated, err = waitForConfiguration(client, update, configChanges, waitTimeout)
+			if err != nil {
+				return err
+			}
+			if !propagated {
+				fmt.Fprintf(os.Stderr, "Warning: the change of flag '%s' was not observed in '%s' after %s\n", flag.Name, environmentName, waitTimeout)
 			}
-		}
-
-		if environmentID == "" {
-			return fmt.Errorf("environment '%s' not found", environmentName)
-		}
-
-		// Set flag configuration using PUT with only specified fields
-		err = client.SetFlagConfiguration(application.ID, flag.ID, environmentID, configChanges)
-		if err != nil {
-			return fmt.Errorf("failed to set flag configuration: %w", err)
 		}
 
 		// Output results
@@ -146,6 +210,9 @@ var setFlagConfigCmd = &cobra.Command{
 		if enabled, ok := configChanges["enabled"].(bool); ok {
 			cloudbees.WriteOutput("enabled", fmt.Sprintf("%t", enabled))
 		}
+		if wait {
+			cloudbees.WriteOutput("propagated", fmt.Sprintf("%t", propagated))
+		}
 		cloudbees.WriteOutput("success", "true")
 
 		if verbose {
@@ -170,10 +237,21 @@ func init() {
 	setFlagConfigCmd.Flags().String("default-value", "", "Default value for the flag (JSON or string)")
 	setFlagConfigCmd.Flags().String("variants-enabled", "", "Enable/disable variants (true/false)")
 	setFlagConfigCmd.Flags().String("stickiness-property", "", "Stickiness property for consistent evaluation")
-	setFlagConfigCmd.Flags().String("config", "", "Complete configuration as YAML")
-	setFlagConfigCmd.Flags().Bool("dry-run", false, "Validate configuration without applying changes")
+	setFlagConfigCmd.Flags().String("config", "", "Complete configuration as YAML, rendered with --set and --values")
+	setFlagConfigCmd.Flags().StringArray("when", nil, "Targeting condition as 'property=<name> op=<operator> value=<value> serve=<variant>' (repeatable, replaces the conditions)")
+	setFlagConfigCmd.Flags().Bool("dry-run", false, "Print the configuration changes without applying them, without calling the API")
+	setFlagConfigCmd.Flags().Bool("validate", false, "With --dry-run, check the default value and --when conditions against the flag (calls the API)")
+	setFlagConfigCmd.Flags().Bool("skip-validation", false, "Send the default value without checking it against the flag type and variants")
+	setFlagConfigCmd.Flags().Bool("normalize-weights", false, "Scale the percentages of a percentage split default value to sum to 100")
+	setFlagConfigCmd.Flags().Bool("skip-prerequisites", false, "Enable the flag even when flags it requires (requires: labels) are disabled in the environment")
+	setFlagConfigCmd.Flags().String("if-match", "", "Only update if the current configuration revision matches (from get-flag-config)")
+	setFlagConfigCmd.Flags().Bool("force", false, "Skip the concurrent modification check and overwrite remote changes")
+	setFlagConfigCmd.Flags().Bool("wait", false, "Wait until the change is observable when reading the configuration back")
+	setFlagConfigCmd.Flags().Duration("wait-timeout", 2*time.Minute, "How long --wait polls for the change")
+	templateFlags(setFlagConfigCmd)
+
+	flagNamesArg(setFlagConfigCmd)
 
 	setFlagConfigCmd.MarkFlagRequired("flag-name")
 	setFlagConfigCmd.MarkFlagRequired("environment-name")
-	setFlagConfigCmd.MarkPersistentFlagRequired("application-name")
 }
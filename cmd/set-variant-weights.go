@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var setVariantWeightsCmd = &cobra.Command{
+	Use:   "set-variant-weights",
+	Short: "Set the percentage split of a flag between its variants",
+	Long: `Set the default value of a flag in an environment to a percentage split between its variants,
+e.g. --weights true=30,false=70. Weights must be variants of the flag and sum to 100; variants with
+a weight of 0 are left out of the split. With --normalize-weights, weights are relative and scaled
+to sum to 100.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		weights, _ := cmd.Flags().GetStringToString("weights")
+		stickinessProperty, _ := cmd.Flags().GetString("stickiness-property")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		ifMatch, _ := cmd.Flags().GetString("if-match")
+		force, _ := cmd.Flags().GetBool("force")
+		normalize, _ := cmd.Flags().GetBool("normalize-weights")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if len(weights) == 0 {
+			return fmt.Errorf("weights are required")
+		}
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		flag := ctx.Flag
+
+		if normalize {
+			if weights, err = normalizeWeights(weights); err != nil {
+				return err
+			}
+		}
+		split, err := percentageSplit(flag, weights)
+		if err != nil {
+			return err
+		}
+		changes := map[string]interface{}{"defaultValue": split}
+		if stickinessProperty != "" {
+			changes["stickinessProperty"] = stickinessProperty
+		}
+
+		splitJSON, _ := json.Marshal(split)
+		if dryRun {
+			fmt.Printf("DRY RUN: Would set the split of flag '%s' in '%s' to %s\n", flag.Name, environmentName, splitJSON)
+			return nil
+		}
+
+		update, err := updateFlagConfiguration(cmd, client, applicationName, flag.Name, environmentName, changes, ifMatch, force)
+		if err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("flag-id", update.Flag.ID)
+		cloudbees.WriteOutput("flag-name", update.Flag.Name)
+		cloudbees.WriteOutput("environment-id", update.Environment.ID)
+		cloudbees.WriteOutput("environment-name", environmentName)
+		cloudbees.WriteOutput("split", string(splitJSON))
+		cloudbees.WriteOutput("success", "true")
+
+		fmt.Printf("Set the split of flag '%s' in '%s' to %s\n", flag.Name, environmentName, splitJSON)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(setVariantWeightsCmd)
+
+	setVariantWeightsCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	setVariantWeightsCmd.Flags().StringP("environment-name", "e", "", "Environment name (required)")
+	setVariantWeightsCmd.Flags().StringToString("weights", nil, "Percentage of each variant, e.g. true=30,false=70 (required)")
+	setVariantWeightsCmd.Flags().String("stickiness-property", "", "Context property used to bucket users, e.g. userId")
+	setVariantWeightsCmd.Flags().Bool("normalize-weights", false, "Scale the weights to sum to 100, e.g. true=1,false=3 is a 25/75 split")
+	setVariantWeightsCmd.Flags().Bool("dry-run", false, "Show the split without applying it")
+	setVariantWeightsCmd.Flags().String("if-match", "", "Only update if the current configuration revision matches (from get-flag-config)")
+	setVariantWeightsCmd.Flags().Bool("force", false, "Skip the concurrent modification check and overwrite remote changes")
+
+	flagNameArg(setVariantWeightsCmd)
+
+	setVariantWeightsCmd.MarkFlagRequired("flag-name")
+	setVariantWeightsCmd.MarkFlagRequired("environment-name")
+	setVariantWeightsCmd.MarkFlagRequired("weights")
+}
+
+// normalizeWeights scales variant weights to sum to 100
+func normalizeWeights(weights map[string]string) (map[string]string, error) {
+	variants := make([]string, 0, len(weights))
+	for variant := range weights {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	percentages := make([]float64, len(variants))
+	for i, variant := range variants {
+		percentage, err := strconv.ParseFloat(weights[variant], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight '%s' for variant '%s', must be a number", weights[variant], variant)
+		}
+		percentages[i] = percentage
+	}
+	normalized, err := normalizePercentages(percentages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(variants))
+	for i, variant := range variants {
+		result[variant] = strconv.FormatFloat(normalized[i], 'f', -1, 64)
+	}
+	return result, nil
+}
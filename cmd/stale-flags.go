@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/workerpool"
+	"github.com/spf13/cobra"
+)
+
+// Cleanup priorities of stale flags, highest first
+const (
+	priorityHigh   = "high"   // Disabled everywhere: safe to delete
+	priorityMedium = "medium" // Fully rolled out: hardcode the value, then delete
+)
+
+// staleFlag describes a flag that is a candidate for cleanup
+type staleFlag struct {
+	Application string   `json:"application,omitempty"` // Set with --all-applications
+	FlagName    string   `json:"flagName"`
+	FlagID      string   `json:"flagId"`
+	Owner       string   `json:"owner,omitempty"`
+	AgeDays     int      `json:"ageDays,omitempty"`
+	Priority    string   `json:"priority"`
+	Reasons     []string `json:"reasons"`
+}
+
+var staleFlagsCmd = &cobra.Command{
+	Use:   "stale-flags",
+	Short: "Report temporary flags that are candidates for cleanup",
+	Long: `Identify temporary (non-permanent) flags that are older than a threshold, have not changed
+recently, and are either fully rolled out (100% one value) or disabled in every environment.
+The prioritized report is written as JSON and Markdown. With --all-applications, every application
+is checked concurrently and the report has a section per application.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minAgeDays, _ := cmd.Flags().GetInt("min-age-days")
+		markdownFile, _ := cmd.Flags().GetString("markdown-file")
+		allApplications, _ := cmd.Flags().GetBool("all-applications")
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		applications, err := targetApplications(cmd, client)
+		if err != nil {
+			return err
+		}
+
+		environments, err := client.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		environments, _ = selectEnvironments(environments, nil)
+
+		now := time.Now()
+		results := workerpool.Run(applications, func(app cloudbees.Application) string { return app.Name }, poolOptions(cmd),
+			func(application cloudbees.Application) (staleFlagsReport, error) {
+				return findStaleFlags(cmd, client, application, environments, minAgeDays, now)
+			})
+		if err := applicationsErr(results); err != nil {
+			return err
+		}
+
+		stale := []staleFlag{}
+		flagCount := 0
+		var sections []string
+		for _, result := range results {
+			report := result.Value
+			for _, flag := range report.Stale {
+				if allApplications {
+					flag.Application = result.Name
+				}
+				stale = append(stale, flag)
+			}
+			flagCount += report.FlagCount
+			sections = append(sections, staleFlagsMarkdown(result.Name, report.Stale, report.FlagCount, minAgeDays))
+		}
+		markdown := strings.Join(sections, "\n")
+
+		if markdownFile != "" {
+			if err := os.WriteFile(markdownFile, []byte(markdown), 0644); err != nil {
+				return fmt.Errorf("failed to write markdown report: %w", err)
+			}
+		}
+
+		// Output results
+		staleJSON, _ := json.Marshal(stale)
+		cloudbees.WriteOutput("flag-count", fmt.Sprintf("%d", flagCount))
+		cloudbees.WriteOutput("stale-count", fmt.Sprintf("%d", len(stale)))
+		cloudbees.WriteOutput("stale-flags", string(staleJSON))
+		writeArtifact(cmd, "stale-flags", stale)
+		cloudbees.WriteOutput("report-markdown", markdown)
+		if allApplications {
+			cloudbees.WriteOutput("application-count", fmt.Sprintf("%d", len(applications)))
+		}
+
+		if verbose || markdownFile == "" {
+			fmt.Print(markdown)
+		}
+
+		return nil
+	},
+}
+
+// staleFlagsReport holds the stale flags of one application
+type staleFlagsReport struct {
+	Stale     []staleFlag
+	FlagCount int
+}
+
+// findStaleFlags evaluates the temporary flags of an application, returning the stale ones by priority and age
+func findStaleFlags(cmd *cobra.Command, client cloudbees.API, application cloudbees.Application, environments []cloudbees.Environment,
+	minAgeDays int, now time.Time) (staleFlagsReport, error) {
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		return staleFlagsReport{}, fmt.Errorf("failed to list flags: %w", err)
+	}
+
+	var temporary []cloudbees.Flag
+	for _, flag := range flags {
+		if !flag.IsPermanent {
+			temporary = append(temporary, flag)
+		}
+	}
+
+	threshold := now.AddDate(0, 0, -minAgeDays)
+	results := workerpool.Run(temporary, func(flag cloudbees.Flag) string { return flag.Name }, poolOptions(cmd),
+		func(flag cloudbees.Flag) (*staleFlag, error) {
+			configs := make([]cloudbees.FlagConfiguration, 0, len(environments))
+			for _, env := range environments {
+				config, err := client.GetFlagConfiguration(application.ID, flag.ID, env.ID)
+				if err != nil {
+					return nil, err
+				}
+				configs = append(configs, config.Configuration)
+			}
+			return evaluateStaleness(flag, configs, threshold, now), nil
+		})
+	if err := results.Err(); err != nil {
+		return staleFlagsReport{}, fmt.Errorf("failed to evaluate flags: %w", err)
+	}
+
+	stale := []staleFlag{}
+	for _, result := range results {
+		if result.Value != nil {
+			stale = append(stale, *result.Value)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		if stale[i].Priority != stale[j].Priority {
+			return stale[i].Priority == priorityHigh
+		}
+		return stale[i].AgeDays > stale[j].AgeDays
+	})
+
+	return staleFlagsReport{Stale: stale, FlagCount: len(flags)}, nil
+}
+
+// evaluateStaleness returns a cleanup candidate for the flag, or nil if it is not stale
+func evaluateStaleness(flag cloudbees.Flag, configs []cloudbees.FlagConfiguration, threshold, now time.Time) *staleFlag {
+	// Flags created or changed after the threshold are still in active use
+	ageDays := 0
+	if created, err := time.Parse(time.RFC3339, flag.Created); err == nil {
+		if created.After(threshold) {
+			return nil
+		}
+		ageDays = int(now.Sub(created).Hours() / 24)
+	}
+	if updated, err := time.Parse(time.RFC3339, flag.Updated); err == nil && updated.After(threshold) {
+		return nil
+	}
+
+	disabledEverywhere := true
+	rolledOut := len(configs) > 0
+	var rolledOutValue interface{}
+	for i, config := range configs {
+		if config.Enabled {
+			disabledEverywhere = false
+		}
+		value, single := singleValue(config.DefaultValue)
+		if !config.Enabled || !single || (i > 0 && fmt.Sprint(value) != fmt.Sprint(rolledOutValue)) {
+			rolledOut = false
+		}
+		rolledOutValue = value
+	}
+
+	result := &staleFlag{FlagName: flag.Name, FlagID: flag.ID, Owner: flag.Owner(), AgeDays: ageDays}
+	switch {
+	case disabledEverywhere:
+		result.Priority = priorityHigh
+		result.Reasons = append(result.Reasons, "disabled in every environment")
+	case rolledOut:
+		result.Priority = priorityMedium
+		result.Reasons = append(result.Reasons, fmt.Sprintf("fully rolled out to %v in every environment", rolledOutValue))
+	default:
+		return nil
+	}
+
+	if flag.IsExpired(now) {
+		expires, _ := flag.Expires()
+		result.Reasons = append(result.Reasons, "expired on "+expires.Format(cloudbees.ExpiryDateFormat))
+	}
+	return result
+}
+
+// singleValue reports whether a default value serves a single value to everyone.
+// Percentage splits ([{option, percentage}]) count as single only when one option has 100%.
+func singleValue(defaultValue interface{}) (interface{}, bool) {
+	split, ok := defaultValue.([]interface{})
+	if !ok {
+		return defaultValue, defaultValue != nil
+	}
+
+	for _, entry := range split {
+		option, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if percentage, ok := option["percentage"].(float64); ok && percentage >= 100 {
+			return option["option"], true
+		}
+	}
+	return nil, false
+}
+
+// staleFlagsMarkdown renders the stale flags report as Markdown
+func staleFlagsMarkdown(applicationName string, stale []staleFlag, total, minAgeDays int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Stale flags report: %s\n\n", applicationName)
+	fmt.Fprintf(&b, "%d of %d flags are cleanup candidates (temporary, unchanged for %d days).\n\n", len(stale), total, minAgeDays)
+	if len(stale) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("| Priority | Flag | Owner | Age (days) | Reasons |\n")
+	b.WriteString("|----------|------|-------|------------|---------|\n")
+	for _, flag := range stale {
+		owner := flag.Owner
+		if owner == "" {
+			owner = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s |\n", flag.Priority, flag.FlagName, owner, flag.AgeDays, strings.Join(flag.Reasons, "; "))
+	}
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(staleFlagsCmd)
+
+	staleFlagsCmd.Flags().Int("min-age-days", 30, "Only report flags created and last changed at least this many days ago")
+	staleFlagsCmd.Flags().String("markdown-file", "", "Write the Markdown report to this file")
+	addAllApplicationsFlag(staleFlagsCmd)
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// bulkState is the progress of a bulk command, saved to --state-file after every completed item
+// so an interrupted run can continue with --resume instead of starting over. A nil state, without
+// --state-file, records nothing.
+type bulkState struct {
+	mu        sync.Mutex
+	file      string
+	command   string
+	completed map[string]bool
+}
+
+// bulkStateFile is the content of a state file
+type bulkStateFile struct {
+	Command   string   `json:"command"`
+	Completed []string `json:"completed"`
+}
+
+// stateFlags adds --state-file and --resume to a bulk command
+func stateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("state-file", "", "Record the completed items in this file, so an interrupted run can be resumed (disables the rollback)")
+	cmd.Flags().Bool("resume", false, "Skip the items completed by a previous run, as recorded in --state-file")
+}
+
+// loadBulkState returns the state of --state-file, with the items completed before when resuming
+func loadBulkState(cmd *cobra.Command) (*bulkState, error) {
+	file, _ := cmd.Flags().GetString("state-file")
+	resume, _ := cmd.Flags().GetBool("resume")
+	if file == "" {
+		if resume {
+			return nil, fmt.Errorf("resume requires state-file")
+		}
+		return nil, nil
+	}
+
+	state := &bulkState{file: file, command: commandName(cmd), completed: map[string]bool{}}
+	if resume {
+		data, err := os.ReadFile(file)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// Interrupted before the first item completed
+		case err != nil:
+			return nil, fmt.Errorf("failed to read state file: %w", err)
+		default:
+			var saved bulkStateFile
+			if err := json.Unmarshal(data, &saved); err != nil {
+				return nil, fmt.Errorf("failed to parse state file '%s': %w", file, err)
+			}
+			if saved.Command != state.command {
+				return nil, fmt.Errorf("state file '%s' was written by %s, not %s", file, saved.Command, state.command)
+			}
+			for _, key := range saved.Completed {
+				state.completed[key] = true
+			}
+			fmt.Printf("Resuming %s: %d items were completed before\n", state.command, len(saved.Completed))
+		}
+	}
+	return state, state.save()
+}
+
+// done reports whether an item was completed, by this or a previous run
+func (s *bulkState) done(key string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.completed[key]
+}
+
+// complete records a completed item. Failing to save the state only prints a warning, since the
+// change itself was applied.
+func (s *bulkState) complete(key string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed[key] = true
+	if err := s.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// finish removes the state file once every item completed, so the next run starts over
+func (s *bulkState) finish() {
+	if s == nil {
+		return
+	}
+	if err := os.Remove(s.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove state file: %v\n", err)
+	}
+}
+
+// save writes the state file atomically; the caller holds the lock or owns the state
+func (s *bulkState) save() error {
+	saved := bulkStateFile{Command: s.command, Completed: make([]string, 0, len(s.completed))}
+	for key := range s.completed {
+		saved.Completed = append(saved.Completed, key)
+	}
+	sort.Strings(saved.Completed)
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.file), ".state-*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.file); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// configurationKey identifies a configuration update in a state file
+func configurationKey(update pendingConfiguration) string {
+	return update.update.FlagID + "/" + update.update.EnvironmentID
+}
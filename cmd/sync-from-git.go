@@ -0,0 +1,426 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var syncFromGitCmd = &cobra.Command{
+	Use:   "sync-from-git",
+	Short: "Apply the flag state committed to a git repository",
+	Long: `Read a manifest from a git repository and change the live flags to match it: flags missing
+from the live state are created, and changed descriptions, labels and environment configurations
+are updated. Flags that are not in the manifest are only deleted with --prune.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		gitURL, _ := cmd.Flags().GetString("git-url")
+		gitRef, _ := cmd.Flags().GetString("git-ref")
+		overlays, _ := cmd.Flags().GetStringSlice("overlay")
+		prune, _ := cmd.Flags().GetBool("prune")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		values, err := templateValues(cmd)
+		if err != nil {
+			return err
+		}
+		desired, err := loadManifest(manifestPath, gitURL, gitRef, overlays, values)
+		if err != nil {
+			return err
+		}
+		if applicationName != "" {
+			desired.Application = applicationName
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		changes, err := applyManifest(cmd, client, desired, prune, dryRun)
+
+		// Output results, including the changes applied before a failure
+		changesJSON, _ := json.Marshal(changes)
+		cloudbees.WriteOutput("change-count", fmt.Sprintf("%d", len(changes)))
+		cloudbees.WriteOutput("changes", string(changesJSON))
+		writeArtifact(cmd, "changes", changes)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			fmt.Printf("Flags of '%s' already match the manifest\n", desired.Application)
+		} else if !dryRun {
+			fmt.Printf("Applied %d changes to '%s'\n", len(changes), desired.Application)
+		}
+		return nil
+	},
+}
+
+// applyManifest changes the live flags of the manifest's application to match it and returns the
+// changes. On failure, the changes applied so far are rolled back (unless --no-rollback is set),
+// and the returned changes are the ones that remain applied.
+func applyManifest(cmd *cobra.Command, client cloudbees.API, desired *manifest.Manifest, prune, dryRun bool) ([]flagChange, error) {
+	live, err := exportManifest(cmd, client, desired.Application, manifestEnvironments(desired))
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []flagChange
+	for _, change := range diffManifests(live, desired) {
+		if change.Kind == changeRemoved && !prune {
+			if verbose {
+				fmt.Printf("Keeping %s, which is not in the manifest (use --prune to delete it)\n", change.Flag)
+			}
+			continue
+		}
+		plan = append(plan, change)
+	}
+
+	if dryRun {
+		for _, change := range plan {
+			fmt.Printf("DRY RUN: %s\n", change.describe())
+		}
+		return plan, nil
+	}
+
+	removed := 0
+	for _, change := range plan {
+		if change.Kind == changeRemoved {
+			removed++
+		}
+	}
+	if removed > 0 {
+		if err := confirmAction(cmd, fmt.Sprintf("permanently delete %d flags that are not in the manifest", removed)); err != nil {
+			return nil, err
+		}
+	}
+
+	application, err := getApplication(cmd, client, desired.Application)
+	if err != nil {
+		return nil, err
+	}
+	state, err := loadBulkState(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Changes are ordered by flag; apply each flag's metadata and each environment's fields together.
+	// Configuration changes are applied last, with as few requests as the API allows. Each applied
+	// change is recorded, so they are all rolled back when a later one fails.
+	applied := []flagChange{}
+	tx := &transaction{}
+	fail := func(err error) ([]flagChange, error) {
+		rolledBack, err := tx.rollback(cmd, err)
+		if rolledBack {
+			return nil, err
+		}
+		return applied, err
+	}
+	var configurations [][]flagChange
+	for start := 0; start < len(plan); {
+		change := plan[start]
+		end := start
+		fields := map[string]interface{}{}
+		for end < len(plan) && plan[end].Flag == change.Flag && plan[end].Kind == change.Kind && plan[end].Environment == change.Environment {
+			fields[plan[end].Field] = plan[end].To
+			end++
+		}
+		key := fmt.Sprintf("%s:%s", change.Kind, change.Flag)
+		if change.Environment == "" && state.done(key) {
+			fmt.Printf("Skipping the %s changes of '%s', completed by a previous run\n", change.Kind, change.Flag)
+			start = end
+			continue
+		}
+
+		var err error
+		switch {
+		case change.Kind == changeAdded:
+			err = createManifestFlag(cmd, client, application, desired.Flag(change.Flag))
+		case change.Kind == changeRemoved:
+			err = deleteManifestFlag(cmd, client, application, change.Flag)
+		case change.Environment == "":
+			err = updateManifestFlag(cmd, client, application, change.Flag, fields)
+		default:
+			configurations = append(configurations, plan[start:end])
+			start = end
+			continue
+		}
+		if err != nil {
+			// A flag whose configuration failed was created, and is deleted again too
+			if change.Kind == changeAdded {
+				if _, getErr := client.GetFlagByName(application.ID, change.Flag); getErr == nil {
+					tx.record(fmt.Sprintf("delete the created flag '%s'", change.Flag), func() error {
+						return revertFlagCreation(cmd, client, application, change.Flag)
+					})
+				}
+			}
+			return fail(fmt.Errorf("failed to apply changes to '%s': %w", change.Flag, err))
+		}
+
+		switch {
+		case change.Kind == changeAdded:
+			tx.record(fmt.Sprintf("delete the created flag '%s'", change.Flag), func() error {
+				return revertFlagCreation(cmd, client, application, change.Flag)
+			})
+		case change.Kind == changeRemoved:
+			previous := live.Flag(change.Flag)
+			tx.record(fmt.Sprintf("recreate the deleted flag '%s'", change.Flag), func() error {
+				return revertFlagDeletion(cmd, client, application, previous)
+			})
+		default:
+			previous := map[string]interface{}{}
+			for _, change := range plan[start:end] {
+				previous[change.Field] = change.From
+			}
+			tx.record(fmt.Sprintf("restore the metadata of '%s'", change.Flag), func() error {
+				return revertFlagUpdate(cmd, client, application, change.Flag, previous)
+			})
+		}
+		for _, change := range plan[start:end] {
+			fmt.Printf("Applied: %s\n", change.describe())
+		}
+		state.complete(key)
+		applied = append(applied, plan[start:end]...)
+		start = end
+	}
+	if len(configurations) == 0 {
+		state.finish()
+		return applied, nil
+	}
+
+	pending, err := manifestConfigurations(client, application, live, configurations)
+	if err != nil {
+		return fail(err)
+	}
+	for i := 0; i < len(pending); {
+		if !state.done(configurationKey(pending[i])) {
+			i++
+			continue
+		}
+		fmt.Printf("Skipping the configuration of '%s' in '%s', completed by a previous run\n", pending[i].change.Flag, pending[i].change.Environment)
+		pending = append(pending[:i], pending[i+1:]...)
+		configurations = append(configurations[:i], configurations[i+1:]...)
+	}
+	if len(pending) == 0 {
+		state.finish()
+		return applied, nil
+	}
+	results := applyConfigurations(cmd, client, application.ID, pending, state)
+	var appliedConfigurations []pendingConfiguration
+	for i, result := range results {
+		if result.Err != nil || result.Skipped {
+			continue
+		}
+		for _, change := range configurations[i] {
+			fmt.Printf("Applied: %s\n", change.describe())
+		}
+		applied = append(applied, configurations[i]...)
+		appliedConfigurations = append(appliedConfigurations, pending[i])
+	}
+	if err := results.Err(); err != nil {
+		if len(appliedConfigurations) > 0 {
+			tx.record(fmt.Sprintf("restore %d flag configurations", len(appliedConfigurations)),
+				revertConfigurations(cmd, client, application.ID, appliedConfigurations))
+		}
+		return fail(fmt.Errorf("failed to apply configuration changes: %w", err))
+	}
+
+	state.finish()
+	return applied, nil
+}
+
+// manifestConfigurations converts the configuration changes of a manifest, grouped by flag and
+// environment, into the updates of the flags' live configurations
+func manifestConfigurations(client cloudbees.API, application *cloudbees.Application, live *manifest.Manifest, groups [][]flagChange) ([]pendingConfiguration, error) {
+	flags, err := client.ListFlags(application.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flags: %w", err)
+	}
+	flagsByName := map[string]cloudbees.Flag{}
+	for _, flag := range flags {
+		flagsByName[flag.Name] = flag
+	}
+	environments, err := client.ListEnvironments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list environments: %w", err)
+	}
+	environmentIDs := map[string]string{}
+	for _, env := range environments {
+		environmentIDs[env.Name] = env.ID
+	}
+
+	pending := make([]pendingConfiguration, len(groups))
+	for i, group := range groups {
+		flag, ok := flagsByName[group[0].Flag]
+		if !ok {
+			return nil, fmt.Errorf("flag '%s' %w", group[0].Flag, cloudbees.ErrNotFound)
+		}
+		environmentID, ok := environmentIDs[group[0].Environment]
+		if !ok {
+			return nil, fmt.Errorf("environment '%s' %w", group[0].Environment, cloudbees.ErrNotFound)
+		}
+
+		changes := map[string]interface{}{}
+		for _, change := range group {
+			changes[change.Field] = change.To
+		}
+		var before cloudbees.FlagConfiguration
+		if liveFlag := live.Flag(flag.Name); liveFlag != nil {
+			before = liveFlag.Environments[group[0].Environment]
+		}
+
+		pending[i] = pendingConfiguration{
+			update: cloudbees.ConfigurationUpdate{FlagID: flag.ID, EnvironmentID: environmentID, Configuration: changes},
+			change: mutation{
+				Operation:   "set-flag-config",
+				Application: application.Name,
+				Flag:        flag.Name,
+				Labels:      flag.Labels,
+				Environment: group[0].Environment,
+				Changes:     changes,
+				Before:      before,
+				After:       mergeConfiguration(before, changes),
+			},
+		}
+	}
+	return pending, nil
+}
+
+// manifestEnvironments returns the names of the environments configured in a manifest
+func manifestEnvironments(m *manifest.Manifest) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, flag := range m.Flags {
+		for name := range flag.Environments {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createManifestFlag creates a flag of a manifest and configures it in the manifest's environments
+func createManifestFlag(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flag *manifest.Flag) error {
+	change := mutation{
+		Operation:   "create-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Changes: map[string]interface{}{
+			"flagType":    flag.Type,
+			"variants":    flag.Variants,
+			"description": flag.Description,
+			"isPermanent": flag.IsPermanent,
+		},
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	created, err := client.CreateFlagFromRequest(application.ID, cloudbees.CreateFlagRequest{
+		Name:        flag.Name,
+		FlagType:    flag.Type,
+		Variants:    flag.Variants,
+		Description: flag.Description,
+		IsPermanent: flag.IsPermanent,
+		Labels:      flag.Labels,
+	})
+	change.After = created
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to create flag: %w", err)
+	}
+
+	environments := make([]string, 0, len(flag.Environments))
+	for name := range flag.Environments {
+		environments = append(environments, name)
+	}
+	sort.Strings(environments)
+	for _, env := range environments {
+		changes := configurationChanges(flag.Environments[env])
+		if _, err := updateFlagConfiguration(cmd, client, application.Name, flag.Name, env, changes, "", false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateManifestFlag updates the metadata of a flag
+func updateManifestFlag(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flagName string, fields map[string]interface{}) error {
+	flag, err := client.GetFlagByName(application.ID, flagName)
+	if err != nil {
+		return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+	}
+
+	change := mutation{
+		Operation:   "update-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Changes:     fields,
+		Before:      flag,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	updated, err := client.UpdateFlag(application.ID, flag.ID, fields)
+	change.After = updated
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to update flag: %w", err)
+	}
+	return nil
+}
+
+// deleteManifestFlag deletes a flag that is not in the manifest
+func deleteManifestFlag(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flagName string) error {
+	flag, err := client.GetFlagByName(application.ID, flagName)
+	if err != nil {
+		return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+	}
+
+	change := mutation{
+		Operation:   "delete-flag",
+		Application: application.Name,
+		Flag:        flag.Name,
+		Labels:      flag.Labels,
+		Before:      flag,
+	}
+	if err := beforeMutation(cmd, change); err != nil {
+		return err
+	}
+
+	err = client.DeleteFlag(application.ID, flag.ID)
+	afterMutation(cmd, change, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete flag: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(syncFromGitCmd)
+
+	syncFromGitCmd.Flags().String("git-url", "", "Git repository containing the manifest (required)")
+	syncFromGitCmd.Flags().String("git-ref", "", "Branch or tag to apply (defaults to the default branch)")
+	syncFromGitCmd.Flags().String("manifest", "", "Path of the manifest in the repository (required)")
+	syncFromGitCmd.Flags().Bool("prune", false, "Delete flags that are not in the manifest")
+	syncFromGitCmd.Flags().Bool("dry-run", false, "Print the changes without applying them")
+	syncFromGitCmd.Flags().Bool("no-rollback", false, "Keep the changes applied before a failure instead of reverting them")
+	stateFlags(syncFromGitCmd)
+	syncFromGitCmd.Flags().StringSlice("overlay", nil, "Overlays in the repository merged into the manifest in order, e.g. the patches of one environment")
+	templateFlags(syncFromGitCmd)
+
+	syncFromGitCmd.MarkFlagRequired("git-url")
+	syncFromGitCmd.MarkFlagRequired("manifest")
+}
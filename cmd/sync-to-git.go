@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/gitrepo"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var syncToGitCmd = &cobra.Command{
+	Use:   "sync-to-git",
+	Short: "Open a pull request when the live flag state differs from the manifest in git",
+	Long: `Export the live state of the application's flags and compare it with the manifest committed
+to a git repository. When they differ, the exported manifest is committed to a new branch and a
+GitHub pull request is opened, with the changes as its description, for review.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifestPath, _ := cmd.Flags().GetString("manifest")
+		gitURL, _ := cmd.Flags().GetString("git-url")
+		gitRef, _ := cmd.Flags().GetString("git-ref")
+		environmentNames, _ := cmd.Flags().GetStringSlice("environments")
+		githubToken, _ := cmd.Flags().GetString("github-token")
+		githubAPIURL, _ := cmd.Flags().GetString("github-api-url")
+		repository, _ := cmd.Flags().GetString("github-repository")
+		authorName, _ := cmd.Flags().GetString("author-name")
+		authorEmail, _ := cmd.Flags().GetString("author-email")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		if githubToken == "" {
+			githubToken = os.Getenv("GITHUB_TOKEN")
+		}
+		if githubToken == "" {
+			return fmt.Errorf("github-token or GITHUB_TOKEN is required to open pull requests")
+		}
+		if repository == "" {
+			repository = gitrepo.GitHubRepository(gitURL)
+			if repository == "" {
+				return fmt.Errorf("cannot determine the GitHub repository from '%s', use --github-repository", gitURL)
+			}
+		}
+
+		repo, err := gitrepo.Clone(gitURL, gitRef)
+		if err != nil {
+			return err
+		}
+		defer repo.Close()
+
+		base, err := repo.Branch()
+		if err != nil {
+			return err
+		}
+
+		// A missing manifest is created by the first sync
+		path := filepath.Join(repo.Dir, manifestPath)
+		committed := &manifest.Manifest{Application: applicationName}
+		if _, err := os.Stat(path); err == nil {
+			if committed, err = manifest.Load(path); err != nil {
+				return err
+			}
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		live, err := exportManifest(cmd, client, applicationName, environmentNames)
+		if err != nil {
+			return err
+		}
+
+		changes := diffManifests(committed, live)
+		changesJSON, _ := json.Marshal(changes)
+		cloudbees.WriteOutput("change-count", fmt.Sprintf("%d", len(changes)))
+		cloudbees.WriteOutput("changes", string(changesJSON))
+		if len(changes) == 0 {
+			fmt.Printf("Manifest %s is up to date\n", manifestPath)
+			return nil
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create manifest directory: %w", err)
+		}
+		if err := live.Save(path); err != nil {
+			return err
+		}
+
+		branch := fmt.Sprintf("fm-actions/sync-%s-%s", live.Application, time.Now().UTC().Format("20060102-150405"))
+		title := fmt.Sprintf("Sync feature flags of %s (%d changes)", live.Application, len(changes))
+		if err := repo.CommitAndPush(branch, title, authorName, authorEmail, manifestPath); err != nil {
+			return err
+		}
+
+		url, err := gitrepo.CreatePullRequest(githubAPIURL, githubToken, repository, gitrepo.PullRequest{
+			Title: title,
+			Body:  changelogMarkdown(live.Application, "the committed manifest", "the live state", changes),
+			Head:  branch,
+			Base:  base,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Output results
+		cloudbees.WriteOutput("branch", branch)
+		cloudbees.WriteOutput("pull-request-url", url)
+
+		fmt.Printf("Opened pull request with %d changes: %s\n", len(changes), url)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncToGitCmd)
+
+	syncToGitCmd.Flags().String("git-url", "", "Git repository containing the manifest (required)")
+	syncToGitCmd.Flags().String("git-ref", "", "Base branch of the pull request (defaults to the default branch)")
+	syncToGitCmd.Flags().String("manifest", "", "Path of the manifest in the repository (required)")
+	syncToGitCmd.Flags().StringSlice("environments", nil, "Environments to export (defaults to all enabled environments)")
+	syncToGitCmd.Flags().String("github-token", "", "GitHub token used to open the pull request (or GITHUB_TOKEN)")
+	syncToGitCmd.Flags().String("github-api-url", "https://api.github.com", "GitHub API URL")
+	syncToGitCmd.Flags().String("github-repository", "", "GitHub repository (owner/name) for the pull request (defaults to the one in --git-url)")
+	syncToGitCmd.Flags().String("author-name", "fm-actions", "Author name of the sync commit")
+	syncToGitCmd.Flags().String("author-email", "fm-actions@users.noreply.github.com", "Author email of the sync commit")
+
+	syncToGitCmd.MarkFlagRequired("git-url")
+	syncToGitCmd.MarkFlagRequired("manifest")
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// templateFlags adds --set and --values to a command that renders manifests as templates
+func templateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("set", nil, "Template value as key=value, e.g. rollout.percentage=25 (repeatable, overrides --values)")
+	cmd.Flags().StringSlice("values", nil, "YAML files with template values, merged in order")
+}
+
+// templateValues returns the values that manifests are rendered with, from --values and --set
+func templateValues(cmd *cobra.Command) (map[string]interface{}, error) {
+	files, _ := cmd.Flags().GetStringSlice("values")
+	assignments, _ := cmd.Flags().GetStringArray("set")
+	return manifest.LoadValues(files, assignments)
+}
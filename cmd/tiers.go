@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// registerTiers adds --tier to every command that has --environment-name. A tier (dev, staging,
+// prod, ...) is mapped to the environment name of the organization in the config file, per
+// profile, so pipelines stay the same across organizations:
+//
+//	tiers:
+//	  prod: production
+//	profiles:
+//	  acme:
+//	    tiers:
+//	      prod: prod-us
+func registerTiers(root *cobra.Command) {
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if cmd.LocalNonPersistentFlags().Lookup("environment-name") != nil && cmd.Flags().Lookup("tier") == nil {
+			cmd.Flags().String("tier", "", "Environment tier (e.g. prod), mapped to an environment name in the config file, instead of --environment-name")
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(root)
+}
+
+// setTierEnvironment copies the environment name of --tier to --environment-name, so the
+// option's requirement is satisfied before it is validated
+func setTierEnvironment(cmd *cobra.Command, args []string) error {
+	tier, _ := cmd.Flags().GetString("tier")
+	if tier == "" {
+		return nil
+	}
+	if cmd.Flags().Changed("environment-name") {
+		return fmt.Errorf("environment-name and tier cannot be used together")
+	}
+	environmentName, err := resolveTier(tier)
+	if err != nil {
+		return err
+	}
+	if verbose {
+		fmt.Printf("Tier %s: environment %s\n", tier, environmentName)
+	}
+	return cmd.Flags().Set("environment-name", environmentName)
+}
+
+// resolveTier returns the environment name of a tier in the --profile, or in the top-level
+// tiers of the config file
+func resolveTier(tier string) (string, error) {
+	if profile := viper.GetString("profile"); profile != "" {
+		if name := viper.GetString("profiles." + profile + ".tiers." + tier); name != "" {
+			return name, nil
+		}
+	}
+	if name := viper.GetString("tiers." + tier); name != "" {
+		return name, nil
+	}
+	return "", fmt.Errorf("tier '%s' is not mapped to an environment in the config file (tiers or profiles.<profile>.tiers)", tier)
+}
+
+// completeTiers completes the tiers of the config file
+func completeTiers(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	tiers := viper.GetStringMapString("tiers")
+	if profile := viper.GetString("profile"); profile != "" {
+		for tier, name := range viper.GetStringMapString("profiles." + profile + ".tiers") {
+			tiers[tier] = name
+		}
+	}
+	names := make([]string, 0, len(tiers))
+	for tier := range tiers {
+		names = append(names, tier)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// tokenProvider renews the API token of the global connection, set up from --token-file,
+// --token-command or the OIDC token exchange, and nil with a fixed --token
+var tokenProvider cloudbees.TokenProvider
+
+// providedToken is the token of tokenProvider when the command started, which --token is set to.
+// Clients created with it renew it through tokenProvider.
+var providedToken string
+
+// initTokenProvider sets up the provider of renewed API tokens, so long-running modes (serve,
+// mcp, drift-watch) outlive the lifetime of a token, and sets --token to its current token
+func initTokenProvider(cmd *cobra.Command) error {
+	provider, err := newTokenProvider(cmd)
+	if err != nil || provider == nil {
+		return err
+	}
+	flags := cmd.Root().PersistentFlags()
+	if flags.Changed("token") {
+		return fmt.Errorf("token cannot be used with token-file, token-command, oidc-token-file or oidc-github-actions")
+	}
+
+	token, err := provider.Token()
+	if err != nil {
+		return err
+	}
+	tokenProvider, providedToken = provider, token
+	return flags.Set("token", token)
+}
+
+// newTokenProvider returns the token provider of the settings, or nil when none is set
+func newTokenProvider(cmd *cobra.Command) (cloudbees.TokenProvider, error) {
+	tokenFile := viper.GetString("token-file")
+	tokenCommand := viper.GetString("token-command")
+	oidcProvider, err := oidcTokenProvider(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	configured := 0
+	for _, set := range []bool{tokenFile != "", tokenCommand != "", oidcProvider != nil} {
+		if set {
+			configured++
+		}
+	}
+	if configured > 1 {
+		return nil, fmt.Errorf("only one of token-file, token-command and the OIDC token exchange can be used")
+	}
+
+	switch {
+	case tokenFile != "":
+		return cloudbees.NewFileToken(tokenFile), nil
+	case tokenCommand != "":
+		return cloudbees.NewExecToken("sh", "-c", tokenCommand), nil
+	}
+	return oidcProvider, nil
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+// transaction records how to revert the changes a command applied, so a command that fails
+// halfway through restores the state it started from instead of leaving it mixed
+type transaction struct {
+	reverts []revert
+}
+
+// revert undoes one applied change
+type revert struct {
+	description string
+	undo        func() error
+}
+
+// record adds how to revert a change that was just applied
+func (t *transaction) record(description string, undo func() error) {
+	t.reverts = append(t.reverts, revert{description: description, undo: undo})
+}
+
+// rollback reverts the recorded changes, newest first, after cause made the command fail, unless
+// --no-rollback or --state-file is set. It reports the final state and returns whether every
+// change was reverted, with cause annotated with the outcome, or with why the changes were kept.
+func (t *transaction) rollback(cmd *cobra.Command, cause error) (bool, error) {
+	if len(t.reverts) == 0 {
+		return true, cause
+	}
+	noRollback, _ := cmd.Flags().GetBool("no-rollback")
+	stateFile, _ := cmd.Flags().GetString("state-file")
+	switch {
+	case noRollback:
+		cloudbees.WriteOutput("rolled-back", "false")
+		return false, fmt.Errorf("%w (%d applied changes were not rolled back because of --no-rollback)", cause, len(t.reverts))
+	case stateFile != "":
+		// The applied changes are kept so the run can be resumed
+		cloudbees.WriteOutput("rolled-back", "false")
+		return false, fmt.Errorf("%w (%d applied changes were not rolled back because --state-file is set, run again with --resume to apply the rest)",
+			cause, len(t.reverts))
+	}
+
+	var failed []string
+	for i := len(t.reverts) - 1; i >= 0; i-- {
+		step := t.reverts[i]
+		if err := step.undo(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to %s: %v\n", step.description, err)
+			failed = append(failed, step.description)
+			continue
+		}
+		fmt.Printf("Rolled back: %s\n", step.description)
+	}
+
+	cloudbees.WriteOutput("rolled-back", fmt.Sprintf("%t", len(failed) == 0))
+	if len(failed) > 0 {
+		return false, fmt.Errorf("%w (rolling back failed, still applied: %s)", cause, strings.Join(failed, "; "))
+	}
+	fmt.Printf("Rolled back %d changes\n", len(t.reverts))
+	return true, fmt.Errorf("%w (all changes were rolled back)", cause)
+}
+
+// afterRollback records a revert in the audit log and notifications as a rollback. Reverts are
+// sent through the client directly: the guardrails accepted the changes they undo, so policy,
+// protected environments and approval do not stand in the way of restoring the previous state.
+func afterRollback(cmd *cobra.Command, m mutation, err error) {
+	m.Operation = "rollback"
+	afterMutation(cmd, m, err)
+}
+
+// revertConfigurations returns how to set the configurations changed by applied back to the
+// configurations they replaced
+func revertConfigurations(cmd *cobra.Command, client cloudbees.API, applicationID string, applied []pendingConfiguration) func() error {
+	return func() error {
+		var failed []string
+		for _, p := range applied {
+			before, ok := p.change.Before.(cloudbees.FlagConfiguration)
+			if !ok {
+				continue
+			}
+			changes := configurationChanges(before)
+			change := p.change
+			change.Changes = changes
+			change.Before = p.change.After
+			change.After = changes
+			err := client.SetFlagConfiguration(applicationID, p.update.FlagID, p.update.EnvironmentID, changes)
+			afterRollback(cmd, change, err)
+			if err != nil {
+				failed = append(failed, fmt.Sprintf("%s in '%s': %v", p.change.Flag, p.change.Environment, err))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to restore %s", strings.Join(failed, "; "))
+		}
+		return nil
+	}
+}
+
+// revertFlagCreation deletes a flag created by the command
+func revertFlagCreation(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flagName string) error {
+	flag, err := client.GetFlagByName(application.ID, flagName)
+	if err != nil {
+		return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+	}
+
+	err = client.DeleteFlag(application.ID, flag.ID)
+	afterRollback(cmd, mutation{Application: application.Name, Flag: flag.Name, Labels: flag.Labels, Before: flag}, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete flag: %w", err)
+	}
+	return nil
+}
+
+// revertFlagUpdate sets the metadata fields of a flag back to their previous values
+func revertFlagUpdate(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flagName string, fields map[string]interface{}) error {
+	flag, err := client.GetFlagByName(application.ID, flagName)
+	if err != nil {
+		return fmt.Errorf("failed to get flag '%s': %w", flagName, err)
+	}
+
+	updated, err := client.UpdateFlag(application.ID, flag.ID, fields)
+	afterRollback(cmd, mutation{Application: application.Name, Flag: flag.Name, Labels: flag.Labels, Changes: fields, Before: flag, After: updated}, err)
+	if err != nil {
+		return fmt.Errorf("failed to update flag: %w", err)
+	}
+	return nil
+}
+
+// revertFlagDeletion recreates a flag deleted by the command, with its configurations
+func revertFlagDeletion(cmd *cobra.Command, client cloudbees.API, application *cloudbees.Application, flag *manifest.Flag) error {
+	created, err := client.CreateFlagFromRequest(application.ID, cloudbees.CreateFlagRequest{
+		Name:        flag.Name,
+		FlagType:    flag.Type,
+		Variants:    flag.Variants,
+		Description: flag.Description,
+		IsPermanent: flag.IsPermanent,
+		Labels:      flag.Labels,
+	})
+	afterRollback(cmd, mutation{Application: application.Name, Flag: flag.Name, Labels: flag.Labels, After: created}, err)
+	if err != nil {
+		return fmt.Errorf("failed to create flag: %w", err)
+	}
+
+	environments := make([]string, 0, len(flag.Environments))
+	for name := range flag.Environments {
+		environments = append(environments, name)
+	}
+	sort.Strings(environments)
+	for _, env := range environments {
+		ctx, err := resolveContext(cmd, client, application.Name, flag.Name, env)
+		if err != nil {
+			return err
+		}
+		changes := configurationChanges(flag.Environments[env])
+		err = client.SetFlagConfiguration(application.ID, created.ID, ctx.Environment.ID, changes)
+		afterRollback(cmd, mutation{Application: application.Name, Flag: flag.Name, Labels: flag.Labels, Environment: env, Changes: changes, After: changes}, err)
+		if err != nil {
+			return fmt.Errorf("failed to set flag configuration in '%s': %w", env, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/seed"
+	"github.com/spf13/cobra"
+)
+
+var unseedCmd = &cobra.Command{
+	Use:   "unseed",
+	Short: "Delete the example flags created by seed",
+	Long: `Delete the flags of the bundled workshop manifest (or a manifest file) that were created by
+seed. Flags with the same name that do not carry the seed label are kept. This action cannot be undone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if !dryRun {
+			if err := confirmAction(cmd, "permanently delete the seeded flags"); err != nil {
+				return err
+			}
+		}
+
+		seeded, err := loadSeedManifest(cmd)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		application, err := getApplication(cmd, client, seeded.Application)
+		if err != nil {
+			return err
+		}
+
+		flags, err := client.ListFlags(application.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list flags: %w", err)
+		}
+
+		deleted := []string{}
+		for _, flag := range flags {
+			if seeded.Flag(flag.Name) == nil {
+				continue
+			}
+			if !containsString(flag.Labels, seed.Label) {
+				fmt.Printf("Keeping '%s', which was not created by seed\n", flag.Name)
+				continue
+			}
+			if dryRun {
+				fmt.Printf("DRY RUN: Would delete flag '%s' (ID: %s)\n", flag.Name, flag.ID)
+				continue
+			}
+			if err := deleteManifestFlag(cmd, client, application, flag.Name); err != nil {
+				return fmt.Errorf("failed to delete '%s': %w", flag.Name, err)
+			}
+			deleted = append(deleted, flag.Name)
+			if verbose {
+				fmt.Printf("Deleted flag '%s'\n", flag.Name)
+			}
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		// Output results
+		deletedJSON, _ := json.Marshal(deleted)
+		cloudbees.WriteOutput("deleted-count", fmt.Sprintf("%d", len(deleted)))
+		cloudbees.WriteOutput("deleted", string(deletedJSON))
+
+		fmt.Printf("Deleted %d example flags from '%s'\n", len(deleted), application.Name)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unseedCmd)
+
+	unseedCmd.Flags().String("manifest", "", "Manifest file with the flags to delete (defaults to the bundled workshop flags)")
+	unseedCmd.Flags().String("prefix", "", "Prefix of the flag names, as passed to seed")
+	unseedCmd.Flags().Bool("dry-run", false, "Preview the deletion without actually deleting")
+	unseedCmd.Flags().Bool("confirm", false, "Confirm that you want to delete the flags (required unless using dry-run)")
+	unseedCmd.Flags().StringSlice("overlay", nil, "Overlays merged into the manifest in order, as passed to seed")
+	templateFlags(unseedCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var updateApplicationCmd = &cobra.Command{
+	Use:   "update-application",
+	Short: "Update an application",
+	Long:  `Update the name, description or repository of an application. Only the specified fields are changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		newName, _ := cmd.Flags().GetString("new-name")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		applicationName, _ := cmd.Root().PersistentFlags().GetString("application-name")
+
+		fields := make(map[string]interface{})
+		if newName != "" {
+			fields["name"] = newName
+		}
+		for _, field := range []struct{ flag, key string }{
+			{"description", "description"},
+			{"repository-url", "repositoryUrl"},
+			{"default-branch", "defaultBranch"},
+		} {
+			if cmd.Flags().Changed(field.flag) {
+				value, _ := cmd.Flags().GetString(field.flag)
+				fields[field.key] = value
+			}
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("no application changes specified")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		application, err := client.GetApplicationByName(applicationName)
+		if err != nil {
+			return fmt.Errorf("failed to get application '%s': %w", applicationName, err)
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would update application '%s' (ID: %s)\n", application.Name, application.ID)
+			fieldsJSON, _ := json.MarshalIndent(fields, "", "  ")
+			fmt.Printf("Changes:\n%s\n", fieldsJSON)
+			return nil
+		}
+
+		// The API replaces the whole application, so apply the changes to its current settings
+		request := *application
+		if value, ok := fields["name"].(string); ok {
+			request.Name = value
+		}
+		if value, ok := fields["description"].(string); ok {
+			request.Description = value
+		}
+		if value, ok := fields["repositoryUrl"].(string); ok {
+			request.RepositoryURL = value
+		}
+		if value, ok := fields["defaultBranch"].(string); ok {
+			request.DefaultBranch = value
+		}
+
+		change := mutation{
+			Operation:   "update-application",
+			Application: application.Name,
+			Changes:     fields,
+			Before:      application,
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		updated, err := client.UpdateApplication(request)
+		change.After = updated
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to update application: %w", err)
+		}
+
+		// Output results
+		writeApplicationOutputs(updated)
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully updated application: %s (ID: %s)\n", updated.Name, updated.ID)
+			for key, value := range fields {
+				fmt.Printf("  %s: %v\n", key, value)
+			}
+		} else {
+			fmt.Printf("Application '%s' updated successfully\n", updated.Name)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateApplicationCmd)
+
+	updateApplicationCmd.Flags().String("new-name", "", "New name of the application")
+	updateApplicationCmd.Flags().String("description", "", "New description of the application")
+	updateApplicationCmd.Flags().String("repository-url", "", "New source repository of the application")
+	updateApplicationCmd.Flags().String("default-branch", "", "New default branch of the repository")
+	updateApplicationCmd.Flags().Bool("dry-run", false, "Preview the changes without applying them")
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var updateEnvironmentCmd = &cobra.Command{
+	Use:   "update-environment",
+	Short: "Update an environment",
+	Long:  `Update the name, description or disabled state of an environment. Only the specified fields are changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environmentName, _ := cmd.Flags().GetString("environment-name")
+		newName, _ := cmd.Flags().GetString("new-name")
+		disabled, _ := cmd.Flags().GetString("disabled")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if environmentName == "" {
+			return fmt.Errorf("environment-name is required")
+		}
+
+		fields := make(map[string]interface{})
+		if newName != "" {
+			fields["name"] = newName
+		}
+		if cmd.Flags().Changed("description") {
+			description, _ := cmd.Flags().GetString("description")
+			fields["description"] = description
+		}
+		if disabled != "" {
+			disabledBool, err := strconv.ParseBool(disabled)
+			if err != nil {
+				return fmt.Errorf("invalid disabled value '%s', must be true or false", disabled)
+			}
+			fields["isDisabled"] = disabledBool
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("no environment changes specified")
+		}
+
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		environment, err := client.GetEnvironmentByName(environmentName)
+		if err != nil {
+			return fmt.Errorf("failed to get environment: %w", err)
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would update environment '%s' (ID: %s)\n", environment.Name, environment.ID)
+			fieldsJSON, _ := json.MarshalIndent(fields, "", "  ")
+			fmt.Printf("Changes:\n%s\n", fieldsJSON)
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "update-environment",
+			Environment: environment.Name,
+			Changes:     fields,
+			Before:      environment,
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		updated, err := client.UpdateEnvironment(environment.ID, fields)
+		change.After = updated
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to update environment: %w", err)
+		}
+
+		// Output results
+		writeEnvironmentOutputs(updated)
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully updated environment: %s (ID: %s)\n", updated.Name, updated.ID)
+			for key, value := range fields {
+				fmt.Printf("  %s: %v\n", key, value)
+			}
+		} else {
+			fmt.Printf("Environment '%s' updated successfully\n", updated.Name)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateEnvironmentCmd)
+
+	updateEnvironmentCmd.Flags().StringP("environment-name", "e", "", "Name of the environment to update (required)")
+	updateEnvironmentCmd.Flags().String("new-name", "", "New name of the environment")
+	updateEnvironmentCmd.Flags().String("description", "", "New description of the environment")
+	updateEnvironmentCmd.Flags().String("disabled", "", "Disable or re-enable the environment (true/false)")
+	updateEnvironmentCmd.Flags().Bool("dry-run", false, "Preview the changes without applying them")
+
+	updateEnvironmentCmd.MarkFlagRequired("environment-name")
+}
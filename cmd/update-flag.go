@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/spf13/cobra"
+)
+
+var updateFlagCmd = &cobra.Command{
+	Use:   "update-flag",
+	Short: "Update feature flag metadata",
+	Long:  `Update the description, owner, expiry date or prerequisites of a feature flag. Environment configuration is changed with set-flag-config.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flagName, _ := cmd.Flags().GetString("flag-name")
+		description, _ := cmd.Flags().GetString("description")
+		owner, _ := cmd.Flags().GetString("owner")
+		expiresStr, _ := cmd.Flags().GetString("expires")
+		requires, _ := cmd.Flags().GetStringSlice("requires")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if flagName == "" {
+			return fmt.Errorf("flag-name is required")
+		}
+
+		var expires time.Time
+		if expiresStr != "" {
+			var err error
+			expires, err = cloudbees.ParseExpiry(expiresStr, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		client, ctx, err := commandContext(cmd, flagName, "")
+		if err != nil {
+			return err
+		}
+		application, flag := ctx.Application, ctx.Flag
+
+		// Build the update with only the fields that were specified
+		fields := make(map[string]interface{})
+		if cmd.Flags().Changed("description") {
+			fields["description"] = description
+		}
+		if owner != "" || !expires.IsZero() {
+			fields["labels"] = cloudbees.WithMetadataLabels(flag.Labels, owner, expires)
+		}
+		if cmd.Flags().Changed("requires") {
+			labels := flag.Labels
+			if current, ok := fields["labels"].([]string); ok {
+				labels = current
+			}
+			updated := *flag
+			updated.Labels = cloudbees.WithPrerequisiteLabels(labels, requires)
+			if err := validatePrerequisites(client, application.ID, updated); err != nil {
+				return err
+			}
+			fields["labels"] = updated.Labels
+		}
+
+		if len(fields) == 0 {
+			return fmt.Errorf("no changes specified")
+		}
+
+		if dryRun {
+			fmt.Printf("DRY RUN: Would update flag '%s' (ID: %s)\n", flag.Name, flag.ID)
+			fieldsJSON, _ := json.MarshalIndent(fields, "", "  ")
+			fmt.Printf("Changes:\n%s\n", fieldsJSON)
+			return nil
+		}
+
+		change := mutation{
+			Operation:   "update-flag",
+			Application: application.Name,
+			Flag:        flag.Name,
+			Labels:      flag.Labels,
+			Changes:     fields,
+			Before:      flag,
+		}
+		if err := beforeMutation(cmd, change); err != nil {
+			return err
+		}
+
+		updated, err := client.UpdateFlag(application.ID, flag.ID, fields)
+		change.After = updated
+		afterMutation(cmd, change, err)
+		if err != nil {
+			return fmt.Errorf("failed to update flag: %w", err)
+		}
+
+		// Output results
+		flagJSON, _ := json.Marshal(updated)
+		cloudbees.WriteOutput("flag-id", flag.ID)
+		cloudbees.WriteOutput("flag-name", flag.Name)
+		cloudbees.WriteOutput("flag", string(flagJSON))
+		cloudbees.WriteOutput("success", "true")
+
+		if verbose {
+			fmt.Printf("Successfully updated flag: %s (ID: %s)\n", flag.Name, flag.ID)
+			if updated.Description != "" {
+				fmt.Printf("Description: %s\n", updated.Description)
+			}
+			if len(updated.Labels) > 0 {
+				fmt.Printf("Labels: %s\n", strings.Join(updated.Labels, ", "))
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(updateFlagCmd)
+
+	updateFlagCmd.Flags().StringP("flag-name", "f", "", "Flag name (required)")
+	updateFlagCmd.Flags().StringP("description", "d", "", "New description of the flag")
+	updateFlagCmd.Flags().String("owner", "", "Owner of the flag (team or person), stored as an owner: label")
+	updateFlagCmd.Flags().String("expires", "", "Expiry date (YYYY-MM-DD) or duration (90d, 6w), stored as an expires: label")
+	updateFlagCmd.Flags().StringSlice("requires", nil, "Flags that must be enabled before this flag, replacing its requires: labels (repeatable, empty to remove them)")
+	updateFlagCmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+
+	flagNamesArg(updateFlagCmd)
+
+	updateFlagCmd.MarkFlagRequired("flag-name")
+}
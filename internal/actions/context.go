@@ -0,0 +1,98 @@
+// Package actions resolves what a command works on: the application, flag and environment given
+// by name or ID. It depends on a Client interface rather than the HTTP client, so the resolution
+// can be exercised without the API.
+package actions
+
+import (
+	"fmt"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+)
+
+// Client is the part of the CloudBees API used to resolve a command context
+type Client interface {
+	GetApplication(id string) (*cloudbees.Application, error)
+	GetApplicationByName(name string) (*cloudbees.Application, error)
+	OrganizationApplication() *cloudbees.Application
+	OrgScoped() bool
+	GetFlagByName(applicationID, flagName string) (*cloudbees.Flag, error)
+	GetEnvironmentByName(name string) (*cloudbees.Environment, error)
+}
+
+// Target names what a command works on. The flag and environment are optional.
+type Target struct {
+	ApplicationName string
+	ApplicationID   string
+	FlagName        string
+	EnvironmentName string
+}
+
+// CommandContext is a resolved Target
+type CommandContext struct {
+	Client      Client
+	Application *cloudbees.Application
+	Flag        *cloudbees.Flag        // nil without Target.FlagName
+	Environment *cloudbees.Environment // nil without Target.EnvironmentName
+}
+
+// NewCommandContext resolves the application of target, then its flag and environment when they are named
+func NewCommandContext(client Client, target Target) (*CommandContext, error) {
+	application, err := ResolveApplication(client, target.ApplicationName, target.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	ctx := &CommandContext{Client: client, Application: application}
+
+	if target.FlagName != "" {
+		if ctx.Flag, err = ResolveFlag(client, application, target.FlagName); err != nil {
+			return nil, err
+		}
+	}
+	if target.EnvironmentName != "" {
+		if ctx.Environment, err = ResolveEnvironment(client, target.EnvironmentName); err != nil {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// ResolveApplication returns the application called name or, without a name, the one with the
+// given ID, or the organization itself when it uses the legacy flags API
+func ResolveApplication(client Client, name, id string) (*cloudbees.Application, error) {
+	switch {
+	case name != "":
+		application, err := client.GetApplicationByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get application '%s': %w", name, err)
+		}
+		return application, nil
+	case id != "":
+		application, err := client.GetApplication(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get application: %w", err)
+		}
+		return application, nil
+	case client.OrgScoped():
+		return client.OrganizationApplication(), nil
+	default:
+		return nil, fmt.Errorf("application-name or application-id is required, unless the organization uses the legacy flags API (--use-org-as-app)")
+	}
+}
+
+// ResolveFlag returns the flag called name in application
+func ResolveFlag(client Client, application *cloudbees.Application, name string) (*cloudbees.Flag, error) {
+	flag, err := client.GetFlagByName(application.ID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get flag '%s': %w", name, err)
+	}
+	return flag, nil
+}
+
+// ResolveEnvironment returns the environment called name
+func ResolveEnvironment(client Client, name string) (*cloudbees.Environment, error) {
+	environment, err := client.GetEnvironmentByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get environment: %w", err)
+	}
+	return environment, nil
+}
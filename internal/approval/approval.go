@@ -0,0 +1,133 @@
+// Package approval requests human approval for changes through a webhook and waits for the decision.
+package approval
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+// ErrNotApproved is returned when an approval request is rejected or times out
+var ErrNotApproved = errors.New("change not approved")
+
+// Approval request statuses reported by the approval service
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Request is sent to the approval webhook
+type Request struct {
+	Summary string      `json:"summary"`
+	Change  interface{} `json:"change"`
+}
+
+// Decision is returned by the approval service when an approval request is created or polled.
+// StatusURL is polled with GET until Status is no longer pending.
+type Decision struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	StatusURL string `json:"statusUrl"`
+	Approver  string `json:"approver,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// Gate creates approval requests and waits for their decision
+type Gate struct {
+	WebhookURL   string
+	Token        string // Optional bearer token for the approval service
+	Timeout      time.Duration
+	PollInterval time.Duration
+	HTTPClient   *http.Client
+}
+
+// MatchesEnvironment reports whether an environment matches any of the protected patterns (shell globs)
+func MatchesEnvironment(environment string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, environment); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Await creates an approval request and blocks until it is approved, rejected or timed out
+func (g *Gate) Await(request Request) (*Decision, error) {
+	client := g.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	var decision Decision
+	if err := g.do(client, "POST", g.WebhookURL, request, &decision); err != nil {
+		return nil, fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	deadline := time.Now().Add(g.Timeout)
+	for decision.Status == StatusPending || decision.Status == "" {
+		if decision.StatusURL == "" {
+			return nil, fmt.Errorf("approval service returned no statusUrl for pending request")
+		}
+		if time.Now().After(deadline) {
+			return &decision, fmt.Errorf("%w: approval request %s timed out after %s", ErrNotApproved, decision.ID, g.Timeout)
+		}
+		time.Sleep(g.PollInterval)
+
+		statusURL := decision.StatusURL
+		if err := g.do(client, "GET", statusURL, nil, &decision); err != nil {
+			return nil, fmt.Errorf("failed to poll approval request: %w", err)
+		}
+		if decision.StatusURL == "" {
+			decision.StatusURL = statusURL
+		}
+	}
+
+	if decision.Status != StatusApproved {
+		msg := fmt.Sprintf("%s by %s", decision.Status, decision.Approver)
+		if decision.Comment != "" {
+			msg += ": " + decision.Comment
+		}
+		return &decision, fmt.Errorf("%w: approval request %s %s", ErrNotApproved, decision.ID, msg)
+	}
+
+	return &decision, nil
+}
+
+func (g *Gate) do(client *http.Client, method, url string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(data))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
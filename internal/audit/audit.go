@@ -0,0 +1,153 @@
+// Package audit writes a tamper-evident, append-only JSONL trail of the changes made by the CLI.
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Record is a single audited operation. Each record includes the hash of the previous record,
+// so removing or editing a line breaks the chain; with a signing key the chain is also signed.
+type Record struct {
+	Timestamp   string                 `json:"timestamp"`
+	Principal   string                 `json:"principal"`
+	Command     string                 `json:"command"`
+	Inputs      map[string]string      `json:"inputs"`
+	Operation   string                 `json:"operation"`
+	Application string                 `json:"application,omitempty"`
+	Flag        string                 `json:"flag,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Before      interface{}            `json:"before,omitempty"`
+	After       interface{}            `json:"after,omitempty"`
+	Changes     map[string]interface{} `json:"changes,omitempty"`
+	Status      string                 `json:"status"` // success or failed
+	Error       string                 `json:"error,omitempty"`
+	PrevHash    string                 `json:"prevHash"`
+	Hash        string                 `json:"hash"`
+	Signature   string                 `json:"signature,omitempty"`
+}
+
+// Logger appends records to a local file or buffers them for upload to an S3-compatible bucket
+type Logger struct {
+	mu       sync.Mutex
+	key      []byte
+	file     *os.File
+	s3       *s3Destination
+	buffer   bytes.Buffer
+	lastHash string
+}
+
+// Open creates a logger for destination, either a file path or s3://bucket/prefix.
+// signingKey may be empty, in which case records are hash-chained but not signed.
+func Open(destination string, signingKey string) (*Logger, error) {
+	logger := &Logger{key: []byte(signingKey)}
+
+	if strings.HasPrefix(destination, "s3://") {
+		s3, err := newS3Destination(destination)
+		if err != nil {
+			return nil, err
+		}
+		logger.s3 = s3
+		return logger, nil
+	}
+
+	lastHash, err := readLastHash(destination)
+	if err != nil {
+		return nil, err
+	}
+	logger.lastHash = lastHash
+
+	file, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	logger.file = file
+	return logger, nil
+}
+
+// Write hashes, signs and appends a record
+func (l *Logger) Write(record Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if record.Timestamp == "" {
+		record.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	}
+	record.PrevHash = l.lastHash
+	record.Hash = ""
+	record.Signature = ""
+
+	unsigned, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(unsigned)
+	record.Hash = hex.EncodeToString(sum[:])
+	if len(l.key) > 0 {
+		mac := hmac.New(sha256.New, l.key)
+		mac.Write(unsigned)
+		record.Signature = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if l.file != nil {
+		if _, err := l.file.Write(line); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	} else {
+		l.buffer.Write(line)
+	}
+
+	l.lastHash = record.Hash
+	return nil
+}
+
+// Close flushes buffered records to the bucket and closes the log file
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		return l.file.Close()
+	}
+	if l.s3 != nil && l.buffer.Len() > 0 {
+		return l.s3.upload(l.buffer.Bytes())
+	}
+	return nil
+}
+
+// readLastHash returns the hash of the last record in an existing audit log
+func readLastHash(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return "", nil
+	}
+
+	var record Record
+	if err := json.Unmarshal(last, &record); err != nil {
+		return "", fmt.Errorf("failed to parse last audit record: %w", err)
+	}
+	return record.Hash, nil
+}
@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// s3Destination uploads audit records to an S3-compatible bucket. Since objects cannot be appended to,
+// each CLI invocation writes its own object named <prefix>/<timestamp>-<random>.jsonl.
+// Credentials and endpoint come from the standard AWS environment variables
+// (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN, AWS_REGION, AWS_ENDPOINT_URL).
+type s3Destination struct {
+	endpoint  string
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	session   string
+}
+
+func newS3Destination(destination string) (*s3Destination, error) {
+	parsed, err := url.Parse(destination)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid audit destination '%s', expected s3://bucket/prefix", destination)
+	}
+
+	d := &s3Destination{
+		endpoint:  os.Getenv("AWS_ENDPOINT_URL"),
+		bucket:    parsed.Host,
+		prefix:    strings.Trim(parsed.Path, "/"),
+		region:    os.Getenv("AWS_REGION"),
+		accessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		session:   os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if d.region == "" {
+		d.region = "us-east-1"
+	}
+	if d.endpoint == "" {
+		d.endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", d.region)
+	}
+	if d.accessKey == "" || d.secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for S3 audit logs")
+	}
+	return d, nil
+}
+
+// upload writes data to a new object using a path-style PUT signed with AWS Signature Version 4
+func (d *s3Destination) upload(data []byte) error {
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	now := time.Now().UTC()
+	key := fmt.Sprintf("%s-%s.jsonl", now.Format("20060102T150405Z"), hex.EncodeToString(suffix))
+	if d.prefix != "" {
+		key = d.prefix + "/" + key
+	}
+
+	objectURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(d.endpoint, "/"), d.bucket, key)
+	req, err := http.NewRequest("PUT", objectURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	d.sign(req, data, now)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload audit log: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to upload audit log: status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (d *s3Destination) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if d.session != "" {
+		req.Header.Set("X-Amz-Security-Token", d.session)
+	}
+
+	signedHeaders := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+	if d.session != "" {
+		signedHeaders = append(signedHeaders, "x-amz-security-token")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(value))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, d.region)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+d.secretKey), date)
+	signingKey = hmacSHA256(signingKey, d.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		d.accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,158 @@
+// Package backstage converts manifests to Backstage catalog entities, so flags appear in the
+// developer portal next to the services that use them.
+package backstage
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// Catalog entity constants
+const (
+	APIVersion   = "backstage.io/v1alpha1"
+	KindResource = "Resource"
+	ResourceType = "feature-flag"
+)
+
+// Lifecycles of flag entities
+const (
+	LifecycleProduction   = "production"   // Permanent flags
+	LifecycleExperimental = "experimental" // Temporary flags
+	LifecycleDeprecated   = "deprecated"   // Temporary flags past their expiry date
+)
+
+// Entity is a Backstage catalog entity
+type Entity struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       Spec     `yaml:"spec"`
+}
+
+// Metadata is the metadata of a catalog entity
+type Metadata struct {
+	Name        string            `yaml:"name"`
+	Title       string            `yaml:"title,omitempty"`
+	Description string            `yaml:"description,omitempty"`
+	Tags        []string          `yaml:"tags,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Spec is the spec of a Resource entity
+type Spec struct {
+	Type      string `yaml:"type"`
+	Owner     string `yaml:"owner"`
+	Lifecycle string `yaml:"lifecycle"`
+	System    string `yaml:"system,omitempty"`
+}
+
+// Options are the catalog settings that are not part of the flags
+type Options struct {
+	Owner  string // Owner of flags without an owner label
+	System string // System the flag entities belong to
+}
+
+var (
+	invalidName = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+	invalidTag  = regexp.MustCompile(`[^a-z0-9:+#]+`)
+)
+
+// FromManifest converts every flag to a Resource entity. Owner and expiry labels become the owner
+// and lifecycle, the other labels become tags.
+func FromManifest(m *manifest.Manifest, opts Options, now time.Time) []Entity {
+	entities := make([]Entity, 0, len(m.Flags))
+	for _, flag := range m.Flags {
+		metadata := cloudbees.Flag{Labels: flag.Labels}
+
+		owner := metadata.Owner()
+		if owner == "" {
+			owner = opts.Owner
+		}
+
+		lifecycle := LifecycleExperimental
+		if flag.IsPermanent {
+			lifecycle = LifecycleProduction
+		} else if metadata.IsExpired(now) {
+			lifecycle = LifecycleDeprecated
+		}
+
+		annotations := map[string]string{
+			"fm-actions/application": m.Application,
+			"fm-actions/flag-name":   flag.Name,
+			"fm-actions/flag-type":   flag.Type,
+		}
+		if expires, ok := metadata.Expires(); ok {
+			annotations["fm-actions/expires"] = expires.Format(cloudbees.ExpiryDateFormat)
+		}
+
+		entities = append(entities, Entity{
+			APIVersion: APIVersion,
+			Kind:       KindResource,
+			Metadata: Metadata{
+				Name:        entityName(m.Application, flag.Name),
+				Title:       flag.Name,
+				Description: flag.Description,
+				Tags:        tags(flag.Labels),
+				Annotations: annotations,
+			},
+			Spec: Spec{
+				Type:      ResourceType,
+				Owner:     owner,
+				Lifecycle: lifecycle,
+				System:    opts.System,
+			},
+		})
+	}
+	return entities
+}
+
+// Marshal writes entities as a multi-document YAML catalog file
+func Marshal(entities []Entity) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, entity := range entities {
+		if err := encoder.Encode(entity); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// entityName returns a valid entity name (at most 63 letters, digits, and - _ . separators),
+// prefixed with the application so flags of different applications do not collide
+func entityName(application, flag string) string {
+	name := strings.Trim(invalidName.ReplaceAllString(application+"-"+flag, "-"), "-_.")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-_.")
+	}
+	return name
+}
+
+// tags converts labels to valid tags (lowercase letters, digits, : + # with - separators),
+// leaving out the owner and expiry labels
+func tags(labels []string) []string {
+	var result []string
+	for _, label := range labels {
+		if strings.HasPrefix(label, cloudbees.OwnerLabelPrefix) || strings.HasPrefix(label, cloudbees.ExpiresLabelPrefix) {
+			continue
+		}
+		tag := strings.Trim(invalidTag.ReplaceAllString(strings.ToLower(label), "-"), "-")
+		if len(tag) > 63 {
+			tag = strings.TrimRight(tag[:63], "-")
+		}
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package cloudbees
+
+// API is the CloudBees Feature Management API as used by the commands. Client implements it
+// over HTTP; tests substitute fakes to run command logic without the platform.
+type API interface {
+	// Applications
+	ListApplications() ([]Application, error)
+	GetApplication(id string) (*Application, error)
+	GetApplicationByName(name string) (*Application, error)
+	CreateApplication(application Application) (*Application, error)
+	UpdateApplication(application Application) (*Application, error)
+	LinkEnvironment(application *Application, environmentID string) (*Application, error)
+	UnlinkEnvironment(application *Application, environmentID string) (*Application, error)
+	OrganizationApplication() *Application
+	OrgScoped() bool
+
+	// Environments
+	ListEnvironments() ([]Environment, error)
+	GetEnvironmentByName(name string) (*Environment, error)
+	CreateEnvironment(request CreateEnvironmentRequest) (*Environment, error)
+	UpdateEnvironment(environmentID string, fields map[string]interface{}) (*Environment, error)
+	DeleteEnvironment(environmentID string) error
+
+	// Flags
+	ListFlags(applicationID string) ([]Flag, error)
+	EachFlag(applicationID string, fn func(Flag) error) error
+	GetFlagByName(applicationID, flagName string) (*Flag, error)
+	CreateFlag(applicationID, name, flagType, description string, variants []string, isPermanent bool) (*Flag, error)
+	CreateFlagFromRequest(applicationID string, request CreateFlagRequest) (*Flag, error)
+	UpdateFlag(applicationID, flagID string, fields map[string]interface{}) (*Flag, error)
+	RenameFlag(applicationID, flagID, newName string) (*Flag, error)
+	SetFlagLabels(applicationID, flagID string, labels []string) (*Flag, error)
+	DeleteFlag(applicationID, flagID string) error
+	GetCasc(cascURL string) ([]byte, error)
+
+	// Flag configurations
+	GetFlagConfiguration(applicationID, flagID, environmentID string) (*FlagConfigurationDetail, error)
+	UpdateFlagConfiguration(applicationID, flagID, environmentID string, config FlagConfiguration) error
+	SetFlagConfiguration(applicationID, flagID, environmentID string, config map[string]interface{}) error
+	SetFlagConfigurationIfMatch(applicationID, flagID, environmentID string, config map[string]interface{}, etag string) error
+	SetFlagConfigurations(applicationID string, updates []ConfigurationUpdate) ([]error, error)
+	DeleteFlagConfiguration(applicationID, flagID, environmentID string) error
+	ListFlagConfigurationRevisions(applicationID, flagID, environmentID string) ([]ConfigurationRevision, error)
+	GetFlagConfigurationRevision(applicationID, flagID, environmentID, revision string) (*ConfigurationRevision, error)
+
+	// Targeting and usage
+	ListProperties(applicationID string) ([]Property, error)
+	ListImpressions(applicationID string, query ImpressionsQuery) ([]Impression, error)
+}
+
+var _ API = (*Client)(nil)
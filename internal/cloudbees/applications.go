@@ -0,0 +1,105 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// GetApplicationResponse represents the response when creating or updating an application
+type GetApplicationResponse struct {
+	Service Application `json:"service"`
+}
+
+// ServiceTypeApplication is the service type of applications
+const ServiceTypeApplication = "APPLICATION"
+
+// CreateApplication creates an application in the organization
+func (c *Client) CreateApplication(application Application) (*Application, error) {
+	url := fmt.Sprintf("%s/v1/organizations/%s/services", c.baseURL, c.orgID)
+
+	if application.ServiceType == "" {
+		application.ServiceType = ServiceTypeApplication
+	}
+	if application.LinkedEnvironmentIDs == nil {
+		application.LinkedEnvironmentIDs = []string{}
+	}
+
+	resp, err := c.makeRequest("POST", url, map[string]interface{}{"service": application})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, newAPIError(resp)
+	}
+
+	var response GetApplicationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response.Service, nil
+}
+
+// UpdateApplication replaces the settings of an application, e.g. its linked environments
+func (c *Client) UpdateApplication(application Application) (*Application, error) {
+	url := fmt.Sprintf("%s/v1/organizations/%s/services/%s", c.baseURL, c.orgID, application.ID)
+
+	resp, err := c.makeRequest("PUT", url, map[string]interface{}{"service": application})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response GetApplicationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response.Service, nil
+}
+
+// LinkEnvironment links an environment to an application, so its flags can be configured in it.
+// Linking an environment that is already linked leaves the application unchanged.
+func (c *Client) LinkEnvironment(application *Application, environmentID string) (*Application, error) {
+	if application.HasEnvironment(environmentID) {
+		return application, nil
+	}
+
+	updated := *application
+	updated.LinkedEnvironmentIDs = append(append([]string{}, application.LinkedEnvironmentIDs...), environmentID)
+	return c.UpdateApplication(updated)
+}
+
+// UnlinkEnvironment removes an environment from the environments linked to an application.
+// Unlinking an environment that is not linked leaves the application unchanged.
+func (c *Client) UnlinkEnvironment(application *Application, environmentID string) (*Application, error) {
+	if !application.HasEnvironment(environmentID) {
+		return application, nil
+	}
+
+	updated := *application
+	updated.LinkedEnvironmentIDs = []string{}
+	for _, id := range application.LinkedEnvironmentIDs {
+		if id != environmentID {
+			updated.LinkedEnvironmentIDs = append(updated.LinkedEnvironmentIDs, id)
+		}
+	}
+	return c.UpdateApplication(updated)
+}
+
+// HasEnvironment reports whether the environment is linked to the application
+func (a *Application) HasEnvironment(environmentID string) bool {
+	for _, id := range a.LinkedEnvironmentIDs {
+		if id == environmentID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrBatchUnsupported is returned by SetFlagConfigurations when the API has no bulk configuration endpoint
+var ErrBatchUnsupported = errors.New("bulk configuration updates are not supported by the API")
+
+// ConfigurationUpdate is the change of one flag configuration in a bulk update
+type ConfigurationUpdate struct {
+	FlagID        string                 `json:"flagId"`
+	EnvironmentID string                 `json:"environmentId"`
+	Configuration map[string]interface{} `json:"configuration"`
+	IfMatch       string                 `json:"ifMatch,omitempty"` // ETag the configuration must still have
+}
+
+// configurationUpdateResult is the outcome of one update of a bulk update
+type configurationUpdateResult struct {
+	FlagID        string `json:"flagId"`
+	EnvironmentID string `json:"environmentId"`
+	Status        int    `json:"status"`
+	Message       string `json:"message,omitempty"`
+}
+
+// SetFlagConfigurations applies configuration changes to several flags and environments of an
+// application in one request. It returns one error per update, in order. When the API does not
+// support bulk updates, it returns ErrBatchUnsupported, now and for every later call, so callers
+// fall back to SetFlagConfigurationIfMatch.
+func (c *Client) SetFlagConfigurations(applicationID string, updates []ConfigurationUpdate) ([]error, error) {
+	if c.batchUnsupported.Load() {
+		return nil, ErrBatchUnsupported
+	}
+
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+	url := fmt.Sprintf("%s/v2/applications/%s/flags/configurations", c.baseURL, apiAppID)
+
+	resp, err := c.makeRequest("PUT", url, map[string]interface{}{"configurations": updates})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusMultiStatus:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		c.batchUnsupported.Store(true)
+		return nil, ErrBatchUnsupported
+	default:
+		return nil, newAPIError(resp)
+	}
+
+	var response struct {
+		Results []configurationUpdateResult `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(response.Results) != len(updates) {
+		return nil, fmt.Errorf("bulk update returned %d results for %d configurations", len(response.Results), len(updates))
+	}
+
+	errs := make([]error, len(updates))
+	for i, result := range response.Results {
+		switch {
+		case result.Status == http.StatusOK || result.Status == http.StatusNoContent:
+		case result.Status == http.StatusPreconditionFailed || result.Status == http.StatusConflict:
+			errs[i] = fmt.Errorf("%w: %s (request ID: %s)", ErrConflict, result.Message, responseRequestID(resp))
+		default:
+			errs[i] = &APIError{StatusCode: result.Status, Body: result.Message, RequestID: responseRequestID(resp)}
+		}
+	}
+	return errs, nil
+}
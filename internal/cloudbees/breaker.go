@@ -0,0 +1,62 @@
+package cloudbees
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when the circuit breaker has tripped and requests fail fast
+var ErrCircuitOpen = errors.New("circuit breaker open: too many consecutive API failures")
+
+// defaultBreakerCooldown is how long the breaker stays open before allowing a trial request
+const defaultBreakerCooldown = 30 * time.Second
+
+// circuitBreaker stops sending requests after a number of consecutive failures
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+// Allow reports whether a request may be sent
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= b.threshold && time.Now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Record updates the breaker with the outcome of a request
+func (b *circuitBreaker) Record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
+
+// SetCircuitBreaker makes the client fail fast after threshold consecutive failures
+// (network errors or 5xx responses); zero disables the breaker
+func (c *Client) SetCircuitBreaker(threshold int) {
+	if threshold <= 0 {
+		c.breaker = nil
+		return
+	}
+	c.breaker = &circuitBreaker{
+		threshold: threshold,
+		cooldown:  defaultBreakerCooldown,
+	}
+}
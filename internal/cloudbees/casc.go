@@ -0,0 +1,44 @@
+package cloudbees
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// GetCasc fetches the configuration-as-code document at the cascUrl of a flag. Relative URLs are
+// resolved against the API URL; the token is only sent to the API host.
+func (c *Client) GetCasc(cascURL string) ([]byte, error) {
+	if cascURL == "" {
+		return nil, fmt.Errorf("no configuration-as-code URL")
+	}
+	if strings.HasPrefix(cascURL, "/") {
+		cascURL = c.baseURL + cascURL
+	}
+
+	target, err := url.Parse(cascURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration-as-code URL '%s': %w", cascURL, err)
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if target.Scheme != base.Scheme || target.Host != base.Host {
+		return nil, fmt.Errorf("configuration-as-code URL '%s' is not on the API host %s", cascURL, base.Host)
+	}
+
+	resp, err := c.makeRequestWithHeaders("GET", cascURL, nil, map[string]string{"Accept": "application/yaml, application/json"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
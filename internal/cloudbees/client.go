@@ -3,12 +3,14 @@ package cloudbees
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"os"
-	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -18,15 +20,33 @@ type Client struct {
 	token       string
 	orgID       string
 	httpClient  *http.Client
+	transport   *http.Transport
 	useOrgAsApp bool // Flag to determine if we use org ID as application ID for flags API
+
+	orgAsAppFallback bool // Switch to useOrgAsApp when the application does not exist
+
+	limiter          *rateLimiter    // Optional client-side request rate limit
+	breaker          *circuitBreaker // Optional circuit breaker for degraded APIs
+	rateLimitedCount int64           // Number of 429 responses received, accessed atomically
+	observer         RequestObserver // Optional observer of every request attempt
+	requestLog       *requestLog     // Optional log of every request attempt
+	userAgent        string
+	batchUnsupported atomic.Bool   // The API has no bulk configuration endpoint
+	envByNameMissing atomic.Bool   // The API has no environment by-name endpoint
+	maxItems         int           // Maximum number of items of a list, 0 for no limit
+	etags            *ETagCache    // Optional cache of GET responses revalidated with If-None-Match
+	diskCache        *DiskCache    // Optional cache of GET responses shared by runs
+	readOnly         bool          // Refuse requests that change data
+	tokens           TokenProvider // Optional provider of renewed tokens, instead of token
 }
 
 // Environment represents an environment
 type Environment struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	ResourceID string `json:"resourceId"`
-	IsDisabled bool   `json:"isDisabled"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ResourceID  string `json:"resourceId"`
+	Description string `json:"description,omitempty"`
+	IsDisabled  bool   `json:"isDisabled"`
 }
 
 // ListEnvironmentsResponse represents the response when listing environments
@@ -44,6 +64,9 @@ type Flag struct {
 	IsPermanent bool     `json:"isPermanent"`
 	ResourceID  string   `json:"resourceId"`
 	CascURL     string   `json:"cascUrl"`
+	Labels      []string `json:"labels,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Updated     string   `json:"updated,omitempty"`
 }
 
 // GetFlagResponse represents the response when getting a flag
@@ -53,11 +76,11 @@ type GetFlagResponse struct {
 
 // FlagConfiguration represents a flag configuration
 type FlagConfiguration struct {
-	Enabled            bool        `json:"enabled"`
-	DefaultValue       interface{} `json:"defaultValue"`
-	Conditions         interface{} `json:"conditions"`
-	VariantsEnabled    bool        `json:"variantsEnabled"`
-	StickinessProperty string      `json:"stickinessProperty,omitempty"`
+	Enabled            bool        `json:"enabled" yaml:"enabled"`
+	DefaultValue       interface{} `json:"defaultValue" yaml:"defaultValue"`
+	Conditions         interface{} `json:"conditions" yaml:"conditions,omitempty"`
+	VariantsEnabled    bool        `json:"variantsEnabled" yaml:"variantsEnabled"`
+	StickinessProperty string      `json:"stickinessProperty,omitempty" yaml:"stickinessProperty,omitempty"`
 }
 
 // FlagConfigurationDetail represents detailed flag configuration
@@ -69,10 +92,17 @@ type FlagConfigurationDetail struct {
 	Created       string            `json:"created"`
 	Updated       string            `json:"updated"`
 	Configuration FlagConfiguration `json:"configuration"`
+	ETag          string            `json:"etag,omitempty"` // ETag header returned by the API, if any
+	Revision      string            `json:"revision"`       // ETag, or a content hash when the API returns none
 }
 
 // GetFlagConfigurationResponse represents the response when getting flag configuration
 type GetFlagConfigurationResponse struct {
+	FlagName      string            `json:"flagName"`
+	Description   string            `json:"description"`
+	Labels        []string          `json:"labels"`
+	Created       string            `json:"created"`
+	Updated       string            `json:"updated"`
 	Configuration FlagConfiguration `json:"configuration"`
 }
 
@@ -88,6 +118,7 @@ type CreateFlagRequest struct {
 	Variants    []string `json:"variants"`
 	Description string   `json:"description"`
 	IsPermanent bool     `json:"isPermanent"`
+	Labels      []string `json:"labels,omitempty"`
 }
 
 // CreateFlagResponse represents response when creating a flag
@@ -136,39 +167,165 @@ func NewClientWithOptions(baseURL, token, orgID string, useOrgAsApp bool) (*Clie
 		return nil, fmt.Errorf("organization ID is required")
 	}
 
+	// Honor HTTPS_PROXY, HTTP_PROXY and NO_PROXY unless overridden with SetProxy
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
 	client := &Client{
 		baseURL:     strings.TrimSuffix(baseURL, "/"),
 		token:       token,
 		orgID:       orgID,
 		useOrgAsApp: useOrgAsApp,
+		transport:   transport,
+		userAgent:   DefaultUserAgent,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: transport,
 		},
 	}
 
 	return client, nil
 }
 
+// roundTripper returns the transport currently used by the HTTP client
+func (c *Client) roundTripper() http.RoundTripper {
+	if c.httpClient.Transport != nil {
+		return c.httpClient.Transport
+	}
+	return http.DefaultTransport
+}
+
 // makeRequest is a helper method to make HTTP requests
 func (c *Client) makeRequest(method, url string, body interface{}) (*http.Response, error) {
-	var reqBody io.Reader
+	return c.makeRequestWithHeaders(method, url, body, nil)
+}
+
+// makeRequestWithHeaders makes an HTTP request with additional request headers. GET requests
+// go through the response caches, which other successful requests invalidate.
+func (c *Client) makeRequestWithHeaders(method, url string, body interface{}, headers map[string]string) (*http.Response, error) {
+	if method != http.MethodGet {
+		if c.readOnly {
+			return nil, fmt.Errorf("%w: refusing %s %s", ErrReadOnly, method, url)
+		}
+		resp, err := c.sendRequest(method, url, body, headers)
+		if err == nil && resp.StatusCode < 400 {
+			if c.etags != nil {
+				c.etags.Invalidate()
+			}
+			if c.diskCache != nil {
+				c.diskCache.Invalidate()
+			}
+		}
+		return resp, err
+	}
+
+	get := func() (*http.Response, error) {
+		if c.etags == nil {
+			return c.sendRequest(method, url, body, headers)
+		}
+		return c.etags.do(c.token+" "+url, headers, func(headers map[string]string) (*http.Response, error) {
+			return c.sendRequest(method, url, body, headers)
+		})
+	}
+	if c.diskCache != nil {
+		return c.diskCache.do(c.token+" "+url, get)
+	}
+	return get()
+}
+
+// sendRequest sends an HTTP request, retrying it when throttled
+func (c *Client) sendRequest(method, url string, body interface{}, headers map[string]string) (*http.Response, error) {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, err
+	// Retries of the same call keep its request ID
+	requestID := headers[RequestIDHeader]
+	if requestID == "" {
+		requestID = newRequestID()
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Content-Type", "application/json")
+	reauthenticated := false
+	for attempt := 0; ; attempt++ {
+		token, err := c.currentToken()
+		if err != nil {
+			return nil, err
+		}
+
+		var reqBody io.Reader
+		if jsonData != nil {
+			reqBody = bytes.NewReader(jsonData)
+		}
+
+		req, err := http.NewRequest(method, url, reqBody)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		req.Header.Set(RequestIDHeader, requestID)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		if c.breaker != nil {
+			if err := c.breaker.Allow(); err != nil {
+				return nil, err
+			}
+		}
+
+		if c.limiter != nil {
+			c.limiter.Wait()
+		}
+
+		start := time.Now()
+		resp, err := c.httpClient.Do(req)
+		if c.breaker != nil {
+			c.breaker.Record(err == nil && resp.StatusCode < 500)
+		}
+		observed := RequestAttempt{Method: method, URL: url, Attempt: attempt, RequestID: requestID, Err: err, Start: start, Duration: time.Since(start)}
+		if resp != nil {
+			observed.StatusCode = resp.StatusCode
+		}
+		if c.observer != nil {
+			c.observer.ObserveRequest(observed)
+		}
+		if c.requestLog != nil {
+			c.requestLog.write(observed)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w (request ID: %s)", err, requestID)
+		}
+
+		// Token expired or revoked: retry once with a new token from the provider
+		if resp.StatusCode == http.StatusUnauthorized && c.tokens != nil && !reauthenticated {
+			reauthenticated = true
+			c.tokens.Invalidate(token)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
 
-	return c.httpClient.Do(req)
+		// Throttled by the API: wait as instructed by Retry-After and try again
+		atomic.AddInt64(&c.rateLimitedCount, 1)
+		if attempt >= maxRateLimitRetries {
+			return resp, nil
+		}
+		wait := retryAfter(resp)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		time.Sleep(wait)
+	}
 }
 
 // ListEnvironments retrieves all environments for the organization
@@ -182,16 +339,61 @@ func (c *Client) ListEnvironments() ([]Environment, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp)
 	}
 
-	var response ListEnvironmentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	environments := []Environment{}
+	err = decodeList(resp.Body, "environments", c.maxItems, func(env Environment) error {
+		environments = append(environments, env)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return environments, nil
+}
+
+// GetEnvironmentByName retrieves an environment by its name. APIs without the by-name endpoint
+// answer 404, in which case the environments are listed instead.
+func (c *Client) GetEnvironmentByName(name string) (*Environment, error) {
+	if !c.envByNameMissing.Load() {
+		url := fmt.Sprintf("%s/v2/organizations/%s/environments/by-name/%s", c.baseURL, c.orgID, neturl.PathEscape(name))
+
+		resp, err := c.makeRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusOK {
+			var response struct {
+				Environment Environment `json:"environment"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+				return nil, err
+			}
+			return &response.Environment, nil
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			return nil, newAPIError(resp)
+		}
+	}
+
+	environments, err := c.ListEnvironments()
+	if err != nil {
 		return nil, err
 	}
 
-	return response.Environments, nil
+	for _, env := range environments {
+		if env.Name == name {
+			// The by-name endpoint did not find an existing environment, so it does not exist
+			c.envByNameMissing.Store(true)
+			return &env, nil
+		}
+	}
+
+	return nil, fmt.Errorf("environment '%s' %w", name, ErrNotFound)
 }
 
 // GetFlagByName retrieves a flag by name from the organization
@@ -210,8 +412,7 @@ func (c *Client) GetFlagByName(applicationID, flagName string) (*Flag, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	var response GetFlagResponse
@@ -239,8 +440,7 @@ func (c *Client) GetFlagConfiguration(applicationID, flagID, environmentID strin
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	var response GetFlagConfigurationResponse
@@ -251,7 +451,17 @@ func (c *Client) GetFlagConfiguration(applicationID, flagID, environmentID strin
 	// Create a FlagConfigurationDetail with the response data
 	config := &FlagConfigurationDetail{
 		FlagID:        flagID,
+		FlagName:      response.FlagName,
+		Description:   response.Description,
+		Labels:        response.Labels,
+		Created:       response.Created,
+		Updated:       response.Updated,
 		Configuration: response.Configuration,
+		ETag:          resp.Header.Get("ETag"),
+	}
+	config.Revision = config.ETag
+	if config.Revision == "" {
+		config.Revision = configurationRevision(response.Configuration)
 	}
 
 	return config, nil
@@ -259,8 +469,6 @@ func (c *Client) GetFlagConfiguration(applicationID, flagID, environmentID strin
 
 // UpdateFlagConfiguration updates flag configuration for a specific environment
 func (c *Client) UpdateFlagConfiguration(applicationID, flagID, environmentID string, config FlagConfiguration) error {
-	fmt.Printf("DEBUG: UpdateFlagConfiguration called with appID=%s, flagID=%s, envID=%s\n", applicationID, flagID, environmentID)
-
 	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
 	apiAppID := applicationID
 	if c.useOrgAsApp {
@@ -273,11 +481,6 @@ func (c *Client) UpdateFlagConfiguration(applicationID, flagID, environmentID st
 		Configuration: config,
 	}
 
-	// Debug: Log the request payload
-	requestJSON, _ := json.Marshal(request)
-	fmt.Printf("DEBUG: Sending PUT request to: %s\n", url)
-	fmt.Printf("DEBUG: Request payload: %s\n", string(requestJSON))
-
 	resp, err := c.makeRequest("PUT", url, request)
 	if err != nil {
 		return err
@@ -285,8 +488,7 @@ func (c *Client) UpdateFlagConfiguration(applicationID, flagID, environmentID st
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp)
 	}
 
 	return nil
@@ -294,6 +496,12 @@ func (c *Client) UpdateFlagConfiguration(applicationID, flagID, environmentID st
 
 // SetFlagConfiguration sets flag configuration using PUT with only specified fields
 func (c *Client) SetFlagConfiguration(applicationID, flagID, environmentID string, config map[string]interface{}) error {
+	return c.SetFlagConfigurationIfMatch(applicationID, flagID, environmentID, config, "")
+}
+
+// SetFlagConfigurationIfMatch sets flag configuration only if the remote ETag still matches etag.
+// An empty etag performs an unconditional update. Returns ErrConflict if the remote changed.
+func (c *Client) SetFlagConfigurationIfMatch(applicationID, flagID, environmentID string, config map[string]interface{}, etag string) error {
 	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
 	apiAppID := applicationID
 	if c.useOrgAsApp {
@@ -302,51 +510,70 @@ func (c *Client) SetFlagConfiguration(applicationID, flagID, environmentID strin
 	url := fmt.Sprintf("%s/v2/applications/%s/flags/%s/configuration/environments/%s",
 		c.baseURL, apiAppID, flagID, environmentID)
 
+	headers := map[string]string{}
+	if etag != "" {
+		headers["If-Match"] = etag
+	}
+
 	// Based on user testing, the API uses PUT for partial updates (opposite to REST conventions)
-	resp, err := c.makeRequest("PUT", url, config)
+	resp, err := c.makeRequestWithHeaders("PUT", url, config, headers)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("%w: %s (request ID: %s)", ErrConflict, string(body), responseRequestID(resp))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
 	}
 
 	return nil
 }
 
-// ListFlags retrieves all flags for the application
-func (c *Client) ListFlags(applicationID string) ([]Flag, error) {
+// DeleteFlagConfiguration removes the configuration of a flag in an environment, so the flag
+// falls back to its defaults there. A missing configuration is not an error.
+func (c *Client) DeleteFlagConfiguration(applicationID, flagID, environmentID string) error {
 	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
 	apiAppID := applicationID
 	if c.useOrgAsApp {
 		apiAppID = c.orgID
 	}
-	url := fmt.Sprintf("%s/v2/applications/%s/flags", c.baseURL, apiAppID)
+	url := fmt.Sprintf("%s/v2/applications/%s/flags/%s/configuration/environments/%s",
+		c.baseURL, apiAppID, flagID, environmentID)
 
-	resp, err := c.makeRequest("GET", url, nil)
+	resp, err := c.makeRequest("DELETE", url, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return newAPIError(resp)
 	}
 
-	var response ListFlagsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	return nil
+}
+
+// ListFlags retrieves all flags for the application
+func (c *Client) ListFlags(applicationID string) ([]Flag, error) {
+	flags := []Flag{}
+	err := c.EachFlag(applicationID, func(flag Flag) error {
+		flags = append(flags, flag)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	return response.Flags, nil
+	return flags, nil
 }
 
-// CreateFlag creates a new feature flag
-func (c *Client) CreateFlag(applicationID, name, flagType, description string, variants []string, isPermanent bool) (*Flag, error) {
+// EachFlag calls fn for every flag of the application as the list is received, without holding
+// the whole response in memory. An error returned by fn stops the iteration and is returned.
+func (c *Client) EachFlag(applicationID string, fn func(Flag) error) error {
 	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
 	apiAppID := applicationID
 	if c.useOrgAsApp {
@@ -354,13 +581,38 @@ func (c *Client) CreateFlag(applicationID, name, flagType, description string, v
 	}
 	url := fmt.Sprintf("%s/v2/applications/%s/flags", c.baseURL, apiAppID)
 
-	request := CreateFlagRequest{
+	resp, err := c.makeRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp)
+	}
+
+	return decodeList(resp.Body, "flags", c.maxItems, fn)
+}
+
+// CreateFlag creates a new feature flag
+func (c *Client) CreateFlag(applicationID, name, flagType, description string, variants []string, isPermanent bool) (*Flag, error) {
+	return c.CreateFlagFromRequest(applicationID, CreateFlagRequest{
 		Name:        name,
 		FlagType:    flagType,
 		Variants:    variants,
 		Description: description,
 		IsPermanent: isPermanent,
+	})
+}
+
+// CreateFlagFromRequest creates a new feature flag from a complete request, including labels
+func (c *Client) CreateFlagFromRequest(applicationID string, request CreateFlagRequest) (*Flag, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
 	}
+	url := fmt.Sprintf("%s/v2/applications/%s/flags", c.baseURL, apiAppID)
 
 	resp, err := c.makeRequest("POST", url, request)
 	if err != nil {
@@ -369,8 +621,7 @@ func (c *Client) CreateFlag(applicationID, name, flagType, description string, v
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp)
 	}
 
 	var response CreateFlagResponse
@@ -381,6 +632,47 @@ func (c *Client) CreateFlag(applicationID, name, flagType, description string, v
 	return &response.Flag, nil
 }
 
+// UpdateFlag updates flag metadata (name, description, labels, ...) with only the specified fields
+func (c *Client) UpdateFlag(applicationID, flagID string, fields map[string]interface{}) (*Flag, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+	url := fmt.Sprintf("%s/v2/applications/%s/flags/%s", c.baseURL, apiAppID, flagID)
+
+	// Like configuration updates, the API uses PUT for partial updates
+	resp, err := c.makeRequest("PUT", url, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response GetFlagResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response.Flag, nil
+}
+
+// RenameFlag changes the name of a feature flag
+func (c *Client) RenameFlag(applicationID, flagID, newName string) (*Flag, error) {
+	return c.UpdateFlag(applicationID, flagID, map[string]interface{}{"name": newName})
+}
+
+// SetFlagLabels replaces the labels of a feature flag
+func (c *Client) SetFlagLabels(applicationID, flagID string, labels []string) (*Flag, error) {
+	if labels == nil {
+		labels = []string{}
+	}
+	return c.UpdateFlag(applicationID, flagID, map[string]interface{}{"labels": labels})
+}
+
 // DeleteFlag deletes a feature flag
 func (c *Client) DeleteFlag(applicationID, flagID string) error {
 	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
@@ -397,8 +689,7 @@ func (c *Client) DeleteFlag(applicationID, flagID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return newAPIError(resp)
 	}
 
 	return nil
@@ -406,7 +697,15 @@ func (c *Client) DeleteFlag(applicationID, flagID string) error {
 
 // ListApplications retrieves all applications for the organization
 func (c *Client) ListApplications() ([]Application, error) {
+	return c.listApplications(nil)
+}
+
+// listApplications retrieves the applications for the organization matching the filters of query
+func (c *Client) listApplications(query neturl.Values) ([]Application, error) {
 	url := fmt.Sprintf("%s/v1/organizations/%s/services?typeFilter=APPLICATION_FILTER", c.baseURL, c.orgID)
+	if len(query) > 0 {
+		url += "&" + query.Encode()
+	}
 
 	resp, err := c.makeRequest("GET", url, nil)
 	if err != nil {
@@ -415,22 +714,29 @@ func (c *Client) ListApplications() ([]Application, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp)
 	}
 
-	var response ListApplicationsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	applications := []Application{}
+	err = decodeList(resp.Body, "service", c.maxItems, func(app Application) error {
+		applications = append(applications, app)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return response.Service, nil
+	return applications, nil
 }
 
-// GetApplicationByName retrieves an application by its name
+// GetApplicationByName retrieves an application by its name. The API filters the applications by
+// name; the filter is checked again in case the API ignored it.
 func (c *Client) GetApplicationByName(name string) (*Application, error) {
-	applications, err := c.ListApplications()
+	applications, err := c.listApplications(neturl.Values{"nameFilter": {name}})
 	if err != nil {
+		if c.orgAsAppFallback && errors.Is(err, ErrNotFound) {
+			return c.organizationApplication(name), nil
+		}
 		return nil, err
 	}
 
@@ -440,18 +746,78 @@ func (c *Client) GetApplicationByName(name string) (*Application, error) {
 		}
 	}
 
-	return nil, fmt.Errorf("application '%s' not found", name)
+	if c.orgAsAppFallback {
+		return c.organizationApplication(name), nil
+	}
+	return nil, fmt.Errorf("application '%s' %w", name, ErrNotFound)
+}
+
+// SetOrgAsAppFallback makes the client switch to the organization-level flags API of legacy
+// organizations when an application is not found, instead of failing
+func (c *Client) SetOrgAsAppFallback(enabled bool) {
+	c.orgAsAppFallback = enabled
 }
 
-// WriteOutput writes outputs in CloudBees format to $CLOUDBEES_OUTPUTS files
-func WriteOutput(name, value string) {
-	if outDir := os.Getenv("CLOUDBEES_OUTPUTS"); outDir != "" {
-		filepath := path.Join(outDir, name)
-		if err := os.WriteFile(filepath, []byte(value), 0640); err != nil {
-			// Don't fail the whole operation if output writing fails, just log it
-			fmt.Printf("Warning: failed to write CloudBees output %s: %v\n", name, err)
+// GetApplication retrieves an application by its ID
+func (c *Client) GetApplication(id string) (*Application, error) {
+	url := fmt.Sprintf("%s/v1/organizations/%s/services/%s", c.baseURL, c.orgID, neturl.PathEscape(id))
+
+	resp, err := c.makeRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var response struct {
+			Service Application `json:"service"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, err
+		}
+		return &response.Service, nil
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("application with ID '%s' %w", id, ErrNotFound)
+	case http.StatusMethodNotAllowed:
+		// Older APIs only list the applications
+	default:
+		return nil, newAPIError(resp)
+	}
+
+	applications, err := c.ListApplications()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, app := range applications {
+		if app.ID == id {
+			return &app, nil
 		}
-	} else {
-		fmt.Printf("Warning: CLOUDBEES_OUTPUTS environment variable not set, skipping output %s=%s\n", name, value)
 	}
+
+	return nil, fmt.Errorf("application with ID '%s' %w", id, ErrNotFound)
+}
+
+// OrgScoped reports whether flags are managed at the organization level (legacy mode), or are
+// when the application does not exist
+func (c *Client) OrgScoped() bool {
+	return c.useOrgAsApp || c.orgAsAppFallback
+}
+
+// OrganizationApplication switches to the organization-level flags API and returns the
+// organization in place of an application
+func (c *Client) OrganizationApplication() *Application {
+	c.useOrgAsApp = true
+	return &Application{ID: c.orgID, Name: c.orgID, OrganizationID: c.orgID}
+}
+
+// organizationApplication falls back to the organization-level flags API for an application that does not exist
+func (c *Client) organizationApplication(name string) *Application {
+	if !c.useOrgAsApp {
+		fmt.Fprintf(os.Stderr, "Warning: application '%s' not found, using the organization-level flags API (legacy mode)\n", name)
+	}
+	application := c.OrganizationApplication()
+	application.Name = name
+	return application
 }
@@ -0,0 +1,100 @@
+package cloudbees
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DiskCache keeps GET responses in a directory for a TTL, so read-only commands run in a row
+// reuse them without calling the API. Any change made through a client with the cache removes
+// the directory, so a change is never followed by a stale read from the same machine.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// diskCacheEntry is a cached response
+type diskCacheEntry struct {
+	Fetched time.Time   `json:"fetched"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+}
+
+// NewDiskCache creates a cache of responses in dir that are fresh for ttl. With a zero TTL
+// nothing is read from nor written to the cache, but changes still invalidate it.
+func NewDiskCache(dir string, ttl time.Duration) *DiskCache {
+	return &DiskCache{dir: dir, ttl: ttl}
+}
+
+// SetDiskCache makes the client read GET responses from the cache and invalidate it on changes
+func (c *Client) SetDiskCache(cache *DiskCache) {
+	c.diskCache = cache
+}
+
+// Invalidate removes every cached response
+func (d *DiskCache) Invalidate() error {
+	return os.RemoveAll(d.dir)
+}
+
+// file returns the file of the response cached for key
+func (d *DiskCache) file(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// do returns the fresh response cached for key, or gets it with send and caches it. The
+// cache is best effort: a missing or unwritable directory only means the API is called.
+func (d *DiskCache) do(key string, send func() (*http.Response, error)) (*http.Response, error) {
+	if d.ttl <= 0 {
+		return send()
+	}
+
+	file := d.file(key)
+	var entry diskCacheEntry
+	if data, err := os.ReadFile(file); err == nil && json.Unmarshal(data, &entry) == nil && time.Since(entry.Fetched) < d.ttl {
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        entry.Header,
+			Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+			ContentLength: int64(len(entry.Body)),
+		}, nil
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry = diskCacheEntry{
+		Fetched: time.Now(),
+		Header:  http.Header{"Content-Type": resp.Header.Values("Content-Type"), "Etag": resp.Header.Values("ETag")},
+		Body:    body,
+	}
+	if data, err := json.Marshal(entry); err == nil && os.MkdirAll(d.dir, 0700) == nil {
+		if tmp, err := os.CreateTemp(d.dir, ".entry-*"); err == nil {
+			_, err := tmp.Write(data)
+			if closeErr := tmp.Close(); err == nil && closeErr == nil {
+				os.Rename(tmp.Name(), file)
+			}
+			os.Remove(tmp.Name())
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,31 @@
+package cloudbees
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// VerifyEndpoint checks that the base URL serves the v2 platform API, so a mistyped API URL is
+// reported as such rather than as a missing application or flag. Authentication errors are
+// accepted, as they come from the API itself.
+func (c *Client) VerifyEndpoint() error {
+	url := fmt.Sprintf("%s/v2/organizations/%s/environments", c.baseURL, c.orgID)
+
+	resp, err := c.makeRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to reach the API at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch {
+	case resp.StatusCode == http.StatusNotFound && mediaType != "application/json":
+		return fmt.Errorf("%s does not serve the CloudBees Platform API v2 (status %d), check the API URL", c.baseURL, resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return newAPIError(resp)
+	case mediaType != "application/json":
+		return fmt.Errorf("%s does not serve the CloudBees Platform API v2 (content type '%s'), check the API URL", c.baseURL, mediaType)
+	}
+	return nil
+}
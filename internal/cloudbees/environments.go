@@ -0,0 +1,80 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CreateEnvironmentRequest represents the request to create an environment
+type CreateEnvironmentRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	IsDisabled  bool   `json:"isDisabled"`
+}
+
+// GetEnvironmentResponse represents the response when creating or updating an environment
+type GetEnvironmentResponse struct {
+	Environment Environment `json:"environment"`
+}
+
+// CreateEnvironment creates an environment in the organization
+func (c *Client) CreateEnvironment(request CreateEnvironmentRequest) (*Environment, error) {
+	url := fmt.Sprintf("%s/v2/organizations/%s/environments", c.baseURL, c.orgID)
+
+	resp, err := c.makeRequest("POST", url, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, newAPIError(resp)
+	}
+
+	var response GetEnvironmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response.Environment, nil
+}
+
+// UpdateEnvironment updates an environment (name, description, isDisabled) with only the specified fields
+func (c *Client) UpdateEnvironment(environmentID string, fields map[string]interface{}) (*Environment, error) {
+	url := fmt.Sprintf("%s/v2/organizations/%s/environments/%s", c.baseURL, c.orgID, environmentID)
+
+	resp, err := c.makeRequest("PUT", url, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response GetEnvironmentResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response.Environment, nil
+}
+
+// DeleteEnvironment deletes an environment together with the flag configurations scoped to it
+func (c *Client) DeleteEnvironment(environmentID string) error {
+	url := fmt.Sprintf("%s/v2/organizations/%s/environments/%s", c.baseURL, c.orgID, environmentID)
+
+	resp, err := c.makeRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return newAPIError(resp)
+	}
+
+	return nil
+}
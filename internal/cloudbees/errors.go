@@ -0,0 +1,36 @@
+package cloudbees
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrNotFound is returned when an application, environment or flag does not exist
+var ErrNotFound = errors.New("not found")
+
+// APIError is returned when the API responds with an unexpected status
+type APIError struct {
+	StatusCode int
+	Body       string
+	RequestID  string // X-Request-ID of the failed request, for CloudBees support
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("API request failed with status %d: %s (request ID: %s)", e.StatusCode, e.Body, e.RequestID)
+	}
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Is makes 404 responses match ErrNotFound
+func (e *APIError) Is(target error) bool {
+	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+}
+
+// newAPIError reads the response body into an APIError
+func newAPIError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{StatusCode: resp.StatusCode, Body: string(body), RequestID: responseRequestID(resp)}
+}
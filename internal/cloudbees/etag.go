@@ -0,0 +1,117 @@
+package cloudbees
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// maxETagCacheBody is the largest response kept by the ETag cache; larger ones, such as the
+	// flag list of a large application, are streamed to the caller and not cached
+	maxETagCacheBody = 1 << 20
+	// maxETagCacheEntries bounds the number of responses kept by the ETag cache
+	maxETagCacheEntries = 10000
+)
+
+// ETagCache keeps the GET responses that carry an ETag, so they are requested again with
+// If-None-Match and an unchanged resource is answered with 304 Not Modified and no body.
+// One cache can be shared by the clients of a process; responses are kept per token.
+type ETagCache struct {
+	mu      sync.Mutex
+	entries map[string]etagEntry
+	hits    atomic.Int64
+}
+
+// etagEntry is a cached response
+type etagEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// NewETagCache creates an empty ETag cache
+func NewETagCache() *ETagCache {
+	return &ETagCache{entries: map[string]etagEntry{}}
+}
+
+// SetETagCache makes the client revalidate its GET requests with the cache
+func (c *Client) SetETagCache(cache *ETagCache) {
+	c.etags = cache
+}
+
+// Hits returns the number of responses served from the cache after a 304 Not Modified
+func (e *ETagCache) Hits() int64 {
+	return e.hits.Load()
+}
+
+// Invalidate drops every cached response, after a change that may affect any of them
+func (e *ETagCache) Invalidate() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	clear(e.entries)
+}
+
+// do sends a GET request with send, conditional on the cached response of key, and returns
+// the cached response when the resource was not modified
+func (e *ETagCache) do(key string, headers map[string]string, send func(map[string]string) (*http.Response, error)) (*http.Response, error) {
+	e.mu.Lock()
+	entry, cached := e.entries[key]
+	e.mu.Unlock()
+
+	if cached && headers["If-None-Match"] == "" {
+		conditional := map[string]string{"If-None-Match": entry.etag}
+		for name, value := range headers {
+			conditional[name] = value
+		}
+		headers = conditional
+	}
+
+	resp, err := send(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		e.hits.Add(1)
+		return &http.Response{
+			Status:        "200 OK",
+			StatusCode:    http.StatusOK,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        entry.header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(entry.body)),
+			ContentLength: int64(len(entry.body)),
+			Request:       resp.Request,
+		}, nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxETagCacheBody+1))
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if len(body) > maxETagCacheBody {
+			resp.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+			return resp, nil
+		}
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		e.mu.Lock()
+		if len(e.entries) >= maxETagCacheEntries {
+			clear(e.entries)
+		}
+		e.entries[key] = etagEntry{etag: resp.Header.Get("ETag"), header: resp.Header.Clone(), body: body}
+		e.mu.Unlock()
+	}
+	return resp, nil
+}
@@ -0,0 +1,69 @@
+package cloudbees
+
+import (
+	"strings"
+	"time"
+)
+
+// Experiments run through a flag are recorded as structured labels, like ownership and expiry
+const (
+	ExperimentLabelPrefix        = "experiment:" // Environment the experiment runs in
+	ExperimentStartedLabelPrefix = "experiment-started:"
+	ExperimentStoppedLabelPrefix = "experiment-stopped:"
+	ExperimentWinnerLabelPrefix  = "experiment-winner:"
+)
+
+// Experiment is the experiment metadata recorded in the labels of a flag
+type Experiment struct {
+	Environment string    `json:"environment"`
+	Started     time.Time `json:"started"`
+	Stopped     time.Time `json:"stopped,omitempty"`
+	Winner      string    `json:"winner,omitempty"`
+}
+
+// Running reports whether the experiment has not been stopped
+func (e Experiment) Running() bool {
+	return e.Stopped.IsZero()
+}
+
+// Experiment returns the experiment recorded in the flag labels, if any
+func (f Flag) Experiment() (Experiment, bool) {
+	var experiment Experiment
+	found := false
+	for _, label := range f.Labels {
+		switch {
+		case strings.HasPrefix(label, ExperimentLabelPrefix):
+			experiment.Environment = strings.TrimPrefix(label, ExperimentLabelPrefix)
+			found = true
+		case strings.HasPrefix(label, ExperimentStartedLabelPrefix):
+			experiment.Started, _ = time.Parse(time.RFC3339, strings.TrimPrefix(label, ExperimentStartedLabelPrefix))
+		case strings.HasPrefix(label, ExperimentStoppedLabelPrefix):
+			experiment.Stopped, _ = time.Parse(time.RFC3339, strings.TrimPrefix(label, ExperimentStoppedLabelPrefix))
+		case strings.HasPrefix(label, ExperimentWinnerLabelPrefix):
+			experiment.Winner = strings.TrimPrefix(label, ExperimentWinnerLabelPrefix)
+		}
+	}
+	return experiment, found
+}
+
+// WithExperimentLabels returns labels with the experiment labels replaced by the given experiment
+func WithExperimentLabels(labels []string, experiment Experiment) []string {
+	result := []string{}
+	for _, label := range labels {
+		if strings.HasPrefix(label, ExperimentLabelPrefix) || strings.HasPrefix(label, ExperimentStartedLabelPrefix) ||
+			strings.HasPrefix(label, ExperimentStoppedLabelPrefix) || strings.HasPrefix(label, ExperimentWinnerLabelPrefix) {
+			continue
+		}
+		result = append(result, label)
+	}
+
+	result = append(result, ExperimentLabelPrefix+experiment.Environment)
+	result = append(result, ExperimentStartedLabelPrefix+experiment.Started.UTC().Format(time.RFC3339))
+	if !experiment.Stopped.IsZero() {
+		result = append(result, ExperimentStoppedLabelPrefix+experiment.Stopped.UTC().Format(time.RFC3339))
+	}
+	if experiment.Winner != "" {
+		result = append(result, ExperimentWinnerLabelPrefix+experiment.Winner)
+	}
+	return result
+}
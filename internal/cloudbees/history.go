@@ -0,0 +1,77 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ConfigurationRevision is a previous version of a flag configuration in an environment
+type ConfigurationRevision struct {
+	Revision      string            `json:"revision"`
+	Timestamp     string            `json:"timestamp"`
+	Actor         string            `json:"actor"`
+	Configuration FlagConfiguration `json:"configuration"`
+}
+
+// ListConfigurationRevisionsResponse represents the response when listing configuration revisions
+type ListConfigurationRevisionsResponse struct {
+	Revisions []ConfigurationRevision `json:"revisions"`
+}
+
+// ListFlagConfigurationRevisions retrieves the revisions of a flag configuration in an environment, newest first
+func (c *Client) ListFlagConfigurationRevisions(applicationID, flagID, environmentID string) ([]ConfigurationRevision, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+	url := fmt.Sprintf("%s/v2/applications/%s/flags/%s/configuration/environments/%s/revisions",
+		c.baseURL, apiAppID, flagID, environmentID)
+
+	resp, err := c.makeRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response ListConfigurationRevisionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Revisions, nil
+}
+
+// GetFlagConfigurationRevision retrieves a revision of a flag configuration in an environment
+func (c *Client) GetFlagConfigurationRevision(applicationID, flagID, environmentID, revision string) (*ConfigurationRevision, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+	requestURL := fmt.Sprintf("%s/v2/applications/%s/flags/%s/configuration/environments/%s/revisions/%s",
+		c.baseURL, apiAppID, flagID, environmentID, url.PathEscape(revision))
+
+	resp, err := c.makeRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response ConfigurationRevision
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
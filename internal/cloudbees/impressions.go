@@ -0,0 +1,73 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Impression is the number of evaluations of a flag that served a variant in an environment
+type Impression struct {
+	FlagID        string `json:"flagId"`
+	FlagName      string `json:"flagName"`
+	EnvironmentID string `json:"environmentId"`
+	Variant       string `json:"variant"`
+	Count         int64  `json:"count"`
+	LastSeen      string `json:"lastSeen,omitempty"`
+}
+
+// ListImpressionsResponse represents the response when listing impressions
+type ListImpressionsResponse struct {
+	Impressions []Impression `json:"impressions"`
+}
+
+// ImpressionsQuery selects the impressions to list. Empty IDs select every flag or environment.
+type ImpressionsQuery struct {
+	EnvironmentID string
+	FlagID        string
+	From          time.Time
+	To            time.Time
+}
+
+// ListImpressions retrieves the evaluation counts of the application's flags per variant over a time window
+func (c *Client) ListImpressions(applicationID string, query ImpressionsQuery) ([]Impression, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+
+	params := url.Values{}
+	if query.EnvironmentID != "" {
+		params.Set("environmentId", query.EnvironmentID)
+	}
+	if query.FlagID != "" {
+		params.Set("flagId", query.FlagID)
+	}
+	if !query.From.IsZero() {
+		params.Set("from", query.From.UTC().Format(time.RFC3339))
+	}
+	if !query.To.IsZero() {
+		params.Set("to", query.To.UTC().Format(time.RFC3339))
+	}
+	requestURL := fmt.Sprintf("%s/v2/applications/%s/flags/impressions?%s", c.baseURL, apiAppID, params.Encode())
+
+	resp, err := c.makeRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response ListImpressionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Impressions, nil
+}
@@ -0,0 +1,90 @@
+package cloudbees
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Flag ownership and expiry are stored as structured labels so they survive in the platform UI
+const (
+	OwnerLabelPrefix   = "owner:"
+	ExpiresLabelPrefix = "expires:"
+
+	// ExpiryDateFormat is the date format used in expires labels
+	ExpiryDateFormat = "2006-01-02"
+)
+
+// Owner returns the owner recorded in the flag labels, or an empty string
+func (f Flag) Owner() string {
+	for _, label := range f.Labels {
+		if strings.HasPrefix(label, OwnerLabelPrefix) {
+			return strings.TrimPrefix(label, OwnerLabelPrefix)
+		}
+	}
+	return ""
+}
+
+// Expires returns the expiry date recorded in the flag labels, if any
+func (f Flag) Expires() (time.Time, bool) {
+	for _, label := range f.Labels {
+		if strings.HasPrefix(label, ExpiresLabelPrefix) {
+			date, err := time.Parse(ExpiryDateFormat, strings.TrimPrefix(label, ExpiresLabelPrefix))
+			if err == nil {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// IsExpired reports whether the flag has an expiry date before now
+func (f Flag) IsExpired(now time.Time) bool {
+	expires, ok := f.Expires()
+	return ok && now.After(expires.AddDate(0, 0, 1))
+}
+
+// ParseExpiry parses an expiry given as a date (2025-12-31) or relative to now in days or weeks (90d, 6w)
+func ParseExpiry(value string, now time.Time) (time.Time, error) {
+	if date, err := time.Parse(ExpiryDateFormat, value); err == nil {
+		return date, nil
+	}
+
+	if len(value) > 1 {
+		amount, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && amount > 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return now.AddDate(0, 0, amount).Truncate(24 * time.Hour), nil
+			case 'w':
+				return now.AddDate(0, 0, 7*amount).Truncate(24 * time.Hour), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid expiry '%s', use a date (YYYY-MM-DD) or a relative duration (90d, 6w)", value)
+}
+
+// WithMetadataLabels returns labels with the owner and expiry labels replaced by the given values.
+// Empty values leave the existing metadata unchanged.
+func WithMetadataLabels(labels []string, owner string, expires time.Time) []string {
+	result := []string{}
+	for _, label := range labels {
+		if owner != "" && strings.HasPrefix(label, OwnerLabelPrefix) {
+			continue
+		}
+		if !expires.IsZero() && strings.HasPrefix(label, ExpiresLabelPrefix) {
+			continue
+		}
+		result = append(result, label)
+	}
+
+	if owner != "" {
+		result = append(result, OwnerLabelPrefix+owner)
+	}
+	if !expires.IsZero() {
+		result = append(result, ExpiresLabelPrefix+expires.Format(ExpiryDateFormat))
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package cloudbees
+
+import "time"
+
+// RequestAttempt describes one HTTP request made to the API
+type RequestAttempt struct {
+	Method     string
+	URL        string
+	Attempt    int    // 0 for the first try, incremented for every retry
+	RequestID  string // X-Request-ID sent with the request, the same for all attempts
+	StatusCode int    // 0 when no response was received
+	Err        error
+	Start      time.Time
+	Duration   time.Duration
+}
+
+// RequestObserver is notified of every request attempt, e.g. to record telemetry
+type RequestObserver interface {
+	ObserveRequest(attempt RequestAttempt)
+}
+
+// SetObserver registers an observer of all requests made by the client
+func (c *Client) SetObserver(observer RequestObserver) {
+	c.observer = observer
+}
@@ -0,0 +1,206 @@
+package cloudbees
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// OutputsManifestFile is written next to the outputs of a command and describes each of them
+const OutputsManifestFile = "outputs-manifest.json"
+
+// Types of outputs in the outputs manifest
+const (
+	OutputTypeString  = "string"
+	OutputTypeNumber  = "number"
+	OutputTypeBoolean = "boolean"
+	OutputTypeJSON    = "json" // A JSON object, array or null
+)
+
+// Encodings of output values
+const (
+	OutputEncodingRaw    = "raw"    // Values are written as is
+	OutputEncodingJSON   = "json"   // Values that are not JSON are written as JSON strings
+	OutputEncodingBase64 = "base64" // Values are written base64-encoded
+)
+
+// Handling of output values larger than the size limit
+const (
+	OutputOverflowFile     = "file"     // The value is written to a file and the output is its path
+	OutputOverflowTruncate = "truncate" // The value is cut at the size limit
+)
+
+// OutputOptions controls how output values are written
+type OutputOptions struct {
+	MaxSize  int    // Largest encoded value written to an output, 0 for no limit
+	Overflow string // OutputOverflowFile or OutputOverflowTruncate
+	Encoding string // OutputEncodingRaw, OutputEncodingJSON or OutputEncodingBase64
+	FileDir  string // Directory of the values written to files
+}
+
+// outputOptions applies to every output of this invocation
+var outputOptions = OutputOptions{Overflow: OutputOverflowFile, Encoding: OutputEncodingRaw}
+
+// SetOutputOptions sets how the outputs of this invocation are written
+func SetOutputOptions(opts OutputOptions) error {
+	switch opts.Encoding {
+	case "":
+		opts.Encoding = OutputEncodingRaw
+	case OutputEncodingRaw, OutputEncodingJSON, OutputEncodingBase64:
+	default:
+		return fmt.Errorf("invalid output encoding '%s', must be %s, %s or %s", opts.Encoding, OutputEncodingRaw, OutputEncodingJSON, OutputEncodingBase64)
+	}
+	switch opts.Overflow {
+	case "":
+		opts.Overflow = OutputOverflowFile
+	case OutputOverflowFile, OutputOverflowTruncate:
+	default:
+		return fmt.Errorf("invalid large output handling '%s', must be %s or %s", opts.Overflow, OutputOverflowFile, OutputOverflowTruncate)
+	}
+	outputOptions = opts
+	return nil
+}
+
+// OutputDescription describes an output in the outputs manifest
+type OutputDescription struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Size      int    `json:"size"`               // Size of the written value in bytes
+	Truncated bool   `json:"truncated"`          // The written value is not the complete value
+	Encoding  string `json:"encoding,omitempty"` // Encoding of the written value, when not raw
+	File      string `json:"file,omitempty"`     // File holding the value, which was too large for an output
+}
+
+// OutputsManifest lists the outputs written by a command
+type OutputsManifest struct {
+	Command string              `json:"command"`
+	Outputs []OutputDescription `json:"outputs"`
+}
+
+// writtenOutputs records the outputs written during this invocation, by name
+var writtenOutputs = struct {
+	sync.Mutex
+	outputs map[string]OutputDescription
+}{outputs: map[string]OutputDescription{}}
+
+// WriteOutput writes outputs in CloudBees format to $CLOUDBEES_OUTPUTS files, encoded and
+// limited in size as set with SetOutputOptions
+func WriteOutput(name, value string) {
+	if outDir := os.Getenv("CLOUDBEES_OUTPUTS"); outDir != "" {
+		description, written, err := encodeOutput(name, value, outputOptions)
+		if err == nil {
+			err = os.WriteFile(path.Join(outDir, name), []byte(written), 0640)
+		}
+		if err != nil {
+			// Don't fail the whole operation if output writing fails, just log it
+			fmt.Printf("Warning: failed to write CloudBees output %s: %v\n", name, err)
+			return
+		}
+		writtenOutputs.Lock()
+		writtenOutputs.outputs[name] = description
+		writtenOutputs.Unlock()
+	} else {
+		fmt.Printf("Warning: CLOUDBEES_OUTPUTS environment variable not set, skipping output %s=%s\n", name, value)
+	}
+}
+
+// encodeOutput returns the value written to the output name and its description. A value over
+// the size limit is truncated or written to a file, whose path is the value of the output.
+func encodeOutput(name, value string, opts OutputOptions) (OutputDescription, string, error) {
+	description := OutputDescription{Name: name, Type: outputType(value)}
+
+	written := value
+	switch opts.Encoding {
+	case OutputEncodingJSON:
+		if description.Type == OutputTypeString {
+			quoted, _ := json.Marshal(value)
+			written = string(quoted)
+		}
+		description.Encoding = opts.Encoding
+	case OutputEncodingBase64:
+		written = base64.StdEncoding.EncodeToString([]byte(value))
+		description.Encoding = opts.Encoding
+	}
+
+	if opts.MaxSize > 0 && len(written) > opts.MaxSize {
+		if opts.Overflow == OutputOverflowTruncate {
+			written = truncateUTF8(written, opts.MaxSize)
+			description.Truncated = true
+		} else {
+			dir := opts.FileDir
+			if dir == "" {
+				dir = "."
+			}
+			file, err := filepath.Abs(filepath.Join(dir, name))
+			if err != nil {
+				return description, "", err
+			}
+			if err := os.MkdirAll(dir, 0750); err != nil {
+				return description, "", err
+			}
+			if err := os.WriteFile(file, []byte(value), 0640); err != nil {
+				return description, "", err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: output %s is larger than %d bytes, its value was written to %s\n", name, opts.MaxSize, file)
+			written = file
+			description.File = file
+			description.Encoding = ""
+		}
+	}
+
+	description.Size = len(written)
+	return description, written, nil
+}
+
+// truncateUTF8 cuts s to at most size bytes without splitting a character
+func truncateUTF8(s string, size int) string {
+	for size > 0 && !utf8.RuneStart(s[size]) {
+		size--
+	}
+	return s[:size]
+}
+
+// outputType classifies an output value for the outputs manifest
+func outputType(value string) string {
+	if value == "" || !json.Valid([]byte(value)) {
+		return OutputTypeString
+	}
+	switch value[0] {
+	case '{', '[', 'n':
+		return OutputTypeJSON
+	case 't', 'f':
+		return OutputTypeBoolean
+	case '"':
+		return OutputTypeString
+	default:
+		return OutputTypeNumber
+	}
+}
+
+// WriteOutputsManifest writes the outputs manifest of command to $CLOUDBEES_OUTPUTS, when
+// outputs were written, so later steps can discover them
+func WriteOutputsManifest(command string) error {
+	outDir := os.Getenv("CLOUDBEES_OUTPUTS")
+	writtenOutputs.Lock()
+	manifest := OutputsManifest{Command: command, Outputs: make([]OutputDescription, 0, len(writtenOutputs.outputs))}
+	for _, output := range writtenOutputs.outputs {
+		manifest.Outputs = append(manifest.Outputs, output)
+	}
+	writtenOutputs.Unlock()
+	if outDir == "" || len(manifest.Outputs) == 0 {
+		return nil
+	}
+	sort.Slice(manifest.Outputs, func(i, j int) bool { return manifest.Outputs[i].Name < manifest.Outputs[j].Name })
+
+	data, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := os.WriteFile(path.Join(outDir, OutputsManifestFile), append(data, '\n'), 0640); err != nil {
+		return fmt.Errorf("failed to write the outputs manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cloudbees
+
+import "strings"
+
+// RequiresLabelPrefix labels the prerequisites of a flag, one requires:<flag name> label per
+// prerequisite flag, which must be enabled in an environment before the flag is enabled there
+const RequiresLabelPrefix = "requires:"
+
+// Prerequisites returns the names of the flags the flag requires, in label order
+func (f Flag) Prerequisites() []string {
+	var prerequisites []string
+	for _, label := range f.Labels {
+		if name, ok := strings.CutPrefix(label, RequiresLabelPrefix); ok && name != "" {
+			prerequisites = append(prerequisites, name)
+		}
+	}
+	return prerequisites
+}
+
+// WithPrerequisiteLabels returns labels with the requires labels replaced by the given
+// prerequisites
+func WithPrerequisiteLabels(labels []string, prerequisites []string) []string {
+	result := []string{}
+	for _, label := range labels {
+		if !strings.HasPrefix(label, RequiresLabelPrefix) {
+			result = append(result, label)
+		}
+	}
+	for _, name := range prerequisites {
+		result = append(result, RequiresLabelPrefix+name)
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Property types
+const (
+	PropertyTypeBoolean = "Boolean"
+	PropertyTypeNumber  = "Number"
+	PropertyTypeString  = "String"
+	PropertyTypeSemver  = "Semver"
+)
+
+// Property is a custom context property registered by the SDKs, usable in targeting conditions
+type Property struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ListPropertiesResponse represents the response when listing properties
+type ListPropertiesResponse struct {
+	Properties []Property `json:"properties"`
+}
+
+// ListProperties retrieves the custom properties of the application
+func (c *Client) ListProperties(applicationID string) ([]Property, error) {
+	// Use org ID as application ID if the flag is set (legacy API), otherwise use the actual application ID
+	apiAppID := applicationID
+	if c.useOrgAsApp {
+		apiAppID = c.orgID
+	}
+	url := fmt.Sprintf("%s/v2/applications/%s/properties", c.baseURL, apiAppID)
+
+	resp, err := c.makeRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	var response ListPropertiesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Properties, nil
+}
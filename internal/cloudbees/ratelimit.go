@@ -0,0 +1,97 @@
+package cloudbees
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxRateLimitRetries is how many times a request is retried after a 429 response
+	maxRateLimitRetries = 5
+	// defaultRetryAfter is used when a 429 response carries no usable Retry-After header
+	defaultRetryAfter = 2 * time.Second
+	// maxRetryAfter caps how long a single Retry-After wait may last
+	maxRetryAfter = 60 * time.Second
+)
+
+// rateLimiter is a token bucket limiting the number of requests per second
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter creates a token bucket allowing rps requests per second
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := math.Max(1, math.Ceil(rps))
+	return &rateLimiter{
+		rate:   rps,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available and consumes it
+func (l *rateLimiter) Wait() {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// SetMaxRequestsPerSecond limits the client to rps requests per second; zero disables the limit
+func (c *Client) SetMaxRequestsPerSecond(rps float64) {
+	if rps <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(rps)
+}
+
+// RateLimitedCount returns the number of 429 responses received by the client
+func (c *Client) RateLimitedCount() int64 {
+	return atomic.LoadInt64(&c.rateLimitedCount)
+}
+
+// retryAfter parses the Retry-After header of a 429 response, in seconds or as an HTTP date
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return defaultRetryAfter
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = time.Until(date)
+	} else {
+		return defaultRetryAfter
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	if wait > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return wait
+}
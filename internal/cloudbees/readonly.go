@@ -0,0 +1,12 @@
+package cloudbees
+
+import "errors"
+
+// ErrReadOnly is returned for requests that would change data when the client is read-only
+var ErrReadOnly = errors.New("read-only mode")
+
+// SetReadOnly makes the client refuse every request that would change data, as a safeguard
+// for jobs that must never write, whatever the scope of their token
+func (c *Client) SetReadOnly(readOnly bool) {
+	c.readOnly = readOnly
+}
@@ -0,0 +1,62 @@
+package cloudbees
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultUserAgent is sent when no User-Agent was configured with SetUserAgent
+const DefaultUserAgent = "fm-actions"
+
+// RequestIDHeader carries the ID that correlates a request with the API server logs
+const RequestIDHeader = "X-Request-ID"
+
+// requestLog writes a line per request attempt
+type requestLog struct {
+	out io.Writer
+	mu  sync.Mutex
+}
+
+// SetUserAgent sets the User-Agent header sent with every request
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// SetRequestLog writes the method, URL, status and request ID of every request attempt to w
+func (c *Client) SetRequestLog(w io.Writer) {
+	c.requestLog = &requestLog{out: w}
+}
+
+func (l *requestLog) write(attempt RequestAttempt) {
+	status := fmt.Sprintf("%d", attempt.StatusCode)
+	if attempt.Err != nil {
+		status = "failed"
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.out, "%s %s -> %s in %s (request ID: %s)\n", attempt.Method, attempt.URL, status, attempt.Duration.Round(time.Millisecond), attempt.RequestID)
+}
+
+// newRequestID returns a random version 4 UUID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// responseRequestID returns the request ID echoed by the API, or the one that was sent
+func responseRequestID(resp *http.Response) string {
+	if id := resp.Header.Get(RequestIDHeader); id != "" {
+		return id
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get(RequestIDHeader)
+	}
+	return ""
+}
@@ -0,0 +1,19 @@
+package cloudbees
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+// ErrConflict is returned when a configuration changed remotely since it was read
+var ErrConflict = errors.New("flag configuration was modified concurrently")
+
+// configurationRevision returns a content hash identifying a configuration,
+// used for optimistic concurrency when the API does not return an ETag
+func configurationRevision(config FlagConfiguration) string {
+	data, _ := json.Marshal(config)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:12])
+}
@@ -0,0 +1,78 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrTooManyItems is returned when a list has more items than the maximum set with SetMaxItems
+var ErrTooManyItems = errors.New("too many items")
+
+// SetMaxItems limits the number of items a list may return, as a safety limit for very large
+// organizations (0 for no limit)
+func (c *Client) SetMaxItems(maxItems int) {
+	c.maxItems = maxItems
+}
+
+// decodeList decodes the array field of a JSON object one item at a time and calls fn for each,
+// so the response is never buffered as a whole. Other fields are skipped.
+func decodeList[T any](r io.Reader, field string, maxItems int, fn func(T) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := token.(string); key != field {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return err
+			}
+			continue
+		}
+
+		token, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			continue
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("expected an array of %s, got %v", field, token)
+		}
+		for count := 1; dec.More(); count++ {
+			if maxItems > 0 && count > maxItems {
+				return fmt.Errorf("%w: more than %d %s, raise --max-items to list them", ErrTooManyItems, maxItems, field)
+			}
+			var item T
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expectDelim reads the next token and checks it is the delimiter want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got %v", want, token)
+	}
+	return nil
+}
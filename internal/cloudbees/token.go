@@ -0,0 +1,164 @@
+package cloudbees
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is how long before it expires a token is renewed, so requests in flight
+// do not use an expired token
+const tokenExpiryMargin = time.Minute
+
+// TokenProvider supplies the API token of a client. Long-running modes (serve, mcp,
+// drift-watch) outlive tokens, so the client asks for the token on every request and, when the
+// API rejects it with 401, invalidates it and retries once with a new one.
+type TokenProvider interface {
+	// Token returns the current token
+	Token() (string, error)
+	// Invalidate discards token after the API rejected it, unless it was already replaced
+	Invalidate(token string)
+}
+
+// SetTokenProvider makes the client authenticate with the tokens of provider instead of a
+// fixed token
+func (c *Client) SetTokenProvider(provider TokenProvider) {
+	c.tokens = provider
+}
+
+// currentToken returns the token requests are sent with
+func (c *Client) currentToken() (string, error) {
+	if c.tokens == nil {
+		return c.token, nil
+	}
+	token, err := c.tokens.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get API token: %w", err)
+	}
+	return token, nil
+}
+
+// RefreshFunc returns a new token and when it expires, zero when unknown
+type RefreshFunc func() (token string, expiresAt time.Time, err error)
+
+// RefreshingToken caches the token of a refresh callback until shortly before it expires or
+// the API rejects it
+type RefreshingToken struct {
+	refresh   RefreshFunc
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewRefreshingToken returns a provider that gets its tokens from refresh
+func NewRefreshingToken(refresh RefreshFunc) *RefreshingToken {
+	return &RefreshingToken{refresh: refresh}
+}
+
+// Token implements TokenProvider
+func (r *RefreshingToken) Token() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.token != "" && (r.expiresAt.IsZero() || time.Now().Before(r.expiresAt.Add(-tokenExpiryMargin))) {
+		return r.token, nil
+	}
+	token, expiresAt, err := r.refresh()
+	if err != nil {
+		return "", err
+	}
+	if token == "" {
+		return "", fmt.Errorf("empty API token")
+	}
+	r.token, r.expiresAt = token, expiresAt
+	return token, nil
+}
+
+// Invalidate implements TokenProvider
+func (r *RefreshingToken) Invalidate(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.token == token {
+		r.token = ""
+	}
+}
+
+// FileToken reads the token from a file, again whenever the file changes, e.g. a token mounted
+// from a secret that is rotated
+type FileToken struct {
+	path    string
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+// NewFileToken returns a provider of the token in a file
+func NewFileToken(path string) *FileToken {
+	return &FileToken{path: path}
+}
+
+// Token implements TokenProvider
+func (f *FileToken) Token() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	if f.token != "" && info.ModTime().Equal(f.modTime) {
+		return f.token, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file '%s' is empty", f.path)
+	}
+	f.token, f.modTime = token, info.ModTime()
+	return token, nil
+}
+
+// Invalidate implements TokenProvider. The file is read again on the next request, in case it
+// was replaced within the resolution of its modification time.
+func (f *FileToken) Invalidate(token string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token == token {
+		f.token = ""
+	}
+}
+
+// NewExecToken returns a provider that runs a command for every new token. The command prints
+// the token, or a JSON object {"token": "...", "expiresAt": "<RFC 3339 time>"} so the token is
+// renewed before it expires.
+func NewExecToken(name string, args ...string) *RefreshingToken {
+	return NewRefreshingToken(func() (string, time.Time, error) {
+		var stderr strings.Builder
+		command := exec.Command(name, args...)
+		command.Stderr = &stderr
+		output, err := command.Output()
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("token command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		text := strings.TrimSpace(string(output))
+		if !strings.HasPrefix(text, "{") {
+			return text, time.Time{}, nil
+		}
+		var credential struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expiresAt"`
+		}
+		if err := json.Unmarshal([]byte(text), &credential); err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to parse the output of the token command: %w", err)
+		}
+		return credential.Token, credential.ExpiresAt, nil
+	})
+}
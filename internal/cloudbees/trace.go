@@ -0,0 +1,183 @@
+package cloudbees
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redactedValue replaces sensitive values in trace output
+const redactedValue = "[REDACTED]"
+
+// sensitiveHeaders lists headers whose values are never written to the trace file
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Api-Key":           true,
+}
+
+// sensitiveBodyFields matches JSON fields that carry credentials
+var sensitiveBodyFields = regexp.MustCompile(`("(?i:token|access_token|refresh_token|id_token|password|secret|client_secret)"\s*:\s*)"[^"]*"`)
+
+// sensitiveQueryParams matches query parameters that carry credentials
+var sensitiveQueryParams = regexp.MustCompile(`(?i)token|secret|password|key|signature|sig|code|auth|credential`)
+
+// traceTransport is an http.RoundTripper that records every request and response to a file
+type traceTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+	// token returns the token of the client when the trace is written, so tokens renewed by a
+	// TokenProvider are redacted too
+	token func() string
+}
+
+// traceFile serializes the writes of the clients sharing a trace file
+type traceFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (f *traceFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+func (f *traceFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+// EnableHTTPTrace writes all HTTP traffic of the client to the given file, with credentials
+// redacted. The returned trace can be shared with other clients with SetHTTPTrace, and must be
+// closed once the clients are done.
+func (c *Client) EnableHTTPTrace(filename string) (io.WriteCloser, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HTTP debug file: %w", err)
+	}
+
+	trace := &traceFile{file: file}
+	c.SetHTTPTrace(trace)
+	return trace, nil
+}
+
+// SetHTTPTrace writes all HTTP traffic of the client to a trace opened by EnableHTTPTrace, with
+// credentials redacted
+func (c *Client) SetHTTPTrace(trace io.Writer) {
+	c.httpClient.Transport = &traceTransport{
+		next: c.roundTripper(),
+		out:  trace,
+		token: func() string {
+			token, _ := c.currentToken()
+			return token
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	// The token the request was sent with, and the current one if it was renewed meanwhile
+	secrets := []string{strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), t.token()}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "=== %s %s %s\n", start.UTC().Format(time.RFC3339Nano), req.Method, redactSecrets(redactQuery(req.URL), secrets))
+	fmt.Fprintf(&buf, "--- request\n")
+	t.writeHeaders(&buf, req.Header)
+	if len(reqBody) > 0 {
+		fmt.Fprintf(&buf, "\n%s\n", redactBody(reqBody, secrets))
+	}
+
+	if err != nil {
+		fmt.Fprintf(&buf, "--- error after %s: %v\n\n", elapsed, err)
+		t.write(buf.Bytes())
+		return resp, err
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fmt.Fprintf(&buf, "--- response %s in %s\n", resp.Status, elapsed)
+	t.writeHeaders(&buf, resp.Header)
+	if len(respBody) > 0 {
+		fmt.Fprintf(&buf, "\n%s\n", redactBody(respBody, secrets))
+	}
+	buf.WriteString("\n")
+	t.write(buf.Bytes())
+
+	return resp, nil
+}
+
+// writeHeaders writes headers in a stable order, redacting sensitive ones
+func (t *traceTransport) writeHeaders(w io.Writer, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := strings.Join(header[key], ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+			value = redactedValue
+		}
+		fmt.Fprintf(w, "%s: %s\n", key, value)
+	}
+}
+
+// redactBody removes credential fields and the client tokens from a body
+func redactBody(body []byte, secrets []string) string {
+	return redactSecrets(sensitiveBodyFields.ReplaceAllString(string(body), `$1"`+redactedValue+`"`), secrets)
+}
+
+// redactSecrets replaces every occurrence of the secrets in text
+func redactSecrets(text string, secrets []string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			text = strings.ReplaceAll(text, secret, redactedValue)
+		}
+	}
+	return text
+}
+
+// redactQuery returns a URL with the password and the values of credential query parameters
+// redacted, keeping the order and encoding of the other parameters
+func redactQuery(u *url.URL) string {
+	redacted := *u
+	params := strings.Split(redacted.RawQuery, "&")
+	for i, param := range params {
+		name, _, ok := strings.Cut(param, "=")
+		if ok && sensitiveQueryParams.MatchString(name) {
+			params[i] = name + "=" + redactedValue
+		}
+	}
+	redacted.RawQuery = strings.Join(params, "&")
+	return redacted.Redacted()
+}
+
+func (t *traceTransport) write(p []byte) {
+	t.out.Write(p)
+}
@@ -0,0 +1,84 @@
+package evaluate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Share is the number of contexts receiving a value
+type Share struct {
+	Value      interface{} `json:"value"` // nil for the coded default
+	Count      int         `json:"count"`
+	Percentage float64     `json:"percentage"`
+	// Some contexts received the value from a split, whose bucketing approximates the SDKs
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// LoadContexts reads contexts from a CSV file whose header row holds the attribute names.
+// Empty cells leave the attribute undefined.
+func LoadContexts(filename string) ([]Context, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contexts file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header of '%s': %w", filename, err)
+	}
+
+	var contexts []Context
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse '%s': %w", filename, err)
+		}
+		ctx := Context{}
+		for i, value := range record {
+			if value != "" {
+				ctx[header[i]] = value
+			}
+		}
+		contexts = append(contexts, ctx)
+	}
+	return contexts, nil
+}
+
+// Distribution counts the contexts receiving each value, most frequent first
+func Distribution(results []Result) []Share {
+	counts := map[string]*Share{}
+	for _, result := range results {
+		key, _ := json.Marshal(result.Value)
+		share, ok := counts[string(key)]
+		if !ok {
+			share = &Share{Value: result.Value}
+			counts[string(key)] = share
+		}
+		share.Count++
+		share.Approximate = share.Approximate || result.Approximate
+	}
+
+	shares := make([]Share, 0, len(counts))
+	for _, share := range counts {
+		share.Percentage = float64(share.Count) / float64(len(results)) * 100
+		shares = append(shares, *share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Count != shares[j].Count {
+			return shares[i].Count > shares[j].Count
+		}
+		a, _ := json.Marshal(shares[i].Value)
+		b, _ := json.Marshal(shares[j].Value)
+		return string(a) < string(b)
+	})
+	return shares
+}
@@ -0,0 +1,330 @@
+// Package evaluate computes the value a flag configuration serves to a context of attributes,
+// so targeting can be verified without an SDK. Bucketing of percentage splits is deterministic
+// but only approximates the SDK algorithm, so users may land in a different bucket than in
+// applications; results that depend on a bucket are marked Approximate.
+package evaluate
+
+import (
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+)
+
+// Reasons for the value served
+const (
+	ReasonDisabled  = "disabled"  // The flag is off: Boolean flags serve false, others the coded default
+	ReasonCondition = "condition" // A targeting condition matched
+	ReasonDefault   = "default"   // No condition matched
+)
+
+// Context holds the attributes of the evaluated user or request
+type Context map[string]string
+
+// Condition is a targeting condition: when its properties match, it serves Value (a value or a percentage split)
+type Condition struct {
+	Property *Property   `json:"property,omitempty"`
+	And      []Property  `json:"and,omitempty"` // All properties must match
+	Or       []Property  `json:"or,omitempty"`  // Any property must match
+	Group    interface{} `json:"group,omitempty"`
+	Value    interface{} `json:"value"`
+}
+
+// Property compares a context attribute with an operand
+type Property struct {
+	Name     string      `json:"name"`
+	Operator string      `json:"operator"`
+	Operand  interface{} `json:"operand,omitempty"`
+}
+
+// Result is the outcome of an evaluation
+type Result struct {
+	Value     interface{} `json:"value"` // nil when the application uses its coded default
+	Reason    string      `json:"reason"`
+	Condition int         `json:"condition,omitempty"` // 1-based index of the matching condition
+	Split     bool        `json:"split,omitempty"`     // The value was picked from a percentage split
+	Bucket    *float64    `json:"bucket,omitempty"`    // Bucket (0-100) of the context in the split
+	// The value depends on the bucket, which approximates the SDK bucketing
+	Approximate bool `json:"approximate,omitempty"`
+}
+
+// String describes the result, e.g. "true (condition 2 matched)"
+func (r Result) String() string {
+	value := "coded default"
+	if r.Value != nil {
+		data, _ := json.Marshal(r.Value)
+		value = string(data)
+	}
+	switch {
+	case r.Reason == ReasonCondition:
+		value += fmt.Sprintf(" (condition %d matched)", r.Condition)
+	default:
+		value += fmt.Sprintf(" (%s)", r.Reason)
+	}
+	if r.Bucket != nil {
+		value += fmt.Sprintf(", split bucket %.2f (approximation, the SDK bucketing may differ)", *r.Bucket)
+	}
+	return value
+}
+
+// Evaluate computes the value served by a flag configuration to a context. Conditions are tried
+// in order; the first match serves its value, otherwise the default value is served.
+func Evaluate(flagName, flagType string, config cloudbees.FlagConfiguration, ctx Context) (Result, error) {
+	if !config.Enabled {
+		result := Result{Reason: ReasonDisabled}
+		if flagType == "Boolean" {
+			result.Value = false
+		}
+		return result, nil
+	}
+
+	conditions, err := ParseConditions(config.Conditions)
+	if err != nil {
+		return Result{}, err
+	}
+	for i, condition := range conditions {
+		matched, err := condition.Matches(ctx)
+		if err != nil {
+			return Result{}, fmt.Errorf("condition %d: %w", i+1, err)
+		}
+		if matched {
+			result := Result{Reason: ReasonCondition, Condition: i + 1}
+			return serve(result, flagName, condition.Value, config.StickinessProperty, ctx)
+		}
+	}
+
+	return serve(Result{Reason: ReasonDefault}, flagName, config.DefaultValue, config.StickinessProperty, ctx)
+}
+
+// ParseConditions decodes the conditions of a configuration (a list of conditions, or nil)
+func ParseConditions(raw interface{}) ([]Condition, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conditions: %w", err)
+	}
+	var conditions []Condition
+	if err := json.Unmarshal(data, &conditions); err != nil {
+		return nil, fmt.Errorf("unsupported conditions format: %w", err)
+	}
+	return conditions, nil
+}
+
+// Matches reports whether the context matches the condition
+func (c Condition) Matches(ctx Context) (bool, error) {
+	if c.Group != nil {
+		return false, fmt.Errorf("target groups cannot be evaluated locally")
+	}
+
+	switch {
+	case c.Property != nil:
+		return c.Property.Matches(ctx)
+	case len(c.And) > 0:
+		for _, property := range c.And {
+			matched, err := property.Matches(ctx)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+		return true, nil
+	case len(c.Or) > 0:
+		for _, property := range c.Or {
+			matched, err := property.Matches(ctx)
+			if err != nil || matched {
+				return matched, err
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("condition has no property, and, or or group")
+}
+
+// Matches reports whether the context attribute matches the property
+func (p Property) Matches(ctx Context) (bool, error) {
+	value, defined := ctx[p.Name]
+	if p.Operator == "is-undefined" {
+		return !defined, nil
+	}
+	if !defined {
+		return false, nil
+	}
+
+	switch p.Operator {
+	case "is-true":
+		return value == "true", nil
+	case "is-false":
+		return value == "false", nil
+	case "=", "!=":
+		equal := equals(value, p.Operand)
+		return equal == (p.Operator == "="), nil
+	case "<", "<=", ">", ">=":
+		cmp, ok := compareNumbers(value, p.Operand)
+		if !ok {
+			return false, nil
+		}
+		return compared(p.Operator, cmp), nil
+	case "in-array", "not-in-array":
+		operands, ok := p.Operand.([]interface{})
+		if !ok {
+			return false, fmt.Errorf("%s needs a list operand", p.Operator)
+		}
+		found := false
+		for _, operand := range operands {
+			if equals(value, operand) {
+				found = true
+				break
+			}
+		}
+		return found == (p.Operator == "in-array"), nil
+	case "regex":
+		pattern, _ := p.Operand.(string)
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex '%s': %w", pattern, err)
+		}
+		return re.MatchString(value), nil
+	case "semver-eq", "semver-gt", "semver-gte", "semver-lt", "semver-lte":
+		cmp := compareVersions(value, fmt.Sprint(p.Operand))
+		operator := map[string]string{"semver-eq": "=", "semver-gt": ">", "semver-gte": ">=", "semver-lt": "<", "semver-lte": "<="}[p.Operator]
+		return compared(operator, cmp), nil
+	}
+	return false, fmt.Errorf("unsupported operator '%s'", p.Operator)
+}
+
+// serve sets the served value, picking the option of a percentage split by the bucket of the context
+func serve(result Result, flagName string, value interface{}, stickinessProperty string, ctx Context) (Result, error) {
+	split, ok := value.([]interface{})
+	if !ok {
+		result.Value = value
+		return result, nil
+	}
+
+	result.Split = true
+	key, ok := ctx[stickinessKey(stickinessProperty)]
+	if !ok {
+		return result, fmt.Errorf("a percentage split is served, set the '%s' attribute to pick a bucket", stickinessKey(stickinessProperty))
+	}
+	bucket := Bucket(flagName, key)
+	result.Bucket = &bucket
+	result.Approximate = true
+
+	total := 0.0
+	for _, entry := range split {
+		option, ok := entry.(map[string]interface{})
+		if !ok {
+			return result, fmt.Errorf("invalid percentage split")
+		}
+		percentage, _ := option["percentage"].(float64)
+		total += percentage
+		result.Value = option["option"]
+		if bucket < total {
+			break
+		}
+	}
+	return result, nil
+}
+
+// stickinessKey returns the attribute used to bucket contexts: the stickiness property, or id
+func stickinessKey(stickinessProperty string) string {
+	if stickinessProperty != "" {
+		return stickinessProperty
+	}
+	return "id"
+}
+
+// Bucket returns the bucket (0 to 100) of a key in the percentage splits of a flag. It hashes
+// the flag name and the key with SHA-1, not with the hashing of the SDKs.
+func Bucket(flagName, key string) float64 {
+	sum := sha1.Sum([]byte(flagName + ":" + key))
+	return float64(binary.BigEndian.Uint32(sum[:4])) / (1 << 32) * 100
+}
+
+// equals compares an attribute with an operand, as numbers when both are numeric
+func equals(value string, operand interface{}) bool {
+	if cmp, ok := compareNumbers(value, operand); ok {
+		return cmp == 0
+	}
+	return value == fmt.Sprint(operand)
+}
+
+// compareNumbers compares an attribute with an operand as numbers, and false when either is not a number
+func compareNumbers(value string, operand interface{}) (int, bool) {
+	a, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+	var b float64
+	switch o := operand.(type) {
+	case float64:
+		b = o
+	case string:
+		if b, err = strconv.ParseFloat(o, 64); err != nil {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	switch {
+	case a < b:
+		return -1, true
+	case a > b:
+		return 1, true
+	}
+	return 0, true
+}
+
+// compareVersions compares dotted versions numerically (1.10.0 > 1.9), ignoring a v prefix and pre-release suffixes
+func compareVersions(a, b string) int {
+	parts := func(version string) []int {
+		version = strings.TrimPrefix(version, "v")
+		version, _, _ = strings.Cut(version, "-")
+		var result []int
+		for _, part := range strings.Split(version, ".") {
+			n, _ := strconv.Atoi(part)
+			result = append(result, n)
+		}
+		return result
+	}
+
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// compared applies a comparison operator to the result of a comparison
+func compared(operator string, cmp int) bool {
+	switch operator {
+	case "=":
+		return cmp == 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package gitrepo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// githubRepositoryPattern matches https and ssh GitHub URLs, capturing owner/name
+var githubRepositoryPattern = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(\.git)?/?$`)
+
+// GitHubRepository returns the owner/name of a GitHub repository URL, or an empty string
+func GitHubRepository(url string) string {
+	match := githubRepositoryPattern.FindStringSubmatch(url)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// PullRequest is a pull request to create on GitHub
+type PullRequest struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+}
+
+// CreatePullRequest opens a pull request in the repository (owner/name) and returns its URL
+func CreatePullRequest(apiURL, token, repository string, pr PullRequest) (string, error) {
+	body, err := json.Marshal(pr)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls", strings.TrimSuffix(apiURL, "/"), repository)
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		data, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to create pull request: status %d: %s", resp.StatusCode, string(data))
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("failed to parse pull request response: %w", err)
+	}
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,93 @@
+// Package gitrepo works with flag manifests kept in git repositories, using the git binary.
+package gitrepo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Repo is a local clone of a repository
+type Repo struct {
+	URL string
+	Dir string
+}
+
+// Clone makes a shallow clone of the repository at ref (the default branch when empty) into a temporary directory.
+// Close removes it.
+func Clone(url, ref string) (*Repo, error) {
+	dir, err := os.MkdirTemp("", "fm-actions-git-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, url, dir)
+
+	repo := &Repo{URL: url, Dir: dir}
+	if _, err := repo.git(args...); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to clone %s: %w", redact(url), err)
+	}
+	return repo, nil
+}
+
+// Branch returns the checked out branch
+func (r *Repo) Branch() (string, error) {
+	out, err := r.git("rev-parse", "--abbrev-ref", "HEAD")
+	return strings.TrimSpace(out), err
+}
+
+// CommitAndPush commits the given files to a new branch and pushes it to origin
+func (r *Repo) CommitAndPush(branch, message, authorName, authorEmail string, files ...string) error {
+	if _, err := r.git("checkout", "--quiet", "-b", branch); err != nil {
+		return err
+	}
+	if _, err := r.git(append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+	if _, err := r.git("-c", "user.name="+authorName, "-c", "user.email="+authorEmail, "commit", "--quiet", "-m", message); err != nil {
+		return err
+	}
+	if _, err := r.git("push", "--quiet", "origin", branch); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Close removes the clone
+func (r *Repo) Close() error {
+	return os.RemoveAll(r.Dir)
+}
+
+// git runs a git command in the clone and returns its output
+func (r *Repo) git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(redact(stderr.String())))
+	}
+	return stdout.String(), nil
+}
+
+// redact removes credentials embedded in URLs, e.g. https://token@github.com/...
+func redact(s string) string {
+	fields := strings.Fields(s)
+	for _, field := range fields {
+		scheme := strings.Index(field, "://")
+		at := strings.Index(field, "@")
+		if scheme >= 0 && at > scheme {
+			s = strings.ReplaceAll(s, field[scheme+3:at+1], "***@")
+		}
+	}
+	return s
+}
@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// FlagsmithAPIURL is the default Flagsmith API URL for environment documents
+const FlagsmithAPIURL = "https://edge.api.flagsmith.com"
+
+// flagsmithDocument is a Flagsmith environment document (GET /api/v1/environment-document/)
+type flagsmithDocument struct {
+	Name          string                  `json:"name"`
+	FeatureStates []flagsmithFeatureState `json:"feature_states"`
+	Project       struct {
+		Segments []struct {
+			Name          string                  `json:"name"`
+			FeatureStates []flagsmithFeatureState `json:"feature_states"`
+		} `json:"segments"`
+	} `json:"project"`
+	IdentityOverrides []struct {
+		IdentityFeatures []flagsmithFeatureState `json:"identity_features"`
+	} `json:"identity_overrides"`
+}
+
+type flagsmithFeatureState struct {
+	Feature struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"feature"`
+	Enabled           bool        `json:"enabled"`
+	Value             interface{} `json:"feature_state_value"`
+	MultivariateValue []struct {
+		Option struct {
+			Value interface{} `json:"value"`
+		} `json:"multivariate_feature_option"`
+		PercentageAllocation float64 `json:"percentage_allocation"`
+	} `json:"multivariate_feature_state_values"`
+}
+
+// Flagsmith reads the flags of Flagsmith environments from their environment documents
+type Flagsmith struct {
+	Files           []string // Environment documents, one per environment
+	APIURL          string
+	EnvironmentKeys []string // Server-side environment keys, used when Files is empty
+}
+
+// Name identifies Flagsmith in reports
+func (f *Flagsmith) Name() string {
+	return "Flagsmith"
+}
+
+// Flags reads the environment documents and converts their features to the intermediate model.
+// Features without a value are Boolean flags; features with values are String or Number flags.
+func (f *Flagsmith) Flags() ([]Flag, error) {
+	documents, err := f.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	var flags []Flag
+	index := map[string]int{}
+	for _, document := range documents {
+		environment := document.Name
+		if environment == "" {
+			return nil, fmt.Errorf("Flagsmith environment document has no environment name")
+		}
+
+		// Overrides of segments and identities have no equivalent
+		overrides := map[string][]string{}
+		for _, segment := range document.Project.Segments {
+			for _, state := range segment.FeatureStates {
+				overrides[state.Feature.Name] = append(overrides[state.Feature.Name], fmt.Sprintf("segment override for '%s' not imported", segment.Name))
+			}
+		}
+		identities := map[string]int{}
+		for _, identity := range document.IdentityOverrides {
+			for _, state := range identity.IdentityFeatures {
+				identities[state.Feature.Name]++
+			}
+		}
+
+		for _, state := range document.FeatureStates {
+			name := state.Feature.Name
+			i, ok := index[name]
+			if !ok {
+				i = len(flags)
+				index[name] = i
+				flags = append(flags, Flag{Key: name, Temporary: true, Environments: map[string]Environment{}})
+			}
+			flag := &flags[i]
+
+			env := Environment{Enabled: state.Enabled}
+			for _, detail := range overrides[name] {
+				env.Issues = append(env.Issues, Issue{Construct: "segment overrides", Detail: detail})
+			}
+			if n := identities[name]; n > 0 {
+				env.Issues = append(env.Issues, Issue{Construct: "identity overrides", Detail: fmt.Sprintf("%d identity overrides not imported", n)})
+			}
+
+			if !flagsmithHasValue(state) {
+				env.Value = true
+				addFlagsmithValue(flag, true)
+				flag.Environments[environment] = env
+				continue
+			}
+
+			control := state.Value
+			addFlagsmithValue(flag, control)
+			if len(state.MultivariateValue) == 0 {
+				env.Value = control
+			} else {
+				env.Split = []Allocation{}
+				remaining := 100.0
+				for _, option := range state.MultivariateValue {
+					addFlagsmithValue(flag, option.Option.Value)
+					env.Split = append(env.Split, Allocation{Value: option.Option.Value, Percentage: option.PercentageAllocation})
+					remaining -= option.PercentageAllocation
+				}
+				if remaining > 0 {
+					env.Split = append(env.Split, Allocation{Value: control, Percentage: remaining})
+				}
+			}
+			flag.Environments[environment] = env
+		}
+	}
+
+	for i := range flags {
+		flagsmithFlagType(&flags[i])
+	}
+	return flags, nil
+}
+
+// flagsmithHasValue reports whether a feature state is a remote config value rather than a plain flag
+func flagsmithHasValue(state flagsmithFeatureState) bool {
+	return (state.Value != nil && state.Value != "") || len(state.MultivariateValue) > 0
+}
+
+// addFlagsmithValue records a value served by the flag
+func addFlagsmithValue(flag *Flag, value interface{}) {
+	if !containsValue(flag.Variants, value) {
+		flag.Variants = append(flag.Variants, value)
+	}
+}
+
+// flagsmithFlagType sets the type of a flag from the values it serves. Flags that are plain
+// in one environment and have a value in another cannot be imported.
+func flagsmithFlagType(flag *Flag) {
+	types := map[string]bool{}
+	for _, value := range flag.Variants {
+		types[valueType(value)] = true
+	}
+
+	switch {
+	case len(types) == 1 && types["Boolean"] && len(flag.Variants) == 1 && flag.Variants[0] == true:
+		flag.Type = "Boolean"
+		flag.Variants = []interface{}{true, false}
+	case len(types) == 1 && types["Number"]:
+		flag.Type = "Number"
+	case len(types) == 1 && types["String"]:
+		flag.Type = "String"
+	default:
+		flag.Issues = append(flag.Issues, Issue{Construct: "values", Detail: "values of mixed types are not supported", Skipped: true})
+	}
+}
+
+// documents reads the environment documents from files or the API
+func (f *Flagsmith) documents() ([]flagsmithDocument, error) {
+	var documents []flagsmithDocument
+	for _, file := range f.Files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Flagsmith environment document: %w", err)
+		}
+		var document flagsmithDocument
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse Flagsmith environment document '%s': %w", file, err)
+		}
+		documents = append(documents, document)
+	}
+	if len(f.Files) > 0 {
+		return documents, nil
+	}
+
+	if len(f.EnvironmentKeys) == 0 {
+		return nil, fmt.Errorf("Flagsmith environment documents or server-side environment keys are required")
+	}
+	apiURL := strings.TrimSuffix(f.APIURL, "/")
+	if apiURL == "" {
+		apiURL = FlagsmithAPIURL
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	for _, key := range f.EnvironmentKeys {
+		req, err := http.NewRequest("GET", apiURL+"/api/v1/environment-document/", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Environment-Key", key)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Flagsmith environment document: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to get Flagsmith environment document: status %d: %s", resp.StatusCode, string(data))
+		}
+		var document flagsmithDocument
+		err = json.NewDecoder(resp.Body).Decode(&document)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Flagsmith environment document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
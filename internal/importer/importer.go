@@ -0,0 +1,184 @@
+// Package importer converts flags exported from other feature flag tools into manifests
+// that can be applied to CloudBees Feature Management.
+//
+// Each source tool is a Provider that reads its flags into the intermediate Flag model,
+// which Import converts to a manifest.
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+)
+
+// Provider reads the flags of a source tool
+type Provider interface {
+	// Name identifies the source tool in reports
+	Name() string
+	Flags() ([]Flag, error)
+}
+
+// Flag is a flag of a source tool
+type Flag struct {
+	Key          string
+	Description  string
+	Type         string        // CloudBees flag type: Boolean, String or Number
+	Variants     []interface{} // Values the flag can serve
+	Tags         []string
+	Temporary    bool
+	Environments map[string]Environment // By source environment key
+	Issues       []Issue                // Constructs of the flag that were not mapped; the flag is skipped if one is marked Skipped
+}
+
+// Environment is the configuration of a flag in a source environment
+type Environment struct {
+	Enabled            bool
+	Value              interface{}  // Value served to everyone, unless Split is set
+	Split              []Allocation // Percentage split of the served values
+	StickinessProperty string
+	Issues             []Issue // Constructs of the configuration that were not mapped
+}
+
+// Allocation is the share of a percentage split receiving a value
+type Allocation struct {
+	Value      interface{}
+	Percentage float64
+}
+
+// Issue describes a construct of the source tool that has no equivalent and was not imported
+type Issue struct {
+	Flag        string `json:"flag"`
+	Environment string `json:"environment,omitempty"`
+	Construct   string `json:"construct"` // e.g. "targeting rules"
+	Detail      string `json:"detail,omitempty"`
+	Skipped     bool   `json:"skipped"` // The whole flag was not imported
+}
+
+// Result is a converted manifest with the constructs that could not be mapped
+type Result struct {
+	Manifest *manifest.Manifest `json:"manifest"`
+	Issues   []Issue            `json:"issues"`
+}
+
+// Import reads the flags of a provider and converts them to a manifest of the application.
+// environments maps source environment keys to CloudBees environment names; environments
+// without a mapping are not imported.
+func Import(provider Provider, application string, environments map[string]string) (*Result, error) {
+	flags, err := provider.Flags()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+
+	result := &Result{Manifest: &manifest.Manifest{Application: application, Flags: []manifest.Flag{}}, Issues: []Issue{}}
+	for _, flag := range flags {
+		skipped := false
+		for _, issue := range flag.Issues {
+			issue.Flag = flag.Key
+			result.Issues = append(result.Issues, issue)
+			skipped = skipped || issue.Skipped
+		}
+		if skipped {
+			continue
+		}
+
+		converted := manifest.Flag{
+			Name:         flag.Key,
+			Type:         flag.Type,
+			Description:  flag.Description,
+			Labels:       flag.Tags,
+			IsPermanent:  !flag.Temporary,
+			Environments: map[string]cloudbees.FlagConfiguration{},
+		}
+		for _, value := range flag.Variants {
+			converted.Variants = append(converted.Variants, fmt.Sprint(value))
+		}
+		keys := make([]string, 0, len(flag.Environments))
+		for key := range flag.Environments {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			env := flag.Environments[key]
+			name, ok := environments[key]
+			if !ok {
+				result.Issues = append(result.Issues, Issue{Flag: flag.Key, Environment: key, Construct: "environment", Detail: "no matching environment"})
+				continue
+			}
+			converted.Environments[name] = env.configuration()
+			for _, issue := range env.Issues {
+				issue.Flag, issue.Environment = flag.Key, key
+				result.Issues = append(result.Issues, issue)
+			}
+		}
+
+		result.Manifest.Flags = append(result.Manifest.Flags, converted)
+	}
+	return result, nil
+}
+
+// configuration converts an environment to a CloudBees flag configuration
+func (e Environment) configuration() cloudbees.FlagConfiguration {
+	config := cloudbees.FlagConfiguration{
+		Enabled:            e.Enabled,
+		DefaultValue:       e.Value,
+		StickinessProperty: e.StickinessProperty,
+	}
+	if e.Split != nil {
+		split := make([]interface{}, 0, len(e.Split))
+		for _, allocation := range e.Split {
+			split = append(split, map[string]interface{}{"option": allocation.Value, "percentage": allocation.Percentage})
+		}
+		config.DefaultValue = split
+	}
+	return config
+}
+
+// valueType returns the CloudBees flag type of a served value, or an empty string
+func valueType(value interface{}) string {
+	switch value.(type) {
+	case bool:
+		return "Boolean"
+	case string:
+		return "String"
+	case float64, int:
+		return "Number"
+	}
+	return ""
+}
+
+// Skipped returns the names of the flags that were not imported
+func (r *Result) Skipped() []string {
+	var names []string
+	for _, issue := range r.Issues {
+		if issue.Skipped {
+			names = append(names, issue.Flag)
+		}
+	}
+	return names
+}
+
+// ReportMarkdown renders the mapping report
+func (r *Result) ReportMarkdown(source string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Import report: %s\n\n", source)
+	fmt.Fprintf(&b, "%d flags imported, %d skipped.\n\n", len(r.Manifest.Flags), len(r.Skipped()))
+	if len(r.Issues) == 0 {
+		b.WriteString("Every construct was mapped.\n")
+		return b.String()
+	}
+
+	b.WriteString("| Flag | Environment | Construct | Detail |\n")
+	b.WriteString("|------|-------------|-----------|--------|\n")
+	for _, issue := range r.Issues {
+		construct := issue.Construct
+		if issue.Skipped {
+			construct += " (flag skipped)"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", issue.Flag, issue.Environment, construct, strings.ReplaceAll(issue.Detail, "|", "\\|"))
+	}
+	return b.String()
+}
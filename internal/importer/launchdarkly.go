@@ -0,0 +1,250 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// LaunchDarklyAPIURL is the default LaunchDarkly API URL
+const LaunchDarklyAPIURL = "https://app.launchdarkly.com"
+
+// ldWeightScale is the weight of a 100% rollout; LaunchDarkly weights are in thousandths of a percent
+const ldWeightScale = 100000
+
+// ldFlag is a flag as returned by the LaunchDarkly flags API (GET /api/v2/flags/{project}?summary=0)
+type ldFlag struct {
+	Key          string                   `json:"key"`
+	Name         string                   `json:"name"`
+	Description  string                   `json:"description"`
+	Kind         string                   `json:"kind"`
+	Tags         []string                 `json:"tags"`
+	Temporary    bool                     `json:"temporary"`
+	Variations   []ldVariation            `json:"variations"`
+	Environments map[string]ldEnvironment `json:"environments"`
+}
+
+type ldVariation struct {
+	Value interface{} `json:"value"`
+	Name  string      `json:"name"`
+}
+
+type ldEnvironment struct {
+	On             bool              `json:"on"`
+	OffVariation   *int              `json:"offVariation"`
+	Fallthrough    ldFallthrough     `json:"fallthrough"`
+	Targets        []json.RawMessage `json:"targets"`
+	ContextTargets []json.RawMessage `json:"contextTargets"`
+	Rules          []json.RawMessage `json:"rules"`
+	Prerequisites  []json.RawMessage `json:"prerequisites"`
+}
+
+type ldFallthrough struct {
+	Variation *int `json:"variation"`
+	Rollout   *struct {
+		Variations []struct {
+			Variation int `json:"variation"`
+			Weight    int `json:"weight"`
+		} `json:"variations"`
+		BucketBy string `json:"bucketBy"`
+	} `json:"rollout"`
+}
+
+// ldFlagList is a page of the flags API, also accepted as an export file
+type ldFlagList struct {
+	Items []ldFlag `json:"items"`
+	Links struct {
+		Next struct {
+			Href string `json:"href"`
+		} `json:"next"`
+	} `json:"_links"`
+}
+
+// LaunchDarkly reads flags from a LaunchDarkly project
+type LaunchDarkly struct {
+	File    string // Export file: a flags API response, or a JSON array of flags
+	APIURL  string
+	Token   string // API access token, used when File is empty
+	Project string
+}
+
+// Name identifies LaunchDarkly in reports
+func (l *LaunchDarkly) Name() string {
+	return "LaunchDarkly"
+}
+
+// Flags reads the flags and converts them to the intermediate model
+func (l *LaunchDarkly) Flags() ([]Flag, error) {
+	flags, err := l.flags()
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Flag, 0, len(flags))
+	for _, flag := range flags {
+		converted = append(converted, convertLaunchDarklyFlag(flag))
+	}
+	return converted, nil
+}
+
+// flags reads the flags from the export file or the API
+func (l *LaunchDarkly) flags() ([]ldFlag, error) {
+	if l.File != "" {
+		data, err := os.ReadFile(l.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read LaunchDarkly export: %w", err)
+		}
+		var list ldFlagList
+		if err := json.Unmarshal(data, &list); err != nil {
+			if err := json.Unmarshal(data, &list.Items); err != nil {
+				return nil, fmt.Errorf("failed to parse LaunchDarkly export: %w", err)
+			}
+		}
+		return list.Items, nil
+	}
+
+	if l.Token == "" || l.Project == "" {
+		return nil, fmt.Errorf("a LaunchDarkly export file, or an API token and project, is required")
+	}
+	apiURL := strings.TrimSuffix(l.APIURL, "/")
+	if apiURL == "" {
+		apiURL = LaunchDarklyAPIURL
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	var flags []ldFlag
+	next := fmt.Sprintf("/api/v2/flags/%s?summary=0", l.Project)
+	for next != "" {
+		req, err := http.NewRequest("GET", apiURL+next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", l.Token)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list LaunchDarkly flags: %w", err)
+		}
+		var page ldFlagList
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list LaunchDarkly flags: status %d: %s", resp.StatusCode, string(body))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse LaunchDarkly flags: %w", err)
+		}
+
+		flags = append(flags, page.Items...)
+		next = page.Links.Next.Href
+	}
+	return flags, nil
+}
+
+// convertLaunchDarklyFlag maps a flag and its environments
+func convertLaunchDarklyFlag(flag ldFlag) Flag {
+	description := flag.Description
+	if description == "" && flag.Name != flag.Key {
+		description = flag.Name
+	}
+	converted := Flag{
+		Key:          flag.Key,
+		Description:  description,
+		Tags:         flag.Tags,
+		Temporary:    flag.Temporary,
+		Environments: map[string]Environment{},
+	}
+
+	flagType, err := launchDarklyFlagType(flag)
+	if err != nil {
+		converted.Issues = []Issue{{Construct: "variations", Detail: err.Error(), Skipped: true}}
+		return converted
+	}
+	converted.Type = flagType
+	for _, variation := range flag.Variations {
+		converted.Variants = append(converted.Variants, variation.Value)
+	}
+
+	for key, env := range flag.Environments {
+		converted.Environments[key] = convertLaunchDarklyEnvironment(flag, env)
+	}
+	return converted
+}
+
+// launchDarklyFlagType returns the CloudBees flag type matching the flag's variations
+func launchDarklyFlagType(flag ldFlag) (string, error) {
+	if flag.Kind == "boolean" {
+		return "Boolean", nil
+	}
+
+	flagType := ""
+	for _, variation := range flag.Variations {
+		t := valueType(variation.Value)
+		if t == "" {
+			return "", fmt.Errorf("JSON variations are not supported")
+		}
+		if flagType != "" && flagType != t {
+			return "", fmt.Errorf("variations of mixed types are not supported")
+		}
+		flagType = t
+	}
+	if flagType == "" {
+		return "", fmt.Errorf("flag has no variations")
+	}
+	return flagType, nil
+}
+
+// convertLaunchDarklyEnvironment maps the on state and the fallthrough (default rule) of an environment.
+// Individual targets, targeting rules and prerequisites have no equivalent and are reported.
+func convertLaunchDarklyEnvironment(flag ldFlag, env ldEnvironment) Environment {
+	converted := Environment{Enabled: env.On}
+
+	value := func(index int) (interface{}, bool) {
+		if index < 0 || index >= len(flag.Variations) {
+			return nil, false
+		}
+		return flag.Variations[index].Value, true
+	}
+
+	switch {
+	case env.Fallthrough.Rollout != nil:
+		rollout := env.Fallthrough.Rollout
+		converted.Split = []Allocation{}
+		for _, weighted := range rollout.Variations {
+			option, ok := value(weighted.Variation)
+			if !ok {
+				converted.Issues = append(converted.Issues, Issue{Construct: "rollout", Detail: fmt.Sprintf("unknown variation %d", weighted.Variation)})
+				continue
+			}
+			converted.Split = append(converted.Split, Allocation{Value: option, Percentage: float64(weighted.Weight) * 100 / ldWeightScale})
+		}
+		if rollout.BucketBy != "" && rollout.BucketBy != "key" {
+			converted.StickinessProperty = rollout.BucketBy
+		}
+	case env.Fallthrough.Variation != nil:
+		converted.Value, _ = value(*env.Fallthrough.Variation)
+	}
+
+	if n := len(env.Targets) + len(env.ContextTargets); n > 0 {
+		converted.Issues = append(converted.Issues, Issue{Construct: "individual targets", Detail: fmt.Sprintf("%d target lists not imported", n)})
+	}
+	if n := len(env.Rules); n > 0 {
+		converted.Issues = append(converted.Issues, Issue{Construct: "targeting rules", Detail: fmt.Sprintf("%d rules not imported; recreate them as conditions", n)})
+	}
+	if n := len(env.Prerequisites); n > 0 {
+		converted.Issues = append(converted.Issues, Issue{Construct: "prerequisites", Detail: fmt.Sprintf("%d prerequisites not imported", n)})
+	}
+	if env.OffVariation != nil && flag.Kind == "boolean" {
+		if off, _ := value(*env.OffVariation); off == true {
+			converted.Issues = append(converted.Issues, Issue{Construct: "off variation", Detail: "serves true when off; disabled flags are off in CloudBees"})
+		}
+	}
+
+	return converted
+}
@@ -0,0 +1,286 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unleashExport is the export format of Unleash (POST /api/admin/features-batch/export),
+// which is also accepted in the older state export layout
+type unleashExport struct {
+	Features []struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description"`
+		Type        string           `json:"type"`
+		Variants    []unleashVariant `json:"variants"` // Before per-environment variants
+	} `json:"features"`
+	FeatureStrategies   []unleashStrategy `json:"featureStrategies"`
+	FeatureEnvironments []struct {
+		FeatureName string           `json:"featureName"`
+		Environment string           `json:"environment"`
+		Enabled     bool             `json:"enabled"`
+		Variants    []unleashVariant `json:"variants"`
+	} `json:"featureEnvironments"`
+	FeatureTags []struct {
+		FeatureName string `json:"featureName"`
+		TagValue    string `json:"tagValue"`
+	} `json:"featureTags"`
+}
+
+type unleashStrategy struct {
+	FeatureName  string            `json:"featureName"`
+	Environment  string            `json:"environment"`
+	Name         string            `json:"name"`
+	StrategyName string            `json:"strategyName"` // Older exports
+	Parameters   map[string]string `json:"parameters"`
+	Constraints  []json.RawMessage `json:"constraints"`
+	Segments     []json.RawMessage `json:"segments"`
+}
+
+type unleashVariant struct {
+	Name       string `json:"name"`
+	Weight     int    `json:"weight"` // In tenths of a percent
+	Stickiness string `json:"stickiness"`
+}
+
+// Unleash reads the flags of an Unleash project
+type Unleash struct {
+	File         string // Export file
+	URL          string // Unleash URL, used when File is empty
+	Token        string // Admin API token
+	Project      string
+	Environments []string // Environments to export from the API
+}
+
+// Name identifies Unleash in reports
+func (u *Unleash) Name() string {
+	return "Unleash"
+}
+
+// Flags reads the feature toggles and converts them to the intermediate model
+func (u *Unleash) Flags() ([]Flag, error) {
+	export, err := u.export()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := make([]Flag, 0, len(export.Features))
+	index := map[string]int{}
+	featureVariants := map[string][]unleashVariant{}
+	for _, feature := range export.Features {
+		if _, ok := index[feature.Name]; ok {
+			continue // Exported once per environment
+		}
+		index[feature.Name] = len(flags)
+		flag := Flag{
+			Key:          feature.Name,
+			Description:  feature.Description,
+			Type:         "Boolean",
+			Variants:     []interface{}{true, false},
+			Temporary:    feature.Type == "" || feature.Type == "release" || feature.Type == "experiment",
+			Environments: map[string]Environment{},
+		}
+		if feature.Type == "kill-switch" {
+			flag.Tags = append(flag.Tags, "kill-switch")
+		}
+		flags = append(flags, flag)
+		if len(feature.Variants) > 0 {
+			featureVariants[feature.Name] = feature.Variants
+			setUnleashVariants(&flags[len(flags)-1], feature.Variants)
+		}
+	}
+
+	for _, tag := range export.FeatureTags {
+		if i, ok := index[tag.FeatureName]; ok && !containsString(flags[i].Tags, tag.TagValue) {
+			flags[i].Tags = append(flags[i].Tags, tag.TagValue)
+		}
+	}
+
+	for _, featureEnv := range export.FeatureEnvironments {
+		i, ok := index[featureEnv.FeatureName]
+		if !ok {
+			continue
+		}
+		if len(featureEnv.Variants) > 0 {
+			setUnleashVariants(&flags[i], featureEnv.Variants)
+		}
+		flags[i].Environments[featureEnv.Environment] = Environment{Enabled: featureEnv.Enabled}
+	}
+
+	type strategyKey struct{ feature, environment string }
+	strategies := map[strategyKey][]unleashStrategy{}
+	for _, strategy := range export.FeatureStrategies {
+		key := strategyKey{strategy.FeatureName, strategy.Environment}
+		strategies[key] = append(strategies[key], strategy)
+	}
+	for i := range flags {
+		flag := &flags[i]
+		for name, env := range flag.Environments {
+			variants := featureVariants[flag.Key]
+			for _, featureEnv := range export.FeatureEnvironments {
+				if featureEnv.FeatureName == flag.Key && featureEnv.Environment == name && len(featureEnv.Variants) > 0 {
+					variants = featureEnv.Variants
+				}
+			}
+
+			rollout, stickiness, issues := unleashRollout(strategies[strategyKey{flag.Key, name}])
+			env.Issues = issues
+			env.StickinessProperty = stickiness
+			switch {
+			case flag.Type == "String" && len(variants) > 0:
+				if rollout < 100 {
+					env.Issues = append(env.Issues, Issue{Construct: "variants", Detail: "variants of a partial rollout not imported"})
+				}
+				env.Split = []Allocation{}
+				for _, variant := range variants {
+					env.Split = append(env.Split, Allocation{Value: variant.Name, Percentage: float64(variant.Weight) / 10})
+					if variant.Stickiness != "" && variant.Stickiness != "default" && variant.Stickiness != "random" {
+						env.StickinessProperty = variant.Stickiness
+					}
+				}
+			case flag.Type == "String":
+				env.Issues = append(env.Issues, Issue{Construct: "variants", Detail: "no variants in this environment"})
+			case rollout >= 100:
+				env.Value = true
+			case rollout <= 0:
+				env.Value = false
+			default:
+				env.Split = []Allocation{{Value: true, Percentage: rollout}, {Value: false, Percentage: 100 - rollout}}
+			}
+			flag.Environments[name] = env
+		}
+	}
+
+	return flags, nil
+}
+
+// unleashRollout returns the percentage of users a toggle is enabled for by its strategies in an
+// environment. Only a single default or flexible rollout strategy without constraints can be mapped;
+// anything else is reported and serves nobody (0%) until the targeting is recreated.
+func unleashRollout(strategies []unleashStrategy) (float64, string, []Issue) {
+	if len(strategies) == 0 {
+		return 100, "", nil
+	}
+	if len(strategies) > 1 {
+		return 0, "", []Issue{{Construct: "strategies", Detail: fmt.Sprintf("%d strategies not imported; the flag serves false until they are recreated", len(strategies))}}
+	}
+
+	strategy := strategies[0]
+	name := strategy.Name
+	if name == "" {
+		name = strategy.StrategyName
+	}
+	if len(strategy.Constraints) > 0 || len(strategy.Segments) > 0 {
+		return 0, "", []Issue{{Construct: "strategy constraints", Detail: fmt.Sprintf("%s strategy with constraints or segments not imported; the flag serves false until it is recreated", name)}}
+	}
+
+	switch name {
+	case "default":
+		return 100, "", nil
+	case "flexibleRollout":
+		rollout, err := strconv.ParseFloat(strategy.Parameters["rollout"], 64)
+		if err != nil {
+			return 0, "", []Issue{{Construct: "strategy", Detail: fmt.Sprintf("invalid rollout percentage '%s'", strategy.Parameters["rollout"])}}
+		}
+		stickiness := strategy.Parameters["stickiness"]
+		if stickiness == "default" || stickiness == "random" {
+			stickiness = ""
+		}
+		return rollout, stickiness, nil
+	default:
+		return 0, "", []Issue{{Construct: "strategy", Detail: fmt.Sprintf("%s strategy not imported; the flag serves false until it is recreated", name)}}
+	}
+}
+
+// setUnleashVariants turns a toggle with variants into a String flag serving the variant names
+func setUnleashVariants(flag *Flag, variants []unleashVariant) {
+	flag.Type = "String"
+	if flag.Variants != nil && valueType(flag.Variants[0]) == "Boolean" {
+		flag.Variants = nil
+	}
+	for _, variant := range variants {
+		if !containsValue(flag.Variants, variant.Name) {
+			flag.Variants = append(flag.Variants, variant.Name)
+		}
+	}
+}
+
+// export reads the export file, or exports each environment from the API
+func (u *Unleash) export() (*unleashExport, error) {
+	if u.File != "" {
+		data, err := os.ReadFile(u.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Unleash export: %w", err)
+		}
+		var export unleashExport
+		if err := json.Unmarshal(data, &export); err != nil {
+			return nil, fmt.Errorf("failed to parse Unleash export: %w", err)
+		}
+		return &export, nil
+	}
+
+	if u.URL == "" || u.Token == "" || u.Project == "" {
+		return nil, fmt.Errorf("an Unleash export file, or an Unleash URL, API token and project, is required")
+	}
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	merged := &unleashExport{}
+	for _, environment := range u.Environments {
+		body, _ := json.Marshal(map[string]interface{}{"environment": environment, "project": u.Project, "downloadFile": false})
+		req, err := http.NewRequest("POST", strings.TrimSuffix(u.URL, "/")+"/api/admin/features-batch/export", bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", u.Token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export Unleash environment '%s': %w", environment, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to export Unleash environment '%s': status %d: %s", environment, resp.StatusCode, string(data))
+		}
+		var export unleashExport
+		err = json.NewDecoder(resp.Body).Decode(&export)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Unleash export: %w", err)
+		}
+
+		merged.Features = append(merged.Features, export.Features...)
+		merged.FeatureStrategies = append(merged.FeatureStrategies, export.FeatureStrategies...)
+		merged.FeatureEnvironments = append(merged.FeatureEnvironments, export.FeatureEnvironments...)
+		merged.FeatureTags = append(merged.FeatureTags, export.FeatureTags...)
+	}
+	return merged, nil
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsValue reports whether a slice contains a value
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+// Package keyring stores secrets in the credential store of the operating system: the login
+// keychain on macOS, and the Secret Service (GNOME Keyring, KWallet) on Linux through
+// secret-tool. The stores are used through their command-line tools, so no cgo or D-Bus client
+// is needed. Secrets are passed to the tools on standard input, never as arguments. Windows and
+// other systems are not supported.
+package keyring
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNotFound is returned when no secret is stored for a service and account
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// ErrUnsupported is returned when the operating system has no supported credential store
+var ErrUnsupported = errors.New("no supported keyring on this system (requires the macOS keychain or secret-tool on Linux; Windows is not supported)")
+
+// exitCodeNotFound is the exit code of security when no item matches
+const exitCodeNotFound = 44
+
+// Set stores the secret of an account of a service, replacing the current one
+func Set(service, account, secret string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		// The command is read from standard input by security -i, so the secret is not on the
+		// command line of the process, which other users can see. -X takes it hex-encoded, so it
+		// needs no quoting, and -U updates the item when it exists.
+		if strings.ContainsAny(service+account, "'\n") {
+			return fmt.Errorf("keyring service and account cannot contain quotes or newlines")
+		}
+		command := fmt.Sprintf("add-generic-password -U -s '%s' -a '%s' -X %s\n", service, account, hex.EncodeToString([]byte(secret)))
+		return runInteractive(command)
+	case "linux":
+		return run(secret, "secret-tool", "store", "--label", fmt.Sprintf("%s (%s)", service, account),
+			"service", service, "account", account)
+	}
+	return ErrUnsupported
+}
+
+// Get returns the secret of an account of a service
+func Get(service, account string) (string, error) {
+	var secret string
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		secret, err = output("security", "find-generic-password", "-s", service, "-a", account, "-w")
+	case "linux":
+		secret, err = output("secret-tool", "lookup", "service", service, "account", account)
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+// Delete removes the secret of an account of a service
+func Delete(service, account string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return run("", "security", "delete-generic-password", "-s", service, "-a", account)
+	case "linux":
+		// clear succeeds when nothing matches, so a missing secret is reported like on macOS
+		if _, err := Get(service, account); err != nil {
+			return err
+		}
+		return run("", "secret-tool", "clear", "service", service, "account", account)
+	}
+	return ErrUnsupported
+}
+
+// run runs a keyring tool with input on its standard input
+func run(input, name string, args ...string) error {
+	command := exec.Command(name, args...)
+	command.Stdin = strings.NewReader(input)
+	if out, err := command.CombinedOutput(); err != nil {
+		return toolError(name, err, string(out))
+	}
+	return nil
+}
+
+// runInteractive runs a command of security in interactive mode, which does not fail with an exit
+// code but prints the errors of the command
+func runInteractive(command string) error {
+	security := exec.Command("security", "-i")
+	security.Stdin = strings.NewReader(command)
+	out, err := security.CombinedOutput()
+	if err != nil {
+		return toolError("security", err, string(out))
+	}
+	if message := strings.TrimSpace(string(out)); message != "" {
+		return fmt.Errorf("security failed: %s", message)
+	}
+	return nil
+}
+
+// output runs a keyring tool and returns its output
+func output(name string, args ...string) (string, error) {
+	var stderr strings.Builder
+	command := exec.Command(name, args...)
+	command.Stderr = &stderr
+	out, err := command.Output()
+	if err != nil {
+		// secret-tool lookup fails without output when nothing matches
+		if name == "secret-tool" && len(out) == 0 && stderr.Len() == 0 {
+			return "", ErrNotFound
+		}
+		return "", toolError(name, err, stderr.String())
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// toolError describes the failure of a keyring tool
+func toolError(name string, err error, message string) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrUnsupported
+	}
+	var exitErr *exec.ExitError
+	if name == "security" && errors.As(err, &exitErr) && exitErr.ExitCode() == exitCodeNotFound {
+		return ErrNotFound
+	}
+	return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(message))
+}
@@ -0,0 +1,126 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration-as-code documents describe one flag each, in a multi-document YAML file
+const (
+	CascAPIVersion = "featuremanagement.cloudbees.io/v1"
+	CascKind       = "FeatureFlag"
+)
+
+// CascDocument is the configuration-as-code document of a flag
+type CascDocument struct {
+	APIVersion string       `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string       `json:"kind" yaml:"kind"`
+	Metadata   CascMetadata `json:"metadata" yaml:"metadata"`
+	Spec       CascSpec     `json:"spec" yaml:"spec"`
+}
+
+// CascMetadata identifies the flag of a configuration-as-code document
+type CascMetadata struct {
+	Name        string   `json:"name" yaml:"name"`
+	Application string   `json:"application" yaml:"application"`
+	Labels      []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// CascSpec is the definition and per-environment configuration of a flag
+type CascSpec struct {
+	Type         string                                 `json:"type" yaml:"type"`
+	Description  string                                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Variants     []string                               `json:"variants,omitempty" yaml:"variants,omitempty"`
+	Permanent    bool                                   `json:"permanent,omitempty" yaml:"permanent,omitempty"`
+	Environments map[string]cloudbees.FlagConfiguration `json:"environments,omitempty" yaml:"environments,omitempty"`
+}
+
+// MarshalCasc encodes the manifest as configuration-as-code documents, one per flag
+func (m *Manifest) MarshalCasc() ([]byte, error) {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	for _, flag := range m.Flags {
+		document := CascDocument{
+			APIVersion: CascAPIVersion,
+			Kind:       CascKind,
+			Metadata:   CascMetadata{Name: flag.Name, Application: m.Application, Labels: flag.Labels},
+			Spec: CascSpec{
+				Type:         flag.Type,
+				Description:  flag.Description,
+				Variants:     flag.Variants,
+				Permanent:    flag.IsPermanent,
+				Environments: flag.Environments,
+			},
+		}
+		if err := encoder.Encode(document); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// isCasc reports whether a YAML file holds configuration-as-code documents rather than a manifest
+func isCasc(data []byte) bool {
+	var header struct {
+		Kind string `yaml:"kind"`
+	}
+	return yaml.NewDecoder(bytes.NewReader(data)).Decode(&header) == nil && header.Kind == CascKind
+}
+
+// parseCasc builds a manifest from configuration-as-code documents. All flags must belong to the same application.
+func parseCasc(filename string, data []byte) (*Manifest, error) {
+	m := &Manifest{Flags: []Flag{}}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var document interface{}
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest '%s': %w", filename, err)
+		}
+		if document == nil {
+			continue
+		}
+
+		// Converted to JSON first so values have the same types as in manifests
+		var casc CascDocument
+		data, err := json.Marshal(document)
+		if err == nil {
+			err = json.Unmarshal(data, &casc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest '%s': %w", filename, err)
+		}
+		if casc.Kind != CascKind {
+			return nil, fmt.Errorf("failed to parse manifest '%s': unexpected kind '%s'", filename, casc.Kind)
+		}
+		if m.Application == "" {
+			m.Application = casc.Metadata.Application
+		} else if casc.Metadata.Application != "" && casc.Metadata.Application != m.Application {
+			return nil, fmt.Errorf("failed to parse manifest '%s': flags of applications '%s' and '%s'", filename, m.Application, casc.Metadata.Application)
+		}
+
+		m.Flags = append(m.Flags, Flag{
+			Name:         casc.Metadata.Name,
+			Type:         casc.Spec.Type,
+			Description:  casc.Spec.Description,
+			Variants:     casc.Spec.Variants,
+			Labels:       casc.Metadata.Labels,
+			IsPermanent:  casc.Spec.Permanent,
+			Environments: casc.Spec.Environments,
+		})
+	}
+	return m, nil
+}
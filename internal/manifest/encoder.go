@@ -0,0 +1,57 @@
+package manifest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Encoder writes a JSON manifest one flag at a time, so exporting a large application does not
+// hold every flag in memory. The output is the same as Marshal with a .json file name.
+type Encoder struct {
+	w     *bufio.Writer
+	count int
+}
+
+// NewEncoder writes the header of the manifest of application
+func NewEncoder(w io.Writer, application, exportedAt string) (*Encoder, error) {
+	e := &Encoder{w: bufio.NewWriter(w)}
+	applicationJSON, _ := json.Marshal(application)
+	fmt.Fprintf(e.w, "{\n  \"application\": %s,\n", applicationJSON)
+	if exportedAt != "" {
+		exportedAtJSON, _ := json.Marshal(exportedAt)
+		fmt.Fprintf(e.w, "  \"exportedAt\": %s,\n", exportedAtJSON)
+	}
+	_, err := e.w.WriteString(`  "flags": [`)
+	return e, err
+}
+
+// Encode writes the next flag
+func (e *Encoder) Encode(flag Flag) error {
+	data, err := json.MarshalIndent(flag, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	if e.count > 0 {
+		e.w.WriteString(",")
+	}
+	e.w.WriteString("\n    ")
+	e.count++
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Count returns the number of flags written
+func (e *Encoder) Count() int {
+	return e.count
+}
+
+// Close ends the manifest and flushes it
+func (e *Encoder) Close() error {
+	if e.count > 0 {
+		e.w.WriteString("\n  ")
+	}
+	e.w.WriteString("]\n}\n")
+	return e.w.Flush()
+}
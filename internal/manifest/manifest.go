@@ -0,0 +1,104 @@
+// Package manifest defines the file format of exported flag state, used for snapshots and reviews of flag changes.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the state of the flags of one application
+type Manifest struct {
+	Application string `json:"application" yaml:"application"`
+	ExportedAt  string `json:"exportedAt,omitempty" yaml:"exportedAt,omitempty"`
+	Flags       []Flag `json:"flags" yaml:"flags"`
+}
+
+// Flag is a flag definition with its configuration per environment name
+type Flag struct {
+	Name         string                                 `json:"name" yaml:"name"`
+	Type         string                                 `json:"type" yaml:"type"`
+	Description  string                                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Variants     []string                               `json:"variants,omitempty" yaml:"variants,omitempty"`
+	Labels       []string                               `json:"labels,omitempty" yaml:"labels,omitempty"`
+	IsPermanent  bool                                   `json:"isPermanent,omitempty" yaml:"isPermanent,omitempty"`
+	Environments map[string]cloudbees.FlagConfiguration `json:"environments,omitempty" yaml:"environments,omitempty"`
+}
+
+// Load reads a manifest from a JSON or YAML file
+func Load(filename string) (*Manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return Parse(filename, data)
+}
+
+// Parse decodes a manifest, as YAML when the file name ends in .yaml or .yml and as JSON otherwise.
+// YAML files can also hold configuration-as-code documents.
+func Parse(filename string, data []byte) (*Manifest, error) {
+	// YAML is converted to JSON first so values have the same types (e.g. float64 numbers) in both formats
+	if IsYAML(filename) {
+		if isCasc(data) {
+			return parseCasc(filename, data)
+		}
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest '%s': %w", filename, err)
+		}
+		var err error
+		if data, err = json.Marshal(document); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest '%s': %w", filename, err)
+		}
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest '%s': %w", filename, err)
+	}
+
+	return &m, nil
+}
+
+// IsYAML reports whether a manifest file name has a YAML extension
+func IsYAML(filename string) bool {
+	return strings.HasSuffix(filename, ".yaml") || strings.HasSuffix(filename, ".yml")
+}
+
+// Marshal encodes the manifest as indented JSON, or YAML when the file name ends in .yaml or .yml
+func (m *Manifest) Marshal(filename string) ([]byte, error) {
+	if IsYAML(filename) {
+		return yaml.Marshal(m)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Save writes the manifest to a file, in the format given by its extension
+func (m *Manifest) Save(filename string) error {
+	data, err := m.Marshal(filename)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Flag returns the flag with the given name, or nil
+func (m *Manifest) Flag(name string) *Flag {
+	for i := range m.Flags {
+		if m.Flags[i].Name == name {
+			return &m.Flags[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patchKey marks a named list item of an overlay, e.g. a flag, that deletes the item ($patch: delete)
+const patchKey = "$patch"
+
+// LoadOverlay reads an overlay from a JSON or YAML file, renders it as a template with values
+// and applies it to the manifest
+func LoadOverlay(m *Manifest, filename string, values map[string]interface{}) (*Manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlay: %w", err)
+	}
+	if data, err = Render(filename, data, values); err != nil {
+		return nil, err
+	}
+	return ApplyOverlay(m, filename, data)
+}
+
+// ApplyOverlay returns the manifest patched with an overlay, a partial manifest that holds
+// only what differs, e.g. in one environment. Objects are merged and a null value removes a
+// field; lists of named objects, such as the flags, are merged by name, with $patch: delete
+// removing an item; other lists and values replace those of the manifest.
+func ApplyOverlay(m *Manifest, filename string, data []byte) (*Manifest, error) {
+	var overlay interface{}
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse overlay '%s': %w", filename, err)
+	}
+	if _, ok := overlay.(map[string]interface{}); !ok && overlay != nil {
+		return nil, fmt.Errorf("failed to parse overlay '%s': not a manifest", filename)
+	}
+
+	// The manifest goes through JSON, so both documents have the same types
+	baseJSON, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	var base interface{}
+	if err := json.Unmarshal(baseJSON, &base); err != nil {
+		return nil, err
+	}
+	overlayJSON, err := json.Marshal(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse overlay '%s': %w", filename, err)
+	}
+	if err := json.Unmarshal(overlayJSON, &overlay); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(mergeOverlay(base, overlay))
+	if err != nil {
+		return nil, err
+	}
+	var result Manifest
+	if err := json.Unmarshal(merged, &result); err != nil {
+		return nil, fmt.Errorf("failed to apply overlay '%s': %w", filename, err)
+	}
+	return &result, nil
+}
+
+// mergeOverlay merges an overlay value into a base value
+func mergeOverlay(base, overlay interface{}) interface{} {
+	switch overlay := overlay.(type) {
+	case map[string]interface{}:
+		baseMap, ok := base.(map[string]interface{})
+		if !ok {
+			baseMap = map[string]interface{}{}
+		}
+		for key, value := range overlay {
+			if value == nil {
+				delete(baseMap, key)
+				continue
+			}
+			baseMap[key] = mergeOverlay(baseMap[key], value)
+		}
+		return baseMap
+	case []interface{}:
+		baseList, ok := base.([]interface{})
+		if !ok || !namedItems(baseList) || !namedItems(overlay) {
+			return overlay
+		}
+		for _, item := range overlay {
+			patch := item.(map[string]interface{})
+			index := -1
+			for i, existing := range baseList {
+				if existing.(map[string]interface{})["name"] == patch["name"] {
+					index = i
+					break
+				}
+			}
+			switch {
+			case patch[patchKey] == "delete":
+				if index >= 0 {
+					baseList = append(baseList[:index], baseList[index+1:]...)
+				}
+			case index >= 0:
+				delete(patch, patchKey)
+				baseList[index] = mergeOverlay(baseList[index], patch)
+			default:
+				delete(patch, patchKey)
+				baseList = append(baseList, patch)
+			}
+		}
+		return baseList
+	default:
+		return overlay
+	}
+}
+
+// namedItems reports whether every item of a list is an object with a name
+func namedItems(list []interface{}) bool {
+	for _, item := range list {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := object["name"].(string); !ok {
+			return false
+		}
+	}
+	return true
+}
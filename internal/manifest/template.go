@@ -0,0 +1,130 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// variablePattern matches ${NAME} and ${NAME:-default}; $${NAME} escapes the substitution
+var variablePattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// LoadTemplate reads a manifest from a JSON or YAML file after rendering it with values
+func LoadTemplate(filename string, values map[string]interface{}) (*Manifest, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if data, err = Render(filename, data, values); err != nil {
+		return nil, err
+	}
+	return Parse(filename, data)
+}
+
+// Render interpolates a manifest or configuration document, so the same file can drive several
+// environments. It is first executed as a Go template with the values as .Values and the
+// environment variables as .Env, e.g. {{ .Values.enabled }}, then ${NAME} is replaced with the
+// top-level value NAME or the environment variable NAME, e.g. ${RELEASE_PERCENTAGE}.
+// Undefined values are errors, unless a default is given as ${NAME:-default}.
+func Render(name string, data []byte, values map[string]interface{}) ([]byte, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to render '%s': %w", name, err)
+	}
+	env := map[string]string{}
+	for _, variable := range os.Environ() {
+		if key, value, ok := strings.Cut(variable, "="); ok {
+			env[key] = value
+		}
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, map[string]interface{}{"Values": values, "Env": env}); err != nil {
+		return nil, fmt.Errorf("failed to render '%s': %w", name, err)
+	}
+
+	var undefined []string
+	result := variablePattern.ReplaceAllFunc(rendered.Bytes(), func(match []byte) []byte {
+		if bytes.HasPrefix(match, []byte("$$")) {
+			return match[1:]
+		}
+		groups := variablePattern.FindSubmatch(match)
+		variable := string(groups[1])
+		if value, ok := values[variable]; ok {
+			return []byte(fmt.Sprint(value))
+		}
+		if value, ok := os.LookupEnv(variable); ok {
+			return []byte(value)
+		}
+		if groups[2] != nil {
+			return groups[3]
+		}
+		undefined = append(undefined, variable)
+		return match
+	})
+	if len(undefined) > 0 {
+		return nil, fmt.Errorf("failed to render '%s': variables not set: %s", name, strings.Join(undefined, ", "))
+	}
+	return result, nil
+}
+
+// LoadValues merges the values of YAML files, in order, and of key=value assignments, where
+// a dotted key sets a nested value (rollout.percentage=25) and the value is parsed as YAML
+func LoadValues(files, assignments []string) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values: %w", err)
+		}
+		var fileValues map[string]interface{}
+		if err := yaml.Unmarshal(data, &fileValues); err != nil {
+			return nil, fmt.Errorf("failed to parse values '%s': %w", file, err)
+		}
+		mergeValues(values, fileValues)
+	}
+
+	for _, assignment := range assignments {
+		key, raw, ok := strings.Cut(assignment, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid value '%s', must be key=value", assignment)
+		}
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			value = raw
+		}
+		path := strings.Split(key, ".")
+		parent := values
+		for _, part := range path[:len(path)-1] {
+			child, ok := parent[part].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				parent[part] = child
+			}
+			parent = child
+		}
+		parent[path[len(path)-1]] = value
+	}
+	return values, nil
+}
+
+// mergeValues copies src into dst, merging nested maps
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,154 @@
+// Package mcp implements a Model Context Protocol server over stdio (JSON-RPC 2.0, one message per line)
+// exposing tools to AI assistants.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ProtocolVersion is the MCP revision implemented by the server
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool is a function the assistant can call
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"` // JSON Schema of the arguments
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+
+	// Handler returns the text result of a call; errors are reported to the assistant as tool errors
+	Handler func(arguments map[string]interface{}) (string, error) `json:"-"`
+}
+
+// Server dispatches MCP requests to tools
+type Server struct {
+	Name    string
+	Version string
+	tools   []Tool
+}
+
+// AddTool registers a tool
+func (s *Server) AddTool(tool Tool) {
+	s.tools = append(s.tools, tool)
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve reads requests from r and writes responses to w until r is closed
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+	send := func(resp response) {
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(resp)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			send(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rpcErr := s.handle(req)
+		if len(req.ID) == 0 {
+			continue // Notifications get no response
+		}
+		send(response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr})
+	}
+	return scanner.Err()
+}
+
+// handle executes one request
+func (s *Server) handle(req request) (interface{}, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "jsonrpc must be 2.0"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]string{"name": s.Name, "version": s.Version},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		for _, tool := range s.tools {
+			if tool.Name == params.Name {
+				return callTool(tool, params.Arguments), nil
+			}
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool '%s'", params.Name)}
+	default:
+		if len(req.ID) == 0 {
+			return nil, nil // Unknown notifications, e.g. notifications/initialized, are ignored
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not found", req.Method)}
+	}
+}
+
+// callTool runs a tool and wraps its output as a CallToolResult
+func callTool(tool Tool, arguments map[string]interface{}) map[string]interface{} {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	text, err := tool.Handler(arguments)
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+	}
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// TypeOperationFailed is the CloudEvents type of operations that failed
+const TypeOperationFailed = "flag.operation.failed"
+
+// CloudEvents writes every operation result as a CloudEvents 1.0 JSON event (structured mode)
+// to stdout ("-"), a JSONL file or an HTTP sink
+type CloudEvents struct {
+	Sink       string
+	HTTPClient *http.Client
+
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Name implements Notifier
+func (c *CloudEvents) Name() string {
+	return "CloudEvents"
+}
+
+// ReportsFailures implements FailureNotifier
+func (c *CloudEvents) ReportsFailures() bool {
+	return true
+}
+
+// Notify implements Notifier
+func (c *CloudEvents) Notify(event Event) error {
+	eventType := event.Type
+	if event.Error != "" {
+		eventType = TypeOperationFailed
+	}
+
+	source := "/fm-actions"
+	if event.Application != "" {
+		source += "/applications/" + event.Application
+	}
+
+	envelope := map[string]interface{}{
+		"specversion":     "1.0",
+		"id":              event.ID,
+		"source":          source,
+		"type":            eventType,
+		"time":            event.Time.Format("2006-01-02T15:04:05.000Z07:00"),
+		"datacontenttype": "application/json",
+		"data":            event,
+	}
+	if event.Flag != "" {
+		envelope["subject"] = event.Flag
+	}
+	body, err := encodeJSON(envelope)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(c.Sink, "http://") || strings.HasPrefix(c.Sink, "https://") {
+		return post(c.HTTPClient, c.Sink, body, map[string]string{"Content-Type": "application/cloudevents+json; charset=UTF-8"})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.out == nil {
+		if c.Sink == "-" || c.Sink == "stdout" {
+			c.out = os.Stdout
+		} else {
+			file, err := os.OpenFile(c.Sink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return fmt.Errorf("failed to open CloudEvents file: %w", err)
+			}
+			c.out = file
+		}
+	}
+	_, err = c.out.Write(append(body, '\n'))
+	return err
+}
@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Datadog posts an event for every change, tagged so dashboards can overlay flag changes on metrics
+type Datadog struct {
+	APIKey     string
+	Site       string // datadoghq.com, datadoghq.eu, us3.datadoghq.com, ... or an API base URL
+	HTTPClient *http.Client
+}
+
+// Name implements Notifier
+func (d *Datadog) Name() string {
+	return "Datadog"
+}
+
+// Notify implements Notifier
+func (d *Datadog) Notify(event Event) error {
+	text := strings.Join(ChangeLines(event), "\n")
+	if event.RunURL != "" {
+		text += "\n" + event.RunURL
+	}
+
+	tags := []string{"source:fm-actions", "operation:" + event.Operation, "event_type:" + event.Type}
+	if event.Flag != "" {
+		tags = append(tags, "flag:"+event.Flag)
+	}
+	if event.Environment != "" {
+		tags = append(tags, "env:"+event.Environment)
+	}
+	if event.Application != "" {
+		tags = append(tags, "service:"+event.Application)
+	}
+
+	payload := map[string]interface{}{
+		"title":            Summary(event),
+		"text":             strings.TrimSpace(text),
+		"tags":             tags,
+		"alert_type":       "info",
+		"source_type_name": "feature_flags",
+		"aggregation_key":  "fm-actions:" + event.Flag,
+		"date_happened":    event.Time.Unix(),
+	}
+
+	body, err := encodeJSON(payload)
+	if err != nil {
+		return err
+	}
+	return post(d.HTTPClient, d.eventsURL(), body, map[string]string{"DD-API-KEY": d.APIKey})
+}
+
+// eventsURL returns the events API endpoint of the configured site
+func (d *Datadog) eventsURL() string {
+	site := d.Site
+	if site == "" {
+		site = "datadoghq.com"
+	}
+	if strings.HasPrefix(site, "http://") || strings.HasPrefix(site, "https://") {
+		return strings.TrimSuffix(site, "/") + "/api/v1/events"
+	}
+	return fmt.Sprintf("https://api.%s/api/v1/events", site)
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Grafana creates an annotation for every change, so flag changes show up on dashboards.
+// Without a dashboard UID the annotation is organization-wide and matched by tags.
+type Grafana struct {
+	URL          string
+	Token        string // Service account token
+	DashboardUID string
+	Tags         []string
+	HTTPClient   *http.Client
+}
+
+// Name implements Notifier
+func (g *Grafana) Name() string {
+	return "Grafana"
+}
+
+// Notify implements Notifier
+func (g *Grafana) Notify(event Event) error {
+	tags := append([]string{"feature-flag"}, g.Tags...)
+	if event.Flag != "" {
+		tags = append(tags, "flag:"+event.Flag)
+	}
+	if event.Environment != "" {
+		tags = append(tags, "env:"+event.Environment)
+	}
+
+	text := Summary(event)
+	if lines := ChangeLines(event); len(lines) > 0 {
+		text += "\n" + strings.Join(lines, "\n")
+	}
+	if event.RunURL != "" {
+		text += "\n<a href=\"" + event.RunURL + "\">View run</a>"
+	}
+
+	payload := map[string]interface{}{
+		"time": event.Time.UnixMilli(),
+		"tags": tags,
+		"text": text,
+	}
+	if g.DashboardUID != "" {
+		payload["dashboardUID"] = g.DashboardUID
+	}
+
+	headers := map[string]string{}
+	if g.Token != "" {
+		headers["Authorization"] = "Bearer " + g.Token
+	}
+	body, err := encodeJSON(payload)
+	if err != nil {
+		return err
+	}
+	return post(g.HTTPClient, strings.TrimSuffix(g.URL, "/")+"/api/annotations", body, headers)
+}
@@ -0,0 +1,190 @@
+// Package notify sends events about flag changes made by the CLI to external systems.
+package notify
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Event types
+const (
+	TypeFlagCreated   = "flag.created"
+	TypeFlagUpdated   = "flag.updated"
+	TypeFlagDeleted   = "flag.deleted"
+	TypeConfigUpdated = "flag.config.updated"
+	TypeDriftDetected = "flag.drift.detected" // Live state diverges from a manifest; Before holds the manifest values
+
+	TypeEnvironmentCreated = "environment.created"
+	TypeEnvironmentUpdated = "environment.updated"
+	TypeEnvironmentDeleted = "environment.deleted"
+	TypeApplicationCreated = "application.created"
+	TypeApplicationUpdated = "application.updated"
+)
+
+// requestTimeout bounds every notification request, so a slow receiver cannot stall a pipeline
+const requestTimeout = 10 * time.Second
+
+// Event describes a change made by the CLI. Only FailureNotifiers receive events of failed changes.
+type Event struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Time        time.Time              `json:"time"`
+	Operation   string                 `json:"operation"`
+	Application string                 `json:"application,omitempty"`
+	Flag        string                 `json:"flag,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Changes     map[string]interface{} `json:"changes,omitempty"`
+	Before      interface{}            `json:"before,omitempty"`
+	After       interface{}            `json:"after,omitempty"`
+	Principal   string                 `json:"principal,omitempty"`
+	RunURL      string                 `json:"runUrl,omitempty"` // Link to the pipeline run that made the change
+	Error       string                 `json:"error,omitempty"`  // Set when the operation failed
+}
+
+// Notifier delivers events to one destination
+type Notifier interface {
+	// Name identifies the destination in warnings
+	Name() string
+	Notify(event Event) error
+}
+
+// FailureNotifier is implemented by notifiers that also receive events for failed operations
+type FailureNotifier interface {
+	Notifier
+	ReportsFailures() bool
+}
+
+// NewEventID returns a random event identifier
+func NewEventID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// postJSON encodes payload and posts it
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := encodeJSON(payload)
+	if err != nil {
+		return err
+	}
+	return post(client, url, body, nil)
+}
+
+// encodeJSON encodes a payload, which fails for values such as the infinite or NaN
+// numbers of a YAML default value
+func encodeJSON(payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return body, nil
+}
+
+// post sends a JSON body and fails on non-2xx responses
+func post(client *http.Client, url string, body []byte, headers map[string]string) error {
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// Summary describes the event in one line, e.g. "checkout enabled in production"
+func Summary(event Event) string {
+	subject := event.Flag
+	if event.Application != "" {
+		subject = event.Application + "/" + event.Flag
+	}
+
+	switch event.Type {
+	case TypeEnvironmentCreated:
+		return fmt.Sprintf("Environment %s created", event.Environment)
+	case TypeEnvironmentUpdated:
+		return fmt.Sprintf("Environment %s updated", event.Environment)
+	case TypeEnvironmentDeleted:
+		return fmt.Sprintf("Environment %s deleted", event.Environment)
+	case TypeApplicationCreated:
+		return fmt.Sprintf("Application %s created", event.Application)
+	case TypeApplicationUpdated:
+		return fmt.Sprintf("Application %s updated", event.Application)
+	case TypeFlagCreated:
+		return fmt.Sprintf("Flag %s created", subject)
+	case TypeFlagDeleted:
+		return fmt.Sprintf("Flag %s deleted", subject)
+	case TypeConfigUpdated:
+		if enabled, ok := event.Changes["enabled"].(bool); ok && len(event.Changes) == 1 {
+			if enabled {
+				return fmt.Sprintf("Flag %s enabled in %s", subject, event.Environment)
+			}
+			return fmt.Sprintf("Flag %s disabled in %s", subject, event.Environment)
+		}
+		return fmt.Sprintf("Flag %s configuration changed in %s", subject, event.Environment)
+	case TypeDriftDetected:
+		if event.Environment == "" {
+			return fmt.Sprintf("Flag %s drifted from the manifest", subject)
+		}
+		return fmt.Sprintf("Flag %s drifted from the manifest in %s", subject, event.Environment)
+	default:
+		return fmt.Sprintf("Flag %s updated", subject)
+	}
+}
+
+// ChangeLines lists each changed field with its value before and after the change, in field order
+func ChangeLines(event Event) []string {
+	before := map[string]interface{}{}
+	if event.Before != nil {
+		data, _ := json.Marshal(event.Before)
+		json.Unmarshal(data, &before)
+	}
+
+	fields := make([]string, 0, len(event.Changes))
+	for field := range event.Changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if old, ok := before[field]; ok {
+			lines = append(lines, fmt.Sprintf("%s: %s → %s", field, formatValue(old), formatValue(event.Changes[field])))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s: %s", field, formatValue(event.Changes[field])))
+		}
+	}
+	return lines
+}
+
+// formatValue renders a configuration value compactly
+func formatValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,80 @@
+package notify
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxPagerDutySummary is the longest summary accepted by the Events API
+const maxPagerDutySummary = 1024
+
+// PagerDuty sends Change Events for changes to production-like environments, so on-call
+// responders see recent flag activity next to deploys. Other changes are ignored.
+type PagerDuty struct {
+	RoutingKey   string
+	URL          string   // Events API base URL, e.g. https://events.eu.pagerduty.com
+	Environments []string // Environment name patterns (shell globs) that send change events
+	HTTPClient   *http.Client
+}
+
+// Name implements Notifier
+func (p *PagerDuty) Name() string {
+	return "PagerDuty"
+}
+
+// Notify implements Notifier
+func (p *PagerDuty) Notify(event Event) error {
+	if !p.matches(event.Environment) {
+		return nil
+	}
+
+	summary := Summary(event)
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:maxPagerDutySummary]
+	}
+
+	details := map[string]interface{}{
+		"operation":   event.Operation,
+		"application": event.Application,
+		"flag":        event.Flag,
+		"environment": event.Environment,
+		"changes":     ChangeLines(event),
+	}
+	if event.Principal != "" {
+		details["principal"] = event.Principal
+	}
+
+	payload := map[string]interface{}{
+		"routing_key": p.RoutingKey,
+		"payload": map[string]interface{}{
+			"summary":        summary,
+			"timestamp":      event.Time.Format(time.RFC3339),
+			"source":         "fm-actions",
+			"custom_details": details,
+		},
+	}
+	if event.RunURL != "" {
+		payload["links"] = []map[string]string{{"href": event.RunURL, "text": "Pipeline run"}}
+	}
+
+	url := p.URL
+	if url == "" {
+		url = "https://events.pagerduty.com"
+	}
+	return postJSON(p.HTTPClient, strings.TrimSuffix(url, "/")+"/v2/change/enqueue", payload)
+}
+
+// matches reports whether changes to the environment send change events
+func (p *PagerDuty) matches(environment string) bool {
+	if environment == "" {
+		return false
+	}
+	for _, pattern := range p.Environments {
+		if ok, _ := path.Match(pattern, environment); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Slack posts a formatted message to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	Channel    string // Overrides the webhook's default channel, if the webhook allows it
+	HTTPClient *http.Client
+}
+
+// Name implements Notifier
+func (s *Slack) Name() string {
+	return "Slack"
+}
+
+// Notify implements Notifier
+func (s *Slack) Notify(event Event) error {
+	summary := Summary(event)
+
+	var details strings.Builder
+	fmt.Fprintf(&details, "*%s*", summary)
+	for _, line := range ChangeLines(event) {
+		fmt.Fprintf(&details, "\n• `%s`", line)
+	}
+
+	var context []string
+	context = append(context, "Operation: "+event.Operation)
+	if event.Principal != "" {
+		context = append(context, "By: "+event.Principal)
+	}
+	if event.RunURL != "" {
+		context = append(context, fmt.Sprintf("<%s|View run>", event.RunURL))
+	}
+
+	payload := map[string]interface{}{
+		"text": summary,
+		"blocks": []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]string{"type": "mrkdwn", "text": details.String()},
+			},
+			{
+				"type":     "context",
+				"elements": []map[string]string{{"type": "mrkdwn", "text": strings.Join(context, " | ")}},
+			},
+		},
+	}
+	if s.Channel != "" {
+		payload["channel"] = s.Channel
+	}
+
+	return postJSON(s.HTTPClient, s.WebhookURL, payload)
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Teams posts an Adaptive Card to a Microsoft Teams incoming webhook (or Workflows webhook)
+type Teams struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+// Name implements Notifier
+func (t *Teams) Name() string {
+	return "Teams"
+}
+
+// Notify implements Notifier
+func (t *Teams) Notify(event Event) error {
+	facts := []map[string]string{}
+	for _, fact := range [][2]string{
+		{"Application", event.Application},
+		{"Flag", event.Flag},
+		{"Environment", event.Environment},
+		{"Operation", event.Operation},
+		{"By", event.Principal},
+	} {
+		if fact[1] != "" {
+			facts = append(facts, map[string]string{"title": fact[0], "value": fact[1]})
+		}
+	}
+
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": Summary(event), "weight": "Bolder", "size": "Medium", "wrap": true},
+		{"type": "FactSet", "facts": facts},
+	}
+	if lines := ChangeLines(event); len(lines) > 0 {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock", "text": "- " + strings.Join(lines, "\n- "), "wrap": true, "fontType": "Monospace",
+		})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if event.RunURL != "" {
+		card["actions"] = []map[string]string{{"type": "Action.OpenUrl", "title": "View run", "url": event.RunURL}}
+	}
+
+	payload := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+	return postJSON(t.HTTPClient, t.WebhookURL, payload)
+}
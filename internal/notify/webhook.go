@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, as "sha256=<hex>"
+const SignatureHeader = "X-FM-Signature-256"
+
+// Webhook posts events as JSON to a URL. When Secret is set, the body is signed so
+// receivers can verify the event came from the CLI.
+type Webhook struct {
+	URL        string
+	Secret     string
+	HTTPClient *http.Client
+}
+
+// Name implements Notifier
+func (w *Webhook) Name() string {
+	return "webhook"
+}
+
+// Notify implements Notifier
+func (w *Webhook) Notify(event Event) error {
+	body, err := encodeJSON(event)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{"X-FM-Event": event.Type}
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		headers[SignatureHeader] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return post(w.HTTPClient, w.URL, body, headers)
+}
@@ -0,0 +1,141 @@
+// Package oidc exchanges the OIDC token a CI system issues to a job (workload identity) for a
+// short-lived CloudBees API token, so pipelines do not need a long-lived token as a secret.
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Token types of the OAuth 2.0 token exchange (RFC 8693)
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeIDToken       = "urn:ietf:params:oauth:token-type:id_token"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// ErrNoGitHubToken is returned when the job has no GitHub Actions OIDC token, i.e. does not run
+// in GitHub Actions or lacks the id-token: write permission
+var ErrNoGitHubToken = errors.New("GitHub Actions OIDC token not available (requires permissions: id-token: write)")
+
+// Token is an API token obtained by an exchange
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time // Zero when the exchange did not report an expiry
+}
+
+// Exchanger trades OIDC tokens for API tokens with a token exchange endpoint
+type Exchanger struct {
+	URL        string
+	Audience   string // Audience of the API token, e.g. the API URL
+	HTTPClient *http.Client
+}
+
+// Exchange trades an OIDC token (a JWT signed by the CI system) for an API token
+func (e *Exchanger) Exchange(subjectToken string) (*Token, error) {
+	client := e.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	form := url.Values{
+		"grant_type":           {grantTypeTokenExchange},
+		"subject_token":        {subjectToken},
+		"subject_token_type":   {tokenTypeIDToken},
+		"requested_token_type": {tokenTypeAccessToken},
+	}
+	if e.Audience != "" {
+		form.Set("audience", e.Audience)
+	}
+	resp, err := client.PostForm(e.URL, form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange OIDC token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to exchange OIDC token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse token exchange response: %w", err)
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("failed to exchange OIDC token: no access_token in response")
+	}
+
+	token := &Token{AccessToken: response.AccessToken}
+	if response.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// ReadTokenFile reads an OIDC token written to a file by the CI system, e.g. a workload
+// identity token mounted in the job
+func ReadTokenFile(filename string) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OIDC token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("OIDC token file '%s' is empty", filename)
+	}
+	return token, nil
+}
+
+// GitHubActionsToken requests an OIDC token for audience from the GitHub Actions runtime
+func GitHubActionsToken(client *http.Client, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", ErrNoGitHubToken
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	tokenURL, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+	if audience != "" {
+		query := tokenURL.Query()
+		query.Set("audience", audience)
+		tokenURL.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequest(http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request GitHub Actions OIDC token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("failed to request GitHub Actions OIDC token: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var response struct {
+		Value string `json:"value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil || response.Value == "" {
+		return "", fmt.Errorf("failed to parse GitHub Actions OIDC token response")
+	}
+	return response.Value, nil
+}
@@ -0,0 +1,173 @@
+// Package openfeature converts manifests to flag definitions for flagd, the OpenFeature
+// evaluation engine, so flags can be evaluated locally without the platform.
+package openfeature
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+)
+
+// SchemaURL is the JSON schema of flagd flag definitions
+const SchemaURL = "https://flagd.dev/schema/v0/flags.json"
+
+// Flag states
+const (
+	StateEnabled  = "ENABLED"
+	StateDisabled = "DISABLED"
+)
+
+// Document is a flagd flag definition file
+type Document struct {
+	Schema string          `json:"$schema"`
+	Flags  map[string]Flag `json:"flags"`
+}
+
+// Flag is a flagd flag definition
+type Flag struct {
+	State          string                 `json:"state"`
+	Variants       map[string]interface{} `json:"variants"`
+	DefaultVariant string                 `json:"defaultVariant"`
+	Targeting      map[string]interface{} `json:"targeting,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// FromManifest converts the configuration of the flags in one environment. Disabled flags are
+// DISABLED, so applications get their coded default value as with the platform. Percentage splits
+// become fractional targeting. Flags that cannot be represented are returned as warnings.
+func FromManifest(m *manifest.Manifest, environment string) (*Document, []string) {
+	document := &Document{Schema: SchemaURL, Flags: map[string]Flag{}}
+	var warnings []string
+
+	for _, flag := range m.Flags {
+		config, ok := flag.Environments[environment]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: not configured in %s", flag.Name, environment))
+			continue
+		}
+
+		converted := Flag{
+			State:    StateDisabled,
+			Variants: variants(flag),
+			Metadata: map[string]interface{}{"application": m.Application, "environment": environment},
+		}
+		if config.Enabled {
+			converted.State = StateEnabled
+		}
+		if flag.Description != "" {
+			converted.Metadata["description"] = flag.Description
+		}
+
+		if split, ok := config.DefaultValue.([]interface{}); ok {
+			targeting, defaultVariant, err := fractional(split, config.StickinessProperty, converted.Variants)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", flag.Name, err))
+				continue
+			}
+			converted.Targeting = targeting
+			converted.DefaultVariant = defaultVariant
+		} else {
+			converted.DefaultVariant = variantKey(config.DefaultValue, converted.Variants)
+		}
+		if converted.DefaultVariant == "" {
+			converted.DefaultVariant = firstVariant(flag)
+		}
+
+		if config.Conditions != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: targeting conditions in %s are not exported", flag.Name, environment))
+		}
+		document.Flags[flag.Name] = converted
+	}
+
+	return document, warnings
+}
+
+// variants maps variant names to typed values
+func variants(flag manifest.Flag) map[string]interface{} {
+	result := map[string]interface{}{}
+	switch flag.Type {
+	case "Boolean":
+		result["true"] = true
+		result["false"] = false
+	case "Number":
+		for _, variant := range flag.Variants {
+			if value, err := strconv.ParseFloat(variant, 64); err == nil {
+				result[variant] = value
+			}
+		}
+	default:
+		for _, variant := range flag.Variants {
+			result[variant] = variant
+		}
+	}
+	return result
+}
+
+// variantKey returns the name of the variant serving a value, or an empty string
+func variantKey(value interface{}, variants map[string]interface{}) string {
+	for key, variant := range variants {
+		if variant == value {
+			return key
+		}
+	}
+	if value != nil {
+		if _, ok := variants[fmt.Sprint(value)]; ok {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// firstVariant is the default variant of flags without a default value
+func firstVariant(flag manifest.Flag) string {
+	if flag.Type == "Boolean" {
+		return "false"
+	}
+	if len(flag.Variants) > 0 {
+		return flag.Variants[0]
+	}
+	return ""
+}
+
+// fractional converts a percentage split ([{option, percentage}]) to flagd fractional targeting,
+// bucketing by the stickiness property (the targeting key by default). The variant with the
+// largest share is the default variant.
+func fractional(split []interface{}, stickinessProperty string, variants map[string]interface{}) (map[string]interface{}, string, error) {
+	var args []interface{}
+	if stickinessProperty != "" {
+		args = append(args, map[string]interface{}{
+			"cat": []interface{}{map[string]interface{}{"var": "$flagd.flagKey"}, map[string]interface{}{"var": stickinessProperty}},
+		})
+	}
+
+	// flagd weights are integers; keep two decimals of precision when needed
+	scale := 1.0
+	for _, entry := range split {
+		option, _ := entry.(map[string]interface{})
+		if percentage, ok := option["percentage"].(float64); ok && percentage != math.Trunc(percentage) {
+			scale = 100
+		}
+	}
+
+	defaultVariant := ""
+	largest := -1.0
+	for _, entry := range split {
+		option, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, "", fmt.Errorf("invalid percentage split")
+		}
+		key := variantKey(option["option"], variants)
+		if key == "" {
+			return nil, "", fmt.Errorf("percentage split serves unknown variant %v", option["option"])
+		}
+		percentage, _ := option["percentage"].(float64)
+		args = append(args, []interface{}{key, int(math.Round(percentage * scale))})
+		if percentage > largest {
+			defaultVariant, largest = key, percentage
+		}
+	}
+
+	return map[string]interface{}{"fractional": args}, defaultVariant, nil
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"gopkg.in/yaml.v3"
+)
+
+// NamingRules are the conventions flag names must follow, so flags can be found by name
+type NamingRules struct {
+	KebabCase         bool         `yaml:"kebabCase" json:"kebabCase,omitempty"`                 // Lowercase words separated by dashes
+	MaxLength         int          `yaml:"maxLength" json:"maxLength,omitempty"`                 // Longest name allowed (0 disables)
+	ForbiddenWords    []string     `yaml:"forbiddenWords" json:"forbiddenWords,omitempty"`       // Words names cannot contain, e.g. tmp or test
+	Teams             []TeamNaming `yaml:"teams" json:"teams,omitempty"`                         // Conventions of the flags of each team
+	RequireTeamPrefix bool         `yaml:"requireTeamPrefix" json:"requireTeamPrefix,omitempty"` // Every name starts with the prefix of a team
+}
+
+// TeamNaming is the convention of the flags whose name starts with the prefix of a team
+type TeamNaming struct {
+	Team    string `yaml:"team" json:"team,omitempty"`
+	Prefix  string `yaml:"prefix" json:"prefix"`
+	Pattern string `yaml:"pattern" json:"pattern,omitempty"` // Regular expression the names with the prefix must match
+}
+
+// Empty returns whether no naming rule is set
+func (r NamingRules) Empty() bool {
+	return !r.KebabCase && r.MaxLength == 0 && len(r.ForbiddenWords) == 0 && len(r.Teams) == 0 && !r.RequireTeamPrefix
+}
+
+// LoadNamingRules reads naming rules from a YAML file
+func LoadNamingRules(filename string) (NamingRules, error) {
+	var rules NamingRules
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return rules, fmt.Errorf("failed to read naming file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("failed to parse naming file: %w", err)
+	}
+	return rules, nil
+}
+
+var kebabCasePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// LintNames checks the name of every flag against the naming rules and returns all violations
+func LintNames(flags []cloudbees.Flag, rules NamingRules) ([]Violation, error) {
+	// The longest prefix wins, so a team can own a sub-prefix of another team
+	teams := append([]TeamNaming{}, rules.Teams...)
+	sort.SliceStable(teams, func(i, j int) bool { return len(teams[i].Prefix) > len(teams[j].Prefix) })
+	patterns := make([]*regexp.Regexp, len(teams))
+	prefixes := make([]string, len(rules.Teams))
+	for i, team := range teams {
+		if team.Prefix == "" {
+			return nil, fmt.Errorf("team '%s' has no prefix", team.Team)
+		}
+		if team.Pattern != "" {
+			var err error
+			patterns[i], err = regexp.Compile(team.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of prefix '%s': %w", team.Prefix, err)
+			}
+		}
+		prefixes[i] = team.Prefix
+	}
+	sort.Strings(prefixes)
+
+	var violations []Violation
+	for _, flag := range flags {
+		add := func(rule, format string, args ...interface{}) {
+			violations = append(violations, Violation{FlagName: flag.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if rules.KebabCase && !kebabCasePattern.MatchString(flag.Name) {
+			add("kebabCase", "name is not kebab-case (e.g. '%s')", KebabCase(flag.Name))
+		}
+		if rules.MaxLength > 0 && len(flag.Name) > rules.MaxLength {
+			add("maxLength", "name is %d characters long (maximum %d)", len(flag.Name), rules.MaxLength)
+		}
+		if forbidden := forbiddenWords(flag.Name, rules.ForbiddenWords); len(forbidden) > 0 {
+			add("forbiddenWords", "name contains the forbidden words %s", strings.Join(forbidden, ", "))
+		}
+
+		team := -1
+		for i := range teams {
+			if strings.HasPrefix(flag.Name, teams[i].Prefix) {
+				team = i
+				break
+			}
+		}
+		switch {
+		case team < 0 && rules.RequireTeamPrefix && len(prefixes) > 0:
+			add("teamPrefix", "name does not start with a team prefix (%s)", strings.Join(prefixes, ", "))
+		case team >= 0 && patterns[team] != nil && !patterns[team].MatchString(flag.Name):
+			owner := teams[team].Team
+			if owner == "" {
+				owner = teams[team].Prefix
+			}
+			add("teamPattern", "name does not match %s of team %s", teams[team].Pattern, owner)
+		}
+	}
+
+	return violations, nil
+}
+
+// KebabCase converts a name to kebab-case, e.g. newCheckout_Flow to new-checkout-flow
+func KebabCase(name string) string {
+	return strings.Join(nameWords(name), "-")
+}
+
+// nameWords splits a name into its lowercase words, at separators and camelCase boundaries
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		// A new word starts at an uppercase letter after a lowercase letter or digit, or before
+		// a lowercase letter at the end of an acronym (HTTPServer is http and server)
+		if unicode.IsUpper(r) && len(word) > 0 {
+			previous := runes[i-1]
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				words = append(words, string(word))
+				word = nil
+			}
+		}
+		word = append(word, unicode.ToLower(r))
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// forbiddenWords returns the forbidden words a name contains, as whole words in any case
+func forbiddenWords(name string, forbidden []string) []string {
+	if len(forbidden) == 0 {
+		return nil
+	}
+	words := map[string]bool{}
+	for _, word := range nameWords(name) {
+		words[word] = true
+	}
+	var found []string
+	for _, word := range forbidden {
+		if words[strings.ToLower(word)] {
+			found = append(found, word)
+		}
+	}
+	return found
+}
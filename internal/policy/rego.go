@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// RegoQuery is the rule evaluated in policy bundles. Policies deny a change by adding a message:
+//
+//	package fm
+//
+//	deny contains msg if {
+//	    input.operation == "set-flag-config"
+//	    input.environment == "production"
+//	    input.changes.enabled == true
+//	    "risky" in input.labels
+//	    input.ci
+//	    msg := sprintf("flag %s is labeled risky and cannot be enabled in production from CI", [input.flag])
+//	}
+const RegoQuery = "data.fm.deny"
+
+// EvaluateRego evaluates the Rego policies in dir with the opa binary and returns the deny messages
+func EvaluateRego(opaPath, dir string, input interface{}) ([]string, error) {
+	inputJSON, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(opaPath, "eval", "--format", "json", "--data", dir, "--stdin-input", RegoQuery)
+	cmd.Stdin = bytes.NewReader(inputJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to evaluate Rego policies with '%s': %v: %s", opaPath, err, stderr.String())
+	}
+
+	var output struct {
+		Result []struct {
+			Expressions []struct {
+				Value interface{} `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse opa output: %w", err)
+	}
+
+	var denials []string
+	for _, result := range output.Result {
+		for _, expression := range result.Expressions {
+			messages, ok := expression.Value.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, message := range messages {
+				denials = append(denials, fmt.Sprint(message))
+			}
+		}
+	}
+	return denials, nil
+}
@@ -0,0 +1,92 @@
+// Package policy evaluates organization rules for feature flags.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrViolation is returned when flags or planned changes violate a policy
+var ErrViolation = errors.New("policy violation")
+
+// Rules are the lifecycle rules every flag must satisfy
+type Rules struct {
+	MaxAgeDays              int    `yaml:"maxAgeDays" json:"maxAgeDays,omitempty"`                           // Temporary flags older than this are violations (0 disables)
+	NamePattern             string `yaml:"namePattern" json:"namePattern,omitempty"`                         // Regular expression flag names must match
+	RequireDescription      bool   `yaml:"requireDescription" json:"requireDescription,omitempty"`           // Every flag needs a description
+	RequireOwner            bool   `yaml:"requireOwner" json:"requireOwner,omitempty"`                       // Every flag needs an owner: label
+	TemporaryRequiresExpiry bool   `yaml:"temporaryRequiresExpiry" json:"temporaryRequiresExpiry,omitempty"` // Temporary flags need an expires: label
+}
+
+// Violation describes a rule broken by a flag
+type Violation struct {
+	Application string `json:"application,omitempty"` // Set when checking several applications
+	FlagName    string `json:"flagName"`
+	Rule        string `json:"rule"`
+	Message     string `json:"message"`
+}
+
+// LoadRules reads rules from a YAML file
+func LoadRules(filename string) (Rules, error) {
+	var rules Rules
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return rules, fmt.Errorf("failed to read policy file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return rules, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	return rules, nil
+}
+
+// Evaluate checks every flag against the rules and returns all violations
+func Evaluate(flags []cloudbees.Flag, rules Rules, now time.Time) ([]Violation, error) {
+	var namePattern *regexp.Regexp
+	if rules.NamePattern != "" {
+		var err error
+		namePattern, err = regexp.Compile(rules.NamePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namePattern: %w", err)
+		}
+	}
+
+	var violations []Violation
+	for _, flag := range flags {
+		add := func(rule, format string, args ...interface{}) {
+			violations = append(violations, Violation{FlagName: flag.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if namePattern != nil && !namePattern.MatchString(flag.Name) {
+			add("namePattern", "name does not match %s", rules.NamePattern)
+		}
+		if rules.RequireDescription && flag.Description == "" {
+			add("requireDescription", "description is missing")
+		}
+		if rules.RequireOwner && flag.Owner() == "" {
+			add("requireOwner", "owner is missing (add an %s label)", cloudbees.OwnerLabelPrefix)
+		}
+		if flag.IsPermanent {
+			continue
+		}
+		if rules.TemporaryRequiresExpiry {
+			if _, ok := flag.Expires(); !ok {
+				add("temporaryRequiresExpiry", "temporary flag has no expiry (add an %s label)", cloudbees.ExpiresLabelPrefix)
+			}
+		}
+		if rules.MaxAgeDays > 0 {
+			if created, err := time.Parse(time.RFC3339, flag.Created); err == nil {
+				if age := int(now.Sub(created).Hours() / 24); age > rules.MaxAgeDays {
+					add("maxAgeDays", "temporary flag is %d days old (maximum %d)", age, rules.MaxAgeDays)
+				}
+			}
+		}
+	}
+
+	return violations, nil
+}
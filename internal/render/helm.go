@@ -0,0 +1,115 @@
+package render
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmMapping maps dotted values paths (e.g. checkout.enabled) to the flags that set them
+type HelmMapping struct {
+	Values map[string]HelmValue `yaml:"values"`
+}
+
+// HelmValue is a values entry: the flag setting it, and the value used when the flag has no baked value
+type HelmValue struct {
+	Flag    string      `yaml:"flag"`
+	Default interface{} `yaml:"default"`
+}
+
+// UnmarshalYAML accepts a flag name as shorthand for an entry without default
+func (v *HelmValue) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&v.Flag)
+	}
+	type plain HelmValue
+	return node.Decode((*plain)(v))
+}
+
+// LoadHelmMapping reads a mapping file
+func LoadHelmMapping(filename string) (*HelmMapping, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+
+	var mapping HelmMapping
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file '%s': %w", filename, err)
+	}
+	for path, value := range mapping.Values {
+		if value.Flag == "" {
+			return nil, fmt.Errorf("mapping of '%s' has no flag", path)
+		}
+	}
+	return &mapping, nil
+}
+
+// HelmValues renders the mapped flag values as a values.yaml fragment. Values of flags without a
+// baked value (see FlagState.Effective) fall back to the mapping default, or are left out with a warning.
+func HelmValues(mapping *HelmMapping, states []FlagState) ([]byte, []string, error) {
+	byName := make(map[string]FlagState, len(states))
+	for _, state := range states {
+		byName[state.Name] = state
+	}
+
+	paths := make([]string, 0, len(mapping.Values))
+	for path := range mapping.Values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	values := map[string]interface{}{}
+	var warnings []string
+	for _, path := range paths {
+		entry := mapping.Values[path]
+		state, ok := byName[entry.Flag]
+		if !ok {
+			return nil, nil, fmt.Errorf("flag '%s' mapped to '%s' not found", entry.Flag, path)
+		}
+
+		value, ok := state.Effective()
+		if !ok {
+			if entry.Default == nil {
+				warnings = append(warnings, fmt.Sprintf("%s: flag %s has no baked value and no default, left out", path, entry.Flag))
+				continue
+			}
+			value = entry.Default
+		}
+		if err := setPath(values, strings.Split(path, "."), value); err != nil {
+			return nil, nil, fmt.Errorf("invalid values path '%s': %w", path, err)
+		}
+	}
+
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, warnings, nil
+}
+
+// setPath sets a value in nested maps, creating intermediate maps
+func setPath(values map[string]interface{}, path []string, value interface{}) error {
+	for _, key := range path[:len(path)-1] {
+		next, exists := values[key]
+		if !exists {
+			next = map[string]interface{}{}
+			values[key] = next
+		}
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("'%s' is already set to a value", key)
+		}
+		values = nested
+	}
+
+	last := path[len(path)-1]
+	if _, exists := values[last]; exists {
+		return fmt.Errorf("'%s' is already set", last)
+	}
+	values[last] = value
+	return nil
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kubernetes object kinds that can hold flag states
+const (
+	KindConfigMap = "ConfigMap"
+	KindSecret    = "Secret"
+)
+
+// StatesKey is the data key holding the states of all flags as JSON
+const StatesKey = "flags.json"
+
+// K8sObject describes the object to render
+type K8sObject struct {
+	Kind        string // ConfigMap or Secret
+	Name        string
+	Namespace   string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// k8sManifest is the YAML layout of the rendered object
+type k8sManifest struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name        string            `yaml:"name"`
+		Namespace   string            `yaml:"namespace,omitempty"`
+		Labels      map[string]string `yaml:"labels,omitempty"`
+		Annotations map[string]string `yaml:"annotations,omitempty"`
+	} `yaml:"metadata"`
+	Type       string            `yaml:"type,omitempty"`
+	Data       map[string]string `yaml:"data,omitempty"`
+	StringData map[string]string `yaml:"stringData,omitempty"`
+}
+
+// K8s renders the flag states as a ConfigMap or Secret manifest. Each flag with a baked value
+// has a key with its value, and flags.json holds the full state of every flag.
+func K8s(object K8sObject, states []FlagState) ([]byte, error) {
+	data := map[string]string{}
+	for _, state := range states {
+		if _, ok := state.Effective(); ok {
+			data[key(state.Name)] = state.String()
+		}
+	}
+	all, err := statesJSON(states)
+	if err != nil {
+		return nil, err
+	}
+	data[StatesKey] = all
+
+	var m k8sManifest
+	m.APIVersion = "v1"
+	m.Kind = object.Kind
+	m.Metadata.Name = object.Name
+	m.Metadata.Namespace = object.Namespace
+	m.Metadata.Labels = object.Labels
+	m.Metadata.Annotations = object.Annotations
+	switch object.Kind {
+	case KindConfigMap:
+		m.Data = data
+	case KindSecret:
+		m.Type = "Opaque"
+		m.StringData = data
+	default:
+		return nil, fmt.Errorf("invalid kind '%s', must be %s or %s", object.Kind, KindConfigMap, KindSecret)
+	}
+
+	return yaml.Marshal(m)
+}
@@ -0,0 +1,88 @@
+// Package render bakes the flag states of an environment into deployment artifacts,
+// for workloads that cannot reach the platform at runtime.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+)
+
+// FlagState is the value a flag serves in an environment
+type FlagState struct {
+	Name    string      `json:"-"`
+	Type    string      `json:"type"`
+	Enabled bool        `json:"enabled"`
+	Value   interface{} `json:"value"` // Default value, or a percentage split
+}
+
+// States returns the state of every flag of a manifest in an environment, in manifest order
+func States(m *manifest.Manifest, environment string) []FlagState {
+	states := make([]FlagState, 0, len(m.Flags))
+	for _, flag := range m.Flags {
+		config, ok := flag.Environments[environment]
+		if !ok {
+			continue
+		}
+		states = append(states, FlagState{Name: flag.Name, Type: flag.Type, Enabled: config.Enabled, Value: config.DefaultValue})
+	}
+	return states
+}
+
+// Static reports whether the flag serves a single value, which can be baked into an artifact.
+// Percentage splits need an SDK to assign users.
+func (s FlagState) Static() bool {
+	_, split := s.Value.([]interface{})
+	return !split
+}
+
+// Effective returns the value served by the flag, and false when it has no baked value:
+// disabled non-Boolean flags (applications use their coded default) and percentage splits
+func (s FlagState) Effective() (interface{}, bool) {
+	if !s.Enabled {
+		if s.Type == "Boolean" {
+			return false, true
+		}
+		return nil, false
+	}
+	if !s.Static() || s.Value == nil {
+		return nil, false
+	}
+	return s.Value, true
+}
+
+// String formats the effective value as a string: true/false, a number, or the string itself
+func (s FlagState) String() string {
+	value, ok := s.Effective()
+	if !ok {
+		return ""
+	}
+	if str, ok := value.(string); ok {
+		return str
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// invalidKeyChars are not allowed in ConfigMap keys and environment variable names
+var invalidKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// key returns a ConfigMap key for a flag name
+func key(name string) string {
+	return invalidKeyChars.ReplaceAllString(name, "_")
+}
+
+// statesJSON encodes the states as a JSON object by flag name
+func statesJSON(states []FlagState) (string, error) {
+	byName := make(map[string]FlagState, len(states))
+	for _, state := range states {
+		byName[state.Name] = state
+	}
+	data, err := json.MarshalIndent(byName, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode flag states: %w", err)
+	}
+	return string(data), nil
+}
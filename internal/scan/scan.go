@@ -0,0 +1,207 @@
+// Package scan searches source trees for references to feature flag names.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// maxFileSize is the largest file that is scanned; bigger files are assumed to be generated or binary
+const maxFileSize = 2 << 20
+
+// skippedDirs are directories that never contain first-party source code
+var skippedDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+}
+
+// Reference kinds
+const (
+	KindToken   = "token"   // The name appears as a whole token
+	KindLiteral = "literal" // The name appears as a complete string literal
+	KindSDK     = "sdk"     // The name is used in a feature flag SDK call or declaration
+)
+
+// Reference is a single occurrence of a flag name in a file
+type Reference struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+	Kind string `json:"kind,omitempty"`
+}
+
+// sdkPatterns match flag names in CloudBees (Rox) and OpenFeature SDK usage; the first group is the name
+var sdkPatterns = []*regexp.Regexp{
+	// OpenFeature and generic clients: client.getBooleanValue('checkout', false), isEnabled("checkout")
+	regexp.MustCompile(`(?:get(?:Boolean|String|Number|Integer|Float|Object)(?:Value|Details)|isEnabled|is_enabled|getValue|dynamicApi\.\w+)\(\s*["'` + "`" + `]([A-Za-z0-9_.\-]+)["'` + "`" + `]`),
+	// Rox container declarations: checkout = new Flag(), checkout: new RoxString('a', ['a', 'b'])
+	regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)\s*[:=]\s*new\s+(?:Rox)?(?:Flag|RoxFlag|String|Number|RoxString|RoxNumber)\b`),
+}
+
+// Result holds the references found by Scan
+type Result struct {
+	References map[string][]Reference `json:"references"` // Known flag name -> references
+	Unknown    map[string][]Reference `json:"unknown"`    // SDK usages of names that are not known flags
+}
+
+// FindReferences walks root and returns, for each flag name, every line that references it.
+// A name matches only as a whole token, so "checkout" does not match "checkout-v2".
+func FindReferences(root string, names []string) (map[string][]Reference, error) {
+	patterns := make(map[string]*regexp.Regexp, len(names))
+	for _, name := range names {
+		patterns[name] = regexp.MustCompile(`(^|[^A-Za-z0-9_\-])` + regexp.QuoteMeta(name) + `($|[^A-Za-z0-9_\-])`)
+	}
+
+	references := make(map[string][]Reference)
+	err := walkSourceFiles(root, func(rel string, line int, text string) {
+		for name, pattern := range patterns {
+			if pattern.MatchString(text) {
+				references[name] = append(references[name], Reference{File: rel, Line: line, Text: trim(text), Kind: KindToken})
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortReferences(references)
+	return references, nil
+}
+
+// Scan walks every path (directory or file) looking for flag names used as string literals
+// and in SDK calls. SDK usages of names that are not in names are reported as unknown.
+func Scan(paths []string, names []string) (*Result, error) {
+	known := make(map[string]bool, len(names))
+	literals := make(map[string]*regexp.Regexp, len(names))
+	for _, name := range names {
+		known[name] = true
+		literals[name] = regexp.MustCompile(`["'` + "`" + `]` + regexp.QuoteMeta(name) + `["'` + "`" + `]`)
+	}
+
+	result := &Result{
+		References: make(map[string][]Reference),
+		Unknown:    make(map[string][]Reference),
+	}
+
+	for _, root := range paths {
+		err := walkSourceFiles(root, func(rel string, line int, text string) {
+			found := make(map[string]bool)
+			for _, pattern := range sdkPatterns {
+				for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+					name := match[1]
+					if found[name] {
+						continue
+					}
+					found[name] = true
+					ref := Reference{File: rel, Line: line, Text: trim(text), Kind: KindSDK}
+					if known[name] {
+						result.References[name] = append(result.References[name], ref)
+					} else {
+						result.Unknown[name] = append(result.Unknown[name], ref)
+					}
+				}
+			}
+			for name, pattern := range literals {
+				if !found[name] && pattern.MatchString(text) {
+					result.References[name] = append(result.References[name], Reference{File: rel, Line: line, Text: trim(text), Kind: KindLiteral})
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sortReferences(result.References)
+	sortReferences(result.Unknown)
+	return result, nil
+}
+
+// Files returns the distinct files of a list of references, in order
+func Files(references []Reference) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, ref := range references {
+		if !seen[ref.File] {
+			seen[ref.File] = true
+			files = append(files, ref.File)
+		}
+	}
+	return files
+}
+
+// walkSourceFiles calls fn for every line of every text file below root. root may also be a single file.
+// File names passed to fn are relative to root, or the file name itself when root is a file.
+func walkSourceFiles(root string, fn func(rel string, line int, text string)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxFileSize {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			return nil // binary file
+		}
+
+		rel, _ := filepath.Rel(root, path)
+		if rel == "." {
+			rel = path
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+		for line := 1; scanner.Scan(); line++ {
+			fn(rel, line, scanner.Text())
+		}
+		return nil
+	})
+}
+
+// sortReferences orders each reference list by file and line
+func sortReferences(references map[string][]Reference) {
+	for name := range references {
+		refs := references[name]
+		sort.SliceStable(refs, func(i, j int) bool {
+			if refs[i].File != refs[j].File {
+				return refs[i].File < refs[j].File
+			}
+			return refs[i].Line < refs[j].Line
+		})
+	}
+}
+
+// trim shortens long lines for reports
+func trim(text string) string {
+	text = string(bytes.TrimSpace([]byte(text)))
+	if len(text) > 200 {
+		return text[:200] + "..."
+	}
+	return text
+}
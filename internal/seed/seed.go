@@ -0,0 +1,19 @@
+// Package seed bundles the example flags created by the seed command for demos and workshops.
+package seed
+
+import (
+	_ "embed"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/manifest"
+)
+
+// Label marks the flags created by the seed command, so unseed only deletes those
+const Label = "seed"
+
+//go:embed workshop.yaml
+var workshop []byte
+
+// Workshop returns the bundled manifest of example flags
+func Workshop() (*manifest.Manifest, error) {
+	return manifest.Parse("workshop.yaml", workshop)
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OTLP JSON encoding, see https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusCodeError  = 2
+
+	aggregationTemporalityCumulative = 2
+)
+
+func (p *Provider) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"attributes": attributes(map[string]interface{}{"service.name": p.serviceName}),
+	}
+}
+
+func scope() map[string]interface{} {
+	return map[string]interface{}{"name": "github.com/cloudbees-days/fm-actions-container"}
+}
+
+// tracesPayload encodes spans as an ExportTraceServiceRequest
+func (p *Provider) tracesPayload(spans []span) []byte {
+	encoded := make([]map[string]interface{}, 0, len(spans))
+	for _, s := range spans {
+		kind := spanKindClient
+		if s.spanID == p.rootSpanID {
+			kind = spanKindInternal
+		}
+		span := map[string]interface{}{
+			"traceId":           p.traceID,
+			"spanId":            s.spanID,
+			"name":              s.name,
+			"kind":              kind,
+			"startTimeUnixNano": unixNano(s.start),
+			"endTimeUnixNano":   unixNano(s.end),
+			"attributes":        attributes(s.attributes),
+		}
+		if s.parentID != "" {
+			span["parentSpanId"] = s.parentID
+		}
+		if s.failed {
+			span["status"] = map[string]interface{}{"code": statusCodeError}
+		}
+		encoded = append(encoded, span)
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource":   p.resource(),
+			"scopeSpans": []map[string]interface{}{{"scope": scope(), "spans": encoded}},
+		}},
+	})
+	return payload
+}
+
+// metricsPayload encodes the counters as monotonic cumulative sums in an ExportMetricsServiceRequest
+func (p *Provider) metricsPayload(command string, counters map[string]int64, now time.Time) []byte {
+	metrics := make([]map[string]interface{}, 0, len(counters))
+	for _, name := range []string{MetricRequests, MetricRetries, MetricFailures, MetricDroppedSpans} {
+		unit := "{request}"
+		if name == MetricDroppedSpans {
+			unit = "{span}"
+		}
+		metrics = append(metrics, map[string]interface{}{
+			"name": name,
+			"unit": unit,
+			"sum": map[string]interface{}{
+				"aggregationTemporality": aggregationTemporalityCumulative,
+				"isMonotonic":            true,
+				"dataPoints": []map[string]interface{}{{
+					"asInt":             fmt.Sprintf("%d", counters[name]),
+					"startTimeUnixNano": unixNano(p.start),
+					"timeUnixNano":      unixNano(now),
+					"attributes":        attributes(map[string]interface{}{"fm_actions.command": command}),
+				}},
+			},
+		})
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"resourceMetrics": []map[string]interface{}{{
+			"resource":     p.resource(),
+			"scopeMetrics": []map[string]interface{}{{"scope": scope(), "metrics": metrics}},
+		}},
+	})
+	return payload
+}
+
+// attributes encodes a map as a list of OTLP KeyValues
+func attributes(values map[string]interface{}) []map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(values))
+	for key, value := range values {
+		var v map[string]interface{}
+		switch value := value.(type) {
+		case int:
+			v = map[string]interface{}{"intValue": fmt.Sprintf("%d", value)}
+		case bool:
+			v = map[string]interface{}{"boolValue": value}
+		default:
+			v = map[string]interface{}{"stringValue": fmt.Sprint(value)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": v})
+	}
+	return encoded
+}
+
+// unixNano encodes a time as a string of nanoseconds, as 64-bit integers are in OTLP JSON
+func unixNano(t time.Time) string {
+	return fmt.Sprintf("%d", t.UnixNano())
+}
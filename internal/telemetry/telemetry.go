@@ -0,0 +1,328 @@
+// Package telemetry records OpenTelemetry traces and metrics of API calls and exports them
+// with the OTLP/HTTP JSON protocol, configured by the standard OTEL_* environment variables.
+// Spans are exported in batches while the command runs, so long bulk jobs show up in the
+// tracing backend before they end and memory stays bounded.
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudbees-days/fm-actions-container/internal/cloudbees"
+)
+
+// exportTimeout bounds each export
+const exportTimeout = 10 * time.Second
+
+// Batch span processor defaults of the OpenTelemetry specification
+const (
+	defaultScheduleDelay  = 5 * time.Second // OTEL_BSP_SCHEDULE_DELAY
+	defaultMaxQueueSize   = 2048            // OTEL_BSP_MAX_QUEUE_SIZE
+	defaultMaxExportBatch = 512             // OTEL_BSP_MAX_EXPORT_BATCH_SIZE
+)
+
+// Metric names
+const (
+	MetricRequests     = "fm_actions.api.requests"            // API requests, including retries
+	MetricRetries      = "fm_actions.api.retries"             // Requests that were retries of an earlier attempt
+	MetricFailures     = "fm_actions.api.failures"            // Requests that failed or returned an error status
+	MetricDroppedSpans = "fm_actions.telemetry.dropped_spans" // Spans dropped because the export queue was full
+)
+
+// span is a finished span
+type span struct {
+	spanID     string
+	parentID   string
+	name       string
+	start, end time.Time
+	attributes map[string]interface{}
+	failed     bool
+}
+
+// Provider collects spans and counters of one CLI run. All spans belong to one trace whose
+// root span is the command; the trace continues a TRACEPARENT from the environment, if any.
+// Finished spans are queued and exported every schedule delay or as soon as a batch is full;
+// spans arriving while the queue is full are dropped and counted.
+type Provider struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	httpClient  *http.Client
+
+	traceID      string
+	rootSpanID   string
+	rootParentID string
+	start        time.Time
+
+	scheduleDelay  time.Duration
+	maxQueueSize   int
+	maxExportBatch int
+	batchReady     chan struct{} // Wakes the exporter when a batch is full
+	stop           chan struct{}
+	stopped        chan struct{}
+
+	mu        sync.Mutex
+	spans     []span // Queued spans, not exported yet
+	counters  map[string]int64
+	exportErr error // First failed export of the background exporter
+}
+
+// FromEnv returns a provider if OTEL_EXPORTER_OTLP_ENDPOINT is set, or nil
+func FromEnv() *Provider {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return nil
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "fm-actions"
+	}
+
+	p := &Provider{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		headers:     parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		serviceName: serviceName,
+		httpClient:  &http.Client{Timeout: exportTimeout},
+		traceID:     randomID(16),
+		rootSpanID:  randomID(8),
+		start:       time.Now(),
+		counters:    map[string]int64{MetricRequests: 0, MetricRetries: 0, MetricFailures: 0, MetricDroppedSpans: 0},
+
+		scheduleDelay:  envDuration("OTEL_BSP_SCHEDULE_DELAY", defaultScheduleDelay),
+		maxQueueSize:   envInt("OTEL_BSP_MAX_QUEUE_SIZE", defaultMaxQueueSize),
+		maxExportBatch: envInt("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", defaultMaxExportBatch),
+		batchReady:     make(chan struct{}, 1),
+		stop:           make(chan struct{}),
+		stopped:        make(chan struct{}),
+	}
+	if p.maxExportBatch > p.maxQueueSize {
+		p.maxExportBatch = p.maxQueueSize
+	}
+
+	// Continue the trace of the calling pipeline (W3C trace context: version-traceid-parentid-flags)
+	if parts := strings.Split(os.Getenv("TRACEPARENT"), "-"); len(parts) == 4 && len(parts[1]) == 32 && len(parts[2]) == 16 {
+		p.traceID = parts[1]
+		p.rootParentID = parts[2]
+	}
+
+	go p.run()
+	return p
+}
+
+// run exports the queued spans every schedule delay, and full batches as soon as they are
+// queued, until Shutdown
+func (p *Provider) run() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(p.scheduleDelay)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.flush(true)
+		case <-p.batchReady:
+			p.flush(false)
+		}
+	}
+}
+
+// flush exports the queued spans in batches, or only the full batches unless all is set
+func (p *Provider) flush(all bool) error {
+	for {
+		p.mu.Lock()
+		n := len(p.spans)
+		if n > p.maxExportBatch {
+			n = p.maxExportBatch
+		}
+		if n == 0 || (!all && n < p.maxExportBatch) {
+			p.mu.Unlock()
+			return nil
+		}
+		batch := append([]span{}, p.spans[:n]...)
+		p.spans = p.spans[n:]
+		p.mu.Unlock()
+
+		if err := p.export("/v1/traces", p.tracesPayload(batch)); err != nil {
+			err = fmt.Errorf("failed to export traces: %w", err)
+			p.mu.Lock()
+			if p.exportErr == nil {
+				p.exportErr = err
+			}
+			p.mu.Unlock()
+			return err
+		}
+	}
+}
+
+// ObserveRequest implements cloudbees.RequestObserver by recording a client span per request
+func (p *Provider) ObserveRequest(attempt cloudbees.RequestAttempt) {
+	attributes := map[string]interface{}{
+		"http.request.method": attempt.Method,
+		"url.full":            attempt.URL,
+	}
+	if parsed, err := url.Parse(attempt.URL); err == nil {
+		attributes["server.address"] = parsed.Hostname()
+		attributes["url.path"] = parsed.Path
+	}
+	if attempt.RequestID != "" {
+		attributes["http.request.header.x-request-id"] = attempt.RequestID
+	}
+	if attempt.Attempt > 0 {
+		attributes["http.request.resend_count"] = attempt.Attempt
+	}
+
+	failed := attempt.Err != nil || attempt.StatusCode >= 400
+	if attempt.Err != nil {
+		attributes["error.type"] = fmt.Sprintf("%T", attempt.Err)
+	} else {
+		attributes["http.response.status_code"] = attempt.StatusCode
+		if failed {
+			attributes["error.type"] = fmt.Sprintf("%d", attempt.StatusCode)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.spans) < p.maxQueueSize {
+		p.spans = append(p.spans, span{
+			spanID:     randomID(8),
+			parentID:   p.rootSpanID,
+			name:       attempt.Method,
+			start:      attempt.Start,
+			end:        attempt.Start.Add(attempt.Duration),
+			attributes: attributes,
+			failed:     failed,
+		})
+		if len(p.spans) >= p.maxExportBatch {
+			select {
+			case p.batchReady <- struct{}{}:
+			default:
+			}
+		}
+	} else {
+		p.counters[MetricDroppedSpans]++
+	}
+	p.counters[MetricRequests]++
+	if attempt.Attempt > 0 {
+		p.counters[MetricRetries]++
+	}
+	if failed {
+		p.counters[MetricFailures]++
+	}
+}
+
+// Shutdown stops the background exporter, ends the root span for the command and exports the
+// remaining spans and the metrics
+func (p *Provider) Shutdown(command string, commandErr error) error {
+	close(p.stop)
+	<-p.stopped
+
+	p.mu.Lock()
+	root := span{
+		spanID:     p.rootSpanID,
+		parentID:   p.rootParentID,
+		name:       command,
+		start:      p.start,
+		end:        time.Now(),
+		attributes: map[string]interface{}{"fm_actions.command": command},
+		failed:     commandErr != nil,
+	}
+	if commandErr != nil {
+		root.attributes["error.message"] = commandErr.Error()
+	}
+	// The root span is queued even when the queue is full
+	p.spans = append([]span{root}, p.spans...)
+	counters := make(map[string]int64, len(p.counters))
+	for name, value := range p.counters {
+		counters[name] = value
+	}
+	exportErr := p.exportErr
+	p.mu.Unlock()
+
+	if err := p.flush(true); err != nil {
+		return err
+	}
+	if err := p.export("/v1/metrics", p.metricsPayload(command, counters, root.end)); err != nil {
+		return fmt.Errorf("failed to export metrics: %w", err)
+	}
+	if counters[MetricDroppedSpans] > 0 {
+		return fmt.Errorf("dropped %d spans because the telemetry export queue was full", counters[MetricDroppedSpans])
+	}
+	return exportErr
+}
+
+// export posts an OTLP JSON payload
+func (p *Provider) export(path string, payload []byte) error {
+	req, err := http.NewRequest("POST", p.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// envInt returns a positive integer environment variable, or def
+func envInt(name string, def int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return def
+}
+
+// envDuration returns a positive environment variable in milliseconds, or def
+func envDuration(name string, def time.Duration) time.Duration {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return time.Duration(value) * time.Millisecond
+	}
+	return def
+}
+
+// parseHeaders parses OTEL_EXPORTER_OTLP_HEADERS ("key1=value1,key2=value2", values URL-encoded)
+func parseHeaders(value string) map[string]string {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		if decoded, err := url.QueryUnescape(strings.TrimSpace(val)); err == nil {
+			val = decoded
+		}
+		headers[strings.TrimSpace(key)] = val
+	}
+	return headers
+}
+
+// randomID returns n random bytes as hex, as used for trace and span IDs
+func randomID(n int) string {
+	id := make([]byte, n)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,165 @@
+// Package workerpool runs multi-item operations (flags, environments, applications) concurrently
+// with per-item results and aggregate error reporting.
+package workerpool
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is used when no concurrency is configured
+const DefaultConcurrency = 4
+
+// Options controls how a pool processes items
+type Options struct {
+	Concurrency int  // Number of items processed in parallel
+	FailFast    bool // Stop dispatching new items after the first failure
+}
+
+// Result holds the outcome of processing a single item
+type Result[T any] struct {
+	Name    string `json:"name"`
+	Value   T      `json:"value,omitempty"`
+	Err     error  `json:"-"`
+	Error   string `json:"error,omitempty"`
+	Skipped bool   `json:"skipped,omitempty"` // Not processed because a fail-fast run was aborted
+}
+
+// Results holds per-item results in the same order as the input items
+type Results[T any] []Result[T]
+
+// Run processes items with fn using a pool of workers. name identifies each item in results and errors.
+func Run[I any, O any](items []I, name func(I) string, opts Options, fn func(I) (O, error)) Results[O] {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make(Results[O], len(items))
+	for i, item := range items {
+		results[i].Name = name(item)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		aborted bool
+		indexes = make(chan int)
+	)
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// An item handed over while another one failed is not started either
+				mu.Lock()
+				stop := aborted
+				mu.Unlock()
+				if stop {
+					results[i].Skipped = true
+					continue
+				}
+
+				value, err := fn(items[i])
+				results[i].Value = value
+				results[i].Err = err
+				if err != nil {
+					results[i].Error = err.Error()
+					if opts.FailFast {
+						mu.Lock()
+						aborted = true
+						mu.Unlock()
+					}
+				}
+			}
+		}()
+	}
+
+	for i := range items {
+		mu.Lock()
+		stop := aborted
+		mu.Unlock()
+		if stop {
+			for j := i; j < len(items); j++ {
+				results[j].Skipped = true
+			}
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+// Succeeded returns the number of items processed without error
+func (r Results[T]) Succeeded() int {
+	count := 0
+	for _, result := range r {
+		if !result.Skipped && result.Err == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns the number of items that returned an error
+func (r Results[T]) Failed() int {
+	count := 0
+	for _, result := range r {
+		if result.Err != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// Failure describes an item that returned an error
+type Failure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Failures returns the items that returned an error, in order
+func (r Results[T]) Failures() []Failure {
+	failures := []Failure{}
+	for _, result := range r {
+		if result.Err != nil {
+			failures = append(failures, Failure{Name: result.Name, Error: result.Err.Error()})
+		}
+	}
+	return failures
+}
+
+// Skipped returns the number of items not processed because of fail-fast
+func (r Results[T]) Skipped() int {
+	count := 0
+	for _, result := range r {
+		if result.Skipped {
+			count++
+		}
+	}
+	return count
+}
+
+// Err returns an aggregate error describing every failed item, or nil if all succeeded
+func (r Results[T]) Err() error {
+	var failures []string
+	for _, result := range r {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", result.Name, result.Err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%d of %d items failed", len(failures), len(r))
+	if skipped := r.Skipped(); skipped > 0 {
+		msg += fmt.Sprintf(" (%d skipped)", skipped)
+	}
+	return fmt.Errorf("%s:\n  %s", msg, strings.Join(failures, "\n  "))
+}
@@ -13,6 +13,6 @@ func main() {
 	godotenv.Load()
 
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
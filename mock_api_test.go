@@ -0,0 +1,534 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// mockAPI is an in-memory CloudBees Platform API used by offline tests
+type mockAPI struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	applications []map[string]interface{}
+	environments []map[string]interface{}
+	flags        []map[string]interface{}
+	configs      map[string]map[string]interface{} // keyed by flagID/environmentID
+	revisions    map[string]int
+	history      map[string][]map[string]interface{} // Configuration revisions keyed by flagID/environmentID, oldest first
+	impressions  []map[string]interface{}
+	properties   []map[string]interface{} // nil makes the properties endpoint return 404
+	requests     []string
+	queries      []string
+	headers      []http.Header
+	staleConfigs bool            // Accept configuration updates without applying them, like an edge that did not catch up
+	bulkConfigs  bool            // Serve the bulk configuration endpoint, which returns 404 otherwise
+	noByName     bool            // Answer 404 on the environment by-name endpoint, like older APIs
+	notModified  int             // Number of 304 responses to conditional configuration requests
+	locked       map[string]bool // Environment IDs, or flagID/environmentID keys, whose configuration updates are refused with 403
+	hidden       map[string]bool // Environment IDs whose configurations cannot be read, refused with 403
+	oidcSubject  string          // OIDC token the token exchange endpoint trades for oidc-api-token
+	validTokens  map[string]bool // API tokens accepted by the v1/v2 endpoints, which answer 401 to others; nil accepts any
+}
+
+// newMockAPI starts a mock API with one application (test-app), two environments
+// (development, production) and no flags
+func newMockAPI(t *testing.T) *mockAPI {
+	m := &mockAPI{
+		applications: []map[string]interface{}{
+			{"id": "app-1", "name": "test-app", "linkedEnvironmentIds": []string{"env-dev", "env-prod"}},
+		},
+		environments: []map[string]interface{}{
+			{"id": "env-dev", "name": "development"},
+			{"id": "env-prod", "name": "production"},
+		},
+		configs:   map[string]map[string]interface{}{},
+		revisions: map[string]int{},
+		history:   map[string][]map[string]interface{}{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/organizations/{org}/services", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("nameFilter")
+		if name == "" {
+			m.writeJSON(w, map[string]interface{}{"service": m.applications})
+			return
+		}
+		applications := []map[string]interface{}{}
+		if application := m.applicationBy("name", name); application != nil {
+			applications = append(applications, application)
+		}
+		m.writeJSON(w, map[string]interface{}{"service": applications})
+	})
+	mux.HandleFunc("GET /v1/organizations/{org}/services/{id}", func(w http.ResponseWriter, r *http.Request) {
+		application := m.applicationBy("id", r.PathValue("id"))
+		if application == nil {
+			http.Error(w, `{"message":"application not found"}`, http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"service": application})
+	})
+	mux.HandleFunc("POST /v1/organizations/{org}/services", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Service map[string]interface{} `json:"service"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		m.mu.Lock()
+		request.Service["id"] = fmt.Sprintf("app-%d", len(m.applications)+1)
+		m.applications = append(m.applications, request.Service)
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"service": request.Service})
+	})
+	mux.HandleFunc("PUT /v1/organizations/{org}/services/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Service map[string]interface{} `json:"service"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, application := range m.applications {
+			if application["id"] == r.PathValue("id") {
+				request.Service["id"] = application["id"]
+				m.applications[i] = request.Service
+				json.NewEncoder(w).Encode(map[string]interface{}{"service": request.Service})
+				return
+			}
+		}
+		http.Error(w, `{"message":"application not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /v2/organizations/{org}/environments", func(w http.ResponseWriter, r *http.Request) {
+		m.writeJSON(w, map[string]interface{}{"environments": m.environments})
+	})
+	mux.HandleFunc("GET /v2/organizations/{org}/environments/by-name/{name}", func(w http.ResponseWriter, r *http.Request) {
+		environment := m.environmentBy("name", r.PathValue("name"))
+		if environment == nil || m.noByName {
+			http.Error(w, `{"message":"environment not found"}`, http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"environment": environment})
+	})
+	mux.HandleFunc("POST /v2/organizations/{org}/environments", func(w http.ResponseWriter, r *http.Request) {
+		var environment map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&environment)
+		m.mu.Lock()
+		environment["id"] = fmt.Sprintf("env-%d", len(m.environments)+1)
+		m.environments = append(m.environments, environment)
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"environment": environment})
+	})
+	mux.HandleFunc("PUT /v2/organizations/{org}/environments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&fields)
+		environment := m.environmentBy("id", r.PathValue("id"))
+		if environment == nil {
+			http.Error(w, `{"message":"environment not found"}`, http.StatusNotFound)
+			return
+		}
+		m.mu.Lock()
+		for field, value := range fields {
+			environment[field] = value
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"environment": environment})
+	})
+	mux.HandleFunc("DELETE /v2/organizations/{org}/environments/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, environment := range m.environments {
+			if environment["id"] == r.PathValue("id") {
+				m.environments = append(m.environments[:i], m.environments[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.Error(w, `{"message":"environment not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags", func(w http.ResponseWriter, r *http.Request) {
+		// Flags without an applicationId belong to test-app
+		m.mu.Lock()
+		flags := []map[string]interface{}{}
+		for _, flag := range m.flags {
+			if app, ok := flag["applicationId"]; ok && app != r.PathValue("app") || !ok && r.PathValue("app") != "app-1" {
+				continue
+			}
+			flags = append(flags, flag)
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"flags": flags})
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags/impressions", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		impressions := []map[string]interface{}{}
+		for _, impression := range m.impressions {
+			if env := r.URL.Query().Get("environmentId"); env != "" && impression["environmentId"] != env {
+				continue
+			}
+			if flag := r.URL.Query().Get("flagId"); flag != "" && impression["flagId"] != flag {
+				continue
+			}
+			impressions = append(impressions, impression)
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"impressions": impressions})
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/properties", func(w http.ResponseWriter, r *http.Request) {
+		if m.properties == nil {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"properties": m.properties})
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags/by-name/{name}", func(w http.ResponseWriter, r *http.Request) {
+		flag := m.flagBy("name", r.PathValue("name"))
+		if flag == nil {
+			http.Error(w, `{"message":"flag not found"}`, http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"flag": flag})
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/casc/flags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		flag := m.flagBy("id", r.PathValue("id"))
+		if flag == nil {
+			http.Error(w, `{"message":"flag not found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		fmt.Fprintf(w, "kind: FeatureFlag\nmetadata:\n  name: %s\nspec:\n  type: %s\n", flag["name"], flag["flagType"])
+	})
+	mux.HandleFunc("POST /v2/applications/{app}/flags", func(w http.ResponseWriter, r *http.Request) {
+		var flag map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&flag)
+		m.mu.Lock()
+		flag["id"] = fmt.Sprintf("flag-%d", len(m.flags)+1)
+		if r.PathValue("app") != "app-1" {
+			flag["applicationId"] = r.PathValue("app")
+		}
+		m.flags = append(m.flags, flag)
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"flag": flag})
+	})
+	mux.HandleFunc("PUT /v2/applications/{app}/flags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var fields map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&fields)
+		flag := m.flagBy("id", r.PathValue("id"))
+		if flag == nil {
+			http.Error(w, `{"message":"flag not found"}`, http.StatusNotFound)
+			return
+		}
+		m.mu.Lock()
+		for field, value := range fields {
+			flag[field] = value
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"flag": flag})
+	})
+	mux.HandleFunc("DELETE /v2/applications/{app}/flags/{id}", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for i, flag := range m.flags {
+			if flag["id"] == r.PathValue("id") {
+				m.flags = append(m.flags[:i], m.flags[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.Error(w, `{"message":"flag not found"}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags/{id}/configuration/environments/{env}", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("id") + "/" + r.PathValue("env")
+		m.mu.Lock()
+		if m.hidden[r.PathValue("env")] {
+			m.mu.Unlock()
+			http.Error(w, `{"message":"forbidden"}`, http.StatusForbidden)
+			return
+		}
+		config := m.configs[key]
+		if config == nil {
+			config = map[string]interface{}{"enabled": false}
+		}
+		etag := fmt.Sprintf(`"v%d"`, m.revisions[key])
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			m.notModified++
+			m.mu.Unlock()
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		m.mu.Unlock()
+		response := map[string]interface{}{"configuration": config}
+		if flag := m.flagBy("id", r.PathValue("id")); flag != nil {
+			for _, field := range []string{"labels", "created", "updated"} {
+				if value, ok := flag[field]; ok {
+					response[field] = value
+				}
+			}
+			response["flagName"] = flag["name"]
+		}
+		m.writeJSON(w, response)
+	})
+	mux.HandleFunc("PUT /v2/applications/{app}/flags/{id}/configuration/environments/{env}", func(w http.ResponseWriter, r *http.Request) {
+		var changes map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&changes)
+		m.mu.Lock()
+		status := m.updateConfig(r.PathValue("id")+"/"+r.PathValue("env"), r.Header.Get("If-Match"), changes)
+		m.mu.Unlock()
+		if status != http.StatusOK {
+			http.Error(w, `{"message":"revision mismatch"}`, status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("PUT /v2/applications/{app}/flags/configurations", func(w http.ResponseWriter, r *http.Request) {
+		if !m.bulkConfigs {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		var request struct {
+			Configurations []struct {
+				FlagID        string                 `json:"flagId"`
+				EnvironmentID string                 `json:"environmentId"`
+				Configuration map[string]interface{} `json:"configuration"`
+				IfMatch       string                 `json:"ifMatch"`
+			} `json:"configurations"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		results := []map[string]interface{}{}
+		m.mu.Lock()
+		for _, update := range request.Configurations {
+			status := m.updateConfig(update.FlagID+"/"+update.EnvironmentID, update.IfMatch, update.Configuration)
+			results = append(results, map[string]interface{}{"flagId": update.FlagID, "environmentId": update.EnvironmentID, "status": status})
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"results": results})
+	})
+	mux.HandleFunc("DELETE /v2/applications/{app}/flags/{id}/configuration/environments/{env}", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.configs, r.PathValue("id")+"/"+r.PathValue("env"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags/{id}/configuration/environments/{env}/revisions", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("id") + "/" + r.PathValue("env")
+		m.mu.Lock()
+		revisions := []map[string]interface{}{}
+		for i := len(m.history[key]) - 1; i >= 0; i-- {
+			revisions = append(revisions, m.history[key][i])
+		}
+		m.mu.Unlock()
+		m.writeJSON(w, map[string]interface{}{"revisions": revisions})
+	})
+	mux.HandleFunc("GET /v2/applications/{app}/flags/{id}/configuration/environments/{env}/revisions/{revision}", func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("id") + "/" + r.PathValue("env")
+		m.mu.Lock()
+		var found map[string]interface{}
+		for _, revision := range m.history[key] {
+			if revision["revision"] == r.PathValue("revision") {
+				found = revision
+			}
+		}
+		m.mu.Unlock()
+		if found == nil {
+			http.Error(w, `{"message":"revision not found"}`, http.StatusNotFound)
+			return
+		}
+		m.writeJSON(w, found)
+	})
+	mux.HandleFunc("POST /v1/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:token-exchange" || r.FormValue("subject_token") != m.oidcSubject {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"access_token": "oidc-api-token", "token_type": "Bearer", "expires_in": 900})
+	})
+	mux.HandleFunc("GET /github/oidc", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer github-request-token" {
+			http.Error(w, `{"message":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		m.writeJSON(w, map[string]interface{}{"value": m.oidcSubject})
+	})
+
+	m.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.requests = append(m.requests, r.Method+" "+r.URL.Path)
+		m.queries = append(m.queries, r.URL.RawQuery)
+		m.headers = append(m.headers, r.Header.Clone())
+		rejected := m.validTokens != nil && r.URL.Path != "/v1/oauth/token" &&
+			!m.validTokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		m.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if rejected && strings.HasPrefix(r.URL.Path, "/v") {
+			http.Error(w, `{"message":"token expired"}`, http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// addFlag adds a flag to the mock and returns its ID
+func (m *mockAPI) addFlag(name, flagType string, labels ...string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id := fmt.Sprintf("flag-%d", len(m.flags)+1)
+	flag := map[string]interface{}{
+		"id":       id,
+		"name":     name,
+		"flagType": flagType,
+		"variants": []string{"true", "false"},
+	}
+	if len(labels) > 0 {
+		flag["labels"] = labels
+	}
+	m.flags = append(m.flags, flag)
+	return id
+}
+
+// setConfig sets the configuration of a flag in an environment
+func (m *mockAPI) setConfig(flagID, environmentID string, config map[string]interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := flagID + "/" + environmentID
+	m.configs[key] = config
+	m.revisions[key]++
+	m.recordRevision(key, "ui-user@example.com")
+}
+
+// recordRevision adds a copy of the current configuration to the history; the caller holds the lock
+func (m *mockAPI) recordRevision(key, actor string) {
+	config := map[string]interface{}{}
+	for field, value := range m.configs[key] {
+		config[field] = value
+	}
+	m.history[key] = append(m.history[key], map[string]interface{}{
+		"revision":      fmt.Sprintf("%d", m.revisions[key]),
+		"timestamp":     fmt.Sprintf("2025-06-01T10:%02d:00Z", m.revisions[key]),
+		"actor":         actor,
+		"configuration": config,
+	})
+}
+
+// addImpressions records evaluations of a flag variant in an environment
+func (m *mockAPI) addImpressions(flagID, environmentID, variant string, count int) {
+	flag := m.flagBy("id", flagID)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.impressions = append(m.impressions, map[string]interface{}{
+		"flagId":        flagID,
+		"flagName":      flag["name"],
+		"environmentId": environmentID,
+		"variant":       variant,
+		"count":         count,
+	})
+}
+
+// config returns the configuration of a flag in an environment
+func (m *mockAPI) config(flagID, environmentID string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.configs[flagID+"/"+environmentID]
+}
+
+// flagBy returns the first flag whose field equals value
+func (m *mockAPI) flagBy(field, value string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, flag := range m.flags {
+		if flag[field] == value {
+			return flag
+		}
+	}
+	return nil
+}
+
+// applicationBy returns the first application whose field equals value
+func (m *mockAPI) applicationBy(field, value string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, application := range m.applications {
+		if application[field] == value {
+			return application
+		}
+	}
+	return nil
+}
+
+// environmentBy returns the first environment whose field equals value
+func (m *mockAPI) environmentBy(field, value string) map[string]interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, environment := range m.environments {
+		if environment[field] == value {
+			return environment
+		}
+	}
+	return nil
+}
+
+// updateConfig applies configuration changes unless the configuration is locked or ifMatch is
+// not the current revision, and returns the response status. Like the platform, permissions are
+// checked before the revision. The caller holds the lock.
+func (m *mockAPI) updateConfig(key, ifMatch string, changes map[string]interface{}) int {
+	if m.locked[key] || m.locked[key[strings.Index(key, "/")+1:]] {
+		return http.StatusForbidden
+	}
+	if ifMatch != "" && ifMatch != fmt.Sprintf(`"v%d"`, m.revisions[key]) {
+		return http.StatusPreconditionFailed
+	}
+	if m.staleConfigs {
+		return http.StatusOK
+	}
+	if m.configs[key] == nil {
+		m.configs[key] = map[string]interface{}{"enabled": false}
+	}
+	for field, value := range changes {
+		m.configs[key][field] = value
+	}
+	m.revisions[key]++
+	m.recordRevision(key, "pipeline")
+	return http.StatusOK
+}
+
+// countRequests returns how many requests were made with the given method and path
+func (m *mockAPI) countRequests(methodAndPath string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, request := range m.requests {
+		if request == methodAndPath {
+			count++
+		}
+	}
+	return count
+}
+
+func (m *mockAPI) writeJSON(w io.Writer, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	json.NewEncoder(w).Encode(value)
+}
+
+// mockArgs returns the connection arguments for running a command against the mock
+func (m *mockAPI) mockArgs(args ...string) []string {
+	return append(args,
+		"--token=test-token",
+		"--org-id=test-org",
+		"--application-name=test-app",
+		"--api-url", m.URL)
+}